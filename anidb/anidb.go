@@ -0,0 +1,260 @@
+// Package anidb adds the watched episodes and ratings of proxer.me
+// watchlists to the MyList of an AniDB user via AniDB's UDP API, see
+// https://wiki.anidb.net/UDP_API_Definition.
+package anidb
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Bios-Marcel/proxerscrape"
+	"github.com/Bios-Marcel/proxerscrape/mapping"
+)
+
+// Address is the location of the AniDB UDP API.
+const Address = "api.anidb.net:9000"
+
+// protocolVersion is the version of the UDP API the client speaks.
+const protocolVersion = 3
+
+var (
+	// ErrLoginFailed is returned if the username or password is wrong.
+	ErrLoginFailed = errors.New("anidb login failed")
+	// ErrNotLoggedIn is returned if the session is missing or has expired.
+	ErrNotLoggedIn = errors.New("not logged in to anidb")
+	// ErrBanned is returned if AniDB banned the client, usually for
+	// exceeding the ratelimit.
+	ErrBanned = errors.New("banned by anidb")
+)
+
+// Client talks to the AniDB UDP API on behalf of a user. Applications have
+// to be registered at https://anidb.net/software/add for obtaining a client
+// name and version.
+type Client struct {
+	ClientName    string
+	ClientVersion int
+	// Address defaults to Address.
+	Address string
+	// Timeout is how long to wait for a reply, 30 seconds if 0.
+	Timeout time.Duration
+	// Limiter keeps us below AniDB's ratelimit of one packet every four
+	// seconds, exceeding it gets the client banned. If nil, requests aren't
+	// limited.
+	Limiter *proxerscrape.Limiter
+
+	conn    net.Conn
+	session string
+}
+
+// NewClient creates a client that respects AniDB's ratelimit.
+func NewClient(clientName string, clientVersion int) *Client {
+	return &Client{
+		ClientName:    clientName,
+		ClientVersion: clientVersion,
+		Limiter:       proxerscrape.NewLimiter(1, 4*time.Second),
+	}
+}
+
+// reply is the status line of the response to a command.
+type reply struct {
+	code int
+	text string
+}
+
+// encodeValue escapes a parameter value, as AniDB uses HTML entities instead
+// of URL encoding.
+func encodeValue(value string) string {
+	value = strings.ReplaceAll(value, "&", "&amp;")
+	return strings.ReplaceAll(value, "\n", "<br />")
+}
+
+// request sends a command with the given parameters, which are alternating
+// keys and values, and waits for the reply. The session is appended for all
+// commands but AUTH.
+func (client *Client) request(command string, parameters ...string) (reply, error) {
+	if command != "AUTH" {
+		if client.session == "" {
+			return reply{}, ErrNotLoggedIn
+		}
+		parameters = append(parameters, "s", client.session)
+	}
+	if client.conn == nil {
+		address := client.Address
+		if address == "" {
+			address = Address
+		}
+		conn, err := net.Dial("udp", address)
+		if err != nil {
+			return reply{}, err
+		}
+		client.conn = conn
+	}
+
+	var builder strings.Builder
+	builder.WriteString(command)
+	for index := 0; index+1 < len(parameters); index += 2 {
+		if index == 0 {
+			builder.WriteByte(' ')
+		} else {
+			builder.WriteByte('&')
+		}
+		builder.WriteString(parameters[index] + "=" + encodeValue(parameters[index+1]))
+	}
+
+	if client.Limiter != nil {
+		client.Limiter.Wait()
+	}
+	if _, err := client.conn.Write([]byte(builder.String())); err != nil {
+		return reply{}, err
+	}
+	timeout := client.Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	if err := client.conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return reply{}, err
+	}
+	buffer := make([]byte, 1400)
+	length, err := client.conn.Read(buffer)
+	if err != nil {
+		return reply{}, fmt.Errorf("anidb didn't reply to %s: %w", command, err)
+	}
+
+	line := strings.SplitN(string(buffer[:length]), "\n", 2)[0]
+	status := strings.SplitN(line, " ", 2)
+	code, err := strconv.Atoi(status[0])
+	if err != nil {
+		return reply{}, fmt.Errorf("invalid anidb reply '%s'", line)
+	}
+	result := reply{code: code}
+	if len(status) > 1 {
+		result.text = status[1]
+	}
+	switch {
+	case code == 501 || code == 506:
+		client.session = ""
+		return result, ErrNotLoggedIn
+	case code == 555:
+		return result, ErrBanned
+	case code >= 500:
+		return result, fmt.Errorf("anidb command %s failed: %d %s", command, code, result.text)
+	}
+	return result, nil
+}
+
+// Login starts a session, which is required for all other commands.
+func (client *Client) Login(username, password string) error {
+	result, err := client.request("AUTH",
+		"user", username,
+		"pass", password,
+		"protover", strconv.Itoa(protocolVersion),
+		"client", client.ClientName,
+		"clientver", strconv.Itoa(client.ClientVersion))
+	if err != nil {
+		if result.code == 500 {
+			return ErrLoginFailed
+		}
+		return err
+	}
+	// 201 additionally announces a new client version.
+	if result.code != 200 && result.code != 201 {
+		return fmt.Errorf("unexpected anidb reply to AUTH: %d %s", result.code, result.text)
+	}
+	client.session = strings.SplitN(result.text, " ", 2)[0]
+	return nil
+}
+
+// Close ends the session, if there is one, and closes the connection.
+func (client *Client) Close() error {
+	if client.conn == nil {
+		return nil
+	}
+	var err error
+	if client.session != "" {
+		_, err = client.request("LOGOUT")
+		client.session = ""
+	}
+	if errClose := client.conn.Close(); err == nil {
+		err = errClose
+	}
+	client.conn = nil
+	return err
+}
+
+// AddWatched adds the episode of the anime to the MyList as watched. Since
+// proxer.me doesn't know which files were watched, a generic file is added.
+// Episodes already in the MyList are marked as watched instead.
+func (client *Client) AddWatched(aid string, episode int) error {
+	parameters := []string{"aid", aid, "generic", "1", "epno", strconv.Itoa(episode), "viewed", "1"}
+	result, err := client.request("MYLISTADD", parameters...)
+	if err != nil {
+		return err
+	}
+	// 310 FILE ALREADY IN MYLIST
+	if result.code == 310 {
+		result, err = client.request("MYLISTADD", append(parameters, "edit", "1")...)
+		if err != nil {
+			return err
+		}
+	}
+	// 210 MYLIST ENTRY ADDED, 311 MYLIST ENTRY EDITED
+	if result.code != 210 && result.code != 311 {
+		return fmt.Errorf("adding episode %d of anime %s to the anidb mylist failed: %d %s", episode, aid, result.code, result.text)
+	}
+	return nil
+}
+
+// Vote rates the anime, where the rating goes from 1 to 10.
+func (client *Client) Vote(aid string, rating int) error {
+	// AniDB votes go from 100 to 1000.
+	result, err := client.request("VOTE", "type", "1", "id", aid, "value", strconv.Itoa(rating*100))
+	if err != nil {
+		return err
+	}
+	// 260 VOTED, 261 VOTE UPDATED
+	if result.code != 260 && result.code != 261 {
+		return fmt.Errorf("voting for anime %s on anidb failed: %d %s", aid, result.code, result.text)
+	}
+	return nil
+}
+
+// Sync adds the watched episodes of all mapped anime of the watchlist to the
+// MyList and votes for those rated on proxer.me. The MyList only tracks
+// episodes, so entries without watched episodes aren't added. Each episode
+// takes a request, which at AniDB's ratelimit takes four seconds. The client
+// has to be logged in, see Login. Entries without a mapping are returned, see
+// 'map resolve --service anidb', just like those that aren't exportable, see
+// proxerscrape.Media.Exportable.
+func (client *Client) Sync(watchlist *proxerscrape.Watchlist, store *mapping.Store) ([]*proxerscrape.Media, error) {
+	var skipped []*proxerscrape.Media
+	for _, item := range watchlist.All() {
+		if !item.Type.IsAnime() {
+			continue
+		}
+		if !item.Exportable() {
+			skipped = append(skipped, item)
+			continue
+		}
+		aniDBMapping, present := store.Get(item.ProxerID(), mapping.ServiceAniDB)
+		if !present {
+			skipped = append(skipped, item)
+			continue
+		}
+
+		for episode := 1; episode <= int(item.EpisodesWatched); episode++ {
+			if err := client.AddWatched(aniDBMapping.ExternalID, episode); err != nil {
+				return skipped, err
+			}
+		}
+		if item.UserRating > 0 {
+			if err := client.Vote(aniDBMapping.ExternalID, int(item.UserRating)); err != nil {
+				return skipped, err
+			}
+		}
+	}
+	return skipped, nil
+}
@@ -0,0 +1,121 @@
+package anidb
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Bios-Marcel/proxerscrape"
+	"github.com/Bios-Marcel/proxerscrape/mapping"
+)
+
+// serve answers each received command via respond and records it.
+func serve(t *testing.T, respond func(command string) string) (string, func() []string) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	var lock sync.Mutex
+	var commands []string
+	go func() {
+		buffer := make([]byte, 1400)
+		for {
+			length, address, err := conn.ReadFrom(buffer)
+			if err != nil {
+				return
+			}
+			command := string(buffer[:length])
+			lock.Lock()
+			commands = append(commands, command)
+			lock.Unlock()
+			conn.WriteTo([]byte(respond(command)+"\n"), address)
+		}
+	}()
+	return conn.LocalAddr().String(), func() []string {
+		lock.Lock()
+		defer lock.Unlock()
+		return commands
+	}
+}
+
+func TestSync(t *testing.T) {
+	address, commands := serve(t, func(command string) string {
+		switch {
+		case strings.HasPrefix(command, "AUTH "):
+			return "200 session LOGIN ACCEPTED"
+		case strings.HasPrefix(command, "MYLISTADD aid=2&generic=1&epno=1&viewed=1&s="):
+			return "310 FILE ALREADY IN MYLIST"
+		case strings.HasPrefix(command, "MYLISTADD") && strings.Contains(command, "&edit=1"):
+			return "311 MYLIST ENTRY EDITED"
+		case strings.HasPrefix(command, "MYLISTADD"):
+			return "210 MYLIST ENTRY ADDED"
+		case strings.HasPrefix(command, "VOTE"):
+			return "260 VOTED"
+		case strings.HasPrefix(command, "LOGOUT"):
+			return "203 LOGGED OUT"
+		}
+		return "598 UNKNOWN COMMAND"
+	})
+
+	watchlist := proxerscrape.Watchlist{}
+	watchlist.Watched.Data = []*proxerscrape.Media{
+		{ProxerURL: "/info/1", Title: "Rated", Type: proxerscrape.MediaTypeSeries, EpisodesWatched: 2, UserRating: 8},
+		{ProxerURL: "/info/3", Title: "Unmapped", Type: proxerscrape.MediaTypeSeries, EpisodesWatched: 1},
+		{ProxerURL: "/info/4", Title: "Gone", Type: proxerscrape.MediaTypeSeries, Unavailable: true},
+		{ProxerURL: "/info/5", Title: "Manga", Type: proxerscrape.MediaTypeManga, EpisodesWatched: 1},
+	}
+	watchlist.CurrentlyWatching.Data = []*proxerscrape.Media{
+		{ProxerURL: "/info/2", Title: "Watching", Type: proxerscrape.MediaTypeSeries, EpisodesWatched: 1},
+	}
+	store := &mapping.Store{}
+	store.Set("1", mapping.ServiceAniDB, mapping.Mapping{ExternalID: "1"})
+	store.Set("2", mapping.ServiceAniDB, mapping.Mapping{ExternalID: "2"})
+	store.Set("5", mapping.ServiceAniDB, mapping.Mapping{ExternalID: "5"})
+
+	client := &Client{ClientName: "proxerscrape", ClientVersion: 1, Address: address}
+	if _, err := client.Sync(&watchlist, store); !errors.Is(err, ErrNotLoggedIn) {
+		t.Errorf("Expected ErrNotLoggedIn, got %v", err)
+	}
+	if err := client.Login("user", "pa&ss"); err != nil {
+		t.Fatal(err)
+	}
+	skipped, err := client.Sync(&watchlist, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skipped) != 2 || skipped[0].Title != "Unmapped" || skipped[1].Title != "Gone" {
+		t.Errorf("Unexpected skipped entries: %v", skipped)
+	}
+	if err := client.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"AUTH user=user&pass=pa&amp;ss&protover=3&client=proxerscrape&clientver=1",
+		"MYLISTADD aid=1&generic=1&epno=1&viewed=1&s=session",
+		"MYLISTADD aid=1&generic=1&epno=2&viewed=1&s=session",
+		"VOTE type=1&id=1&value=800&s=session",
+		"MYLISTADD aid=2&generic=1&epno=1&viewed=1&s=session",
+		"MYLISTADD aid=2&generic=1&epno=1&viewed=1&edit=1&s=session",
+		"LOGOUT s=session",
+	}
+	if received := commands(); !reflect.DeepEqual(received, expected) {
+		t.Errorf("Unexpected commands:\n%s", strings.Join(received, "\n"))
+	}
+}
+
+func TestLogin_Failed(t *testing.T) {
+	address, _ := serve(t, func(command string) string {
+		return "500 LOGIN FAILED"
+	})
+	client := &Client{Address: address}
+	if err := client.Login("user", "wrong"); !errors.Is(err, ErrLoginFailed) {
+		t.Errorf("Expected ErrLoginFailed, got %v", err)
+	}
+	client.Close()
+}
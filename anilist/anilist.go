@@ -0,0 +1,309 @@
+// Package anilist pushes proxer.me watchlists to AniList via its GraphQL API.
+// Unlike exporting to XML, this allows keeping both lists in sync.
+package anilist
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Bios-Marcel/proxerscrape"
+	"github.com/Bios-Marcel/proxerscrape/mapping"
+	"github.com/Bios-Marcel/proxerscrape/reconcile"
+)
+
+// Endpoint is the GraphQL endpoint of AniList.
+const Endpoint = "https://graphql.anilist.co"
+
+// maxRetries is how often a request is retried after AniList responded with
+// 429 Too Many Requests.
+const maxRetries = 3
+
+// defaultRetryAfter is how long we wait after a 429 response that lacks a
+// usable Retry-After header. AniList's ratelimit window is a minute long.
+const defaultRetryAfter = time.Minute
+
+// defaultHTTPClient is used if Client.HTTPClient is nil.
+var defaultHTTPClient = &http.Client{Timeout: proxerscrape.DefaultTimeout}
+
+// ErrUnauthorized is returned if the token is invalid or has expired.
+var ErrUnauthorized = errors.New("anilist token is invalid or expired")
+
+// Client talks to the AniList API on behalf of a user.
+type Client struct {
+	// Token is the OAuth access token of the user, see
+	// https://anilist.co/settings/developer.
+	Token string
+	// HTTPClient defaults to a client with proxerscrape.DefaultTimeout.
+	HTTPClient *http.Client
+	// Endpoint defaults to Endpoint.
+	Endpoint string
+	// Limiter keeps us below the ratelimit of AniList. If nil, only the
+	// Retry-After header of rejected requests is respected.
+	Limiter *proxerscrape.Limiter
+}
+
+// NewClient creates a client that stays below the documented ratelimit of 90
+// requests per minute.
+func NewClient(token string) *Client {
+	return &Client{
+		Token:   token,
+		Limiter: proxerscrape.NewLimiter(90, time.Minute),
+	}
+}
+
+type graphQLError struct {
+	Message string `json:"message"`
+}
+
+// query executes a GraphQL query and decodes the data into result.
+func (client *Client) query(query string, variables map[string]any, result any) error {
+	body, err := json.Marshal(map[string]any{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return err
+	}
+
+	httpClient := client.HTTPClient
+	if httpClient == nil {
+		httpClient = defaultHTTPClient
+	}
+	endpoint := client.Endpoint
+	if endpoint == "" {
+		endpoint = Endpoint
+	}
+
+	for attempt := 0; ; attempt++ {
+		if client.Limiter != nil {
+			client.Limiter.Wait()
+		}
+		request, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Accept", "application/json")
+		request.Header.Set("Authorization", "Bearer "+client.Token)
+		response, err := httpClient.Do(request)
+		if err != nil {
+			return err
+		}
+
+		if response.StatusCode == http.StatusTooManyRequests && attempt < maxRetries {
+			response.Body.Close()
+			time.Sleep(retryAfter(response.Header))
+			continue
+		}
+
+		err = decodeResponse(response, result)
+		response.Body.Close()
+		return err
+	}
+}
+
+// retryAfter returns how long to wait according to the Retry-After header,
+// which is either a number of seconds or a date.
+func retryAfter(header http.Header) time.Duration {
+	value := header.Get("Retry-After")
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && time.Until(date) > 0 {
+		return time.Until(date)
+	}
+	return defaultRetryAfter
+}
+
+func decodeResponse(response *http.Response, result any) error {
+	if response.StatusCode == http.StatusUnauthorized {
+		return ErrUnauthorized
+	}
+
+	var envelope struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []graphQLError  `json:"errors"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("anilist request failed with status %d: %w", response.StatusCode, err)
+	}
+	if len(envelope.Errors) > 0 {
+		messages := make([]string, 0, len(envelope.Errors))
+		for _, graphQLError := range envelope.Errors {
+			messages = append(messages, graphQLError.Message)
+		}
+		return fmt.Errorf("anilist request failed with status %d: %s", response.StatusCode, strings.Join(messages, "; "))
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("anilist request failed with status %d", response.StatusCode)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(envelope.Data, result)
+}
+
+// Status is the list status of an entry on AniList.
+type Status string
+
+const (
+	StatusCurrent   Status = "CURRENT"
+	StatusPlanning  Status = "PLANNING"
+	StatusCompleted Status = "COMPLETED"
+	StatusDropped   Status = "DROPPED"
+)
+
+// statuses translates proxer categories into the AniList status.
+var statuses = map[proxerscrape.ListCategory]Status{
+	proxerscrape.CategoryWatched:           StatusCompleted,
+	proxerscrape.CategoryCurrentlyWatching: StatusCurrent,
+	proxerscrape.CategoryToWatch:           StatusPlanning,
+	proxerscrape.CategoryStoppedWatching:   StatusDropped,
+}
+
+// Entry is the state of a list entry on AniList.
+type Entry struct {
+	Status   Status
+	Progress uint16
+	// Score is the rating on a scale from 1 to 10, 0 meaning unrated.
+	// Unrated entries keep their score on AniList.
+	Score uint8
+	// Repeat is how often the entry has been rewatched. 0 keeps the repeat
+	// count on AniList.
+	Repeat uint16
+	// Notes are the user's notes on the entry. Empty notes are left
+	// untouched on AniList.
+	Notes string
+}
+
+const saveEntryMutation = `mutation ($mediaId: Int, $status: MediaListStatus, $progress: Int, $scoreRaw: Int, $repeat: Int, $notes: String) {
+	SaveMediaListEntry(mediaId: $mediaId, status: $status, progress: $progress, scoreRaw: $scoreRaw, repeat: $repeat, notes: $notes) {
+		id
+	}
+}`
+
+// SaveEntry creates or updates the list entry of the given AniList media.
+func (client *Client) SaveEntry(mediaID string, entry Entry) error {
+	id, err := strconv.Atoi(mediaID)
+	if err != nil {
+		return fmt.Errorf("invalid anilist id '%s': %w", mediaID, err)
+	}
+	variables := map[string]any{
+		"mediaId":  id,
+		"status":   entry.Status,
+		"progress": entry.Progress,
+	}
+	// Omitted variables don't change the entry, while null or 0 would clear
+	// it. Entries that are unrated or were never rewatched on proxer.me
+	// therefore keep their score and repeat count on AniList.
+	if entry.Score > 0 {
+		// The raw score is always on a scale of 100, independent of the
+		// score format configured by the user.
+		variables["scoreRaw"] = int(entry.Score) * 10
+	}
+	if entry.Repeat > 0 {
+		variables["repeat"] = entry.Repeat
+	}
+	if entry.Notes != "" {
+		variables["notes"] = entry.Notes
+	}
+	return client.query(saveEntryMutation, variables, nil)
+}
+
+const saveDetailsMutation = `mutation ($mediaId: Int, $repeat: Int, $notes: String) {
+	SaveMediaListEntry(mediaId: $mediaId, repeat: $repeat, notes: $notes) {
+		id
+	}
+}`
+
+// saveDetails sets the repeat count and notes of an existing list entry,
+// leaving everything else untouched. Zero values aren't sent, see Entry.
+func (client *Client) saveDetails(mediaID string, repeat uint16, notes string) error {
+	id, err := strconv.Atoi(mediaID)
+	if err != nil {
+		return fmt.Errorf("invalid anilist id '%s': %w", mediaID, err)
+	}
+	variables := map[string]any{"mediaId": id}
+	if repeat > 0 {
+		variables["repeat"] = repeat
+	}
+	if notes != "" {
+		variables["notes"] = notes
+	}
+	return client.query(saveDetailsMutation, variables, nil)
+}
+
+// Sync pushes the status, progress and score of all anime in the watchlist
+// to AniList. The changes are planned just like 'sync anilist --direction
+// push', see reconcile.PlanSync, so only differing fields are changed and
+// scores are never cleared. Entries without an AniList mapping and those
+// that aren't exportable, see proxerscrape.Media.Exportable, are skipped and
+// returned. Reviews and rewatches, which the plan doesn't cover, are set
+// afterwards for entries where they differ from AniList. Rewatches are taken
+// from the user data, which may be nil.
+func (client *Client) Sync(watchlist *proxerscrape.Watchlist, store *mapping.Store, userData *proxerscrape.UserData) ([]*proxerscrape.Media, error) {
+	var skipped []*proxerscrape.Media
+	var synced proxerscrape.Watchlist
+	for _, category := range proxerscrape.ListCategories {
+		target := synced.Category(category)
+		for _, item := range watchlist.Category(category).Data {
+			if !item.Type.IsAnime() {
+				continue
+			}
+			if !item.Exportable() {
+				skipped = append(skipped, item)
+				continue
+			}
+			target.Data = append(target.Data, item)
+		}
+	}
+
+	remote, err := client.List()
+	if err != nil {
+		return skipped, err
+	}
+	report := reconcile.Reconcile(&synced, remote, store, mapping.ServiceAniList)
+	skipped = append(skipped, report.Unmapped...)
+	// Pushing never changes proxer.me, so there's no need for a writer.
+	plan := reconcile.PlanSync(report, store, reconcile.DirectionPush)
+	if _, err := plan.Execute(nil, client); err != nil {
+		return skipped, err
+	}
+
+	remoteByID := make(map[string]reconcile.RemoteEntry, len(remote))
+	for _, entry := range remote {
+		remoteByID[entry.ExternalID] = entry
+	}
+	for _, item := range synced.All() {
+		aniListMapping, present := store.Get(item.ProxerID(), mapping.ServiceAniList)
+		if !present {
+			continue
+		}
+		// Like SaveEntry, zero values keep what's on AniList.
+		current := remoteByID[aniListMapping.ExternalID]
+		var repeat uint16
+		if userData != nil {
+			repeat = userData.Rewatched(item)
+		}
+		if repeat == current.Repeat {
+			repeat = 0
+		}
+		notes := item.Review
+		if notes == current.Notes {
+			notes = ""
+		}
+		if repeat == 0 && notes == "" {
+			continue
+		}
+		if err := client.saveDetails(aniListMapping.ExternalID, repeat, notes); err != nil {
+			return skipped, fmt.Errorf("error syncing '%s': %w", item.Title, err)
+		}
+	}
+	return skipped, nil
+}
@@ -0,0 +1,124 @@
+package anilist
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Bios-Marcel/proxerscrape"
+	"github.com/Bios-Marcel/proxerscrape/mapping"
+)
+
+func TestSync(t *testing.T) {
+	var saved []map[string]any
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requests++
+		if request.Header.Get("Authorization") != "Bearer token" {
+			writer.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		// The first request is rejected, in order to test the retry.
+		if requests == 1 {
+			writer.Header().Set("Retry-After", "0")
+			writer.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		var body struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		switch {
+		case strings.Contains(body.Query, "Viewer"):
+			writer.Write([]byte(`{"data":{"Viewer":{"id":42}}}`))
+		case strings.Contains(body.Query, "MediaListCollection"):
+			writer.Write([]byte(`{"data":{"MediaListCollection":{"lists":[{"entries":[
+				{"mediaId":101573,"status":"CURRENT","progress":5,"score":6,"notes":"Unchanged","media":{"title":{"userPreferred":"Tsurune"}}},
+				{"mediaId":21,"status":"COMPLETED","progress":12,"score":9,"media":{"title":{"userPreferred":"Unrated"}}}
+			]}]}}}`))
+		default:
+			saved = append(saved, body.Variables)
+			writer.Write([]byte(`{"data":{"SaveMediaListEntry":{"id":1}}}`))
+		}
+	}))
+	defer server.Close()
+
+	watchlist := proxerscrape.Watchlist{}
+	watchlist.Watched.Data = []*proxerscrape.Media{
+		{ProxerURL: "/info/1", Title: "Unrated", Type: proxerscrape.MediaTypeSeries, Category: proxerscrape.CategoryWatched, EpisodesWatched: 12, Review: "Great"},
+		{ProxerURL: "/info/2", Title: "Gone", Type: proxerscrape.MediaTypeSeries, Category: proxerscrape.CategoryWatched, Unavailable: true},
+	}
+	watchlist.CurrentlyWatching.Data = []*proxerscrape.Media{
+		{ProxerURL: "/info/296", Title: "Tsurune", Type: proxerscrape.MediaTypeSeries, Category: proxerscrape.CategoryCurrentlyWatching, EpisodesWatched: 5, UserRating: 8, Review: "Unchanged"},
+		{ProxerURL: "/info/297", Title: "Unmapped", Type: proxerscrape.MediaTypeSeries},
+	}
+	store := &mapping.Store{}
+	store.Set("1", mapping.ServiceAniList, mapping.Mapping{ExternalID: "21"})
+	store.Set("2", mapping.ServiceAniList, mapping.Mapping{ExternalID: "22"})
+	store.Set("296", mapping.ServiceAniList, mapping.Mapping{ExternalID: "101573"})
+
+	client := &Client{Token: "token", Endpoint: server.URL}
+	skipped, err := client.Sync(&watchlist, store, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skipped) != 2 || skipped[0].Title != "Gone" || skipped[1].Title != "Unmapped" {
+		t.Errorf("Unexpected skipped entries: %v", skipped)
+	}
+	// Only the differing score is pushed, the unrated entry keeps its score
+	// on AniList and merely receives the review. The unchanged review isn't
+	// sent again.
+	if len(saved) != 2 {
+		t.Fatalf("Expected two saved entries, got %v", saved)
+	}
+	if len(saved[0]) != 2 || saved[0]["mediaId"] != float64(101573) || saved[0]["scoreRaw"] != float64(80) {
+		t.Errorf("Unexpected variables: %v", saved[0])
+	}
+	if len(saved[1]) != 2 || saved[1]["mediaId"] != float64(21) || saved[1]["notes"] != "Great" {
+		t.Errorf("Unexpected variables: %v", saved[1])
+	}
+
+	client.Token = "invalid"
+	if _, err := client.Sync(&watchlist, store, nil); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Expected ErrUnauthorized, got %v", err)
+	}
+}
+
+func TestSaveEntry_Unrated(t *testing.T) {
+	var variables map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		var body struct {
+			Variables map[string]any `json:"variables"`
+		}
+		json.NewDecoder(request.Body).Decode(&body)
+		variables = body.Variables
+		writer.Write([]byte(`{"data":{"SaveMediaListEntry":{"id":1}}}`))
+	}))
+	defer server.Close()
+
+	client := &Client{Token: "token", Endpoint: server.URL}
+	if err := client.SaveEntry("101573", Entry{Status: StatusCompleted, Progress: 12}); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"scoreRaw", "repeat", "notes"} {
+		if _, present := variables[name]; present {
+			t.Errorf("%s would be cleared: %v", name, variables)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	if wait := retryAfter(http.Header{"Retry-After": []string{"3"}}); wait != 3*time.Second {
+		t.Errorf("Expected 3s, got %s", wait)
+	}
+	if wait := retryAfter(http.Header{}); wait != defaultRetryAfter {
+		t.Errorf("Missing header should fall back to %s, got %s", defaultRetryAfter, wait)
+	}
+}
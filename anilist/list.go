@@ -0,0 +1,139 @@
+package anilist
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Bios-Marcel/proxerscrape"
+	"github.com/Bios-Marcel/proxerscrape/mapping"
+	"github.com/Bios-Marcel/proxerscrape/reconcile"
+)
+
+const (
+	StatusPaused    Status = "PAUSED"
+	StatusRepeating Status = "REPEATING"
+)
+
+// categories translates the AniList status into proxer categories. Since
+// proxer doesn't have "Paused", it's treated as stopped.
+var categories = map[Status]proxerscrape.ListCategory{
+	StatusCompleted: proxerscrape.CategoryWatched,
+	StatusCurrent:   proxerscrape.CategoryCurrentlyWatching,
+	StatusRepeating: proxerscrape.CategoryCurrentlyWatching,
+	StatusPlanning:  proxerscrape.CategoryToWatch,
+	StatusDropped:   proxerscrape.CategoryStoppedWatching,
+	StatusPaused:    proxerscrape.CategoryStoppedWatching,
+}
+
+const viewerQuery = `query {
+	Viewer {
+		id
+	}
+}`
+
+const listQuery = `query ($userId: Int) {
+	MediaListCollection(userId: $userId, type: ANIME) {
+		lists {
+			entries {
+				mediaId
+				status
+				progress
+				score(format: POINT_10)
+				updatedAt
+				repeat
+				notes
+				media {
+					title {
+						userPreferred
+					}
+				}
+			}
+		}
+	}
+}`
+
+// List retrieves the anime list of the user the token belongs to.
+func (client *Client) List() ([]reconcile.RemoteEntry, error) {
+	var viewer struct {
+		Viewer struct {
+			ID int `json:"id"`
+		} `json:"Viewer"`
+	}
+	if err := client.query(viewerQuery, nil, &viewer); err != nil {
+		return nil, err
+	}
+
+	var collection struct {
+		MediaListCollection struct {
+			Lists []struct {
+				Entries []struct {
+					MediaID   int     `json:"mediaId"`
+					Status    Status  `json:"status"`
+					Progress  uint16  `json:"progress"`
+					Score     float64 `json:"score"`
+					UpdatedAt int64   `json:"updatedAt"`
+					Repeat    uint16  `json:"repeat"`
+					Notes     string  `json:"notes"`
+					Media     struct {
+						Title struct {
+							UserPreferred string `json:"userPreferred"`
+						} `json:"title"`
+					} `json:"media"`
+				} `json:"entries"`
+			} `json:"lists"`
+		} `json:"MediaListCollection"`
+	}
+	if err := client.query(listQuery, map[string]any{"userId": viewer.Viewer.ID}, &collection); err != nil {
+		return nil, err
+	}
+
+	var entries []reconcile.RemoteEntry
+	for _, list := range collection.MediaListCollection.Lists {
+		for _, entry := range list.Entries {
+			remote := reconcile.RemoteEntry{
+				Service:    mapping.ServiceAniList,
+				ExternalID: strconv.Itoa(entry.MediaID),
+				Title:      entry.Media.Title.UserPreferred,
+				Category:   categories[entry.Status],
+				Progress:   entry.Progress,
+				Score:      uint8(entry.Score),
+				Repeat:     entry.Repeat,
+				Notes:      entry.Notes,
+			}
+			if entry.UpdatedAt > 0 {
+				remote.UpdatedAt = time.Unix(entry.UpdatedAt, 0)
+			}
+			entries = append(entries, remote)
+		}
+	}
+	return entries, nil
+}
+
+// Apply changes only the fields of the list entry affected by the given
+// sync operation, which allows using the client as a reconcile.Writer.
+func (client *Client) Apply(operation reconcile.Operation) error {
+	id, err := strconv.Atoi(operation.ExternalID)
+	if err != nil {
+		return fmt.Errorf("invalid anilist id '%s': %w", operation.ExternalID, err)
+	}
+	// Omitted variables don't change the entry.
+	variables := map[string]any{"mediaId": id}
+	switch operation.Type {
+	case reconcile.OperationAdd:
+		variables["status"] = statuses[operation.Category]
+		variables["progress"] = operation.Progress
+		if operation.Score > 0 {
+			variables["scoreRaw"] = int(operation.Score) * 10
+		}
+	case reconcile.OperationSetProgress:
+		variables["progress"] = operation.Progress
+	case reconcile.OperationSetScore:
+		variables["scoreRaw"] = int(operation.Score) * 10
+	case reconcile.OperationSetStatus:
+		variables["status"] = statuses[operation.Category]
+	default:
+		return fmt.Errorf("unknown operation '%s'", operation.Type)
+	}
+	return client.query(saveEntryMutation, variables, nil)
+}
@@ -0,0 +1,72 @@
+package anilist
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Bios-Marcel/proxerscrape"
+	"github.com/Bios-Marcel/proxerscrape/reconcile"
+)
+
+func TestList(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		var body struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		if strings.Contains(body.Query, "Viewer") {
+			writer.Write([]byte(`{"data":{"Viewer":{"id":42}}}`))
+			return
+		}
+		if body.Variables["userId"] != float64(42) {
+			t.Errorf("Unexpected variables: %v", body.Variables)
+		}
+		writer.Write([]byte(`{"data":{"MediaListCollection":{"lists":[{"entries":[
+			{"mediaId":101573,"status":"PAUSED","progress":4,"score":7.5,"updatedAt":1790000000,"media":{"title":{"userPreferred":"Tsurune"}}}
+		]}]}}}`))
+	}))
+	defer server.Close()
+
+	client := &Client{Token: "token", Endpoint: server.URL}
+	entries, err := client.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Expected one entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.ExternalID != "101573" || entry.Category != proxerscrape.CategoryStoppedWatching || entry.Progress != 4 || entry.Score != 7 || entry.UpdatedAt.Unix() != 1790000000 {
+		t.Errorf("Unexpected entry: %+v", entry)
+	}
+}
+
+func TestApply(t *testing.T) {
+	var variables map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		var body struct {
+			Variables map[string]any `json:"variables"`
+		}
+		if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		variables = body.Variables
+		writer.Write([]byte(`{"data":{"SaveMediaListEntry":{"id":1}}}`))
+	}))
+	defer server.Close()
+
+	client := &Client{Token: "token", Endpoint: server.URL}
+	if err := client.Apply(reconcile.Operation{Type: reconcile.OperationSetProgress, ExternalID: "5", Progress: 3, Score: 9}); err != nil {
+		t.Fatal(err)
+	}
+	// Only the progress may be changed, leaving score and status untouched.
+	if len(variables) != 2 || variables["progress"] != float64(3) {
+		t.Errorf("Unexpected variables: %v", variables)
+	}
+}
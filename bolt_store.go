@@ -0,0 +1,134 @@
+package proxerscrape
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	boltDataBucket     = []byte("data")
+	boltMetadataBucket = []byte("metadata")
+)
+
+// BoltStore is a CacheStore keeping all entries in a single bbolt database
+// file. Compared to the FileStore, this is faster to enumerate and easier to
+// sync between machines.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// NewBoltStore opens or creates the database at the given path. The store
+// has to be closed after use, as the database file is locked while open.
+func NewBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0o644, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{boltDataBucket, boltMetadataBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+// Close closes the underlying database.
+func (store *BoltStore) Close() error {
+	return store.db.Close()
+}
+
+func (store *BoltStore) Get(key string) (io.ReadCloser, CacheMetadata, error) {
+	var data []byte
+	var metadata CacheMetadata
+	err := store.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(boltDataBucket).Get([]byte(key))
+		if value == nil {
+			return ErrCacheMiss
+		}
+		// Values are only valid during the transaction.
+		data = append([]byte(nil), value...)
+
+		var err error
+		metadata, err = boltMetadata(tx.Bucket(boltMetadataBucket), []byte(key))
+		return err
+	})
+	if err != nil {
+		return nil, metadata, err
+	}
+
+	return io.NopCloser(bytes.NewReader(data)), metadata, nil
+}
+
+func (store *BoltStore) Put(key string, data []byte, metadata CacheMetadata) error {
+	metadataBytes, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	return store.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(boltDataBucket).Put([]byte(key), data); err != nil {
+			return err
+		}
+		return tx.Bucket(boltMetadataBucket).Put([]byte(key), metadataBytes)
+	})
+}
+
+func (store *BoltStore) Delete(key string) error {
+	return store.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(boltDataBucket).Delete([]byte(key)); err != nil {
+			return err
+		}
+		return tx.Bucket(boltMetadataBucket).Delete([]byte(key))
+	})
+}
+
+func (store *BoltStore) Stats() (CacheStats, error) {
+	var stats CacheStats
+	err := store.db.View(func(tx *bolt.Tx) error {
+		metadataBucket := tx.Bucket(boltMetadataBucket)
+		return tx.Bucket(boltDataBucket).ForEach(func(key, value []byte) error {
+			metadata, err := boltMetadata(metadataBucket, key)
+			if err != nil {
+				return err
+			}
+			stats.addEntry(int64(len(value)), metadata.FetchedAt)
+			return nil
+		})
+	})
+	return stats, err
+}
+
+func (store *BoltStore) Walk(callback func(key string, metadata CacheMetadata) error) error {
+	return store.db.View(func(tx *bolt.Tx) error {
+		metadataBucket := tx.Bucket(boltMetadataBucket)
+		return tx.Bucket(boltDataBucket).ForEach(func(key, value []byte) error {
+			metadata, err := boltMetadata(metadataBucket, key)
+			if err != nil {
+				return err
+			}
+			return callback(string(key), metadata)
+		})
+	})
+}
+
+func boltMetadata(bucket *bolt.Bucket, key []byte) (CacheMetadata, error) {
+	var metadata CacheMetadata
+	if metadataBytes := bucket.Get(key); metadataBytes != nil {
+		if err := json.Unmarshal(metadataBytes, &metadata); err != nil {
+			return metadata, err
+		}
+	}
+	return metadata, nil
+}
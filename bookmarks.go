@@ -0,0 +1,86 @@
+package proxerscrape
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Bookmark marks the episode or chapter the user is at, including the
+// language it's watched in. Unlike the progress shown on the profile, this
+// is specific to a language.
+type Bookmark struct {
+	ProxerURL string
+	Title     string
+	Episode   uint16
+	// Language is the language of the bookmarked episode. Manga use other
+	// languages, such as "de" and "en", than anime.
+	Language Language
+	// Manga is set for bookmarked chapters.
+	Manga bool
+}
+
+// ProxerID returns the ID of the bookmarked entry, or an empty string if
+// unknown.
+func (bookmark *Bookmark) ProxerID() string {
+	return formatMediaID(bookmark.ProxerURL)
+}
+
+// parseBookmarks parses the bookmark list of the user control panel. Every
+// row linking an episode or chapter is considered a bookmark.
+func parseBookmarks(document *goquery.Document) []Bookmark {
+	var bookmarks []Bookmark
+	document.Find("tr").Each(func(_ int, row *goquery.Selection) {
+		var bookmark *Bookmark
+		row.Find("a[href]").EachWithBreak(func(_ int, link *goquery.Selection) bool {
+			href, _ := link.Attr("href")
+			match := historyEpisodeRegex.FindStringSubmatch(href)
+			if match == nil {
+				return true
+			}
+			episode, _ := strconv.ParseUint(match[2], 10, 16)
+			bookmark = &Bookmark{
+				ProxerURL: "/info/" + match[1],
+				Title:     strings.TrimSpace(normalizeText(link.Text())),
+				Episode:   uint16(episode),
+				Language:  Language(match[3]),
+				Manga:     strings.Contains(href, "/read/"),
+			}
+			return false
+		})
+		if bookmark == nil {
+			return
+		}
+		if info := row.Find("a[href*='/info/']"); info.Length() > 0 {
+			bookmark.Title = strings.TrimSpace(normalizeText(info.First().Text()))
+		}
+		bookmarks = append(bookmarks, *bookmark)
+	})
+	return bookmarks
+}
+
+// LoadBookmarks retrieves the bookmarks of the logged in user from the user
+// control panel. ErrLoginRequired is returned if the session isn't logged in.
+func (session *Session) LoadBookmarks() ([]Bookmark, error) {
+	document, err := session.getDocument("/ucp?s=reminder")
+	if err != nil {
+		return nil, err
+	}
+	return parseBookmarks(document), nil
+}
+
+// ApplyBookmarks records the bookmarked anime episodes as the progress of
+// their language, see UserData.EpisodesWatched. Returns the amount of
+// bookmarks applied.
+func (userData *UserData) ApplyBookmarks(bookmarks []Bookmark) int {
+	var applied int
+	for _, bookmark := range bookmarks {
+		if bookmark.Manga || bookmark.Language == "" {
+			continue
+		}
+		userData.SetLanguageProgress(&Media{ProxerURL: bookmark.ProxerURL}, bookmark.Language, bookmark.Episode)
+		applied++
+	}
+	return applied
+}
@@ -0,0 +1,164 @@
+// Package browsercookie imports the proxer.me cookies of locally installed
+// browsers, so that users don't have to copy their login cookie manually.
+// Reading the cookie databases requires cgo, which is why this isn't part of
+// the main package.
+package browsercookie
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// ErrNoDatabase is returned if no cookie database could be found.
+var ErrNoDatabase = errors.New("no cookie database found")
+
+// Browser names accepted by Load.
+const (
+	Firefox = "firefox"
+	Chrome  = "chrome"
+)
+
+// Load reads the proxer.me cookies of the given browser from its default
+// profile location. Chrome is only supported on Linux, see LoadChrome.
+func Load(browser string) ([]*http.Cookie, error) {
+	switch browser {
+	case Firefox:
+		path, err := findDatabase(firefoxDatabasePatterns())
+		if err != nil {
+			return nil, err
+		}
+		return LoadFirefox(path)
+	case Chrome:
+		if !chromeDecryptable {
+			return nil, ErrUnsupportedPlatform
+		}
+		path, err := findDatabase(chromeDatabasePatterns())
+		if err != nil {
+			return nil, err
+		}
+		return LoadChrome(path)
+	}
+	return nil, errors.New("unsupported browser '" + browser + "'")
+}
+
+// findDatabase returns the most recently modified file matching any of the
+// patterns, since that's most likely the profile in use.
+func findDatabase(patterns []string) (string, error) {
+	var newestPath string
+	var newest time.Time
+	for _, pattern := range patterns {
+		matches, _ := filepath.Glob(pattern)
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err == nil && info.ModTime().After(newest) {
+				newestPath = match
+				newest = info.ModTime()
+			}
+		}
+	}
+	if newestPath == "" {
+		return "", ErrNoDatabase
+	}
+	return newestPath, nil
+}
+
+func firefoxDatabasePatterns() []string {
+	home, _ := os.UserHomeDir()
+	config, _ := os.UserConfigDir()
+	return []string{
+		filepath.Join(home, ".mozilla", "firefox", "*", "cookies.sqlite"),
+		filepath.Join(home, "snap", "firefox", "common", ".mozilla", "firefox", "*", "cookies.sqlite"),
+		filepath.Join(config, "Firefox", "Profiles", "*", "cookies.sqlite"),
+		filepath.Join(config, "Mozilla", "Firefox", "Profiles", "*", "cookies.sqlite"),
+	}
+}
+
+// chromeDatabasePatterns only covers Linux, since Load can't decrypt the
+// cookies of other systems anyway.
+func chromeDatabasePatterns() []string {
+	config, _ := os.UserConfigDir()
+	var patterns []string
+	for _, browserDir := range []string{"google-chrome", "chromium"} {
+		patterns = append(patterns,
+			filepath.Join(config, browserDir, "*", "Cookies"),
+			filepath.Join(config, browserDir, "*", "Network", "Cookies"),
+		)
+	}
+	return patterns
+}
+
+// openCopy opens a copy of the given database, since browsers keep their
+// databases locked while running. The returned function removes the copy.
+func openCopy(path string) (*sql.DB, func(), error) {
+	source, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer source.Close()
+
+	target, err := os.CreateTemp("", "proxerscrape-cookies-*.sqlite")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { os.Remove(target.Name()) }
+	_, err = io.Copy(target, source)
+	if errClose := target.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	database, err := sql.Open("sqlite3", "file:"+target.Name()+"?mode=ro")
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return database, func() {
+		database.Close()
+		cleanup()
+	}, nil
+}
+
+// LoadFirefox reads the proxer.me cookies from the given Firefox
+// cookies.sqlite.
+func LoadFirefox(path string) ([]*http.Cookie, error) {
+	database, closeDatabase, err := openCopy(path)
+	if err != nil {
+		return nil, err
+	}
+	defer closeDatabase()
+
+	rows, err := database.Query(`SELECT host, name, value, path, expiry, isSecure, isHttpOnly
+		FROM moz_cookies WHERE host = 'proxer.me' OR host LIKE '%.proxer.me'`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cookies []*http.Cookie
+	for rows.Next() {
+		var cookie http.Cookie
+		var expiry int64
+		if err := rows.Scan(&cookie.Domain, &cookie.Name, &cookie.Value, &cookie.Path, &expiry, &cookie.Secure, &cookie.HttpOnly); err != nil {
+			return nil, err
+		}
+		cookie.Domain = strings.TrimPrefix(cookie.Domain, ".")
+		// Newer versions store the expiry in milliseconds.
+		if expiry > 1e12 {
+			expiry /= 1000
+		}
+		cookie.Expires = time.Unix(expiry, 0)
+		cookies = append(cookies, &cookie)
+	}
+	return cookies, rows.Err()
+}
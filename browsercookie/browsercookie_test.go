@@ -0,0 +1,103 @@
+package browsercookie
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadFirefox(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.sqlite")
+	database, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = database.Exec(`CREATE TABLE moz_cookies (host TEXT, name TEXT, value TEXT, path TEXT, expiry INTEGER, isSecure INTEGER, isHttpOnly INTEGER);
+		INSERT INTO moz_cookies VALUES ('.proxer.me', 'joomla_remember_me_abc', 'secret', '/', 1893456000, 1, 1);
+		INSERT INTO moz_cookies VALUES ('.example.com', 'other', 'value', '/', 1893456000, 0, 0);`)
+	database.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cookies, err := LoadFirefox(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cookies) != 1 || cookies[0].Value != "secret" || cookies[0].Domain != "proxer.me" || !cookies[0].HttpOnly {
+		t.Errorf("Unexpected cookies: %+v", cookies)
+	}
+}
+
+// encryptChromeValue encrypts the value the way Chrome does on Linux without
+// a keyring.
+func encryptChromeValue(value string) []byte {
+	plain := []byte(value)
+	padding := aes.BlockSize - len(plain)%aes.BlockSize
+	plain = append(plain, bytes.Repeat([]byte{byte(padding)}, padding)...)
+
+	block, _ := aes.NewCipher(chromeLinuxKey())
+	encrypted := make([]byte, len(plain))
+	cipher.NewCBCEncrypter(block, bytes.Repeat([]byte{' '}, aes.BlockSize)).CryptBlocks(encrypted, plain)
+	return append([]byte("v10"), encrypted...)
+}
+
+func TestLoadChrome(t *testing.T) {
+	createDatabase := func(encryptedValue []byte) string {
+		path := filepath.Join(t.TempDir(), "Cookies")
+		database, err := sql.Open("sqlite3", path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer database.Close()
+		_, err = database.Exec(`CREATE TABLE meta (key TEXT, value TEXT);
+			INSERT INTO meta VALUES ('version', '18');
+			CREATE TABLE cookies (host_key TEXT, name TEXT, value TEXT, encrypted_value BLOB, path TEXT, expires_utc INTEGER, is_secure INTEGER, is_httponly INTEGER);
+			INSERT INTO cookies VALUES ('.example.com', 'other', 'value', '', '/', 0, 0, 0);
+			INSERT INTO cookies VALUES ('proxer.me', 'tags', 'show', '', '/', 0, 0, 0);`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = database.Exec(`INSERT INTO cookies VALUES ('.proxer.me', 'joomla_remember_me_abc', '', ?, '/', 13253932800000000, 1, 1)`, encryptedValue)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	cookies, err := LoadChrome(createDatabase(encryptChromeValue("secret")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cookies) != 2 || cookies[0].Value != "show" || cookies[1].Value != "secret" || cookies[1].Domain != "proxer.me" || cookies[1].Expires.Year() != 2021 {
+		t.Errorf("Unexpected cookies: %+v", cookies)
+	}
+
+	if _, err := LoadChrome(createDatabase([]byte("v11abc"))); err != ErrKeyringEncrypted {
+		t.Errorf("Expected ErrKeyringEncrypted, got %v", err)
+	}
+
+	// Other systems encrypt the key differently, so the value is garbage.
+	chromeDecryptable = false
+	defer func() { chromeDecryptable = true }()
+	if _, err := LoadChrome(createDatabase(encryptChromeValue("secret"))); err != ErrUnsupportedPlatform {
+		t.Errorf("Expected ErrUnsupportedPlatform, got %v", err)
+	}
+}
+
+func Test_decryptChromeValue(t *testing.T) {
+	value, err := decryptChromeValue(encryptChromeValue("secret"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != "secret" {
+		t.Errorf("Value = %s, instead of secret", value)
+	}
+
+	if _, err := decryptChromeValue([]byte("v11abc"), false); err != ErrKeyringEncrypted {
+		t.Errorf("Expected ErrKeyringEncrypted, got %v", err)
+	}
+}
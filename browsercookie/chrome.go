@@ -0,0 +1,132 @@
+package browsercookie
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"errors"
+	"net/http"
+	"runtime"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrKeyringEncrypted is returned for cookies encrypted with a key
+	// stored in the system keyring, which isn't supported.
+	ErrKeyringEncrypted = errors.New("cookie is encrypted via the system keyring")
+	// ErrUnsupportedPlatform is returned for encrypted cookies on systems
+	// other than Linux, where Chrome keeps the key in the keychain or
+	// encrypts it via DPAPI, neither of which is supported.
+	ErrUnsupportedPlatform = errors.New("chrome cookies can only be decrypted on linux")
+)
+
+// chromeDecryptable tells whether Chrome's encrypted cookies can be
+// decrypted on this system.
+var chromeDecryptable = runtime.GOOS == "linux"
+
+// chromeEpochOffset is the amount of seconds between 1601-01-01, which
+// Chrome's timestamps are relative to, and the Unix epoch. The timestamps
+// can't be added to the former as time.Duration, as that would overflow.
+const chromeEpochOffset = 11644473600
+
+// LoadChrome reads the proxer.me cookies from the given Chrome or Chromium
+// cookie database. Only cookies that are unencrypted or encrypted with
+// Chrome's hardcoded Linux key are supported, others cause ErrKeyringEncrypted
+// or ErrUnsupportedPlatform.
+func LoadChrome(path string) ([]*http.Cookie, error) {
+	database, closeDatabase, err := openCopy(path)
+	if err != nil {
+		return nil, err
+	}
+	defer closeDatabase()
+
+	// Starting with version 24, the decrypted value is prefixed with a hash
+	// of the domain.
+	var version int
+	database.QueryRow(`SELECT value FROM meta WHERE key = 'version'`).Scan(&version)
+
+	rows, err := database.Query(`SELECT host_key, name, value, encrypted_value, path, expires_utc, is_secure, is_httponly
+		FROM cookies WHERE host_key = 'proxer.me' OR host_key LIKE '%.proxer.me'`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cookies []*http.Cookie
+	for rows.Next() {
+		var cookie http.Cookie
+		var encryptedValue []byte
+		var expires int64
+		if err := rows.Scan(&cookie.Domain, &cookie.Name, &cookie.Value, &encryptedValue, &cookie.Path, &expires, &cookie.Secure, &cookie.HttpOnly); err != nil {
+			return nil, err
+		}
+		if cookie.Value == "" && len(encryptedValue) > 0 {
+			if !chromeDecryptable {
+				return nil, ErrUnsupportedPlatform
+			}
+			value, err := decryptChromeValue(encryptedValue, version >= 24)
+			if err != nil {
+				return nil, err
+			}
+			cookie.Value = value
+		}
+		cookie.Domain = strings.TrimPrefix(cookie.Domain, ".")
+		if expires > 0 {
+			cookie.Expires = time.Unix(expires/1e6-chromeEpochOffset, expires%1e6*1e3).UTC()
+		}
+		cookies = append(cookies, &cookie)
+	}
+	return cookies, rows.Err()
+}
+
+// chromeLinuxKey derives the key Chrome uses on Linux if no keyring is
+// available. It's PBKDF2 with a single iteration, which fits into one block.
+func chromeLinuxKey() []byte {
+	mac := hmac.New(sha1.New, []byte("peanuts"))
+	mac.Write([]byte("saltysalt"))
+	mac.Write([]byte{0, 0, 0, 1})
+	return mac.Sum(nil)[:16]
+}
+
+func decryptChromeValue(encrypted []byte, hashPrefixed bool) (string, error) {
+	if bytes.HasPrefix(encrypted, []byte("v11")) {
+		return "", ErrKeyringEncrypted
+	}
+	if !bytes.HasPrefix(encrypted, []byte("v10")) {
+		return "", errors.New("unknown cookie encryption")
+	}
+	encrypted = encrypted[3:]
+	if len(encrypted) == 0 || len(encrypted)%aes.BlockSize != 0 {
+		return "", errors.New("invalid encrypted cookie length")
+	}
+
+	block, err := aes.NewCipher(chromeLinuxKey())
+	if err != nil {
+		return "", err
+	}
+	decrypted := make([]byte, len(encrypted))
+	cipher.NewCBCDecrypter(block, bytes.Repeat([]byte{' '}, aes.BlockSize)).CryptBlocks(decrypted, encrypted)
+
+	padding := int(decrypted[len(decrypted)-1])
+	if padding == 0 || padding > aes.BlockSize {
+		return "", errors.New("invalid cookie padding")
+	}
+	decrypted = decrypted[:len(decrypted)-padding]
+	if hashPrefixed {
+		if len(decrypted) < sha256.Size {
+			return "", errors.New("invalid cookie hash prefix")
+		}
+		decrypted = decrypted[sha256.Size:]
+	}
+	// A wrong key usually fails the padding check, but not always.
+	for _, character := range decrypted {
+		if character < 0x20 || character > 0x7e {
+			return "", errors.New("cookie couldn't be decrypted")
+		}
+	}
+	return string(decrypted), nil
+}
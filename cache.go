@@ -2,12 +2,14 @@ package proxerscrape
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
-	"regexp"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -21,38 +23,145 @@ var (
 	mangaRateLImiter = NewLimiter(8, time.Minute*5)
 	// Original limit for `/info/xxx/anime` is 40r/6min.
 	userRateLImiter = NewLimiter(38, time.Minute*6)
-
-	cacheBaseDir, profileTabCacheDir string
-	loginCookieKey, loginCookieValue string
 )
 
-func init() {
-	var err error
-	cacheBaseDir, err = os.UserCacheDir()
+// DefaultCacheDir returns the directory used by CreateDefaultCache, which is
+// located inside of the user cache directory.
+func DefaultCacheDir() (string, error) {
+	userCacheDir, err := os.UserCacheDir()
 	if err != nil {
-		panic(err)
-	}
-
-	cacheBaseDir = filepath.Join(cacheBaseDir, "proxerscrape")
-	profileTabCacheDir = filepath.Join(cacheBaseDir, "profile")
-	if err = os.MkdirAll(profileTabCacheDir, os.ModePerm); err != nil {
-		panic(err)
+		return "", err
 	}
-
-	loginCookieKey = os.Getenv("LOGIN_COOKIE_KEY")
-	loginCookieValue = os.Getenv("LOGIN_COOKIE_VALUE")
+	return filepath.Join(userCacheDir, "proxerscrape"), nil
 }
 
 func getCacheIdentifier(anime *Media) string {
-	return regexp.MustCompile(`/info/(\d+).*`).FindStringSubmatch(anime.ProxerURL)[1]
+	return anime.ProxerID()
+}
+
+// mediaCacheKey returns the cache key of the info page of the given entry.
+// Entries without an ID can't be retrieved, since they'd all share the same
+// key and URL.
+func mediaCacheKey(item *Media) (string, error) {
+	identifier := getCacheIdentifier(item)
+	if identifier == "" {
+		return "", ErrMissingID
+	}
+	return identifier, nil
 }
 
+// Cache retrieves pages from proxer.me and keeps them in a CacheStore. Each
+// Query function is passed additional headers that have to be sent with the
+// request, these are used for revalidating stale entries.
 type Cache struct {
-	QueryMedia                 func(*Media) (*http.Response, error)
-	QueryProfileTab            func(string, ProfileTabType) (*http.Response, error)
+	// The counters have to be the first fields, since 64 bit atomic
+	// operations require 64 bit alignment on 32 bit platforms.
+	hits, misses, requests, warnings, failures uint64
+	blocked                                    int64
+
+	// Store is where all retrieved pages are kept. If no entry is present
+	// for a page, it is queried and put into the store.
+	Store CacheStore
+	// MaxAge decides how long an entry may be served from the store before
+	// it's considered stale and fetched again. If nil, entries never expire.
+	MaxAge           MaxAgePolicy
+	QueryMedia       func(*Media, http.Header) (*http.Response, error)
+	QueryProfileTab  func(string, ProfileTabType, http.Header) (*http.Response, error)
+	QueryLeaderboard func(LeaderboardType, http.Header) (*http.Response, error)
+	QueryRelations   func(*Media, http.Header) (*http.Response, error)
+	// QueryPage queries an arbitrary page of proxer.me by its path, such as
+	// `/calendar`. It's used for pages that don't need special handling.
+	QueryPage                  func(path string, header http.Header) (*http.Response, error)
 	AnimeQueryRatelimiter      *Limiter
 	MangaQueryRatelimiter      *Limiter
 	ProfileTabQueryRatelimiter *Limiter
+	// Queue holds fetches deferred to a later run. If nil, nothing is
+	// deferred.
+	Queue *FetchQueue
+	// Retry configures how transient errors, such as timeouts, are retried.
+	// The zero value disables retrying.
+	Retry RetryPolicy
+	// OnProgress receives a ProgressWaiting event, whenever a request is
+	// blocked by a ratelimiter.
+	OnProgress ProgressFunc
+	// OnCooldown is called whenever a ratelimiter enters cooldown, since
+	// proxer.me responded with a captcha. The time is the point at which
+	// requests will be resumed.
+	OnCooldown func(until time.Time)
+	// OnMetric is called whenever one of the counters of Metrics changes,
+	// for example to forward them to a monitoring system. May be nil.
+	OnMetric MetricHook
+	// Logger receives diagnostic messages. If nil, nothing is logged.
+	Logger Logger
+	// Profile spreads out live requests, see RequestProfile. NewCache uses
+	// PoliteProfile by default.
+	Profile RequestProfile
+	// Offline prevents all requests. Pages are served from the store, even
+	// if they're stale, and ErrNotCached is returned for missing ones.
+	Offline bool
+	// MaxRequests is the amount of live requests that may be issued without
+	// asking ConfirmRequests, see Reserve. Zero disables the limit.
+	MaxRequests int
+	// ConfirmRequests is asked whether more live requests than allowed via
+	// MaxRequests may be issued. Pending is 0 if the amount isn't known
+	// upfront, in which case confirming lifts the limit. Returning an error
+	// aborts the requests. If nil, ErrRequestLimit is returned instead.
+	ConfirmRequests func(pending int) error
+
+	intervalLock sync.Mutex
+	lastRequest  time.Time
+	budgetLock   sync.Mutex
+	// issued counts the live requests admitted so far, granted the ones
+	// confirmed on top of MaxRequests. Once ConfirmRequests has refused,
+	// refused is returned without asking again.
+	issued, granted int
+	unlimited       bool
+	refused         error
+	// client performs the requests of sessions, see Cache.Session.
+	client *Client
+}
+
+// CacheEntryKind is the type of page a cache entry contains.
+type CacheEntryKind string
+
+const (
+	CacheEntryProfileTab  CacheEntryKind = "profile"
+	CacheEntryLeaderboard CacheEntryKind = "leaderboard"
+	CacheEntryMedia       CacheEntryKind = "media"
+	CacheEntryRelations   CacheEntryKind = "relations"
+	CacheEntryCalendar    CacheEntryKind = "calendar"
+	CacheEntrySearch      CacheEntryKind = "search"
+	CacheEntrySeason      CacheEntryKind = "season"
+	CacheEntryTopList     CacheEntryKind = "toplist"
+)
+
+// CacheEntryKinds are all kinds of cache entries.
+var CacheEntryKinds = []CacheEntryKind{
+	CacheEntryProfileTab, CacheEntryLeaderboard, CacheEntryMedia, CacheEntryRelations,
+	CacheEntryCalendar, CacheEntrySearch, CacheEntrySeason, CacheEntryTopList,
+}
+
+// MaxAgePolicy returns the maximum age of a cache entry. Item is only set for
+// media and relation entries. A return value of 0 means the entry never
+// expires.
+type MaxAgePolicy func(kind CacheEntryKind, item *Media) time.Duration
+
+// DefaultMaxAge refreshes profile tabs, leaderboards, search results,
+// seasonal listings and toplists daily. Media pages are only refreshed daily if the media hasn't been
+// finished yet, since their rating and episode count still change.
+func DefaultMaxAge(kind CacheEntryKind, item *Media) time.Duration {
+	switch kind {
+	case CacheEntryProfileTab, CacheEntryLeaderboard, CacheEntrySearch, CacheEntrySeason, CacheEntryTopList:
+		return 24 * time.Hour
+	case CacheEntryCalendar:
+		// Delays and new episodes are announced at short notice.
+		return 6 * time.Hour
+	case CacheEntryMedia, CacheEntryRelations:
+		if item != nil && (item.Status == StatusAiring || item.Status == StatusPreAiring) {
+			return 24 * time.Hour
+		}
+	}
+	return 0
 }
 
 type MediaRawDataRetriever func(*Media) (io.ReadCloser, CacheInvalidator, error)
@@ -71,13 +180,32 @@ const (
 	ProfileTabNovel ProfileTabType = "novel"
 )
 
+func profileTabCacheKey(profileId string, tabType ProfileTabType) string {
+	// Multiple profiles may be cached at once, so we need to namespace them.
+	return "profile/" + profileId + "/" + string(tabType)
+}
+
 func (cache *Cache) RetrieveProfileTabRawData(profileId string, tabType ProfileTabType) (io.ReadCloser, CacheInvalidator, error) {
-	cacheFilePath := filepath.Join(profileTabCacheDir, string(tabType)+".html")
-	return retrieve(cacheFilePath, tabType, func(tabType ProfileTabType) (*http.Response, error) {
-		if cache.ProfileTabQueryRatelimiter != nil {
-			cache.ProfileTabQueryRatelimiter.Wait()
-		}
-		return cache.QueryProfileTab(profileId, tabType)
+	cacheKey := profileTabCacheKey(profileId, tabType)
+	return cache.retrieve(CacheEntryProfileTab, nil, cacheKey, cache.ProfileTabQueryRatelimiter, func(header http.Header) (*http.Response, error) {
+		return cache.QueryProfileTab(profileId, tabType, header)
+	})
+}
+
+// retrievePage retrieves a page via QueryPage. The given kind decides how
+// long the page may be served from the store, see MaxAgePolicy.
+func (cache *Cache) retrievePage(kind CacheEntryKind, cacheKey, path string, limiter *Limiter) (io.ReadCloser, CacheInvalidator, error) {
+	return cache.retrieve(kind, nil, cacheKey, limiter, func(header http.Header) (*http.Response, error) {
+		return cache.QueryPage(path, header)
+	})
+}
+
+// RetrieveLeaderboardRawData retrieves the HTML page of the given leaderboard.
+// Since leaderboards are user pages, the profile tab ratelimiter applies.
+func (cache *Cache) RetrieveLeaderboardRawData(leaderboardType LeaderboardType) (io.ReadCloser, CacheInvalidator, error) {
+	cacheKey := "leaderboard/" + string(leaderboardType)
+	return cache.retrieve(CacheEntryLeaderboard, nil, cacheKey, cache.ProfileTabQueryRatelimiter, func(header http.Header) (*http.Response, error) {
+		return cache.QueryLeaderboard(leaderboardType, header)
 	})
 }
 
@@ -88,13 +216,12 @@ func (cache *Cache) RetrieveProfileTabRawData(profileId string, tabType ProfileT
 // receiveing the data, deems that it is invalid an should be removed from
 // cache.
 func (cache *Cache) RetrieveAnimeRawData(item *Media) (io.ReadCloser, CacheInvalidator, error) {
-	cacheIdentifier := getCacheIdentifier(item)
-	cacheFilePath := filepath.Join(cacheBaseDir, cacheIdentifier+".html")
-	return retrieve(cacheFilePath, item, func(item *Media) (*http.Response, error) {
-		if cache.AnimeQueryRatelimiter != nil {
-			cache.AnimeQueryRatelimiter.Wait()
-		}
-		return cache.QueryMedia(item)
+	cacheKey, err := mediaCacheKey(item)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cache.retrieve(CacheEntryMedia, item, cacheKey, cache.AnimeQueryRatelimiter, func(header http.Header) (*http.Response, error) {
+		return cache.QueryMedia(item, header)
 	})
 }
 
@@ -105,67 +232,296 @@ func (cache *Cache) RetrieveAnimeRawData(item *Media) (io.ReadCloser, CacheInval
 // receiveing the data, deems that it is invalid an should be removed from
 // cache.
 func (cache *Cache) RetrieveMangaRawData(item *Media) (io.ReadCloser, CacheInvalidator, error) {
-	cacheIdentifier := getCacheIdentifier(item)
-	cacheFilePath := filepath.Join(cacheBaseDir, cacheIdentifier+".html")
-	return retrieve(cacheFilePath, item, func(item *Media) (*http.Response, error) {
-		if cache.MangaQueryRatelimiter != nil {
-			cache.MangaQueryRatelimiter.Wait()
-		}
-		return cache.QueryMedia(item)
+	cacheKey, err := mediaCacheKey(item)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cache.retrieve(CacheEntryMedia, item, cacheKey, cache.MangaQueryRatelimiter, func(header http.Header) (*http.Response, error) {
+		return cache.QueryMedia(item, header)
+	})
+}
+
+// RetrieveRelationsRawData retrieves the HTML page listing all entries
+// related to the given media entry. Relations of manga and novels are
+// retrieved via the manga ratelimiter, all others via the anime ratelimiter.
+func (cache *Cache) RetrieveRelationsRawData(item *Media) (io.ReadCloser, CacheInvalidator, error) {
+	cacheKey, err := mediaCacheKey(item)
+	if err != nil {
+		return nil, nil, err
+	}
+	cacheKey += "_relation"
+	limiter := cache.AnimeQueryRatelimiter
+	if item.Type.IsManga() || item.Type.IsNovel() {
+		limiter = cache.MangaQueryRatelimiter
+	}
+	return cache.retrieve(CacheEntryRelations, item, cacheKey, limiter, func(header http.Header) (*http.Response, error) {
+		return cache.QueryRelations(item, header)
 	})
 }
 
-func retrieve[T any](cacheFilePath string, item T, query func(T) (*http.Response, error)) (io.ReadCloser, CacheInvalidator, error) {
+// Stats returns statistics about the underlying store, as well as how many
+// requests were served from the store during this session.
+func (cache *Cache) Stats() (CacheStats, error) {
+	stats, err := cache.Store.Stats()
+	stats.Hits = atomic.LoadUint64(&cache.hits)
+	stats.Misses = atomic.LoadUint64(&cache.misses)
+	return stats, err
+}
+
+// Cooldown returns the time until which requests are paused, because the
+// captcha wall has been hit. The zero time means there's no cooldown.
+func (cache *Cache) Cooldown() time.Time {
+	var until time.Time
+	for _, limiter := range []*Limiter{cache.AnimeQueryRatelimiter, cache.MangaQueryRatelimiter, cache.ProfileTabQueryRatelimiter} {
+		if limiter == nil {
+			continue
+		}
+		if limiterUntil := limiter.CooldownUntil(); limiterUntil.After(until) {
+			until = limiterUntil
+		}
+	}
+	if until.Before(time.Now()) {
+		return time.Time{}
+	}
+	return until
+}
+
+func (cache *Cache) isStale(kind CacheEntryKind, item *Media, metadata CacheMetadata) bool {
+	if cache.MaxAge == nil || metadata.FetchedAt.IsZero() {
+		return false
+	}
+	maxAge := cache.MaxAge(kind, item)
+	return maxAge > 0 && time.Since(metadata.FetchedAt) > maxAge
+}
+
+// maxCaptchaRetries is the amount of times a request is retried after hitting
+// the captcha wall, before giving up.
+const maxCaptchaRetries = 5
+
+// isCaptchaPage checks whether proxer.me responded with a captcha instead of
+// the requested page, which happens if the ratelimit has been exceeded.
+func isCaptchaPage(data []byte) bool {
+	return bytes.Contains(data, []byte("//www.google.com/recaptcha/api.js"))
+}
+
+func (cache *Cache) logf(format string, args ...any) {
+	loggerOrDiscard(cache.Logger).Printf(format, args...)
+}
+
+func (cache *Cache) retrieve(
+	kind CacheEntryKind,
+	item *Media,
+	cacheKey string,
+	limiter *Limiter,
+	query func(http.Header) (*http.Response, error),
+) (io.ReadCloser, CacheInvalidator, error) {
+	reader, cacheInvalidator, _, err := cache.retrieveEntry(kind, item, cacheKey, limiter, query, false, time.Time{})
+	return reader, cacheInvalidator, err
+}
+
+// retrieveEntry is like retrieve, but can revalidate entries that haven't
+// expired yet. It additionally reports whether the page changed, which is
+// the case if it wasn't cached or the server didn't confirm that the cached
+// version is still up to date. If the captcha wall is hit and the cooldown
+// would last past a non-zero deadline, ErrRateLimited is returned right away
+// instead of waiting.
+func (cache *Cache) retrieveEntry(
+	kind CacheEntryKind,
+	item *Media,
+	cacheKey string,
+	limiter *Limiter,
+	query func(http.Header) (*http.Response, error),
+	revalidate bool,
+	deadline time.Time,
+) (io.ReadCloser, CacheInvalidator, bool, error) {
 	cacheInvalidator := func() error {
-		return os.Remove(cacheFilePath)
+		return cache.Store.Delete(cacheKey)
+	}
+	reader, metadata, err := cache.Store.Get(cacheKey)
+	if err != nil && !errors.Is(err, ErrCacheMiss) {
+		return nil, nil, false, err
+	}
+
+	if cache.Offline && err != nil {
+		return nil, nil, false, ErrNotCached
 	}
-	file, err := os.Open(cacheFilePath)
+
+	header := http.Header{}
+	var staleData []byte
 	if err == nil {
-		return file, cacheInvalidator, nil
+		if cache.Offline || (!revalidate && !cache.isStale(kind, item, metadata)) {
+			cache.count(MetricCacheHits, &cache.hits)
+			return reader, cacheInvalidator, false, nil
+		}
+
+		// Stale entries are revalidated, so that we don't have to download
+		// the whole page again if it hasn't changed.
+		staleData, err = io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return nil, nil, false, err
+		}
+		if metadata.ETag != "" {
+			header.Set("If-None-Match", metadata.ETag)
+		}
+		if metadata.LastModified != "" {
+			header.Set("If-Modified-Since", metadata.LastModified)
+		}
 	}
 
-	if !os.IsNotExist(err) {
-		return nil, nil, err
+	if err := cache.admitRequest(); err != nil {
+		return nil, nil, false, err
 	}
+	cache.count(MetricCacheMisses, &cache.misses)
+	staleMetadata := metadata
+	var data []byte
+	var modified bool
+	for attempt := 0; ; attempt++ {
+		data, metadata, modified, err = cache.fetchWithRetry(limiter, query, header, staleData, staleMetadata)
+		if err != nil {
+			cache.count(MetricErrors, &cache.failures)
+			return nil, nil, false, err
+		}
+		if !isCaptchaPage(data) {
+			if limiter != nil {
+				limiter.ResetBackoff()
+			}
+			break
+		}
 
-	response, err := query(item)
-	if err != nil {
-		return nil, nil, err
+		// Instead of failing, we cool down and try again, as the captcha
+		// wall disappears after a while.
+		if limiter == nil || attempt >= maxCaptchaRetries {
+			cache.count(MetricErrors, &cache.failures)
+			return nil, nil, false, ErrRateLimited
+		}
+		cache.count(MetricWarnings, &cache.warnings)
+		until := limiter.Backoff()
+		cache.logf("Hit captcha while retrieving %s, cooling down until %s.\n", describeMedia(item), until.Format(time.Kitchen))
+		if cache.OnCooldown != nil {
+			cache.OnCooldown(until)
+		}
+		if !deadline.IsZero() && until.After(deadline) {
+			cache.count(MetricErrors, &cache.failures)
+			return nil, nil, false, ErrRateLimited
+		}
+	}
+
+	if err = cache.Store.Put(cacheKey, data, metadata); err != nil {
+		return nil, nil, false, err
 	}
-	defer response.Body.Close()
 
-	cacheFileWriter, err := os.Create(cacheFilePath)
+	return io.NopCloser(bytes.NewReader(data)), cacheInvalidator, modified, nil
+}
+
+// fetchWithRetry performs fetch, retrying transient errors according to
+// the cache's RetryPolicy. Each try counts against the limiter.
+func (cache *Cache) fetchWithRetry(
+	limiter *Limiter,
+	query func(http.Header) (*http.Response, error),
+	header http.Header,
+	staleData []byte,
+	metadata CacheMetadata,
+) ([]byte, CacheMetadata, bool, error) {
+	for retry := 0; ; retry++ {
+		cache.wait(limiter)
+		cache.count(MetricRequests, &cache.requests)
+		data, newMetadata, modified, err := fetch(query, header, staleData, metadata)
+		if err == nil || retry >= cache.Retry.Attempts || !isTransient(err) {
+			return data, newMetadata, modified, err
+		}
+		cache.count(MetricWarnings, &cache.warnings)
+		time.Sleep(cache.Retry.delay(retry))
+	}
+}
+
+// fetch performs the query and returns the page and its metadata. If the
+// server responds that the page hasn't been modified, the stale data and its
+// metadata are reused and modified is false. Error statuses other than 404
+// cause a StatusError, since their pages are useless and mustn't be cached.
+func fetch(
+	query func(http.Header) (*http.Response, error),
+	header http.Header,
+	staleData []byte,
+	metadata CacheMetadata,
+) ([]byte, CacheMetadata, bool, error) {
+	response, err := query(header)
 	if err != nil {
-		return nil, nil, err
+		return nil, metadata, false, err
 	}
-	defer cacheFileWriter.Close()
+	defer response.Body.Close()
 
-	//This doesn't actually copy the data underneath and therefore allows
-	//us to read the same buffer twice without actually reallocating the
-	//byte array. We need this since we also can't read the body twice.
-	var buffer bytes.Buffer
-	if _, err = io.Copy(&buffer, response.Body); err != nil {
-		return nil, nil, err
+	if response.StatusCode >= http.StatusBadRequest && response.StatusCode != http.StatusNotFound {
+		statusError := &StatusError{StatusCode: response.StatusCode}
+		if response.Request != nil && response.Request.URL != nil {
+			statusError.URL = response.Request.URL.String()
+		}
+		return nil, metadata, false, statusError
 	}
-	bufferCopy := buffer
 
-	if _, err = io.Copy(cacheFileWriter, &buffer); err != nil {
-		return nil, nil, err
+	var data []byte
+	modified := response.StatusCode != http.StatusNotModified || staleData == nil
+	if !modified {
+		data = staleData
+	} else {
+		data, err = io.ReadAll(response.Body)
+		if err != nil {
+			return nil, metadata, false, err
+		}
+		// Pages are always cached as UTF-8, so that parsing doesn't have
+		// to care about encodings.
+		data = NormalizeEncoding(data, response.Header.Get("Content-Type"))
+		metadata = CacheMetadata{
+			ETag:         response.Header.Get("ETag"),
+			LastModified: response.Header.Get("Last-Modified"),
+		}
+		if response.Request != nil && response.Request.URL != nil {
+			metadata.URL = response.Request.URL.String()
+		}
 	}
+	metadata.FetchedAt = time.Now()
+	return data, metadata, modified, nil
+}
 
-	return io.NopCloser(&bufferCopy), cacheInvalidator, nil
+// CreateDefaultCache creates a cache writing to DefaultCacheDir, using the
+// login cookie from the environment, see WithLoginCookieFromEnv. An error is
+// returned if there's no usable user cache directory on this system, use
+// NewCache with WithStore(NewMemoryStore()) for only caching in memory.
+func CreateDefaultCache() (*Cache, error) {
+	return NewCache(WithLoginCookieFromEnv())
 }
 
-func CreateDefaultCache() *Cache {
+// BaseURL returns the URL pages are retrieved from, see WithBaseURL.
+func (cache *Cache) BaseURL() string {
+	if cache.client == nil {
+		return DefaultBaseURL
+	}
+	return cache.client.URL("")
+}
+
+func newCache(store CacheStore, client *Client) *Cache {
 	return &Cache{
-		QueryMedia: func(item *Media) (*http.Response, error) {
-			return QueryDirectly("https://proxer.me" + item.ProxerURL)
+		Store:  store,
+		MaxAge: DefaultMaxAge,
+		Retry:  DefaultRetryPolicy,
+		QueryMedia: func(item *Media, header http.Header) (*http.Response, error) {
+			return client.Query(client.URL(item.ProxerURL), header)
+		},
+		QueryRelations: func(item *Media, header http.Header) (*http.Response, error) {
+			return client.Query(client.URL(fmt.Sprintf("/info/%s/relation", getCacheIdentifier(item))), header)
+		},
+		QueryProfileTab: func(profileId string, tabType ProfileTabType, header http.Header) (*http.Response, error) {
+			return client.Query(client.URL(fmt.Sprintf("/user/%s/%s", profileId, tabType)), header)
+		},
+		QueryLeaderboard: func(leaderboardType LeaderboardType, header http.Header) (*http.Response, error) {
+			return client.Query(client.URL(fmt.Sprintf("/users/ranking?s=%s", leaderboardType)), header)
 		},
-		QueryProfileTab: func(profileId string, tabType ProfileTabType) (*http.Response, error) {
-			return QueryDirectly(fmt.Sprintf("https://proxer.me/user/%s/%s", profileId, tabType))
+		QueryPage: func(path string, header http.Header) (*http.Response, error) {
+			return client.Query(client.URL(path), header)
 		},
 		AnimeQueryRatelimiter:      animeRateLimiter,
 		MangaQueryRatelimiter:      mangaRateLImiter,
 		ProfileTabQueryRatelimiter: userRateLImiter,
+		client:                     client,
 	}
 }
@@ -0,0 +1,90 @@
+package proxerscrape
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// cacheEntryKindOf determines the kind of an entry by its key.
+func cacheEntryKindOf(key string) CacheEntryKind {
+	switch {
+	case strings.HasPrefix(key, "profile/"):
+		return CacheEntryProfileTab
+	case strings.HasPrefix(key, "leaderboard/"):
+		return CacheEntryLeaderboard
+	case key == "calendar":
+		return CacheEntryCalendar
+	case strings.HasPrefix(key, "search/"):
+		return CacheEntrySearch
+	case strings.HasPrefix(key, "season/"):
+		return CacheEntrySeason
+	case strings.HasPrefix(key, "toplist/"):
+		return CacheEntryTopList
+	case strings.HasSuffix(key, "_relation"):
+		return CacheEntryRelations
+	}
+	return CacheEntryMedia
+}
+
+// deleteWhere removes all entries matching the filter and returns how many
+// entries have been removed.
+func (cache *Cache) deleteWhere(filter func(key string, metadata CacheMetadata) bool) (int, error) {
+	// Entries mustn't be deleted while walking, so we collect them first.
+	var keys []string
+	err := cache.Store.Walk(func(key string, metadata CacheMetadata) error {
+		if filter(key, metadata) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for index, key := range keys {
+		if err := cache.Store.Delete(key); err != nil {
+			return index, err
+		}
+	}
+	return len(keys), nil
+}
+
+// Clear removes all entries from the cache.
+func (cache *Cache) Clear() (int, error) {
+	return cache.deleteWhere(func(string, CacheMetadata) bool {
+		return true
+	})
+}
+
+// Prune removes all entries that have been fetched longer than the given
+// duration ago.
+func (cache *Cache) Prune(olderThan time.Duration) (int, error) {
+	threshold := time.Now().Add(-olderThan)
+	return cache.deleteWhere(func(key string, metadata CacheMetadata) bool {
+		return metadata.FetchedAt.Before(threshold)
+	})
+}
+
+// ClearKind removes all entries of the given kind, for example all profile
+// tabs. Kinds not contained in CacheEntryKinds are rejected.
+func (cache *Cache) ClearKind(kind CacheEntryKind) (int, error) {
+	known := false
+	for _, candidate := range CacheEntryKinds {
+		known = known || candidate == kind
+	}
+	if !known {
+		return 0, fmt.Errorf("unknown cache entry kind '%s'", kind)
+	}
+	return cache.deleteWhere(func(key string, metadata CacheMetadata) bool {
+		return cacheEntryKindOf(key) == kind
+	})
+}
+
+// InvalidateMedia removes all entries belonging to the media with the given
+// proxer ID, such as the info page and the relations page.
+func (cache *Cache) InvalidateMedia(proxerID string) (int, error) {
+	return cache.deleteWhere(func(key string, metadata CacheMetadata) bool {
+		return key == proxerID || key == proxerID+"_relation"
+	})
+}
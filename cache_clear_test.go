@@ -0,0 +1,47 @@
+package proxerscrape
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCache_Clear(t *testing.T) {
+	cache := &Cache{Store: NewMemoryStore()}
+	fill := func() {
+		cache.Store.Put("296", nil, CacheMetadata{FetchedAt: time.Now()})
+		cache.Store.Put("296_relation", nil, CacheMetadata{FetchedAt: time.Now()})
+		cache.Store.Put("297", nil, CacheMetadata{FetchedAt: time.Now().Add(-48 * time.Hour)})
+		cache.Store.Put("profile/1/anime", nil, CacheMetadata{FetchedAt: time.Now()})
+	}
+
+	for name, testCase := range map[string]struct {
+		clear    func() (int, error)
+		expected int
+	}{
+		"all":   {cache.Clear, 4},
+		"prune": {func() (int, error) { return cache.Prune(24 * time.Hour) }, 1},
+		"kind":  {func() (int, error) { return cache.ClearKind(CacheEntryProfileTab) }, 1},
+		"media": {func() (int, error) { return cache.InvalidateMedia("296") }, 2},
+	} {
+		fill()
+		removed, err := testCase.clear()
+		if err != nil {
+			t.Fatal(err)
+		}
+		stats, _ := cache.Stats()
+		if removed != testCase.expected || stats.Entries != 4-testCase.expected {
+			t.Errorf("%s: removed %d instead of %d, %d entries left", name, removed, testCase.expected, stats.Entries)
+		}
+	}
+}
+
+func TestCache_ClearKind_Unknown(t *testing.T) {
+	cache := &Cache{Store: NewMemoryStore()}
+	cache.Store.Put("profile/1/anime", nil, CacheMetadata{FetchedAt: time.Now()})
+	if _, err := cache.ClearKind("profiles"); err == nil {
+		t.Error("Expected an unknown kind to be rejected")
+	}
+	if stats, _ := cache.Stats(); stats.Entries != 1 {
+		t.Errorf("Expected no entries to be removed, %d left", stats.Entries)
+	}
+}
@@ -1,6 +1,14 @@
 package proxerscrape
 
-import "testing"
+import (
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
 
 func Test_getCacheIdentifier(t *testing.T) {
 	result := getCacheIdentifier(&Media{
@@ -10,3 +18,279 @@ func Test_getCacheIdentifier(t *testing.T) {
 		t.Errorf("Result = %s, instead of 296", result)
 	}
 }
+
+func TestCache_RetrieveMissingID(t *testing.T) {
+	cache, queries := newTestCache(nil)
+	item := &Media{Title: "No Link", Type: MediaTypeSeries}
+	if _, _, err := cache.RetrieveAnimeRawData(item); err != ErrMissingID {
+		t.Errorf("Expected ErrMissingID, got %v", err)
+	}
+	if _, _, err := cache.RetrieveRelationsRawData(item); err != ErrMissingID {
+		t.Errorf("Expected ErrMissingID, got %v", err)
+	}
+	if *queries != 0 {
+		t.Errorf("Entry without ID has been queried %d times", *queries)
+	}
+}
+
+func newTestCache(pages map[string]string) (*Cache, *int) {
+	queries := new(int)
+	respond := func(url string, header http.Header) (*http.Response, error) {
+		*queries++
+		page, present := pages[url]
+		if present && header.Get("If-None-Match") == page {
+			return &http.Response{StatusCode: http.StatusNotModified, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}
+		if !present {
+			return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			// Simply using the content as ETag allows for easy testing.
+			Header: http.Header{"Etag": []string{page}},
+			Body:   io.NopCloser(strings.NewReader(page)),
+		}, nil
+	}
+	return &Cache{
+		Store: NewMemoryStore(),
+		QueryMedia: func(item *Media, header http.Header) (*http.Response, error) {
+			return respond(item.ProxerURL, header)
+		},
+		QueryProfileTab: func(profileID string, tabType ProfileTabType, header http.Header) (*http.Response, error) {
+			return respond("/user/"+profileID+"/"+string(tabType), header)
+		},
+		QueryPage: respond,
+	}, queries
+}
+
+func TestCache_RetrieveAnimeRawData(t *testing.T) {
+	cache, queries := newTestCache(map[string]string{"/info/296": "page"})
+	for i := 0; i < 2; i++ {
+		reader, _, err := cache.RetrieveAnimeRawData(&Media{ProxerURL: "/info/296"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(reader)
+		if string(data) != "page" {
+			t.Errorf("Data = %s, instead of page", data)
+		}
+	}
+	if *queries != 1 {
+		t.Errorf("Queried %d times instead of once", *queries)
+	}
+	if stats, _ := cache.Stats(); stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("Unexpected hits and misses: %+v", stats)
+	}
+
+	_, invalidate, _ := cache.RetrieveAnimeRawData(&Media{ProxerURL: "/info/296"})
+	if err := invalidate(); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := cache.Store.Get("296"); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("Entry wasn't invalidated: %v", err)
+	}
+}
+
+func TestCache_Offline(t *testing.T) {
+	cache, queries := newTestCache(map[string]string{"/info/296": "page", "/info/297": "other"})
+	reader, _, err := cache.RetrieveAnimeRawData(&Media{ProxerURL: "/info/296"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader.Close()
+
+	cache.Offline = true
+	// Stale entries are served as is, instead of being revalidated.
+	cache.MaxAge = func(CacheEntryKind, *Media) time.Duration { return time.Nanosecond }
+	time.Sleep(time.Millisecond)
+	reader, _, err = cache.RetrieveAnimeRawData(&Media{ProxerURL: "/info/296"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader.Close()
+	if _, _, err := cache.RetrieveAnimeRawData(&Media{ProxerURL: "/info/297"}); !errors.Is(err, ErrNotCached) {
+		t.Errorf("Expected ErrNotCached, got %v", err)
+	}
+	if _, err := cache.Session().LoadBookmarks(); !errors.Is(err, ErrNotCached) {
+		t.Errorf("Expected session to fail with ErrNotCached, got %v", err)
+	}
+	if *queries != 1 {
+		t.Errorf("Queried %d times instead of once", *queries)
+	}
+}
+
+func TestCacheStores(t *testing.T) {
+	boltStore, err := NewBoltStore(filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer boltStore.Close()
+
+	for name, store := range map[string]CacheStore{
+		"file":   NewFileStore(t.TempDir()),
+		"memory": NewMemoryStore(),
+		"bolt":   boltStore,
+	} {
+		t.Run(name, func(t *testing.T) {
+			testCacheStore(t, store)
+		})
+	}
+}
+
+func testCacheStore(t *testing.T, store CacheStore) {
+	if _, _, err := store.Get("profile/1/anime"); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("Expected cache miss, got %v", err)
+	}
+	if err := store.Put("profile/1/anime", []byte("data"), CacheMetadata{ETag: "etag", FetchedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, metadata, err := store.Get("profile/1/anime")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(reader)
+	reader.Close()
+	if string(data) != "data" || metadata.ETag != "etag" {
+		t.Errorf("Unexpected entry: %s %+v", data, metadata)
+	}
+
+	stats, err := store.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Entries != 1 || stats.Size != 4 || stats.Oldest.IsZero() || !stats.Oldest.Equal(stats.Newest) {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	if err := store.Delete("profile/1/anime"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete("profile/1/anime"); err != nil {
+		t.Errorf("Deleting missing entry failed: %v", err)
+	}
+}
+
+func TestCache_MaxAge(t *testing.T) {
+	cache, queries := newTestCache(map[string]string{"/info/296": "page"})
+	cache.MaxAge = DefaultMaxAge
+	cache.Store.Put("296", []byte("old"), CacheMetadata{FetchedAt: time.Now().Add(-48 * time.Hour)})
+
+	reader, _, err := cache.RetrieveAnimeRawData(&Media{ProxerURL: "/info/296", Status: StatusFinished})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := io.ReadAll(reader); string(data) != "old" || *queries != 0 {
+		t.Errorf("Finished entry was refreshed: %s", data)
+	}
+
+	reader, _, err = cache.RetrieveAnimeRawData(&Media{ProxerURL: "/info/296", Status: StatusAiring})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := io.ReadAll(reader); string(data) != "page" || *queries != 1 {
+		t.Errorf("Airing entry wasn't refreshed: %s", data)
+	}
+}
+
+func TestCache_Revalidation(t *testing.T) {
+	cache, queries := newTestCache(map[string]string{"/info/296": "page"})
+	cache.MaxAge = DefaultMaxAge
+	fetchedAt := time.Now().Add(-48 * time.Hour)
+	cache.Store.Put("296", []byte("page"), CacheMetadata{FetchedAt: fetchedAt, ETag: "page"})
+
+	reader, _, err := cache.RetrieveAnimeRawData(&Media{ProxerURL: "/info/296", Status: StatusAiring})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := io.ReadAll(reader); string(data) != "page" || *queries != 1 {
+		t.Errorf("Unexpected data after revalidation: %s", data)
+	}
+
+	_, metadata, _ := cache.Store.Get("296")
+	if !metadata.FetchedAt.After(fetchedAt) || metadata.ETag != "page" {
+		t.Errorf("Metadata wasn't refreshed: %+v", metadata)
+	}
+}
+
+func TestGzipStore(t *testing.T) {
+	underlying := NewMemoryStore()
+	underlying.Put("uncompressed", []byte("plain"), CacheMetadata{})
+	store := NewGzipStore(underlying)
+	if err := store.Put("compressed", []byte(strings.Repeat("page", 100)), CacheMetadata{}); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, _ := store.Stats()
+	if stats.Size >= 400+5 {
+		t.Errorf("Data doesn't seem to be compressed, size is %d", stats.Size)
+	}
+
+	for key, expected := range map[string]string{
+		"uncompressed": "plain",
+		"compressed":   strings.Repeat("page", 100),
+	} {
+		reader, _, err := store.Get(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(reader)
+		reader.Close()
+		if string(data) != expected {
+			t.Errorf("Data for %s = %s, instead of %s", key, data, expected)
+		}
+	}
+}
+
+func TestCache_Captcha(t *testing.T) {
+	captcha := `<script src="//www.google.com/recaptcha/api.js"></script>`
+	cache, _ := newTestCache(map[string]string{"/info/296": captcha})
+	if _, _, err := cache.RetrieveAnimeRawData(&Media{ProxerURL: "/info/296"}); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Expected ErrRateLimited, got %v", err)
+	}
+	if _, _, err := cache.Store.Get("296"); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("Captcha page was cached: %v", err)
+	}
+}
+
+func TestCache_CaptchaRetry(t *testing.T) {
+	captcha := `<script src="//www.google.com/recaptcha/api.js"></script>`
+	captchas := 2
+	cache := &Cache{
+		Store: NewMemoryStore(),
+		QueryMedia: func(item *Media, header http.Header) (*http.Response, error) {
+			page := "page"
+			if captchas > 0 {
+				captchas--
+				page = captcha
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(page))}, nil
+		},
+		AnimeQueryRatelimiter: NewLimiter(10, time.Minute),
+	}
+	cache.AnimeQueryRatelimiter.baseCooldown = 10 * time.Millisecond
+	cache.AnimeQueryRatelimiter.maxCooldown = 20 * time.Millisecond
+	var cooldowns int
+	cache.OnCooldown = func(time.Time) { cooldowns++ }
+
+	reader, _, err := cache.RetrieveAnimeRawData(&Media{ProxerURL: "/info/296"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(reader)
+	if string(data) != "page" {
+		t.Errorf("Data = %s, instead of page", data)
+	}
+	if cooldowns != 2 {
+		t.Errorf("Expected two cooldowns, got %d", cooldowns)
+	}
+	if cache.AnimeQueryRatelimiter.strikes != 0 {
+		t.Error("Backoff wasn't reset after the successful retry")
+	}
+
+	// Once the retries are exhausted, the caller is told to stop.
+	captchas = maxCaptchaRetries + 1
+	cache.Store = NewMemoryStore()
+	if _, _, err := cache.RetrieveAnimeRawData(&Media{ProxerURL: "/info/296"}); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Expected ErrRateLimited, got %v", err)
+	}
+}
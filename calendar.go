@@ -0,0 +1,118 @@
+package proxerscrape
+
+import (
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// AiringEpisode is an upcoming episode as announced in the proxer.me calendar.
+type AiringEpisode struct {
+	ProxerURL string
+	Title     string
+	// Episode is the number of the episode, 0 if unknown.
+	Episode int
+	AiresAt time.Time
+	// Duration is the estimated runtime of the episode, which is only known
+	// for episodes returned by UpcomingEpisodes, 0 otherwise.
+	Duration time.Duration
+}
+
+// ProxerID returns the ID of the entry the episode belongs to, or an empty
+// string if unknown.
+func (episode *AiringEpisode) ProxerID() string {
+	return formatMediaID(episode.ProxerURL)
+}
+
+var (
+	calendarDateRegex    = regexp.MustCompile(`(\d{2}\.\d{2}\.\d{4})\s+(\d{2}:\d{2})`)
+	calendarEpisodeRegex = regexp.MustCompile(`(?i)(?:episode|folge|ep\.)\s*(\d+)`)
+)
+
+// proxerLocation is the timezone the calendar is displayed in.
+func proxerLocation() *time.Location {
+	location, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		// Systems without timezone database.
+		return time.FixedZone("CET", 60*60)
+	}
+	return location
+}
+
+// RetrieveCalendarRawData retrieves the airing calendar of proxer.me.
+func (cache *Cache) RetrieveCalendarRawData() (io.ReadCloser, CacheInvalidator, error) {
+	return cache.retrievePage(CacheEntryCalendar, "calendar", "/calendar", cache.ProfileTabQueryRatelimiter)
+}
+
+// ParseCalendar parses the airing calendar. Every row linking an entry and
+// containing a date of the form `02.01.2006 15:04` is considered an episode,
+// since the exact layout of the calendar changes from time to time. The
+// episodes are ordered by their airing time.
+func ParseCalendar(reader io.Reader) ([]AiringEpisode, error) {
+	document, err := newDocument(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	location := proxerLocation()
+	var episodes []AiringEpisode
+	document.Find("tr").Each(func(_ int, row *goquery.Selection) {
+		link := row.Find("a[href*='/info/']").First()
+		href, _ := link.Attr("href")
+		if !proxerIDRegex.MatchString(href) {
+			return
+		}
+		text := row.Text()
+		date := calendarDateRegex.FindStringSubmatch(text)
+		if date == nil {
+			return
+		}
+		airesAt, err := time.ParseInLocation("02.01.2006 15:04", date[1]+" "+date[2], location)
+		if err != nil {
+			return
+		}
+
+		episode := AiringEpisode{
+			ProxerURL: href,
+			Title:     strings.TrimSpace(normalizeText(link.Text())),
+			AiresAt:   airesAt,
+		}
+		if number := calendarEpisodeRegex.FindStringSubmatch(text); number != nil {
+			episode.Episode, _ = strconv.Atoi(number[1])
+		}
+		episodes = append(episodes, episode)
+	})
+
+	sort.SliceStable(episodes, func(a, b int) bool {
+		return episodes[a].AiresAt.Before(episodes[b].AiresAt)
+	})
+	return episodes, nil
+}
+
+// UpcomingEpisodes returns the episodes airing after the given point in time,
+// that belong to entries the user is watching or plans to watch. Their
+// duration is estimated from the entry, see Media.EstimatedEpisodeDuration.
+func UpcomingEpisodes(episodes []AiringEpisode, watchlist *Watchlist, after time.Time) []AiringEpisode {
+	relevant := make(map[string]*Media)
+	for _, category := range []*WatchlistCategory{&watchlist.CurrentlyWatching, &watchlist.ToWatch} {
+		for _, item := range category.Data {
+			if id := item.ProxerID(); id != "" {
+				relevant[id] = item
+			}
+		}
+	}
+
+	var upcoming []AiringEpisode
+	for _, episode := range episodes {
+		if item := relevant[episode.ProxerID()]; item != nil && episode.AiresAt.After(after) {
+			episode.Duration = item.EstimatedEpisodeDuration()
+			upcoming = append(upcoming, episode)
+		}
+	}
+	return upcoming
+}
@@ -0,0 +1,52 @@
+package proxerscrape
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const calendarPage = `<html><body><table>
+<tr><th>Zeit</th><th>Titel</th></tr>
+<tr><td>17.10.2026 16:30</td><td><a href="/info/2"> Later </a></td><td>Episode 5</td></tr>
+<tr><td>16.10.2026 18:00</td><td><a href="/info/1">Tsurune</a></td><td>Folge 3</td></tr>
+<tr><td>16.10.2026 19:00</td><td><a href="/info/3">Not watched</a></td></tr>
+<tr><td>Kein Datum</td><td><a href="/info/4">Unknown</a></td></tr>
+</table></body></html>`
+
+func TestParseCalendar(t *testing.T) {
+	episodes, err := ParseCalendar(strings.NewReader(calendarPage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(episodes) != 3 {
+		t.Fatalf("Expected 3 episodes, got %d", len(episodes))
+	}
+	first := episodes[0]
+	if first.Title != "Tsurune" || first.Episode != 3 || first.ProxerID() != "1" {
+		t.Errorf("Unexpected first episode: %+v", first)
+	}
+	if expected := time.Date(2026, 10, 16, 16, 0, 0, 0, time.UTC); !first.AiresAt.Equal(expected) {
+		t.Errorf("Airing time %s isn't %s", first.AiresAt.UTC(), expected)
+	}
+
+	watchlist := &Watchlist{}
+	watchlist.CurrentlyWatching.Data = []*Media{{ProxerURL: "/info/1"}}
+	watchlist.ToWatch.Data = []*Media{{ProxerURL: "/info/2", Type: MediaTypeOVA}}
+	upcoming := UpcomingEpisodes(episodes, watchlist, time.Date(2026, 10, 16, 17, 0, 0, 0, time.UTC))
+	if len(upcoming) != 1 || upcoming[0].Title != "Later" || upcoming[0].Duration != 25*time.Minute {
+		t.Errorf("Unexpected upcoming episodes: %+v", upcoming)
+	}
+}
+
+func TestUpcomingEpisodes_WithoutID(t *testing.T) {
+	episodes := []AiringEpisode{{ProxerURL: "/calendar", AiresAt: time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)}}
+	if id := episodes[0].ProxerID(); id != "" {
+		t.Errorf("Expected no ID, got %q", id)
+	}
+	watchlist := &Watchlist{}
+	watchlist.CurrentlyWatching.Data = []*Media{{Title: "Without link"}}
+	if upcoming := UpcomingEpisodes(episodes, watchlist, time.Time{}); len(upcoming) != 0 {
+		t.Errorf("Unexpected upcoming episodes: %+v", upcoming)
+	}
+}
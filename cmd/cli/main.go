@@ -1,44 +1,3328 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/Bios-Marcel/proxerscrape"
+	"github.com/Bios-Marcel/proxerscrape/anidb"
+	"github.com/Bios-Marcel/proxerscrape/anilist"
+	"github.com/Bios-Marcel/proxerscrape/browsercookie"
+	"github.com/Bios-Marcel/proxerscrape/export"
+	"github.com/Bios-Marcel/proxerscrape/internal/atomicfile"
+	"github.com/Bios-Marcel/proxerscrape/jsonrpc"
+	"github.com/Bios-Marcel/proxerscrape/kitsu"
+	"github.com/Bios-Marcel/proxerscrape/mapping"
+	"github.com/Bios-Marcel/proxerscrape/notify"
+	"github.com/Bios-Marcel/proxerscrape/reconcile"
+	"github.com/Bios-Marcel/proxerscrape/rest"
+	"github.com/Bios-Marcel/proxerscrape/simkl"
+	"github.com/Bios-Marcel/proxerscrape/trakt"
+	"github.com/Bios-Marcel/proxerscrape/tui"
 	"github.com/spf13/cobra"
 )
 
-var verbose = new(bool)
+var (
+	verbose   = new(bool)
+	storeType = new(string)
+	cacheDir  = new(string)
+	userAgent = new(string)
+	proxy     = new(string)
+	baseURL   = new(string)
+	offline   = new(bool)
+	timeout   = new(time.Duration)
+	cookies   = new(string)
+	browser   = new(string)
+	headless  = new(string)
+	redaction = new(string)
+	profile   = new(string)
+	yes       = new(bool)
+	// maxRequests is the amount of live requests a command may issue
+	// without asking for confirmation.
+	maxRequests = new(int)
+
+	// usedCache is the cache created by the executed command, if any. It's
+	// used for printing a summary once the command has finished.
+	usedCache *proxerscrape.Cache
+)
 
 func main() {
 	rootCmd := cobra.Command{Use: "proxercli"}
 	rootCmd.PersistentFlags().BoolVarP(verbose, "verbose", "v", false, "Decides whether additional, potentially unnecessary extra information, is printed to the terminal.")
+	rootCmd.PersistentFlags().StringVar(storeType, "store", "file", "Storage used for caching pages, either 'file' (one file per page) or 'bolt' (single database file).")
+	rootCmd.PersistentFlags().StringVar(cacheDir, "cache-dir", "", "Directory to cache pages in. Defaults to a directory inside of the user cache directory.")
+	rootCmd.PersistentFlags().StringVar(userAgent, "user-agent", proxerscrape.DefaultUserAgent, "User-Agent sent with every request.")
+	rootCmd.PersistentFlags().StringVar(proxy, "proxy", "", "HTTP or SOCKS proxy to route requests through, for example 'socks5://localhost:1080'.")
+	rootCmd.PersistentFlags().StringVar(baseURL, "base-url", proxerscrape.DefaultBaseURL, "URL pages are retrieved from, for example a mirror or a local proxy.")
+	rootCmd.PersistentFlags().BoolVar(offline, "offline", false, "Only uses cached pages and never queries proxer.me, failing for pages that aren't cached.")
+	rootCmd.PersistentFlags().DurationVar(timeout, "timeout", proxerscrape.DefaultTimeout, "Timeout for a single request.")
+	rootCmd.PersistentFlags().StringVar(cookies, "cookies", "", "Netscape cookies.txt to read the proxer.me cookies, such as the login, from.")
+	rootCmd.PersistentFlags().StringVar(browser, "browser-cookies", "", "Browser to import the proxer.me cookies from, either 'firefox' or 'chrome', the latter only on Linux.")
+	rootCmd.PersistentFlags().StringVar(headless, "headless", "", "Chrome or Chromium executable used for rendering info pages that lack javascript content, such as tags. Off by default, since it's slow.")
+	rootCmd.PersistentFlags().StringVar(redaction, "redact", string(proxerscrape.RedactNone), "How titles and URLs appear in logs, either 'none', 'hash' or 'omit'. IDs are always kept.")
+	rootCmd.PersistentFlags().StringVar(profile, "profile", "polite", "How aggressively proxer.me is queried, either 'polite' (spread out requests) or 'fast' (only respect the ratelimits).")
+	rootCmd.PersistentFlags().BoolVarP(yes, "yes", "y", false, "Don't ask for confirmation before issuing many requests.")
+	rootCmd.PersistentFlags().IntVar(maxRequests, "max-requests", 50, "Amount of live requests a command may issue without asking for confirmation.")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if _, known := proxerscrape.RequestProfiles[*profile]; !known {
+			return fmt.Errorf("unknown profile '%s'", *profile)
+		}
+		switch mode := proxerscrape.Redaction(*redaction); mode {
+		case proxerscrape.RedactNone, proxerscrape.RedactHash, proxerscrape.RedactOmit:
+			proxerscrape.LogRedaction = mode
+			return nil
+		}
+		return fmt.Errorf("unknown redaction '%s'", *redaction)
+	}
 	rootCmd.AddCommand(generateCacheCmd())
-	if err := rootCmd.Execute(); err != nil {
-		log.Fatalln("Error executing root cmd:", err)
+	rootCmd.AddCommand(generateStatsCmd())
+	rootCmd.AddCommand(generateWatchOrderCmd())
+	rootCmd.AddCommand(generateFetchCmd())
+	rootCmd.AddCommand(generateSearchCmd())
+	rootCmd.AddCommand(generateSeasonCmd())
+	rootCmd.AddCommand(generateTopCmd())
+	rootCmd.AddCommand(generateCompareCmd())
+	rootCmd.AddCommand(generateHistoryCmd())
+	rootCmd.AddCommand(generateBookmarksCmd())
+	rootCmd.AddCommand(generateWatchNextCmd())
+	rootCmd.AddCommand(generateParseCmd())
+	rootCmd.AddCommand(generateProgressCmd())
+	rootCmd.AddCommand(generateNoteCmd())
+	rootCmd.AddCommand(generateMapCmd())
+	rootCmd.AddCommand(generateReconcileCmd())
+	rootCmd.AddCommand(generateRPCCmd())
+	rootCmd.AddCommand(generateLedgerCmd())
+	rootCmd.AddCommand(generateRewatchCmd())
+	rootCmd.AddCommand(generateExportCmd())
+	rootCmd.AddCommand(generateReportCmd())
+	rootCmd.AddCommand(generateAniListCmd())
+	rootCmd.AddCommand(generateKitsuCmd())
+	rootCmd.AddCommand(generateNotificationsCmd())
+	rootCmd.AddCommand(generateWatchCmd())
+	rootCmd.AddCommand(generateServeCmd())
+	rootCmd.AddCommand(generateTUICmd())
+	rootCmd.AddCommand(generateFindCmd())
+	rootCmd.AddCommand(generateGenresCmd())
+	rootCmd.AddCommand(generateValidateCmd())
+	rootCmd.AddCommand(generateDevtoolsCmd())
+	rootCmd.AddCommand(generateSyncCmd())
+	rootCmd.AddCommand(generateTraktCmd())
+	rootCmd.AddCommand(generateSimklCmd())
+	rootCmd.AddCommand(generateAniDBCmd())
+	err := rootCmd.Execute()
+	if usedCache != nil {
+		fmt.Fprintln(os.Stderr, "Summary:", usedCache.Metrics())
+	}
+	if errors.Is(err, proxerscrape.ErrLoginRequired) {
+		fmt.Fprintln(os.Stderr, "Some entries require a login, since they are most likely rated 18+. Use --cookies, --browser-cookies or set the environment variables `LOGIN_COOKIE_KEY` and `LOGIN_COOKIE_VALUE` to `joomla_remember_me_XXX=XXX`.")
+	}
+	if err != nil {
+		log.Fatalln("Error executing root cmd:", proxerscrape.RedactText(err.Error()))
+	}
+}
+
+// retrieveWatchlist retrieves and parses the given profile tab. If the
+// ratelimit stops the retrieval, it's deferred to the next run.
+func retrieveWatchlist(cache *proxerscrape.Cache, userID string, tabType proxerscrape.ProfileTabType) (proxerscrape.Watchlist, error) {
+	reader, _, err := cache.RetrieveProfileTabRawData(userID, tabType)
+	if err != nil {
+		return proxerscrape.Watchlist{}, cache.Defer(proxerscrape.DeferProfileTab(userID, tabType), err)
+	}
+	defer reader.Close()
+
+	return parseProfileTab(reader)
+}
+
+// parseProfileTab parses a profile tab, printing the rows that had to be
+// skipped to stderr. In verbose mode, every parsed entry is logged, which
+// helps debugging the parser.
+func parseProfileTab(reader io.Reader) (proxerscrape.Watchlist, error) {
+	var options proxerscrape.ParseOptions
+	if *verbose {
+		options.Logger = log.Default()
+	}
+	watchlist, warnings, err := proxerscrape.ParseProfileMediaTabWithOptions(reader, options)
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+	return watchlist, err
+}
+
+// applyComments sets the reviews the user has written as the review of the
+// watchlist entries, so that exports and syncs include them.
+func applyComments(cache *proxerscrape.Cache, userID string, watchlist *proxerscrape.Watchlist, pages int) error {
+	if pages <= 0 {
+		return nil
+	}
+	comments, err := cache.RetrieveComments(userID, pages)
+	if err != nil {
+		return err
+	}
+	applied := proxerscrape.ApplyComments(watchlist, comments)
+	if *verbose {
+		fmt.Fprintf(os.Stderr, "Applied %d of %d comments.\n", applied, len(comments))
+	}
+	return nil
+}
+
+// resolveCacheDir returns the directory passed via flag or the default one.
+func resolveCacheDir() (string, error) {
+	if *cacheDir != "" {
+		return *cacheDir, nil
+	}
+	return proxerscrape.DefaultCacheDir()
+}
+
+// createCache creates the cache configured via the persistent flags. The
+// returned function has to be called once the cache isn't needed anymore.
+func createCache() (*proxerscrape.Cache, func(), error) {
+	cache, closeCache, err := openCache()
+	if err != nil {
+		return nil, nil, err
+	}
+	cache.OnCooldown = func(until time.Time) {
+		fmt.Fprintf(os.Stderr, "Ratelimit hit, pausing requests until %s (%s).\n",
+			until.Format("15:04:05"), time.Until(until).Round(time.Second))
+	}
+	if !*yes {
+		cache.MaxRequests = *maxRequests
+		cache.ConfirmRequests = func(pending int) error {
+			return confirmLiveRequests(cache, pending)
+		}
+	}
+	usedCache = cache
+	return cache, closeCache, nil
+}
+
+// resumeQueue performs the fetches deferred by previous runs. Being
+// ratelimited again isn't an error, the fetches simply stay queued.
+func resumeQueue(cache *proxerscrape.Cache) error {
+	if cache.Queue == nil || cache.Queue.Len() == 0 {
+		return nil
 	}
+
+	fmt.Fprintf(os.Stderr, "Resuming %d deferred fetches.\n", cache.Queue.Len())
+	completed, err := cache.ResumeQueue()
+	if errors.Is(err, proxerscrape.ErrRateLimited) {
+		fmt.Fprintf(os.Stderr, "Ratelimited again, %d fetches remain queued.\n", cache.Queue.Len())
+		return nil
+	}
+	if *verbose {
+		fmt.Fprintf(os.Stderr, "Completed %d deferred fetches.\n", completed)
+	}
+	return err
+}
+
+// errAborted is returned if the user declined issuing the requests.
+var errAborted = errors.New("aborted")
+
+// stdinBusy is set by commands that read stdin otherwise, so that exceeding
+// --max-requests fails instead of asking.
+var stdinBusy bool
+
+// confirmRequests announces the live requests loading the extra data of the
+// given entries requires, so that the user is asked upfront instead of
+// midway, see confirmLiveRequests.
+func confirmRequests(cache *proxerscrape.Cache, items []*proxerscrape.Media) error {
+	pending, err := cache.PendingMediaRequests(items)
+	if err != nil {
+		return err
+	}
+	return cache.Reserve(pending)
+}
+
+// confirmLiveRequests asks the user for confirmation, if a command requires
+// more live requests than allowed via --max-requests. This protects from
+// accidentally hammering proxer.me. If the amount isn't known upfront,
+// pending is 0.
+func confirmLiveRequests(cache *proxerscrape.Cache, pending int) error {
+	if stdinBusy {
+		return fmt.Errorf("%w, pass --yes to allow more than %d", proxerscrape.ErrRequestLimit, *maxRequests)
+	}
+	if pending > 0 {
+		fmt.Fprintf(os.Stderr, "This requires %d requests to proxer.me, which takes about %s. Continue? [y/N] ",
+			pending, cache.EstimateDuration(pending).Round(time.Minute))
+	} else {
+		fmt.Fprintf(os.Stderr, "This requires more than %d requests to proxer.me. Continue? [y/N] ", *maxRequests)
+	}
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+		return errAborted
+	}
+	return nil
+}
+
+func openCache() (*proxerscrape.Cache, func(), error) {
+	directory, err := resolveCacheDir()
+	if err != nil {
+		return nil, nil, err
+	}
+	httpClient, err := proxerscrape.NewHTTPClient(*timeout, *proxy)
+	if err != nil {
+		return nil, nil, err
+	}
+	options := []proxerscrape.CacheOption{
+		proxerscrape.WithCacheDir(directory),
+		proxerscrape.WithHTTPClient(httpClient),
+		proxerscrape.WithUserAgent(*userAgent),
+		proxerscrape.WithBaseURL(*baseURL),
+		proxerscrape.WithOffline(*offline),
+		proxerscrape.WithLogger(log.Default()),
+		proxerscrape.WithLoginCookieFromEnv(),
+		proxerscrape.WithRequestProfile(proxerscrape.RequestProfiles[*profile]),
+	}
+	if *cookies != "" {
+		imported, err := proxerscrape.LoadCookiesTxt(*cookies)
+		if err != nil {
+			return nil, nil, err
+		}
+		options = append(options, proxerscrape.WithCookies(imported...))
+	}
+	if *browser != "" {
+		imported, err := browsercookie.Load(*browser)
+		if err != nil {
+			return nil, nil, err
+		}
+		options = append(options, proxerscrape.WithCookies(imported...))
+	}
+	if *headless != "" {
+		options = append(options, proxerscrape.WithHeadlessFallback(&proxerscrape.CommandRenderer{Command: *headless, Timeout: *timeout}))
+	}
+
+	switch *storeType {
+	case "file":
+		cache, err := proxerscrape.NewCache(options...)
+		return cache, func() {}, err
+	case "bolt":
+		if err := os.MkdirAll(directory, os.ModePerm); err != nil {
+			return nil, nil, err
+		}
+		store, err := proxerscrape.NewBoltStore(filepath.Join(directory, "cache.db"))
+		if err != nil {
+			return nil, nil, err
+		}
+		cache, err := proxerscrape.NewCache(append(options, proxerscrape.WithStore(proxerscrape.NewGzipStore(store)))...)
+		if err != nil {
+			store.Close()
+			return nil, nil, err
+		}
+		return cache, func() { store.Close() }, nil
+	}
+	return nil, nil, fmt.Errorf("unknown store '%s'", *storeType)
 }
 
 func generateCacheCmd() *cobra.Command {
 	cacheCmd := &cobra.Command{
 		Use:     "cache",
-		Short:   "TODO",
+		Short:   "Inspects and manages the cache of retrieved pages.",
 		Example: "cache clear",
 	}
 	cacheCmd.AddCommand(&cobra.Command{
 		Use:     "info",
-		Short:   "TODO",
+		Short:   "Prints statistics about the cached pages.",
 		Example: "cache info",
-		Run: func(cmd *cobra.Command, args []string) {
-			log.Println("cache info (DUMMY CALL)")
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+
+			stats, err := cache.Stats()
+			if err != nil {
+				return err
+			}
+			directory, err := resolveCacheDir()
+			if err != nil {
+				return err
+			}
+			fmt.Println("Location:", directory)
+			fmt.Println("Entries:", stats.Entries)
+			fmt.Printf("Size: %.2f MiB\n", float64(stats.Size)/1024/1024)
+			if stats.Entries > 0 {
+				fmt.Println("Oldest entry:", stats.Oldest.Format(time.RFC1123))
+				fmt.Println("Newest entry:", stats.Newest.Format(time.RFC1123))
+			}
+			if cache.Queue != nil && cache.Queue.Len() > 0 {
+				fmt.Println("Deferred fetches:", cache.Queue.Len())
+			}
+			if cooldown := cache.Cooldown(); !cooldown.IsZero() {
+				fmt.Printf("Ratelimit cooldown until: %s (%s left)\n",
+					cooldown.Format(time.RFC1123), time.Until(cooldown).Round(time.Second))
+			}
+			return nil
 		},
 	})
-	cacheCmd.AddCommand(&cobra.Command{
+	var olderThan time.Duration
+	var kind, mediaID string
+	clearCmd := &cobra.Command{
 		Use:     "clear",
-		Short:   "TODO",
-		Example: "cache clear",
-		Run: func(cmd *cobra.Command, args []string) {
-			log.Println("clearing cache (DUMMY CALL)")
+		Short:   "Removes cached pages, either all or only those matching the given filters.",
+		Example: "cache clear --older-than 720h",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filters := 0
+			for _, name := range []string{"media", "kind", "older-than"} {
+				if cmd.Flags().Changed(name) {
+					filters++
+				}
+			}
+			if filters > 1 {
+				return errors.New("--media, --kind and --older-than can't be combined")
+			}
+
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+
+			var removed int
+			switch {
+			case mediaID != "":
+				removed, err = cache.InvalidateMedia(mediaID)
+			case kind != "":
+				removed, err = cache.ClearKind(proxerscrape.CacheEntryKind(kind))
+			case olderThan > 0:
+				removed, err = cache.Prune(olderThan)
+			default:
+				removed, err = cache.Clear()
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Removed %d entries.\n", removed)
+			return nil
 		},
-	})
+	}
+	clearCmd.Flags().DurationVar(&olderThan, "older-than", 0, "Only remove entries fetched longer ago than the given duration.")
+	kindNames := make([]string, 0, len(proxerscrape.CacheEntryKinds))
+	for _, entryKind := range proxerscrape.CacheEntryKinds {
+		kindNames = append(kindNames, string(entryKind))
+	}
+	clearCmd.Flags().StringVar(&kind, "kind", "", "Only remove entries of the given kind ("+strings.Join(kindNames, ", ")+").")
+	clearCmd.Flags().StringVar(&mediaID, "media", "", "Only remove entries of the media with the given proxer ID.")
+	cacheCmd.AddCommand(clearCmd)
+
+	var userID, tab string
+	var budget time.Duration
+	warmCmd := &cobra.Command{
+		Use:   "warm",
+		Short: "Retrieves the info pages of a watchlist that aren't cached yet, within a time budget.",
+		Long: `Retrieves the info pages of a watchlist that aren't cached yet or are stale,
+as fast as the ratelimit allows, but only within the given time budget. Every
+page is cached right away, so running the command again continues where the
+previous run stopped.`,
+		Example: "cache warm --user 252835 --tab anime --budget 2h",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+
+			watchlist, err := retrieveWatchlist(cache, userID, proxerscrape.ProfileTabType(tab))
+			if err != nil {
+				return err
+			}
+			result, err := cache.WarmCache(watchlist.All(), budget, func(event proxerscrape.ProgressEvent) {
+				if *verbose && event.Type == proxerscrape.ProgressFailed {
+					fmt.Fprintf(os.Stderr, "Failed retrieving %s: %s\n", event.Item.Title, event.Err)
+				} else if *verbose && event.Type == proxerscrape.ProgressDone {
+					fmt.Fprintf(os.Stderr, "[%d/%d] %s\n", event.Finished, event.Total, event.Item.Title)
+				}
+			})
+			if errors.Is(err, proxerscrape.ErrRateLimited) {
+				fmt.Fprintln(os.Stderr, "Ratelimited, stopping early.")
+			} else if err != nil {
+				return err
+			}
+			fmt.Printf("Fetched %d pages, %d failed, %d remaining.\n", result.Fetched, result.Failed, result.Remaining)
+			return nil
+		},
+	}
+	warmCmd.Flags().StringVar(&userID, "user", "", "ID of the user whose watchlist is walked.")
+	warmCmd.Flags().StringVar(&tab, "tab", string(proxerscrape.ProfileTabAnime), "Profile tab to walk (anime, manga or novel).")
+	warmCmd.Flags().DurationVar(&budget, "budget", time.Hour, "Maximum time to spend, 0 meaning no limit.")
+	warmCmd.MarkFlagRequired("user")
+	cacheCmd.AddCommand(warmCmd)
 
 	return cacheCmd
 }
+
+func generateStatsCmd() *cobra.Command {
+	var userID string
+	statsCmd := &cobra.Command{
+		Use:     "stats",
+		Short:   "Prints statistics about a user, such as their leaderboard ranks.",
+		Example: "stats --user 252835",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+			if err := resumeQueue(cache); err != nil {
+				return err
+			}
+
+			profile, err := cache.RetrieveProfile(userID)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s (%s), registered %s\n", profile.Username, profile.Rank, profile.RegisteredAt.Format("2006-01-02"))
+			fmt.Printf("Points: %d (anime %d, manga %d, uploads %d)\n", profile.Points(), profile.AnimePoints, profile.MangaPoints, profile.UploadPoints)
+
+			for _, leaderboardType := range []proxerscrape.LeaderboardType{
+				proxerscrape.LeaderboardEpisodes,
+				proxerscrape.LeaderboardActivity,
+			} {
+				reader, _, err := cache.RetrieveLeaderboardRawData(leaderboardType)
+				if err != nil {
+					return err
+				}
+				leaderboard, err := proxerscrape.ParseLeaderboard(reader, leaderboardType)
+				reader.Close()
+				if err != nil {
+					return err
+				}
+
+				if entry, found := leaderboard.RankOf(userID); found {
+					fmt.Printf("Leaderboard %s: #%d (%d)\n", leaderboardType, entry.Rank, entry.Value)
+				} else {
+					fmt.Printf("Leaderboard %s: not ranked\n", leaderboardType)
+				}
+			}
+
+			userData, _, err := loadUserData()
+			if err != nil {
+				return err
+			}
+			fmt.Println("Rewatches:", userData.TotalRewatches())
+			return nil
+		},
+	}
+	statsCmd.Flags().StringVar(&userID, "user", "", "ID of the user to print statistics for.")
+	statsCmd.MarkFlagRequired("user")
+
+	return statsCmd
+}
+
+func generateWatchOrderCmd() *cobra.Command {
+	var format string
+	var depth int
+	watchOrderCmd := &cobra.Command{
+		Use:     "watch-order <id>",
+		Short:   "Prints the relations of a franchise in watch order as a DOT or Mermaid graph.",
+		Example: "watch-order 296 --format mermaid",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+			if err := resumeQueue(cache); err != nil {
+				return err
+			}
+
+			graph, err := proxerscrape.BuildRelationGraph(cache.RetrieveRelationsRawData, &proxerscrape.Media{
+				ProxerURL: "/info/" + args[0],
+			}, depth)
+			if err != nil {
+				return err
+			}
+			// Hints on where to start watching are only present on the
+			// detail page of the entry.
+			// The graph is still useful without them, so a dead detail page
+			// isn't fatal.
+			retrieveRawData := cache.RetrieveAnimeRawData
+			if graph.Root.Type.IsManga() || graph.Root.Type.IsNovel() {
+				retrieveRawData = cache.RetrieveMangaRawData
+			}
+			rootCategory := proxerscrape.WatchlistCategory{Data: []*proxerscrape.Media{graph.Root}}
+			if err := rootCategory.LoadExtraData(retrieveRawData); errors.Is(err, proxerscrape.ErrDeadLink) {
+				fmt.Fprintln(os.Stderr, "The detail page doesn't exist anymore, there are no hints on where to start.")
+			} else if err != nil {
+				return err
+			}
+
+			switch format {
+			case "dot":
+				return graph.WriteDOT(os.Stdout)
+			case "mermaid":
+				return graph.WriteMermaid(os.Stdout)
+			}
+			return fmt.Errorf("unknown format '%s'", format)
+		},
+	}
+	watchOrderCmd.Flags().StringVar(&format, "format", "dot", "Output format, either 'dot' or 'mermaid'.")
+	watchOrderCmd.Flags().IntVar(&depth, "depth", 2, "How many levels of relations to follow, each level costs a request per newly found entry.")
+
+	return watchOrderCmd
+}
+
+func generateWatchNextCmd() *cobra.Command {
+	var userID string
+	var count int
+	var types, weightOverrides []string
+	var sequels bool
+	var filter proxerscrape.RecommendationFilter
+	watchNextCmd := &cobra.Command{
+		Use:   "watchnext",
+		Short: "Suggests what to watch next from the to-watch list.",
+		Long: `Suggests what to watch next from the to-watch list.
+
+Entries are scored by the recommenders configured in the user data, see
+proxerscrape.UserData.RecommenderWeights. The available recommenders are
+rating, votes, genre, length, sequel and airing, their weights can be
+overridden via --weight. Pre-airing entries, entries that don't exist
+anymore and sequels of series that haven't been watched yet are never
+suggested. Using --sequels, new entries of watched franchises are listed as
+well.`,
+		Example: "watchnext --user 252835 --genre action --exclude-genre horror --max-episodes 26 --count 5",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, name := range types {
+				mediaType := proxerscrape.MediaType(name)
+				if !mediaType.IsAnime() {
+					return fmt.Errorf("unknown type '%s'", name)
+				}
+				filter.Types = append(filter.Types, mediaType)
+			}
+
+			userData, _, err := loadUserData()
+			if err != nil {
+				return err
+			}
+			filter.UserData = userData
+			weights := userData.Weights()
+			for _, override := range weightOverrides {
+				name, value, found := strings.Cut(override, "=")
+				weight, err := strconv.ParseFloat(value, 64)
+				if !found || err != nil {
+					return fmt.Errorf("invalid weight '%s', expected name=weight", override)
+				}
+				weights[name] = weight
+			}
+			recommender, err := proxerscrape.NewBlendedRecommender(weights)
+			if err != nil {
+				return err
+			}
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+
+			watchlist, err := retrieveWatchlist(cache, userID, proxerscrape.ProfileTabAnime)
+			if err != nil {
+				return err
+			}
+			// Genre preferences are based on the watched entries.
+			categories := []*proxerscrape.WatchlistCategory{&watchlist.ToWatch}
+			if weights["genre"] > 0 {
+				categories = append(categories, &watchlist.Watched)
+			}
+			for _, category := range categories {
+				if err := confirmRequests(cache, category.Data); err != nil {
+					return err
+				}
+				// Failed entries simply lack a rating, so we can still make
+				// suggestions based on the others.
+				var extraDataError proxerscrape.ExtraDataError
+				if err := category.LoadExtraData(cache.RetrieveAnimeRawData); errors.As(err, &extraDataError) {
+					if *verbose {
+						for _, itemError := range extraDataError {
+							fmt.Fprintln(os.Stderr, itemError)
+						}
+					}
+				} else if err != nil {
+					return err
+				}
+			}
+
+			recommendations, err := proxerscrape.Recommend(&watchlist, recommender)
+			if err != nil {
+				return err
+			}
+			recommendations = filter.Filter(recommendations)
+			// Prerequisites are checked lazily, since each check requires
+			// the relations of the entry.
+			var suggested int
+			for _, recommendation := range recommendations {
+				if suggested >= count {
+					break
+				}
+				prerequisites, err := watchlist.Prerequisites(cache.RetrieveRelationsRawData, recommendation.Media)
+				if err != nil {
+					return err
+				}
+				if len(prerequisites) > 0 {
+					if *verbose {
+						fmt.Fprintf(os.Stderr, "Skipping %s, watch %s first.\n", recommendation.Media.Title, prerequisites[0].Title)
+					}
+					continue
+				}
+				fmt.Printf("%s (%.2f)\n", recommendation.Media.Title, recommendation.Score)
+				suggested++
+			}
+			if suggested == 0 {
+				fmt.Println("It seems like there's nothing matching on your watchlist right now.")
+			}
+
+			if sequels {
+				pending, err := cache.PendingRelationRequests(watchlist.Watched.Data)
+				if err != nil {
+					return err
+				}
+				if err := cache.Reserve(pending); err != nil {
+					return err
+				}
+				// Entries whose relations failed to load are skipped, the
+				// sequels of the others are still worth listing.
+				next, err := watchlist.NextInSeries(cache.RetrieveRelationsRawData)
+				var extraDataError proxerscrape.ExtraDataError
+				if errors.As(err, &extraDataError) {
+					if *verbose {
+						for _, itemError := range extraDataError {
+							fmt.Fprintln(os.Stderr, itemError)
+						}
+					}
+				} else if err != nil {
+					return err
+				}
+				if len(next) > 0 {
+					fmt.Println("\nNot on your watchlist yet:")
+				}
+				for _, item := range next {
+					fmt.Printf("%s (%s)\n", item.Title, item.ProxerID())
+				}
+			}
+			return nil
+		},
+	}
+	watchNextCmd.Flags().StringVar(&userID, "user", "", "ID of the user whose to-watch list is used.")
+	watchNextCmd.Flags().IntVar(&count, "count", 1, "Amount of suggestions to print.")
+	watchNextCmd.Flags().StringSliceVar(&filter.IncludeGenres, "genre", nil, "Genres, by name or ID, that suggestions must have.")
+	watchNextCmd.Flags().StringSliceVar(&filter.ExcludeGenres, "exclude-genre", nil, "Genres, by name or ID, that suggestions must not have.")
+	watchNextCmd.Flags().Uint16Var(&filter.MaxEpisodes, "max-episodes", 0, "Maximum episode count of suggestions.")
+	watchNextCmd.Flags().Float64Var(&filter.MinRating, "min-rating", 0, "Minimum average rating of suggestions on proxer.me.")
+	watchNextCmd.Flags().StringSliceVar(&types, "type", nil, "Allowed types, such as 'series', 'movie', 'ova' or 'special'.")
+	watchNextCmd.Flags().StringSliceVar(&filter.Tags, "tag", nil, "Tags, see 'note tag', that suggestions must have.")
+	watchNextCmd.Flags().BoolVar(&sequels, "sequels", false, "Also list sequels of watched entries that aren't on any list yet.")
+	watchNextCmd.Flags().StringSliceVar(&weightOverrides, "weight", nil, "Weight of a recommender, such as 'genre=2'. Overrides the user data.")
+	watchNextCmd.MarkFlagRequired("user")
+	return watchNextCmd
+}
+
+func generateSearchCmd() *cobra.Command {
+	var query proxerscrape.SearchQuery
+	var mediaType, season string
+	searchCmd := &cobra.Command{
+		Use:     "search [name]",
+		Short:   "Searches proxer.me for entries matching the given criteria.",
+		Example: "search --genre Action --type series --year 2012 --season Q4",
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				query.Name = args[0]
+			}
+			query.Type = proxerscrape.MediaType(mediaType)
+			query.Season = proxerscrape.Season(strings.ToUpper(season))
+			if err := query.Validate(); err != nil {
+				return err
+			}
+
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+
+			results, err := cache.Search(query)
+			if err != nil {
+				return err
+			}
+			for _, item := range results {
+				fmt.Printf("%s (%s, %.2f) %s%s\n", item.Title, item.RawType, item.Rating, cache.BaseURL(), item.ProxerURL)
+			}
+			return nil
+		},
+	}
+	searchCmd.Flags().StringSliceVar(&query.Genres, "genre", nil, "Genre IDs, such as 'Action', that results must have.")
+	searchCmd.Flags().StringVar(&mediaType, "type", "", "Type of the results, such as 'series', 'movie', 'ova', 'special' or 'manga'.")
+	searchCmd.Flags().UintVar(&query.Year, "year", 0, "Year the results were released in.")
+	searchCmd.Flags().StringVar(&season, "season", "", "Season the results were released in, Q1 to Q4.")
+	searchCmd.Flags().StringVar(&query.Language, "language", "", "Language of subtitles or dubs, either 'de' or 'en'.")
+	searchCmd.Flags().IntVar(&query.Page, "page", 1, "Page of the results.")
+	return searchCmd
+}
+
+func generateSeasonCmd() *cobra.Command {
+	var userID string
+	seasonCmd := &cobra.Command{
+		Use:   "season <year> <Q1|Q2|Q3|Q4>",
+		Short: "Lists the anime released in the given season, grouped by airing weekday.",
+		Long: `Lists the anime released in the given season, grouped by airing weekday.
+
+Passing a user, only entries not on the user's watchlist are listed.`,
+		Example: "season 2024 Q4 --user 252835",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			year, err := strconv.ParseUint(args[0], 10, 32)
+			if err != nil {
+				return fmt.Errorf("invalid year '%s'", args[0])
+			}
+			season := proxerscrape.Season(strings.ToUpper(args[1]))
+			switch season {
+			case proxerscrape.Q1, proxerscrape.Q2, proxerscrape.Q3, proxerscrape.Q4:
+			default:
+				return fmt.Errorf("unknown season '%s'", args[1])
+			}
+
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+
+			entries, err := cache.ScrapeSeason(season, uint(year))
+			if err != nil {
+				return err
+			}
+			if userID != "" {
+				watchlist, err := retrieveWatchlist(cache, userID, proxerscrape.ProfileTabAnime)
+				if err != nil {
+					return err
+				}
+				entries = proxerscrape.NotOnWatchlist(entries, &watchlist)
+			}
+
+			// Weeks start on monday in Germany, entries without weekday
+			// come last.
+			sort.SliceStable(entries, func(a, b int) bool {
+				return weekdayOrder(entries[a]) < weekdayOrder(entries[b])
+			})
+			for index, entry := range entries {
+				if index == 0 || weekdayOrder(entries[index-1]) != weekdayOrder(entry) {
+					if entry.KnownWeekday {
+						fmt.Println(entry.Weekday)
+					} else {
+						fmt.Println("Unknown weekday")
+					}
+				}
+				fmt.Printf("\t%s (%s, %s)\n", entry.Title, entry.RawType, entry.Status)
+			}
+			return nil
+		},
+	}
+	seasonCmd.Flags().StringVar(&userID, "user", "", "ID of the user whose watchlist entries are omitted.")
+	return seasonCmd
+}
+
+func weekdayOrder(entry proxerscrape.SeasonEntry) int {
+	if !entry.KnownWeekday {
+		return 7
+	}
+	return (int(entry.Weekday) + 6) % 7
+}
+
+func generateTopCmd() *cobra.Command {
+	var kind, userID string
+	var pages int
+	topCmd := &cobra.Command{
+		Use:     "top",
+		Short:   "Lists the top rated or most popular anime.",
+		Long:    "Lists the top rated or most popular anime. Passing a user, only entries not on the user's watchlist are listed.",
+		Example: "top --kind popular --pages 2 --user 252835",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			topListKind := proxerscrape.TopListKind(kind)
+			if kind == "rated" {
+				topListKind = proxerscrape.TopListRated
+			}
+			if topListKind != proxerscrape.TopListRated && topListKind != proxerscrape.TopListPopular {
+				return fmt.Errorf("unknown kind '%s'", kind)
+			}
+
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+
+			entries, err := cache.TopList(topListKind, pages)
+			if err != nil {
+				return err
+			}
+			known := make(map[string]bool)
+			if userID != "" {
+				watchlist, err := retrieveWatchlist(cache, userID, proxerscrape.ProfileTabAnime)
+				if err != nil {
+					return err
+				}
+				for _, item := range watchlist.All() {
+					known[item.ProxerID()] = true
+				}
+			}
+			for rank, item := range entries {
+				if !known[item.ProxerID()] {
+					fmt.Printf("%d. %s (%.2f)\n", rank+1, item.Title, item.Rating)
+				}
+			}
+			return nil
+		},
+	}
+	topCmd.Flags().StringVar(&kind, "kind", "rated", "Ranking to list, either 'rated' or 'popular'.")
+	topCmd.Flags().IntVar(&pages, "pages", 1, "Amount of pages to retrieve.")
+	topCmd.Flags().StringVar(&userID, "user", "", "ID of the user whose watchlist entries are omitted.")
+	return topCmd
+}
+
+func generateCompareCmd() *cobra.Command {
+	var limit int
+	compareCmd := &cobra.Command{
+		Use:   "compare <user> [other user]",
+		Short: "Compares the anime watchlists of two users.",
+		Long: `Compares the anime watchlists of two users.
+
+Given a single user, the user is compared with all of their friends instead,
+printing how many entries they have in common.`,
+		Example: "compare 252835 123456",
+		Args:    cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+
+			watchlist, err := retrieveWatchlist(cache, args[0], proxerscrape.ProfileTabAnime)
+			if err != nil {
+				return err
+			}
+
+			if len(args) == 1 {
+				friends, err := cache.RetrieveFriends(args[0])
+				if err != nil {
+					return err
+				}
+				names := make(map[string]string)
+				var friendIDs []string
+				for _, friend := range friends {
+					names[friend.UserID] = friend.Username
+					friendIDs = append(friendIDs, friend.UserID)
+				}
+				for result := range cache.FetchProfiles(friendIDs) {
+					if result.Err != nil {
+						fmt.Fprintf(os.Stderr, "Error fetching %s: %s\n", names[result.ProfileID], result.Err)
+						continue
+					}
+					comparison := proxerscrape.CompareWatchlists(watchlist, result.Watchlist)
+					fmt.Printf("%s: %d shared, %d rated differently, %d watched only by them\n",
+						names[result.ProfileID], len(comparison.Shared), len(comparison.Disagreements), len(comparison.WatchedOnlyByB))
+				}
+				return nil
+			}
+
+			other, err := retrieveWatchlist(cache, args[1], proxerscrape.ProfileTabAnime)
+			if err != nil {
+				return err
+			}
+			comparison := proxerscrape.CompareWatchlists(watchlist, other)
+			fmt.Printf("Shared entries: %d\n", len(comparison.Shared))
+			fmt.Printf("\nRated differently (%d)\n", len(comparison.Disagreements))
+			for index, shared := range comparison.Disagreements {
+				if index == limit {
+					break
+				}
+				fmt.Printf("\t%s (%d vs %d)\n", shared.A.Title, shared.A.UserRating, shared.B.UserRating)
+			}
+			for _, list := range []struct {
+				userID string
+				items  []*proxerscrape.Media
+			}{
+				{args[0], comparison.WatchedOnlyByA},
+				{args[1], comparison.WatchedOnlyByB},
+			} {
+				fmt.Printf("\nOnly watched by %s (%d)\n", list.userID, len(list.items))
+				for index, item := range list.items {
+					if index == limit {
+						break
+					}
+					fmt.Printf("\t%s\n", item.Title)
+				}
+			}
+			return nil
+		},
+	}
+	compareCmd.Flags().IntVar(&limit, "limit", 20, "Maximum amount of entries printed per list, -1 meaning unlimited.")
+	return compareCmd
+}
+
+func generateHistoryCmd() *cobra.Command {
+	var userID, period string
+	var pages int
+	historyCmd := &cobra.Command{
+		Use:     "history",
+		Short:   "Prints how many episodes a user watched per week or month, based on their history.",
+		Example: "history --user 252835 --pages 10 --by month",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			historyPeriod := proxerscrape.HistoryPeriod(period)
+			if historyPeriod != proxerscrape.HistoryWeek && historyPeriod != proxerscrape.HistoryMonth {
+				return fmt.Errorf("unknown period '%s'", period)
+			}
+
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+
+			events, err := cache.RetrieveHistory(userID, pages)
+			if err != nil {
+				return err
+			}
+			layout := "2006-01-02"
+			if historyPeriod == proxerscrape.HistoryMonth {
+				layout = "2006-01"
+			}
+			for _, count := range proxerscrape.EpisodesPerPeriod(events, historyPeriod) {
+				fmt.Printf("%s: %d\n", count.Start.Format(layout), count.Count)
+			}
+
+			titles := make(map[string]string)
+			for _, event := range events {
+				titles[event.ProxerID()] = event.Title
+			}
+			rewatches := proxerscrape.DetectRewatches(events)
+			if len(rewatches) > 0 {
+				ids := make([]string, 0, len(rewatches))
+				for id := range rewatches {
+					ids = append(ids, id)
+				}
+				sort.Slice(ids, func(a, b int) bool {
+					return rewatches[ids[a]] > rewatches[ids[b]]
+				})
+				fmt.Println("\nRewatches")
+				for _, id := range ids {
+					fmt.Printf("\t%s: %d\n", titles[id], rewatches[id])
+				}
+			}
+			return nil
+		},
+	}
+	historyCmd.Flags().StringVar(&userID, "user", "", "ID of the user whose history is used.")
+	historyCmd.Flags().IntVar(&pages, "pages", 1, "Amount of history pages to retrieve.")
+	historyCmd.Flags().StringVar(&period, "by", string(proxerscrape.HistoryWeek), "Period to count episodes per, either 'week' or 'month'.")
+	historyCmd.MarkFlagRequired("user")
+	return historyCmd
+}
+
+func generateBookmarksCmd() *cobra.Command {
+	var apply bool
+	bookmarksCmd := &cobra.Command{
+		Use:   "bookmarks",
+		Short: "Lists the bookmarks of the logged in user, including the language.",
+		Long: `Lists the bookmarks of the logged in user, including the language.
+
+Using --apply, the bookmarked episodes are saved as the progress of their
+language in the user data, which is used by 'progress' and watchtimeleft.
+This requires being logged in, see --cookies.`,
+		Example: "bookmarks --apply",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+
+			bookmarks, err := cache.Session().LoadBookmarks()
+			if err != nil {
+				return err
+			}
+			for _, bookmark := range bookmarks {
+				fmt.Printf("%s: %d (%s)\n", bookmark.Title, bookmark.Episode, bookmark.Language)
+			}
+			if !apply {
+				return nil
+			}
+
+			userData, userDataPath, err := loadUserData()
+			if err != nil {
+				return err
+			}
+			applied := userData.ApplyBookmarks(bookmarks)
+			fmt.Fprintf(os.Stderr, "Applied %d bookmarks.\n", applied)
+			return userData.Save(userDataPath)
+		},
+	}
+	bookmarksCmd.Flags().BoolVar(&apply, "apply", false, "Save the bookmarked episodes as progress per language.")
+	return bookmarksCmd
+}
+
+func generateNotificationsCmd() *cobra.Command {
+	var userID string
+	var notifierFlags notifierFlags
+	notificationsCmd := &cobra.Command{
+		Use:   "notifications",
+		Short: "Lists the notifications of the logged in user.",
+		Long: `Lists the notifications of the logged in user. This requires being
+logged in, see --cookies.
+
+Using --user, only new episodes and chapters of entries the user is
+currently watching are listed. Besides stdout, notifications can be sent to
+the desktop, a webhook or a Discord channel.`,
+		Example: "notifications --user 123456",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+
+			notifications, err := cache.Session().LoadNotifications()
+			if err != nil {
+				return err
+			}
+			if userID != "" {
+				watchlist, err := retrieveWatchlist(cache, userID, proxerscrape.ProfileTabAnime)
+				if err != nil {
+					return err
+				}
+				notifications = proxerscrape.NewEpisodes(notifications, &watchlist)
+			}
+			notifier := notifierFlags.notifier()
+			for _, notification := range notifications {
+				message := notify.Message{Text: notification.Text}
+				if notification.Kind != proxerscrape.NotificationOther {
+					message = notify.Message{
+						Title: notification.Title,
+						Text:  fmt.Sprintf("%s %d (%s)", notification.Kind, notification.Episode, notification.Language),
+						URL:   cache.BaseURL() + notification.ProxerURL,
+					}
+				}
+				if err := notifier.Notify(message); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	notificationsCmd.Flags().StringVar(&userID, "user", "", "Only list new releases of entries this user is currently watching.")
+	notifierFlags.register(notificationsCmd)
+	return notificationsCmd
+}
+
+// notifierFlags are the flags of commands that can send notifications.
+type notifierFlags struct {
+	desktop          bool
+	webhook, discord string
+}
+
+func (flags *notifierFlags) register(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&flags.desktop, "desktop", false, "Show desktop notifications.")
+	cmd.Flags().StringVar(&flags.webhook, "webhook", "", "URL to POST notifications to as JSON.")
+	cmd.Flags().StringVar(&flags.discord, "discord", "", "URL of a Discord webhook to post notifications to.")
+}
+
+// notifier returns a notifier for all configured targets, always including
+// stdout.
+func (flags *notifierFlags) notifier() notify.Notifier {
+	notifiers := notify.Multi{&notify.Writer{Writer: os.Stdout}}
+	if flags.desktop {
+		notifiers = append(notifiers, notify.Desktop{})
+	}
+	if flags.webhook != "" {
+		notifiers = append(notifiers, &notify.Webhook{URL: flags.webhook})
+	}
+	if flags.discord != "" {
+		notifiers = append(notifiers, &notify.Discord{WebhookURL: flags.discord})
+	}
+	return notifiers
+}
+
+// loadWatchState reads the watchlist the last run of watch has seen. ok is
+// false if there is none yet.
+func loadWatchState(path string) (watchlist proxerscrape.Watchlist, ok bool, err error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return watchlist, false, nil
+	} else if err != nil {
+		return watchlist, false, err
+	}
+	defer file.Close()
+	watchlist, err = proxerscrape.ImportWatchlistJSON(file)
+	return watchlist, err == nil, err
+}
+
+// loadNotifiedReleases reads the keys of the releases the watch command has
+// already reported, see proxerscrape.DetectReleases. ok is false if there
+// are none yet.
+func loadNotifiedReleases(path string) (notified map[string]bool, ok bool, err error) {
+	notified = make(map[string]bool)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return notified, false, nil
+	} else if err != nil {
+		return notified, false, err
+	}
+	var keys []string
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return notified, false, err
+	}
+	for _, key := range keys {
+		notified[key] = true
+	}
+	return notified, true, nil
+}
+
+func saveNotifiedReleases(path string, notified map[string]bool) error {
+	keys := make([]string, 0, len(notified))
+	for key := range notified {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	data, err := json.Marshal(keys)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(path, data, 0o644)
+}
+
+// withoutEpisodeUpdates drops the updates based on the announced episode
+// count, for when the releases are known.
+func withoutEpisodeUpdates(updates []proxerscrape.Update) []proxerscrape.Update {
+	var filtered []proxerscrape.Update
+	for _, update := range updates {
+		if update.Type != proxerscrape.UpdateEpisodes {
+			filtered = append(filtered, update)
+		}
+	}
+	return filtered
+}
+
+func generateWatchCmd() *cobra.Command {
+	var userID, statePath string
+	var interval time.Duration
+	var once bool
+	var notifierFlags notifierFlags
+	watchCmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Periodically checks the watchlist for new episodes and status changes.",
+		Long: `Periodically checks the watchlist for new episodes and status changes of
+entries being watched or planned to be watched, and notifies about them.
+
+The watchlist is only fetched again once the cached version has expired, so
+intervals shorter than the maximum age of profile tabs don't cause more
+requests. The last seen watchlist is kept in a state file, so that updates
+between runs aren't lost.
+
+Profile tabs only show the announced amount of episodes. If logged in, see
+--cookies, new episodes are therefore taken from the notifications of the
+user instead. Releases that were already reported are kept in a second state
+file next to the first one.`,
+		Example: "watch --user 123456 --interval 6h --desktop",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			notifier := notifierFlags.notifier()
+			if statePath == "" {
+				userDataPath, err := proxerscrape.DefaultUserDataPath()
+				if err != nil {
+					return err
+				}
+				statePath = filepath.Join(filepath.Dir(userDataPath), "watch-"+userID+".json")
+			}
+
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+			// The checks are spread over the whole lifetime of the daemon, so
+			// there's no single run to confirm.
+			cache.MaxRequests = 0
+
+			previous, hasPrevious, err := loadWatchState(statePath)
+			if err != nil {
+				return err
+			}
+			notifiedPath := strings.TrimSuffix(statePath, ".json") + "-releases.json"
+			notified, hasNotified, err := loadNotifiedReleases(notifiedPath)
+			if err != nil {
+				return err
+			}
+			pollNotifications := true
+			for {
+				// Fetches deferred by a previous check, or another command,
+				// are caught up on once the ratelimit allows it again.
+				if err := resumeQueue(cache); err != nil {
+					fmt.Fprintln(os.Stderr, "Resuming deferred fetches failed:", err)
+				}
+				current, err := retrieveWatchlist(cache, userID, proxerscrape.ProfileTabAnime)
+				if err != nil {
+					// A single failed refresh shouldn't stop the daemon.
+					fmt.Fprintln(os.Stderr, "Refreshing the watchlist failed:", err)
+				} else {
+					var updates []proxerscrape.Update
+					if hasPrevious {
+						updates = proxerscrape.DetectUpdates(previous, current)
+					}
+					// The notifications know about releases, while the
+					// profile tab only knows the announced episode count.
+					if pollNotifications {
+						notifications, err := cache.Session().LoadNotifications()
+						if err == nil {
+							releases := proxerscrape.DetectReleases(notifications, current, notified)
+							if !hasNotified {
+								// Releases from before the first check aren't news.
+								releases = nil
+							}
+							updates = append(withoutEpisodeUpdates(updates), releases...)
+							// Releases that dropped out of the notifications
+							// can't be reported again, so they needn't be
+							// remembered.
+							listed := make(map[string]bool)
+							for _, notification := range notifications {
+								if notified[notification.Key()] {
+									listed[notification.Key()] = true
+								}
+							}
+							notified = listed
+							if err := saveNotifiedReleases(notifiedPath, notified); err != nil {
+								return err
+							}
+							hasNotified = true
+						} else if errors.Is(err, proxerscrape.ErrLoginRequired) {
+							// Logging in requires a restart, so there's no use
+							// in trying again every interval.
+							fmt.Fprintln(os.Stderr, "Loading the notifications requires a login, releases won't be reported.")
+							pollNotifications = false
+						} else {
+							fmt.Fprintln(os.Stderr, "Loading the notifications failed:", err)
+						}
+					}
+					for _, update := range updates {
+						message := notify.Message{Text: update.Message(), URL: cache.BaseURL() + update.Current.ProxerURL}
+						if err := notifier.Notify(message); err != nil {
+							fmt.Fprintln(os.Stderr, "Notifying failed:", err)
+						}
+					}
+
+					var buffer bytes.Buffer
+					if err := proxerscrape.ExportWatchlistJSON(&buffer, &current); err != nil {
+						return err
+					}
+					if err := atomicfile.WriteFile(statePath, buffer.Bytes(), 0o644); err != nil {
+						return err
+					}
+					previous, hasPrevious = current, true
+				}
+
+				if once {
+					return nil
+				}
+				time.Sleep(interval)
+			}
+		},
+	}
+	watchCmd.Flags().StringVar(&userID, "user", "", "ID of the user whose watchlist is checked.")
+	watchCmd.Flags().DurationVar(&interval, "interval", 6*time.Hour, "Time between checks.")
+	notifierFlags.register(watchCmd)
+	watchCmd.Flags().StringVar(&statePath, "state", "", "File to keep the last seen watchlist in. Defaults to a file next to the user data.")
+	watchCmd.Flags().BoolVar(&once, "once", false, "Check only once, for example when run via cron.")
+	watchCmd.MarkFlagRequired("user")
+	return watchCmd
+}
+
+func generateServeCmd() *cobra.Command {
+	var address, userID string
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serves the scraped data as JSON via HTTP.",
+		Long: `Serves the scraped data as JSON via HTTP. The endpoints are
+
+	/watchlist/{user}/{anime|manga|novel}
+	/media/{id}[/{anime|manga|novel}]
+	/stats/{user}
+	/metrics
+	/rpc
+
+Data is served from the cache where possible, so the usual ratelimits apply.
+/media assumes an anime, unless the type is given, since manga and novels use
+a separate ratelimit. /metrics serves the metrics of the cache in the format of Prometheus.
+
+/rpc accepts JSON-RPC 2.0 calls via POST. The endpoints are available as the
+methods getWatchlist ({"user": "...", "tab": "anime"}), getMedia ({"id": "...",
+"tab": "anime"}) and getUserStats ({"user": "..."}). With --user, the methods of the rpc
+command are available for the anime watchlist of that user as well.`,
+		Example: "serve --addr localhost:8080 --user 252835",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+			// Every request is triggered by a client over the whole lifetime
+			// of the server, so there's no single run to confirm.
+			cache.MaxRequests = 0
+
+			server := &rest.Server{Cache: cache, RPC: jsonrpc.NewServer()}
+			server.RegisterMethods(server.RPC)
+			if userID != "" {
+				library, err := newRPCLibrary(cache, userID)
+				if err != nil {
+					return err
+				}
+				jsonrpc.RegisterLibrary(server.RPC, library)
+			}
+
+			fmt.Fprintln(os.Stderr, "Serving REST API and JSON-RPC on", address)
+			return http.ListenAndServe(address, server)
+		},
+	}
+	// Anyone able to reach the server can issue requests in the name of the
+	// logged in user, so it's only reachable locally by default.
+	serveCmd.Flags().StringVar(&address, "addr", "localhost:8080", "Address to serve HTTP on. Binding to all interfaces exposes the login cookie and ratelimit to the network.")
+	serveCmd.Flags().StringVar(&userID, "user", "", "ID of the user whose watchlist is served via the JSON-RPC methods of the rpc command.")
+	return serveCmd
+}
+
+func generateTUICmd() *cobra.Command {
+	var userID, tab, exportPath string
+	tuiCmd := &cobra.Command{
+		Use:   "tui",
+		Short: "Browses the watchlist of a user interactively.",
+		Long: `Browses the watchlist of a user interactively. The categories can be
+filtered and sorted, details are loaded once an entry is opened and 'e'
+exports the watchlist as JSON to the path given via --export. Filtering for
+'#tag' lists the entries tagged via 'note tag'.`,
+		Example: "tui --user 252835",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			userData, _, err := loadUserData()
+			if err != nil {
+				return err
+			}
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+
+			tabType := proxerscrape.ProfileTabType(tab)
+			watchlist, err := retrieveWatchlist(cache, userID, tabType)
+			if err != nil {
+				return err
+			}
+			retrieveRawData := cache.RetrieveAnimeRawData
+			if tabType != proxerscrape.ProfileTabAnime {
+				retrieveRawData = cache.RetrieveMangaRawData
+			}
+			return tui.Run(tui.Options{
+				Watchlist:       &watchlist,
+				RetrieveRawData: retrieveRawData,
+				UserData:        userData,
+				Export: func(watchlist *proxerscrape.Watchlist) (string, error) {
+					var buffer bytes.Buffer
+					if err := proxerscrape.ExportWatchlistJSON(&buffer, watchlist); err != nil {
+						return "", err
+					}
+					if err := atomicfile.WriteFile(exportPath, buffer.Bytes(), 0o644); err != nil {
+						return "", err
+					}
+					return "Exported to " + exportPath, nil
+				},
+			})
+		},
+	}
+	tuiCmd.Flags().StringVar(&userID, "user", "", "ID of the user whose watchlist is browsed.")
+	tuiCmd.Flags().StringVar(&tab, "tab", string(proxerscrape.ProfileTabAnime), "Profile tab to browse (anime, manga or novel).")
+	tuiCmd.Flags().StringVar(&exportPath, "export", "watchlist.json", "File the watchlist is exported to.")
+	tuiCmd.MarkFlagRequired("user")
+	return tuiCmd
+}
+
+func generateFindCmd() *cobra.Command {
+	var userID, tab string
+	var allTitles bool
+	findCmd := &cobra.Command{
+		Use:   "find <title>",
+		Short: "Finds entries of a watchlist by a title typed by hand, printing their IDs.",
+		Long: `Finds entries of a watchlist by a title typed by hand, printing their IDs.
+Typos and incomplete words are tolerated. Using --all-titles, the English and
+Japanese titles and synonyms are searched as well, which requires loading the
+extra data of all entries.`,
+		Example: "find --user 252835 'attack titan'",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+
+			watchlist, err := retrieveWatchlist(cache, userID, proxerscrape.ProfileTabType(tab))
+			if err != nil {
+				return err
+			}
+			if allTitles {
+				// Entries that failed to load can still be found by their title.
+				var extraDataError proxerscrape.ExtraDataError
+				if err := watchlist.LoadExtraData(cache); err != nil && !errors.As(err, &extraDataError) {
+					return err
+				}
+			}
+			matches := watchlist.Find(strings.Join(args, " "))
+			if len(matches) == 0 {
+				return errors.New("no matching entries found")
+			}
+			for _, item := range matches {
+				fmt.Printf("%s\t%s (%s)\n", item.ProxerID(), item.Title, item.Category)
+			}
+			return nil
+		},
+	}
+	findCmd.Flags().StringVar(&userID, "user", "", "ID of the user whose watchlist is searched.")
+	findCmd.Flags().BoolVar(&allTitles, "all-titles", false, "Search alternative titles as well.")
+	findCmd.Flags().StringVar(&tab, "tab", string(proxerscrape.ProfileTabAnime), "Profile tab to search (anime, manga or novel).")
+	findCmd.MarkFlagRequired("user")
+	return findCmd
+}
+
+func generateGenresCmd() *cobra.Command {
+	var userID string
+	genresCmd := &cobra.Command{
+		Use:   "genres",
+		Short: "Prints the genre preferences of a user, based on the watched entries and their ratings.",
+		Long: `Prints the genre preferences of a user, based on the watched entries and
+their ratings. This requires loading the extra data of all watched entries.`,
+		Example: "genres --user 252835",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+
+			watchlist, err := retrieveWatchlist(cache, userID, proxerscrape.ProfileTabAnime)
+			if err != nil {
+				return err
+			}
+			if err := confirmRequests(cache, watchlist.Watched.Data); err != nil {
+				return err
+			}
+			var extraDataError proxerscrape.ExtraDataError
+			if err := watchlist.Watched.LoadExtraData(cache.RetrieveAnimeRawData); err != nil && !errors.As(err, &extraDataError) {
+				return err
+			}
+
+			for _, preference := range proxerscrape.NewGenreProfile(&watchlist).Preferences() {
+				fmt.Printf("%-20s %3.0f%%  %3d entries", preference.Name, preference.Affinity*100, preference.Entries)
+				if preference.AverageRating > 0 {
+					fmt.Printf(", rated %.1f", preference.AverageRating)
+				}
+				fmt.Println()
+			}
+			return nil
+		},
+	}
+	genresCmd.Flags().StringVar(&userID, "user", "", "ID of the user whose preferences are printed.")
+	genresCmd.MarkFlagRequired("user")
+	return genresCmd
+}
+
+func generateValidateCmd() *cobra.Command {
+	var userID, tab string
+	validateCmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Checks a watchlist for inconsistencies, such as entries listed in multiple categories.",
+		Long: `Checks a watchlist for inconsistencies, such as entries listed in multiple
+categories, more episodes watched than available or finished entries that are
+still being watched. Fails if any issue was found.`,
+		Example: "validate --user 252835",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+
+			watchlist, err := retrieveWatchlist(cache, userID, proxerscrape.ProfileTabType(tab))
+			if err != nil {
+				return err
+			}
+			report := watchlist.Validate()
+			for _, issue := range report {
+				fmt.Printf("%s\t%s\n", issue.Media.ProxerID(), issue)
+			}
+			if len(report) > 0 {
+				return fmt.Errorf("found %d issues", len(report))
+			}
+			fmt.Println("No issues found.")
+			return nil
+		},
+	}
+	validateCmd.Flags().StringVar(&userID, "user", "", "ID of the user whose watchlist is checked.")
+	validateCmd.Flags().StringVar(&tab, "tab", string(proxerscrape.ProfileTabAnime), "Profile tab to check (anime, manga or novel).")
+	validateCmd.MarkFlagRequired("user")
+	return validateCmd
+}
+
+func generateDevtoolsCmd() *cobra.Command {
+	devtoolsCmd := &cobra.Command{
+		Use:     "devtools",
+		Short:   "Tools for developing the parsers.",
+		Example: "devtools record /info/296",
+	}
+
+	var output string
+	recordCmd := &cobra.Command{
+		Use:   "record <url>",
+		Short: "Downloads a page sanitized, so it can be used as a test fixture.",
+		Long: `Downloads a page sanitized, so it can be used as a test fixture. Error pages,
+such as 404s, login walls and captchas are recorded as well. Fixtures placed
+in testdata/fixtures are parsed by the golden tests, depending on their name
+prefix ('profile_' or 'info_'). Afterwards, the golden files can be created
+via 'go test -run TestGolden -update'.`,
+		Example: "devtools record /info/296 -o testdata/fixtures/info_tsurune.html",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+
+			var buffer bytes.Buffer
+			if err := cache.RecordFixture(args[0], &buffer); err != nil {
+				return err
+			}
+			if output == "" {
+				_, err := os.Stdout.Write(buffer.Bytes())
+				return err
+			}
+			return atomicfile.WriteFile(output, buffer.Bytes(), 0o644)
+		},
+	}
+	recordCmd.Flags().StringVarP(&output, "output", "o", "", "File to write to. Defaults to stdout.")
+	devtoolsCmd.AddCommand(recordCmd)
+	return devtoolsCmd
+}
+
+func generateSyncCmd() *cobra.Command {
+	syncCmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Syncs the watchlist of a user with an external service in both directions.",
+	}
+
+	var userID, token, direction, prefer string
+	var historyPages int
+	var dryRun bool
+	aniListCmd := &cobra.Command{
+		Use:   "anilist",
+		Short: "Syncs the anime watchlist with the AniList list of the user.",
+		Long: `Syncs the anime watchlist with the AniList list of the user.
+
+Progress, status and score are taken from the side that is ahead. Conflicts,
+such as two different scores, are resolved via --prefer. Changing proxer.me
+isn't supported yet, so syncing in both directions or pulling only works
+with --dry-run. The AniList OAuth access token can be passed via --token or
+the environment variable ANILIST_TOKEN. Entries have to be mapped first, see
+'map resolve'.`,
+		Example: "sync anilist --user 252835 --direction both --prefer newest --dry-run",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if token == "" {
+				token = os.Getenv("ANILIST_TOKEN")
+			}
+			if token == "" {
+				return errors.New("no anilist token given, use --token or ANILIST_TOKEN")
+			}
+			switch reconcile.Direction(direction) {
+			case reconcile.DirectionBoth, reconcile.DirectionPush, reconcile.DirectionPull:
+			default:
+				return fmt.Errorf("unknown direction '%s'", direction)
+			}
+			if reconcile.Direction(direction) != reconcile.DirectionPush && !dryRun {
+				return errors.New("changing proxer.me isn't supported yet, use --direction push or --dry-run")
+			}
+
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+
+			var resolve reconcile.ConflictResolver
+			switch reconcile.ConflictStrategy(prefer) {
+			case reconcile.StrategyPreferProxer:
+				resolve = reconcile.PreferSide(reconcile.SideProxer)
+			case reconcile.StrategyPreferRemote:
+				resolve = reconcile.PreferSide(reconcile.SideRemote)
+			case reconcile.StrategyNewest:
+				events, err := cache.RetrieveHistory(userID, historyPages)
+				if err != nil {
+					return err
+				}
+				resolve = reconcile.PreferNewest(proxerscrape.LastWatched(events))
+			case reconcile.StrategyInteractive:
+				resolve = reconcile.Prompt(os.Stdin, os.Stdout)
+			default:
+				return fmt.Errorf("unknown conflict strategy '%s'", prefer)
+			}
+
+			watchlist, err := retrieveWatchlist(cache, userID, proxerscrape.ProfileTabAnime)
+			if err != nil {
+				return err
+			}
+			client := anilist.NewClient(token)
+			remote, err := client.List()
+			if err != nil {
+				return err
+			}
+			storePath, err := mapping.DefaultStorePath()
+			if err != nil {
+				return err
+			}
+			store, err := mapping.LoadStore(storePath)
+			if err != nil {
+				return err
+			}
+
+			report := reconcile.Reconcile(&watchlist, remote, store, mapping.ServiceAniList)
+			plan := reconcile.PlanSync(report, store, reconcile.Direction(direction))
+			if err := plan.ResolveConflicts(resolve); err != nil {
+				return err
+			}
+			if dryRun {
+				printSyncPlan(plan)
+				return nil
+			}
+
+			applied, err := plan.Execute(nil, client)
+			fmt.Printf("Applied %d of %d operations, %d conflicts left unresolved.\n", applied, len(plan.Operations), len(plan.Conflicts))
+			return err
+		},
+	}
+	aniListCmd.Flags().StringVar(&userID, "user", "", "ID of the user whose watchlist is synced.")
+	aniListCmd.Flags().StringVar(&token, "token", "", "OAuth access token of AniList.")
+	aniListCmd.Flags().StringVar(&direction, "direction", string(reconcile.DirectionPush), "Sides that are changed: both, push (only AniList) or pull (only proxer). Only push can be applied yet.")
+	aniListCmd.Flags().StringVar(&prefer, "prefer", string(reconcile.StrategyNewest), "Conflict strategy: prefer-proxer, prefer-remote, newest or interactive.")
+	aniListCmd.Flags().IntVar(&historyPages, "history-pages", 5, "Amount of history pages used for determining when entries were changed on proxer, only used by --prefer newest.")
+	aniListCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Prints the operations instead of applying them.")
+	aniListCmd.MarkFlagRequired("user")
+	syncCmd.AddCommand(aniListCmd)
+
+	return syncCmd
+}
+
+func generateTraktCmd() *cobra.Command {
+	traktCmd := &cobra.Command{
+		Use:   "trakt",
+		Short: "Pushes the watch history of proxer.me to Trakt.",
+		Long: `Pushes the watch history of proxer.me to Trakt.
+
+Trakt requires a registered application, see https://trakt.tv/oauth/applications.
+Its client ID and secret are read from the environment variables
+TRAKT_CLIENT_ID and TRAKT_CLIENT_SECRET.`,
+	}
+
+	newClient := func() (*trakt.Client, error) {
+		clientID, clientSecret := os.Getenv("TRAKT_CLIENT_ID"), os.Getenv("TRAKT_CLIENT_SECRET")
+		if clientID == "" || clientSecret == "" {
+			return nil, errors.New("no trakt application given, set TRAKT_CLIENT_ID and TRAKT_CLIENT_SECRET")
+		}
+		return trakt.NewClient(clientID, clientSecret), nil
+	}
+
+	loginCmd := &cobra.Command{
+		Use:   "login",
+		Short: "Authorizes access to a Trakt account and prints the token.",
+		Long: `Authorizes access to a Trakt account and prints the token.
+
+The printed token has to be set as TRAKT_TOKEN for syncing.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient()
+			if err != nil {
+				return err
+			}
+			code, err := client.RequestDeviceCode()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Enter the code %s at %s\n", code.UserCode, code.VerificationURL)
+			if err := client.PollToken(code); err != nil {
+				return err
+			}
+			fmt.Println(client.Token)
+			return nil
+		},
+	}
+	traktCmd.AddCommand(loginCmd)
+
+	var userID string
+	var historyPages int
+	syncCmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Adds the watched episodes of all mapped anime to the Trakt history.",
+		Long: `Adds the watched episodes of all mapped anime to the Trakt history.
+
+Episodes that are already in the history aren't added again. Entries have
+to be mapped first, see 'map resolve --service trakt'. Episodes are dated by
+the proxer.me history, entries that aren't part of it are dated by their
+release.`,
+		Example: "trakt sync --user 252835",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient()
+			if err != nil {
+				return err
+			}
+			client.Token = os.Getenv("TRAKT_TOKEN")
+			if client.Token == "" {
+				return errors.New("no trakt token given, see 'trakt login' and set TRAKT_TOKEN")
+			}
+
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+
+			watchlist, err := retrieveWatchlist(cache, userID, proxerscrape.ProfileTabAnime)
+			if err != nil {
+				return err
+			}
+			storePath, err := mapping.DefaultStorePath()
+			if err != nil {
+				return err
+			}
+			store, err := mapping.LoadStore(storePath)
+			if err != nil {
+				return err
+			}
+
+			events, err := cache.RetrieveHistory(userID, historyPages)
+			if err != nil {
+				return err
+			}
+
+			result, err := client.Sync(&watchlist, store, proxerscrape.LastWatched(events))
+			printSkipped(result.Skipped)
+			for _, item := range result.Unseasoned {
+				fmt.Fprintf(os.Stderr, "Skipped '%s'(%s), since its season on Trakt is unknown, see 'map resolve --service trakt'.\n", item.Title, item.ProxerID())
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Added %d episodes and %d movies to the history.\n", result.Episodes, result.Movies)
+			return nil
+		},
+	}
+	syncCmd.Flags().StringVar(&userID, "user", "", "ID of the user whose watchlist is synced.")
+	syncCmd.Flags().IntVar(&historyPages, "history-pages", 5, "Amount of history pages used for determining when episodes were watched.")
+	syncCmd.MarkFlagRequired("user")
+	traktCmd.AddCommand(syncCmd)
+
+	return traktCmd
+}
+
+func generateSimklCmd() *cobra.Command {
+	simklCmd := &cobra.Command{
+		Use:   "simkl",
+		Short: "Keeps the Simkl anime list of a user in sync with proxer.me.",
+		Long: `Keeps the Simkl anime list of a user in sync with proxer.me.
+
+Simkl requires a registered application, see https://simkl.com/settings/developer/.
+Its client ID is read from the environment variable SIMKL_CLIENT_ID.`,
+	}
+
+	newClient := func() (*simkl.Client, error) {
+		clientID := os.Getenv("SIMKL_CLIENT_ID")
+		if clientID == "" {
+			return nil, errors.New("no simkl application given, set SIMKL_CLIENT_ID")
+		}
+		return simkl.NewClient(clientID), nil
+	}
+
+	loginCmd := &cobra.Command{
+		Use:   "login",
+		Short: "Authorizes access to a Simkl account and prints the token.",
+		Long: `Authorizes access to a Simkl account and prints the token.
+
+The printed token has to be set as SIMKL_TOKEN for syncing.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient()
+			if err != nil {
+				return err
+			}
+			pin, err := client.RequestPIN()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Enter the code %s at %s\n", pin.UserCode, pin.VerificationURL)
+			if err := client.PollToken(pin); err != nil {
+				return err
+			}
+			fmt.Println(client.Token)
+			return nil
+		},
+	}
+	simklCmd.AddCommand(loginCmd)
+
+	var userID string
+	syncCmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Pushes status and progress of all mapped anime to Simkl.",
+		Long: `Pushes status and progress of all mapped anime to Simkl.
+
+Entries have to be mapped first, see 'map resolve --service simkl'.`,
+		Example: "simkl sync --user 252835",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient()
+			if err != nil {
+				return err
+			}
+			client.Token = os.Getenv("SIMKL_TOKEN")
+			if client.Token == "" {
+				return errors.New("no simkl token given, see 'simkl login' and set SIMKL_TOKEN")
+			}
+
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+
+			watchlist, err := retrieveWatchlist(cache, userID, proxerscrape.ProfileTabAnime)
+			if err != nil {
+				return err
+			}
+			storePath, err := mapping.DefaultStorePath()
+			if err != nil {
+				return err
+			}
+			store, err := mapping.LoadStore(storePath)
+			if err != nil {
+				return err
+			}
+
+			skipped, err := client.Sync(&watchlist, store)
+			printSkipped(skipped)
+			return err
+		},
+	}
+	syncCmd.Flags().StringVar(&userID, "user", "", "ID of the user whose watchlist is synced.")
+	syncCmd.MarkFlagRequired("user")
+	simklCmd.AddCommand(syncCmd)
+
+	return simklCmd
+}
+
+func generateAniDBCmd() *cobra.Command {
+	aniDBCmd := &cobra.Command{
+		Use:   "anidb",
+		Short: "Adds the watched anime of a user to their AniDB MyList.",
+		Long: `Adds the watched anime of a user to their AniDB MyList.
+
+AniDB requires a registered client, see https://anidb.net/software/add. Its
+name and version are read from the environment variables ANIDB_CLIENT and
+ANIDB_CLIENT_VERSION, the AniDB login from ANIDB_USERNAME and ANIDB_PASSWORD.`,
+	}
+
+	var userID string
+	syncCmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Adds the watched episodes and ratings of all mapped anime to the MyList.",
+		Long: `Adds the watched episodes and ratings of all mapped anime to the MyList.
+
+Entries have to be mapped first, see 'map resolve --service anidb'. Every
+watched episode takes a request and AniDB only allows one every four seconds,
+so syncing a large watchlist takes a while.`,
+		Example: "anidb sync --user 252835",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientName := os.Getenv("ANIDB_CLIENT")
+			clientVersion, err := strconv.Atoi(os.Getenv("ANIDB_CLIENT_VERSION"))
+			if clientName == "" || err != nil {
+				return errors.New("no anidb client given, set ANIDB_CLIENT and ANIDB_CLIENT_VERSION")
+			}
+			username, password := os.Getenv("ANIDB_USERNAME"), os.Getenv("ANIDB_PASSWORD")
+			if username == "" || password == "" {
+				return errors.New("no anidb login given, set ANIDB_USERNAME and ANIDB_PASSWORD")
+			}
+
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+
+			watchlist, err := retrieveWatchlist(cache, userID, proxerscrape.ProfileTabAnime)
+			if err != nil {
+				return err
+			}
+			storePath, err := mapping.DefaultStorePath()
+			if err != nil {
+				return err
+			}
+			store, err := mapping.LoadStore(storePath)
+			if err != nil {
+				return err
+			}
+
+			client := anidb.NewClient(clientName, clientVersion)
+			defer client.Close()
+			if err := client.Login(username, password); err != nil {
+				return err
+			}
+			skipped, err := client.Sync(&watchlist, store)
+			printSkipped(skipped)
+			return err
+		},
+	}
+	syncCmd.Flags().StringVar(&userID, "user", "", "ID of the user whose watchlist is synced.")
+	syncCmd.MarkFlagRequired("user")
+	aniDBCmd.AddCommand(syncCmd)
+
+	return aniDBCmd
+}
+
+func generateFetchCmd() *cobra.Command {
+	var userID, tab, format, output string
+	fetchCmd := &cobra.Command{
+		Use:   "fetch",
+		Short: "Downloads a profile tab, so it doesn't have to be saved from the browser manually.",
+		Long: `Downloads a profile tab, so it doesn't have to be saved from the browser manually.
+
+Using the 'html' format, the page is written as is, so it can be piped into the
+tools reading a profile tab from stdin, such as watchtimeleft. Using the 'json'
+format, the parsed watchlist is written, see 'export json'.`,
+		Example: "fetch --user 252835 --tab anime | watchtimeleft",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "html" && format != "json" {
+				return fmt.Errorf("unknown format '%s'", format)
+			}
+
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+
+			tabType := proxerscrape.ProfileTabType(tab)
+			reader, _, err := cache.RetrieveProfileTabRawData(userID, tabType)
+			if err != nil {
+				return cache.Defer(proxerscrape.DeferProfileTab(userID, tabType), err)
+			}
+			page, err := io.ReadAll(reader)
+			reader.Close()
+			if err != nil {
+				return err
+			}
+
+			if format == "json" {
+				watchlist, err := parseProfileTab(bytes.NewReader(page))
+				if err != nil {
+					return err
+				}
+				var buffer bytes.Buffer
+				if err := proxerscrape.ExportWatchlistJSON(&buffer, &watchlist); err != nil {
+					return err
+				}
+				page = buffer.Bytes()
+			}
+
+			if output == "" {
+				_, err := os.Stdout.Write(page)
+				return err
+			}
+			return atomicfile.WriteFile(output, page, 0o644)
+		},
+	}
+	fetchCmd.Flags().StringVar(&userID, "user", "", "ID of the user whose profile tab is downloaded.")
+	fetchCmd.Flags().StringVar(&tab, "tab", string(proxerscrape.ProfileTabAnime), "Profile tab to download.")
+	fetchCmd.Flags().StringVar(&format, "format", "html", "Output format, either 'html' (the page as is) or 'json' (the parsed watchlist).")
+	fetchCmd.Flags().StringVarP(&output, "output", "o", "", "File to write to. Defaults to stdout.")
+	fetchCmd.MarkFlagRequired("user")
+	return fetchCmd
+}
+
+func generateParseCmd() *cobra.Command {
+	var sanitize bool
+	parseCmd := &cobra.Command{
+		Use:     "parse",
+		Short:   "Parses a profile tab passed via stdin and prints the contained watchlists.",
+		Long:    "Parses a profile tab passed via stdin and prints the contained watchlists. Using --sanitize, the page is instead stripped of user specific data and printed, so it can be attached to bug reports safely.",
+		Example: "parse --sanitize < anime.html > anime_sanitized.html",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if sanitize {
+				return proxerscrape.Sanitize(os.Stdin, os.Stdout)
+			}
+
+			watchlist, err := parseProfileTab(os.Stdin)
+			if err != nil {
+				return err
+			}
+			for _, category := range []struct {
+				name string
+				data []*proxerscrape.Media
+			}{
+				{"Watched", watchlist.Watched.Data},
+				{"Currently Watching", watchlist.CurrentlyWatching.Data},
+				{"To Watch", watchlist.ToWatch.Data},
+				{"Stopped Watching", watchlist.StoppedWatching.Data},
+			} {
+				fmt.Printf("%s (%d)\n", category.name, len(category.data))
+				for _, item := range category.data {
+					fmt.Printf("\t%s (%d/%d)\n", item.Title, item.EpisodesWatched, item.EpisodeCount)
+				}
+			}
+			return nil
+		},
+	}
+	parseCmd.Flags().BoolVar(&sanitize, "sanitize", false, "Prints the sanitized page instead of parsing it.")
+
+	return parseCmd
+}
+
+// loadUserData loads the user data from its default location and returns
+// the path, so that it can be saved again.
+func loadUserData() (*proxerscrape.UserData, string, error) {
+	userDataPath, err := proxerscrape.DefaultUserDataPath()
+	if err != nil {
+		return nil, "", err
+	}
+	userData, err := proxerscrape.LoadUserData(userDataPath)
+	return userData, userDataPath, err
+}
+
+func generateProgressCmd() *cobra.Command {
+	progressCmd := &cobra.Command{
+		Use:     "progress",
+		Short:   "Manages locally tracked progress, such as separate dub and sub progress.",
+		Example: "progress set 296 gerdub 3",
+	}
+	progressCmd.AddCommand(&cobra.Command{
+		Use:     "set <id> <language> <episodes>",
+		Short:   "Sets the amount of episodes watched in a language (gersub, gerdub, engsub, engdub).",
+		Example: "progress set 296 gerdub 3",
+		Args:    cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			language := proxerscrape.Language(args[1])
+			if !knownLanguage(language) {
+				return fmt.Errorf("unknown language '%s', expected gersub, gerdub, engsub or engdub", args[1])
+			}
+			episodes, err := strconv.ParseUint(args[2], 10, 16)
+			if err != nil {
+				return err
+			}
+
+			userData, userDataPath, err := loadUserData()
+			if err != nil {
+				return err
+			}
+			userData.SetLanguageProgress(&proxerscrape.Media{ProxerURL: "/info/" + args[0]}, language, uint16(episodes))
+			return userData.Save(userDataPath)
+		},
+	})
+
+	return progressCmd
+}
+
+// printSkipped prints the entries an export or sync skipped, either since
+// they aren't mapped or since they don't exist on proxer.me anymore.
+func printSkipped(skipped []*proxerscrape.Media) {
+	for _, item := range skipped {
+		if !item.Exportable() {
+			fmt.Fprintf(os.Stderr, "Skipped '%s'(%s), since it doesn't exist on proxer.me anymore.\n", item.Title, item.ProxerID())
+		} else {
+			fmt.Fprintf(os.Stderr, "Skipped '%s'(%s), since it isn't mapped, see 'map resolve'.\n", item.Title, item.ProxerID())
+		}
+	}
+}
+
+func knownLanguage(language proxerscrape.Language) bool {
+	for _, known := range proxerscrape.Languages {
+		if language == known {
+			return true
+		}
+	}
+	return false
+}
+
+func generateNoteCmd() *cobra.Command {
+	noteCmd := &cobra.Command{
+		Use:     "note",
+		Short:   "Manages local notes and tags on entries, such as \"waiting for dub\".",
+		Example: "note tag 296 \"waiting for dub\"",
+	}
+	noteCmd.AddCommand(&cobra.Command{
+		Use:     "set <id> <text>",
+		Short:   "Sets the note of an entry, an empty text removes it.",
+		Example: "note set 296 \"Continue after the movie\"",
+		Args:    cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			userData, userDataPath, err := loadUserData()
+			if err != nil {
+				return err
+			}
+			userData.SetNote(&proxerscrape.Media{ProxerURL: "/info/" + args[0]}, strings.Join(args[1:], " "))
+			return userData.Save(userDataPath)
+		},
+	})
+	noteCmd.AddCommand(&cobra.Command{
+		Use:     "tag <id> <tag>",
+		Short:   "Adds a tag to an entry.",
+		Example: "note tag 296 \"waiting for dub\"",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			userData, userDataPath, err := loadUserData()
+			if err != nil {
+				return err
+			}
+			userData.AddTag(&proxerscrape.Media{ProxerURL: "/info/" + args[0]}, args[1])
+			return userData.Save(userDataPath)
+		},
+	})
+	noteCmd.AddCommand(&cobra.Command{
+		Use:     "untag <id> <tag>",
+		Short:   "Removes a tag from an entry.",
+		Example: "note untag 296 \"waiting for dub\"",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			userData, userDataPath, err := loadUserData()
+			if err != nil {
+				return err
+			}
+			userData.RemoveTag(&proxerscrape.Media{ProxerURL: "/info/" + args[0]}, args[1])
+			return userData.Save(userDataPath)
+		},
+	})
+	var tag string
+	listCmd := &cobra.Command{
+		Use:     "list",
+		Short:   "Prints all notes, optionally only those with the given tag.",
+		Example: "note list --tag \"waiting for dub\"",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			userData, _, err := loadUserData()
+			if err != nil {
+				return err
+			}
+			identifiers := make([]string, 0, len(userData.Notes))
+			if tag != "" {
+				identifiers = userData.Tagged(tag)
+			} else {
+				for identifier := range userData.Notes {
+					identifiers = append(identifiers, identifier)
+				}
+				sort.Strings(identifiers)
+			}
+			for _, identifier := range identifiers {
+				note := userData.Notes[identifier]
+				fmt.Printf("%s\t[%s]\t%s\n", identifier, strings.Join(note.Tags, ", "), note.Text)
+			}
+			return nil
+		},
+	}
+	listCmd.Flags().StringVar(&tag, "tag", "", "Only print notes with this tag.")
+	noteCmd.AddCommand(listCmd)
+
+	return noteCmd
+}
+
+func generateMapCmd() *cobra.Command {
+	mapCmd := &cobra.Command{
+		Use:     "map",
+		Short:   "Manages mappings between proxer entries and external services such as MyAnimeList.",
+		Example: "map resolve --user 252835",
+	}
+
+	var userID, tabType, service, aniDBTitlesPath string
+	resolveCmd := &cobra.Command{
+		Use:   "resolve",
+		Short: "Interactively maps all entries of a watchlist that can't be mapped automatically.",
+		Long: `Interactively maps all entries of a watchlist that can't be mapped automatically.
+
+Entries are matched by their titles, episode count, type and year. Mappings in
+mapping-overrides.json inside of the config directory always take precedence
+and are never overwritten. When mapping to Trakt, the season of each show is
+asked for as well, since proxer.me lists seasons as separate entries.`,
+		Example: "map resolve --user 252835 --service mal",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+			if err := resumeQueue(cache); err != nil {
+				return err
+			}
+
+			watchlist, err := retrieveWatchlist(cache, userID, proxerscrape.ProfileTabType(tabType))
+			if err != nil {
+				return err
+			}
+
+			storePath, err := mapping.DefaultStorePath()
+			if err != nil {
+				return err
+			}
+			store, err := mapping.LoadStore(storePath)
+			if err != nil {
+				return err
+			}
+
+			overridesPath, err := mapping.DefaultOverridesPath()
+			if err != nil {
+				return err
+			}
+			overrides, err := mapping.LoadStore(overridesPath)
+			if err != nil {
+				return err
+			}
+			resolver := &mapping.Resolver{
+				Sources: map[mapping.Service]mapping.CandidateSource{
+					mapping.ServiceMyAnimeList: &mapping.AniListSearch{Service: mapping.ServiceMyAnimeList},
+					mapping.ServiceAniList:     &mapping.AniListSearch{Service: mapping.ServiceAniList},
+					mapping.ServiceKitsu:       &mapping.KitsuSearch{},
+				},
+				Store:     store,
+				Overrides: overrides,
+			}
+			if mapping.Service(service) == mapping.ServiceAniDB {
+				if aniDBTitlesPath == "" {
+					return fmt.Errorf("mapping to anidb requires --anidb-titles, download it from %s", mapping.AniDBTitlesURL)
+				}
+				titles, err := mapping.LoadAniDBTitlesFile(aniDBTitlesPath)
+				if err != nil {
+					return err
+				}
+				resolver.Sources[mapping.ServiceAniDB] = titles
+			}
+			if mapping.Service(service) == mapping.ServiceTrakt {
+				clientID := os.Getenv("TRAKT_CLIENT_ID")
+				if clientID == "" {
+					return errors.New("mapping to trakt requires a client id, set TRAKT_CLIENT_ID")
+				}
+				resolver.Sources[mapping.ServiceTrakt] = &mapping.TraktSearch{ClientID: clientID}
+			}
+			if mapping.Service(service) == mapping.ServiceSimkl {
+				clientID := os.Getenv("SIMKL_CLIENT_ID")
+				if clientID == "" {
+					return errors.New("mapping to simkl requires a client id, set SIMKL_CLIENT_ID")
+				}
+				resolver.Sources[mapping.ServiceSimkl] = &mapping.SimklSearch{ClientID: clientID}
+			}
+
+			input := bufio.NewScanner(os.Stdin)
+			for _, item := range watchlist.All() {
+				resolved, candidates, err := resolver.Resolve(item, mapping.Service(service))
+				if errors.Is(err, mapping.ErrNoCandidates) {
+					fmt.Printf("No candidates found for '%s'.\n", item.Title)
+					continue
+				}
+				if !errors.Is(err, mapping.ErrAmbiguous) {
+					if err != nil {
+						return err
+					}
+					if mapping.Service(service) == mapping.ServiceTrakt {
+						if err := resolveTraktSeason(input, store, item, resolved); err != nil {
+							return err
+						}
+						if err := store.Save(storePath); err != nil {
+							return err
+						}
+					}
+					continue
+				}
+
+				fmt.Printf("\nCandidates for '%s':\n", item.Title)
+				for index, candidate := range candidates {
+					fmt.Printf("\t%d) %s (%s, %.0f%%)\n", index+1, candidate.Title, candidate.ID, candidate.Similarity*100)
+				}
+				fmt.Print("Choose a candidate (empty to skip): ")
+				if !input.Scan() {
+					break
+				}
+				choice, err := strconv.Atoi(strings.TrimSpace(input.Text()))
+				if err != nil || choice < 1 || choice > len(candidates) {
+					continue
+				}
+				accepted := resolver.Accept(item, candidates[choice-1])
+				if mapping.Service(service) == mapping.ServiceTrakt {
+					if err := resolveTraktSeason(input, store, item, accepted); err != nil {
+						return err
+					}
+				}
+				// Saving after each decision, so that aborting doesn't lose
+				// any progress.
+				if err := store.Save(storePath); err != nil {
+					return err
+				}
+			}
+
+			return store.Save(storePath)
+		},
+	}
+	resolveCmd.Flags().StringVar(&userID, "user", "", "ID of the user whose watchlist should be mapped.")
+	resolveCmd.Flags().StringVar(&tabType, "tab", string(proxerscrape.ProfileTabAnime), "Profile tab to map.")
+	resolveCmd.Flags().StringVar(&service, "service", string(mapping.ServiceMyAnimeList), "Service to map to, either 'mal', 'anilist', 'kitsu', 'anidb', 'trakt' or 'simkl'.")
+	resolveCmd.Flags().StringVar(&aniDBTitlesPath, "anidb-titles", "", "Path to the AniDB title dump, required for mapping to AniDB.")
+	resolveCmd.MarkFlagRequired("user")
+	mapCmd.AddCommand(resolveCmd)
+
+	var verifiedOnly bool
+	exportCmd := &cobra.Command{
+		Use:     "export",
+		Short:   "Prints all mappings in a format that can be shared with other users.",
+		Example: "map export --verified > mappings.json",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			storePath, err := mapping.DefaultStorePath()
+			if err != nil {
+				return err
+			}
+			store, err := mapping.LoadStore(storePath)
+			if err != nil {
+				return err
+			}
+			return store.Export(os.Stdout, verifiedOnly)
+		},
+	}
+	exportCmd.Flags().BoolVar(&verifiedOnly, "verified", false, "Only export mappings that were chosen manually.")
+	mapCmd.AddCommand(exportCmd)
+
+	var overwrite bool
+	importCmd := &cobra.Command{
+		Use:     "import <file>",
+		Short:   "Imports mappings shared by another user, reporting conflicts with existing mappings.",
+		Example: "map import mappings.json",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+
+			storePath, err := mapping.DefaultStorePath()
+			if err != nil {
+				return err
+			}
+			store, err := mapping.LoadStore(storePath)
+			if err != nil {
+				return err
+			}
+			conflicts, err := store.Import(file, overwrite)
+			if err != nil {
+				return err
+			}
+			for _, conflict := range conflicts {
+				fmt.Printf("Conflict for %s (%s): existing %s, imported %s\n",
+					conflict.Existing.ProxerID, conflict.Existing.Service,
+					conflict.Existing.ExternalID, conflict.Imported.ExternalID)
+			}
+			return store.Save(storePath)
+		},
+	}
+	importCmd.Flags().BoolVar(&overwrite, "overwrite", false, "Replace existing mappings with conflicting imported ones.")
+	mapCmd.AddCommand(importCmd)
+
+	return mapCmd
+}
+
+func generateReconcileCmd() *cobra.Command {
+	var userID, malExportPath, direction string
+	var plan bool
+	reconcileCmd := &cobra.Command{
+		Use:     "reconcile",
+		Short:   "Compares a proxer watchlist with a MyAnimeList export and prints all differences.",
+		Example: "reconcile --user 252835 --mal animelist.xml",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+			if err := resumeQueue(cache); err != nil {
+				return err
+			}
+
+			watchlist, err := retrieveWatchlist(cache, userID, proxerscrape.ProfileTabAnime)
+			if err != nil {
+				return err
+			}
+
+			file, err := os.Open(malExportPath)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			remote, err := reconcile.ParseMALExport(file)
+			if err != nil {
+				return err
+			}
+
+			storePath, err := mapping.DefaultStorePath()
+			if err != nil {
+				return err
+			}
+			store, err := mapping.LoadStore(storePath)
+			if err != nil {
+				return err
+			}
+
+			report := reconcile.Reconcile(&watchlist, remote, store, mapping.ServiceMyAnimeList)
+			if plan {
+				switch reconcile.Direction(direction) {
+				case reconcile.DirectionBoth, reconcile.DirectionPush, reconcile.DirectionPull:
+				default:
+					return fmt.Errorf("unknown direction '%s'", direction)
+				}
+				printSyncPlan(reconcile.PlanSync(report, store, reconcile.Direction(direction)))
+				return nil
+			}
+			for _, difference := range report.OutOfSync() {
+				fmt.Println(difference.Media.Title)
+				if difference.ProgressDelta > 0 {
+					fmt.Printf("\tproxer is %d episodes ahead\n", difference.ProgressDelta)
+				} else if difference.ProgressDelta < 0 {
+					fmt.Printf("\tproxer is %d episodes behind\n", -difference.ProgressDelta)
+				}
+				if difference.StatusMismatch {
+					fmt.Printf("\tstatus: proxer %s, remote %s\n", difference.Media.Category, difference.Remote.Category)
+				}
+				if difference.ScoreMismatch {
+					fmt.Printf("\tscore: proxer %d, remote %d\n", difference.Media.UserRating, difference.Remote.Score)
+				}
+			}
+			fmt.Printf("\n%d in sync, %d out of sync, %d unmapped, %d only on proxer, %d only on remote.\n",
+				len(report.Differences)-len(report.OutOfSync()), len(report.OutOfSync()),
+				len(report.Unmapped), len(report.OnlyProxer), len(report.OnlyRemote))
+			return nil
+		},
+	}
+	reconcileCmd.Flags().StringVar(&userID, "user", "", "ID of the user whose anime watchlist should be compared.")
+	reconcileCmd.Flags().StringVar(&malExportPath, "mal", "", "Path to the XML export of MyAnimeList.")
+	reconcileCmd.Flags().BoolVar(&plan, "plan", false, "Prints the operations required for syncing both lists instead of the differences, without changing anything.")
+	reconcileCmd.Flags().StringVar(&direction, "direction", string(reconcile.DirectionBoth), "Sides changed by the plan: both, push (only remote) or pull (only proxer).")
+	reconcileCmd.MarkFlagRequired("user")
+	reconcileCmd.MarkFlagRequired("mal")
+
+	return reconcileCmd
+}
+
+func printSyncPlan(plan *reconcile.Plan) {
+	for _, operation := range plan.Operations {
+		fmt.Println(operation)
+	}
+	for _, conflict := range plan.Conflicts {
+		fmt.Printf("conflict: %s\n", conflict)
+	}
+	fmt.Printf("\n%d operations (%d on proxer, %d on remote), %d conflicts, %d unmapped on proxer, %d unmapped on remote.\n",
+		len(plan.Operations), len(plan.For(reconcile.SideProxer)), len(plan.For(reconcile.SideRemote)),
+		len(plan.Conflicts), len(plan.Unmapped), len(plan.UnmappedRemote))
+}
+
+// newRPCLibrary creates the JSON-RPC library methods for the anime watchlist
+// of the given user.
+func newRPCLibrary(cache *proxerscrape.Cache, userID string) (*jsonrpc.Library, error) {
+	userData, _, err := loadUserData()
+	if err != nil {
+		return nil, err
+	}
+	recommender, err := userData.Recommender()
+	if err != nil {
+		return nil, err
+	}
+	return &jsonrpc.Library{
+		Watchlist: func() (proxerscrape.Watchlist, error) {
+			return retrieveWatchlist(cache, userID, proxerscrape.ProfileTabAnime)
+		},
+		RetrieveRawData: cache.RetrieveAnimeRawData,
+		Recommender:     recommender,
+	}, nil
+}
+
+func generateRPCCmd() *cobra.Command {
+	var userID string
+	rpcCmd := &cobra.Command{
+		Use:   "rpc",
+		Short: "Serves the watchlist of a user via JSON-RPC 2.0 on stdin and stdout.",
+		Long: `Serves the watchlist of a user via JSON-RPC 2.0 on stdin and stdout, one call
+per line, for example for tools launching proxercli as a subprocess.
+
+Available methods are searchList ({"query": "...", "category": "watching"}),
+getStats and getRecommendations ({"limit": 5}). For serving them via HTTP,
+see 'serve --user'.`,
+		Example: "rpc --user 252835",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+
+			library, err := newRPCLibrary(cache, userID)
+			if err != nil {
+				return err
+			}
+			// Recommendations require the extra data of the whole to-watch
+			// list, so we ask upfront instead of hammering proxer.me later.
+			watchlist, err := library.Watchlist()
+			if err != nil {
+				return err
+			}
+			stdinBusy = true
+			if err := confirmRequests(cache, watchlist.ToWatch.Data); err != nil {
+				return err
+			}
+			// Once serving, requests are triggered by the calls.
+			cache.MaxRequests = 0
+
+			server := jsonrpc.NewServer()
+			jsonrpc.RegisterLibrary(server, library)
+			return server.ServeStream(os.Stdin, os.Stdout)
+		},
+	}
+	rpcCmd.Flags().StringVar(&userID, "user", "", "ID of the user whose watchlist is served.")
+	rpcCmd.MarkFlagRequired("user")
+
+	return rpcCmd
+}
+
+func generateLedgerCmd() *cobra.Command {
+	ledgerCmd := &cobra.Command{
+		Use:     "ledger",
+		Short:   "Keeps track of finished entries per season.",
+		Example: "ledger update --user 252835",
+	}
+
+	var userID string
+	updateCmd := &cobra.Command{
+		Use:     "update",
+		Short:   "Refreshes the watchlists of a user and records all entries finished or restarted since the last update.",
+		Example: "ledger update --user 252835",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+			userData, userDataPath, err := loadUserData()
+			if err != nil {
+				return err
+			}
+
+			for _, tabType := range proxerscrape.ProfileTabTypes {
+				previous, current, err := cache.RefreshWatchlist(userID, tabType)
+				if err != nil {
+					return err
+				}
+				// Without a previous version, everything would look new.
+				if len(previous.All()) == 0 {
+					continue
+				}
+				changes := proxerscrape.DiffWatchlists(previous, current)
+				recorded := userData.RecordCompletions(changes, time.Now())
+				rewatches := userData.RecordRewatches(changes)
+				fmt.Printf("Recorded %d finished entries and %d rewatches from %s.\n", recorded, rewatches, tabType)
+			}
+			return userData.Save(userDataPath)
+		},
+	}
+	updateCmd.Flags().StringVar(&userID, "user", "", "ID of the user to check for finished entries.")
+	updateCmd.MarkFlagRequired("user")
+	ledgerCmd.AddCommand(updateCmd)
+
+	var year uint
+	showCmd := &cobra.Command{
+		Use:     "show",
+		Short:   "Prints the amount of finished entries per season.",
+		Example: "ledger show --year 2025",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			userData, _, err := loadUserData()
+			if err != nil {
+				return err
+			}
+			for _, total := range userData.SeasonTotals(year) {
+				fmt.Println(total)
+				if *verbose {
+					for _, completion := range total.Completions {
+						fmt.Printf("\t%s (%s)\n", completion.Title, completion.ID)
+					}
+				}
+			}
+			return nil
+		},
+	}
+	showCmd.Flags().UintVar(&year, "year", 0, "Only print seasons of this year.")
+	ledgerCmd.AddCommand(showCmd)
+
+	return ledgerCmd
+}
+
+func generateRewatchCmd() *cobra.Command {
+	rewatchCmd := &cobra.Command{
+		Use:     "rewatch",
+		Short:   "Manages locally tracked rewatch counts.",
+		Example: "rewatch add 296",
+	}
+	rewatchCmd.AddCommand(&cobra.Command{
+		Use:     "add <id>",
+		Short:   "Counts another rewatch of an entry.",
+		Example: "rewatch add 296",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			userData, userDataPath, err := loadUserData()
+			if err != nil {
+				return err
+			}
+			userData.AddRewatch(&proxerscrape.Media{ProxerURL: "/info/" + args[0]})
+			return userData.Save(userDataPath)
+		},
+	})
+	rewatchCmd.AddCommand(&cobra.Command{
+		Use:     "set <id> <count>",
+		Short:   "Sets how often an entry has been rewatched.",
+		Example: "rewatch set 296 2",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			count, err := strconv.ParseUint(args[1], 10, 16)
+			if err != nil {
+				return err
+			}
+			userData, userDataPath, err := loadUserData()
+			if err != nil {
+				return err
+			}
+			userData.SetRewatches(&proxerscrape.Media{ProxerURL: "/info/" + args[0]}, uint16(count))
+			return userData.Save(userDataPath)
+		},
+	})
+
+	return rewatchCmd
+}
+
+func generateExportCmd() *cobra.Command {
+	exportCmd := &cobra.Command{
+		Use:     "export",
+		Short:   "Exports watchlists in the formats of other services.",
+		Example: "export mal --user 252835 --output animelist.xml",
+	}
+
+	var userID, output string
+	var malCommentPages int
+	malCmd := &cobra.Command{
+		Use:     "mal",
+		Short:   "Exports the anime watchlist as MyAnimeList XML, which can be imported by MyAnimeList and AniList.",
+		Example: "export mal --user 252835 --output animelist.xml",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+
+			watchlist, err := retrieveWatchlist(cache, userID, proxerscrape.ProfileTabAnime)
+			if err != nil {
+				return err
+			}
+			if err := applyComments(cache, userID, &watchlist, malCommentPages); err != nil {
+				return err
+			}
+			storePath, err := mapping.DefaultStorePath()
+			if err != nil {
+				return err
+			}
+			store, err := mapping.LoadStore(storePath)
+			if err != nil {
+				return err
+			}
+			userData, _, err := loadUserData()
+			if err != nil {
+				return err
+			}
+
+			writer := os.Stdout
+			if output != "" {
+				file, err := os.Create(output)
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+				writer = file
+			}
+			skipped, err := export.WriteMAL(writer, &watchlist, store, userData)
+			if err != nil {
+				return err
+			}
+			printSkipped(skipped)
+			return nil
+		},
+	}
+	malCmd.Flags().StringVar(&userID, "user", "", "ID of the user whose watchlist is exported.")
+	malCmd.Flags().IntVar(&malCommentPages, "comments", 0, "Amount of pages of the user's comments to include as reviews, 0 meaning none.")
+	malCmd.Flags().StringVarP(&output, "output", "o", "", "File to write to. Defaults to stdout.")
+	malCmd.MarkFlagRequired("user")
+	exportCmd.AddCommand(malCmd)
+
+	var jsonUserID, jsonTab, jsonOutput string
+	var withExtraData bool
+	var jsonCommentPages int
+	jsonCmd := &cobra.Command{
+		Use:   "json",
+		Short: "Exports a watchlist as JSON, optionally including the extra data of all entries.",
+		Long: `Exports a watchlist as JSON, optionally including the extra data of all entries.
+
+The export can be loaded again via proxerscrape.ImportWatchlistJSON, which
+allows analysing a watchlist offline without scraping it again.`,
+		Example: "export json --user 252835 --extra --output watchlist.json",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+
+			watchlist, err := retrieveWatchlist(cache, jsonUserID, proxerscrape.ProfileTabType(jsonTab))
+			if err != nil {
+				return err
+			}
+			if err := applyComments(cache, jsonUserID, &watchlist, jsonCommentPages); err != nil {
+				return err
+			}
+			if withExtraData {
+				if err := confirmRequests(cache, watchlist.All()); err != nil {
+					return err
+				}
+				// Failed entries are exported without extra data, their
+				// category is then enriched again after importing.
+				var extraDataError proxerscrape.ExtraDataError
+				if err := watchlist.LoadExtraData(cache); errors.As(err, &extraDataError) {
+					for _, itemError := range extraDataError {
+						fmt.Fprintln(os.Stderr, itemError)
+					}
+				} else if err != nil {
+					return err
+				}
+			}
+
+			if jsonOutput == "" {
+				return proxerscrape.ExportWatchlistJSON(os.Stdout, &watchlist)
+			}
+			var buffer bytes.Buffer
+			if err := proxerscrape.ExportWatchlistJSON(&buffer, &watchlist); err != nil {
+				return err
+			}
+			return atomicfile.WriteFile(jsonOutput, buffer.Bytes(), 0o644)
+		},
+	}
+	jsonCmd.Flags().StringVar(&jsonUserID, "user", "", "ID of the user whose watchlist is exported.")
+	jsonCmd.Flags().IntVar(&jsonCommentPages, "comments", 0, "Amount of pages of the user's comments to include as reviews, 0 meaning none.")
+	jsonCmd.Flags().StringVar(&jsonTab, "tab", string(proxerscrape.ProfileTabAnime), "Profile tab to export.")
+	jsonCmd.Flags().BoolVar(&withExtraData, "extra", false, "Load the extra data, such as ratings and genres, of all entries.")
+	jsonCmd.Flags().StringVarP(&jsonOutput, "output", "o", "", "File to write to. Defaults to stdout.")
+	jsonCmd.MarkFlagRequired("user")
+	exportCmd.AddCommand(jsonCmd)
+
+	var icalUserID, icalOutput string
+	icalCmd := &cobra.Command{
+		Use:     "ical",
+		Short:   "Exports upcoming episodes of watched and planned anime as iCalendar.",
+		Example: "export ical --user 252835 --output airing.ics",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+
+			watchlist, err := retrieveWatchlist(cache, icalUserID, proxerscrape.ProfileTabAnime)
+			if err != nil {
+				return err
+			}
+			reader, _, err := cache.RetrieveCalendarRawData()
+			if err != nil {
+				return err
+			}
+			episodes, err := proxerscrape.ParseCalendar(reader)
+			reader.Close()
+			if err != nil {
+				return err
+			}
+			upcoming := proxerscrape.UpcomingEpisodes(episodes, &watchlist, time.Now())
+
+			if icalOutput == "" {
+				return export.WriteICS(os.Stdout, upcoming, time.Now())
+			}
+			var buffer bytes.Buffer
+			if err := export.WriteICS(&buffer, upcoming, time.Now()); err != nil {
+				return err
+			}
+			return atomicfile.WriteFile(icalOutput, buffer.Bytes(), 0o644)
+		},
+	}
+	icalCmd.Flags().StringVar(&icalUserID, "user", "", "ID of the user whose watchlist is used.")
+	icalCmd.Flags().StringVarP(&icalOutput, "output", "o", "", "File to write to. Defaults to stdout.")
+	icalCmd.MarkFlagRequired("user")
+	exportCmd.AddCommand(icalCmd)
+
+	var badgeUserID, badgeOutput string
+	badgeCmd := &cobra.Command{
+		Use:       "badge <watched|backlog>",
+		Short:     "Exports a statistic as shields.io endpoint JSON, which can be embedded as a badge.",
+		Example:   "export badge backlog --user 252835 --output backlog.json",
+		Args:      cobra.ExactValidArgs(1),
+		ValidArgs: []string{"watched", "backlog"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+
+			watchlist, err := retrieveWatchlist(cache, badgeUserID, proxerscrape.ProfileTabAnime)
+			if err != nil {
+				return err
+			}
+			var badge export.Badge
+			switch args[0] {
+			case "watched":
+				badge = export.WatchedBadge(&watchlist)
+			case "backlog":
+				badge = export.BacklogBadge(&watchlist)
+			}
+
+			if badgeOutput == "" {
+				return export.WriteBadge(os.Stdout, badge)
+			}
+			var buffer bytes.Buffer
+			if err := export.WriteBadge(&buffer, badge); err != nil {
+				return err
+			}
+			// The file is usually served directly, so it must never be
+			// observed half written.
+			return atomicfile.WriteFile(badgeOutput, buffer.Bytes(), 0o644)
+		},
+	}
+	badgeCmd.Flags().StringVar(&badgeUserID, "user", "", "ID of the user whose watchlist is used.")
+	badgeCmd.Flags().StringVarP(&badgeOutput, "output", "o", "", "File to write to. Defaults to stdout.")
+	badgeCmd.MarkFlagRequired("user")
+	exportCmd.AddCommand(badgeCmd)
+
+	// exportDatabase loads the watchlist, including its extra data if
+	// requested, and writes it via the given database exporter.
+	exportDatabase := func(userID string, columnMappings []string, withExtraData bool, write func(*proxerscrape.Watchlist, export.Columns) ([]*proxerscrape.Media, error)) error {
+		columns := export.DefaultColumns
+		if len(columnMappings) > 0 {
+			var err error
+			if columns, err = export.ParseColumns(columnMappings); err != nil {
+				return err
+			}
+		}
+
+		cache, closeCache, err := createCache()
+		if err != nil {
+			return err
+		}
+		defer closeCache()
+
+		watchlist, err := retrieveWatchlist(cache, userID, proxerscrape.ProfileTabAnime)
+		if err != nil {
+			return err
+		}
+		if withExtraData {
+			if err := confirmRequests(cache, watchlist.All()); err != nil {
+				return err
+			}
+			var extraDataError proxerscrape.ExtraDataError
+			if err := watchlist.LoadExtraData(cache); errors.As(err, &extraDataError) {
+				for _, itemError := range extraDataError {
+					fmt.Fprintln(os.Stderr, itemError)
+				}
+			} else if err != nil {
+				return err
+			}
+		}
+		skipped, err := write(&watchlist, columns)
+		printSkipped(skipped)
+		return err
+	}
+	fieldNames := make([]string, 0, len(export.Fields()))
+	for _, field := range export.Fields() {
+		fieldNames = append(fieldNames, string(field))
+	}
+	columnUsage := fmt.Sprintf("Column a field is written to, such as 'user_rating=Score'. Defaults to all fields using their default names. Fields are %s.", strings.Join(fieldNames, ", "))
+
+	var notionUserID, notionDatabaseID string
+	var notionColumns []string
+	var notionExtraData bool
+	notionCmd := &cobra.Command{
+		Use:   "notion",
+		Short: "Writes the anime watchlist into a Notion database.",
+		Long: `Writes the anime watchlist into a Notion database.
+
+The token of the integration is read from the environment variable
+NOTION_TOKEN, the database has to be shared with it. All columns have to
+exist in the database already. Entries that already have a row, identified
+by their URL or title, are updated.`,
+		Example: "export notion --user 252835 --database 668d797c76fa49349b05ad288df2d136 --column title=Name --column user_rating=Score",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			token := os.Getenv("NOTION_TOKEN")
+			if token == "" {
+				return errors.New("no notion token given, set NOTION_TOKEN")
+			}
+			return exportDatabase(notionUserID, notionColumns, notionExtraData, export.NewNotion(token, notionDatabaseID).Export)
+		},
+	}
+	notionCmd.Flags().StringVar(&notionUserID, "user", "", "ID of the user whose watchlist is exported.")
+	notionCmd.Flags().StringVar(&notionDatabaseID, "database", "", "ID of the Notion database to write to.")
+	notionCmd.Flags().StringSliceVar(&notionColumns, "column", nil, columnUsage)
+	notionCmd.Flags().BoolVar(&notionExtraData, "extra", false, "Load the extra data, such as ratings and genres, of all entries.")
+	notionCmd.MarkFlagRequired("user")
+	notionCmd.MarkFlagRequired("database")
+	exportCmd.AddCommand(notionCmd)
+
+	var airtableUserID, airtableBaseID, airtableTable string
+	var airtableColumns []string
+	var airtableExtraData bool
+	airtableCmd := &cobra.Command{
+		Use:   "airtable",
+		Short: "Writes the anime watchlist into an Airtable table.",
+		Long: `Writes the anime watchlist into an Airtable table.
+
+The personal access token is read from the environment variable
+AIRTABLE_TOKEN. The genres have to be written into a multiple select field.
+Entries that already have a record, identified by their URL or title, are
+updated.`,
+		Example: "export airtable --user 252835 --base appXXXXXXXXXXXXXX --table Anime --column user_rating=Score",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			token := os.Getenv("AIRTABLE_TOKEN")
+			if token == "" {
+				return errors.New("no airtable token given, set AIRTABLE_TOKEN")
+			}
+			return exportDatabase(airtableUserID, airtableColumns, airtableExtraData, export.NewAirtable(token, airtableBaseID, airtableTable).Export)
+		},
+	}
+	airtableCmd.Flags().StringVar(&airtableUserID, "user", "", "ID of the user whose watchlist is exported.")
+	airtableCmd.Flags().StringVar(&airtableBaseID, "base", "", "ID of the Airtable base to write to.")
+	airtableCmd.Flags().StringVar(&airtableTable, "table", "", "Name or ID of the table to write to.")
+	airtableCmd.Flags().StringSliceVar(&airtableColumns, "column", nil, columnUsage)
+	airtableCmd.Flags().BoolVar(&airtableExtraData, "extra", false, "Load the extra data, such as ratings and genres, of all entries.")
+	airtableCmd.MarkFlagRequired("user")
+	airtableCmd.MarkFlagRequired("base")
+	airtableCmd.MarkFlagRequired("table")
+	exportCmd.AddCommand(airtableCmd)
+
+	var letterboxdUserID, letterboxdOutput string
+	var letterboxdHistoryPages int
+	var letterboxdExtraData bool
+	letterboxdCmd := &cobra.Command{
+		Use:   "letterboxd",
+		Short: "Exports the watched anime movies as Letterboxd import CSV.",
+		Long: `Exports the watched anime movies as Letterboxd import CSV.
+
+The release year and English titles, which Letterboxd matches best on, are
+only included if the extra data of the movies is loaded via --extra. The
+watched date is the last time a movie appears in the history, so movies
+watched before the loaded history pages have none.`,
+		Example: "export letterboxd --user 252835 --extra --output letterboxd.csv",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+
+			watchlist, err := retrieveWatchlist(cache, letterboxdUserID, proxerscrape.ProfileTabAnime)
+			if err != nil {
+				return err
+			}
+			if letterboxdExtraData {
+				var movies []*proxerscrape.Media
+				for _, item := range watchlist.Watched.Data {
+					if item.Type == proxerscrape.MediaTypeMovie {
+						movies = append(movies, item)
+					}
+				}
+				if err := confirmRequests(cache, movies); err != nil {
+					return err
+				}
+				registry := proxerscrape.NewRegistry()
+				registry.Add(movies...)
+				var extraDataError proxerscrape.ExtraDataError
+				if err := registry.LoadExtraData(cache); errors.As(err, &extraDataError) {
+					for _, itemError := range extraDataError {
+						fmt.Fprintln(os.Stderr, itemError)
+					}
+				} else if err != nil {
+					return err
+				}
+			}
+			events, err := cache.RetrieveHistory(letterboxdUserID, letterboxdHistoryPages)
+			if err != nil {
+				return err
+			}
+
+			var buffer bytes.Buffer
+			skipped, err := export.WriteLetterboxd(&buffer, &watchlist, proxerscrape.LastWatched(events))
+			if err != nil {
+				return err
+			}
+			printSkipped(skipped)
+			if letterboxdOutput == "" {
+				_, err := os.Stdout.Write(buffer.Bytes())
+				return err
+			}
+			return atomicfile.WriteFile(letterboxdOutput, buffer.Bytes(), 0o644)
+		},
+	}
+	letterboxdCmd.Flags().StringVar(&letterboxdUserID, "user", "", "ID of the user whose watchlist is exported.")
+	letterboxdCmd.Flags().IntVar(&letterboxdHistoryPages, "history-pages", 5, "Amount of history pages used for determining when movies were watched.")
+	letterboxdCmd.Flags().BoolVar(&letterboxdExtraData, "extra", false, "Load the extra data, such as the release year, of all watched movies.")
+	letterboxdCmd.Flags().StringVarP(&letterboxdOutput, "output", "o", "", "File to write to. Defaults to stdout.")
+	letterboxdCmd.MarkFlagRequired("user")
+	exportCmd.AddCommand(letterboxdCmd)
+
+	return exportCmd
+}
+
+func generateReportCmd() *cobra.Command {
+	var userID, format, title, output string
+	var covers, withExtraData, notes bool
+	reportCmd := &cobra.Command{
+		Use:   "report",
+		Short: "Renders a watchlist as a self-contained HTML page or Markdown document.",
+		Long: `Renders a watchlist as a self-contained HTML page or Markdown document.
+
+Ratings and genres are only included if the extra data is loaded via --extra.`,
+		Example: "report --user 252835 --format html --covers --extra --output watchlist.html",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var write func(io.Writer, *proxerscrape.Watchlist, export.ReportOptions, time.Time) error
+			switch format {
+			case "html":
+				write = export.WriteHTMLReport
+			case "markdown":
+				write = export.WriteMarkdownReport
+			default:
+				return fmt.Errorf("unknown format '%s'", format)
+			}
+
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+
+			watchlist, err := retrieveWatchlist(cache, userID, proxerscrape.ProfileTabAnime)
+			if err != nil {
+				return err
+			}
+			if withExtraData {
+				if err := confirmRequests(cache, watchlist.All()); err != nil {
+					return err
+				}
+				var extraDataError proxerscrape.ExtraDataError
+				if err := watchlist.LoadExtraData(cache); errors.As(err, &extraDataError) {
+					for _, itemError := range extraDataError {
+						fmt.Fprintln(os.Stderr, itemError)
+					}
+				} else if err != nil {
+					return err
+				}
+			}
+
+			options := export.ReportOptions{Title: title, Covers: covers}
+			if notes {
+				userData, _, err := loadUserData()
+				if err != nil {
+					return err
+				}
+				options.UserData = userData
+			}
+			if output == "" {
+				return write(os.Stdout, &watchlist, options, time.Now())
+			}
+			var buffer bytes.Buffer
+			if err := write(&buffer, &watchlist, options, time.Now()); err != nil {
+				return err
+			}
+			return atomicfile.WriteFile(output, buffer.Bytes(), 0o644)
+		},
+	}
+	reportCmd.Flags().StringVar(&userID, "user", "", "ID of the user whose watchlist is rendered.")
+	reportCmd.Flags().StringVar(&format, "format", "html", "Format of the report, either 'html' or 'markdown'.")
+	reportCmd.Flags().StringVar(&title, "title", "", "Heading of the report.")
+	reportCmd.Flags().BoolVar(&covers, "covers", false, "Include the cover images, which are loaded from proxer.me when viewing the report.")
+	reportCmd.Flags().BoolVar(&withExtraData, "extra", false, "Load the extra data, such as ratings and genres, of all entries.")
+	reportCmd.Flags().BoolVar(&notes, "notes", false, "Include the local notes and tags of the entries.")
+	reportCmd.Flags().StringVarP(&output, "output", "o", "", "File to write to. Defaults to stdout.")
+	reportCmd.MarkFlagRequired("user")
+	return reportCmd
+}
+
+func generateAniListCmd() *cobra.Command {
+	aniListCmd := &cobra.Command{
+		Use:   "anilist",
+		Short: "Keeps the AniList list of a user in sync with proxer.me.",
+	}
+
+	var userID, token string
+	var commentPages int
+	syncCmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Pushes status, progress and score of all mapped anime to AniList.",
+		Long: `Pushes status, progress and score of all mapped anime to AniList.
+
+Only fields that differ are changed, just like 'sync anilist --direction push',
+and scores are never cleared. Additionally, rewatches and, via --comments,
+reviews are pushed. The OAuth access token can be passed via --token or the environment variable
+ANILIST_TOKEN. Entries have to be mapped first, see 'map resolve'.`,
+		Example: "anilist sync --user 252835",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if token == "" {
+				token = os.Getenv("ANILIST_TOKEN")
+			}
+			if token == "" {
+				return errors.New("no anilist token given, use --token or ANILIST_TOKEN")
+			}
+
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+
+			watchlist, err := retrieveWatchlist(cache, userID, proxerscrape.ProfileTabAnime)
+			if err != nil {
+				return err
+			}
+			if err := applyComments(cache, userID, &watchlist, commentPages); err != nil {
+				return err
+			}
+			storePath, err := mapping.DefaultStorePath()
+			if err != nil {
+				return err
+			}
+			store, err := mapping.LoadStore(storePath)
+			if err != nil {
+				return err
+			}
+			userData, _, err := loadUserData()
+			if err != nil {
+				return err
+			}
+
+			skipped, err := anilist.NewClient(token).Sync(&watchlist, store, userData)
+			printSkipped(skipped)
+			return err
+		},
+	}
+	syncCmd.Flags().StringVar(&userID, "user", "", "ID of the user whose watchlist is synced.")
+	syncCmd.Flags().IntVar(&commentPages, "comments", 0, "Amount of pages of the user's comments to include as reviews, 0 meaning none.")
+	syncCmd.Flags().StringVar(&token, "token", "", "OAuth access token of AniList.")
+	syncCmd.MarkFlagRequired("user")
+	aniListCmd.AddCommand(syncCmd)
+
+	return aniListCmd
+}
+
+func generateKitsuCmd() *cobra.Command {
+	kitsuCmd := &cobra.Command{
+		Use:   "kitsu",
+		Short: "Keeps the Kitsu library of a user in sync with proxer.me.",
+	}
+
+	var userID, username string
+	var commentPages int
+	syncCmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Pushes status, progress and score of all mapped anime to Kitsu.",
+		Long: `Pushes status, progress and score of all mapped anime to Kitsu.
+
+The Kitsu library is retrieved once and only entries that differ are
+changed. Ratings are never cleared on Kitsu.
+
+The password of the Kitsu account is read from the environment variable
+KITSU_PASSWORD. Entries have to be mapped first, see 'map resolve --service kitsu'.`,
+		Example: "kitsu sync --user 252835 --username me@example.com",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			password := os.Getenv("KITSU_PASSWORD")
+			if password == "" {
+				return errors.New("no kitsu password given, set KITSU_PASSWORD")
+			}
+
+			cache, closeCache, err := createCache()
+			if err != nil {
+				return err
+			}
+			defer closeCache()
+
+			watchlist, err := retrieveWatchlist(cache, userID, proxerscrape.ProfileTabAnime)
+			if err != nil {
+				return err
+			}
+			if err := applyComments(cache, userID, &watchlist, commentPages); err != nil {
+				return err
+			}
+			storePath, err := mapping.DefaultStorePath()
+			if err != nil {
+				return err
+			}
+			store, err := mapping.LoadStore(storePath)
+			if err != nil {
+				return err
+			}
+			userData, _, err := loadUserData()
+			if err != nil {
+				return err
+			}
+
+			client := kitsu.NewClient()
+			if err := client.Login(username, password); err != nil {
+				return err
+			}
+			skipped, err := client.Sync(&watchlist, store, userData)
+			printSkipped(skipped)
+			return err
+		},
+	}
+	syncCmd.Flags().StringVar(&userID, "user", "", "ID of the user whose watchlist is synced.")
+	syncCmd.Flags().IntVar(&commentPages, "comments", 0, "Amount of pages of the user's comments to include as reviews, 0 meaning none.")
+	syncCmd.Flags().StringVar(&username, "username", "", "Username or email of the Kitsu account.")
+	syncCmd.MarkFlagRequired("user")
+	syncCmd.MarkFlagRequired("username")
+	kitsuCmd.AddCommand(syncCmd)
+
+	return kitsuCmd
+}
+
+// resolveTraktSeason asks for the season of Trakt show mappings, since
+// proxer.me lists seasons as separate entries, while Trakt has one show for
+// all of them. Without a season, 'trakt sync' skips the entry.
+func resolveTraktSeason(input *bufio.Scanner, store *mapping.Store, item *proxerscrape.Media, resolved mapping.Mapping) error {
+	target, err := trakt.ParseTarget(resolved.ExternalID)
+	if err != nil || target.Movie || target.Season != trakt.NoSeason {
+		return err
+	}
+
+	fmt.Printf("Season of '%s' on Trakt (empty to skip): ", item.Title)
+	if !input.Scan() {
+		return nil
+	}
+	season, err := strconv.Atoi(strings.TrimSpace(input.Text()))
+	if err != nil || season < 0 {
+		return nil
+	}
+	target.Season = season
+	resolved.ExternalID = target.String()
+	store.Set(item.ProxerID(), mapping.ServiceTrakt, resolved)
+	return nil
+}
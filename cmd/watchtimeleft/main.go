@@ -1,7 +1,9 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"log"
 	"os"
 	"time"
 
@@ -9,39 +11,63 @@ import (
 )
 
 func main() {
-	watchlist, parseError := parse.ParseProfileMediaTab(os.Stdin)
+	language := flag.String("language", "", "If set, locally tracked progress for this language (e.g. gerdub) is used instead of the profile progress.")
+	chapterTime := flag.Duration("chapter-time", 0, "Reading time per manga chapter. If unset, the average for the manga type is used.")
+	verbose := flag.Bool("verbose", false, "Logs every parsed entry to stderr, which helps debugging the parser.")
+	flag.Parse()
+
+	var options parse.ParseOptions
+	if *verbose {
+		options.Logger = log.Default()
+	}
+	watchlist, warnings, parseError := parse.ParseProfileMediaTabWithOptions(os.Stdin, options)
 	if parseError != nil {
 		panic(parseError)
 	}
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+
+	// Without a language, we simply use the progress from the profile.
+	episodesWatched := func(item *parse.Media) uint16 {
+		return item.EpisodesWatched
+	}
+	if *language != "" {
+		userDataPath, err := parse.DefaultUserDataPath()
+		if err != nil {
+			panic(err)
+		}
+		userData, err := parse.LoadUserData(userDataPath)
+		if err != nil {
+			panic(err)
+		}
+		episodesWatched = func(item *parse.Media) uint16 {
+			return userData.EpisodesWatched(item, parse.Language(*language))
+		}
+	}
+
+	// Manga tabs can be passed as well, which are estimated via reading time.
+	timeLeft := func(item *parse.Media) time.Duration {
+		if manga, ok := parse.AsManga(item); ok {
+			return manga.EstimatedReadingTimeAfter(episodesWatched(item), *chapterTime)
+		}
+		return item.EstimatedWatchTimeAfter(episodesWatched(item))
+	}
 
 	var currentlyWatchingLeft time.Duration
 	fmt.Printf("Currently Watching (%d)\n", len(watchlist.CurrentlyWatching.Data))
 	for _, item := range watchlist.CurrentlyWatching.Data {
-		currentlyWatchingLeft += getWatchtimeLeft(item)
+		currentlyWatchingLeft += timeLeft(item)
 		fmt.Println(item.Title)
 	}
 
 	fmt.Printf("\nTo Watch (%d)\n", len(watchlist.ToWatch.Data))
 	var toWatchLeft time.Duration
 	for _, item := range watchlist.ToWatch.Data {
-		toWatchLeft += getWatchtimeLeft(item)
+		toWatchLeft += timeLeft(item)
 		fmt.Println(item.Title)
 	}
 
-	fmt.Printf("\n%s hours (%d anime) on to watch list.\n", toWatchLeft, len(watchlist.ToWatch.Data))
-	fmt.Printf("%s hours (%d anime) on currently watching list.\n", currentlyWatchingLeft, len(watchlist.CurrentlyWatching.Data))
-}
-
-func getWatchtimeLeft(anime *parse.Media) time.Duration {
-	switch anime.Type {
-	case parse.Series:
-		return time.Duration(time.Duration(anime.EpisodeCount-anime.EpisodesWatched) * 20 * time.Minute)
-	case parse.Movie:
-		return time.Duration(90 * time.Minute)
-	case parse.Special:
-		//What to put here?
-		return time.Duration(time.Duration(anime.EpisodeCount-anime.EpisodesWatched) * 7 * time.Minute)
-	}
-
-	return 0
+	fmt.Printf("\n%s hours (%d entries) on to watch list.\n", toWatchLeft, len(watchlist.ToWatch.Data))
+	fmt.Printf("%s hours (%d entries) on currently watching list.\n", currentlyWatchingLeft, len(watchlist.CurrentlyWatching.Data))
 }
@@ -0,0 +1,132 @@
+package proxerscrape
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Comment is a comment or review a user has written about an entry.
+type Comment struct {
+	ProxerURL string
+	Title     string
+	Text      string
+	// Rating is the amount of stars given in the comment, 0 if none.
+	Rating    uint8
+	WrittenAt time.Time
+}
+
+// ProxerID returns the ID of the entry the comment is about, or an empty
+// string if unknown.
+func (comment *Comment) ProxerID() string {
+	return formatMediaID(comment.ProxerURL)
+}
+
+// RetrieveCommentsRawData retrieves a page of the comments written by the
+// given user, starting at 1. Comments on entries restricted to logged in
+// users are only listed if the cache is logged in.
+func (cache *Cache) RetrieveCommentsRawData(profileID string, page int) (io.ReadCloser, CacheInvalidator, error) {
+	cacheKey := fmt.Sprintf("profile/%s/comments/%d", profileID, page)
+	path := fmt.Sprintf("/user/%s/comments?p=%d", profileID, page)
+	return cache.retrievePage(CacheEntryProfileTab, cacheKey, path, cache.ProfileTabQueryRatelimiter)
+}
+
+// ParseComments parses a page of comments. Every comment is expected to be
+// a table row or a block with the class "comment", linking the entry. The
+// text is taken from the element with the class "comment-text" or, if there
+// is none, from the longest cell.
+func ParseComments(reader io.Reader) ([]Comment, error) {
+	document, err := newDocument(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var comments []Comment
+	document.Find("tr, .comment").Each(func(_ int, block *goquery.Selection) {
+		// Comments inside of a layout table would be visited twice.
+		if block.Find("tr, .comment").Length() > 0 {
+			return
+		}
+		link := block.Find("a[href*='/info/']").First()
+		href, _ := link.Attr("href")
+		id := proxerIDRegex.FindStringSubmatch(href)
+		if id == nil {
+			return
+		}
+
+		comment := Comment{
+			ProxerURL: "/info/" + id[1],
+			Title:     strings.TrimSpace(normalizeText(link.Text())),
+		}
+		if text := block.Find(".comment-text").First(); text.Length() > 0 {
+			comment.Text = strings.TrimSpace(normalizeText(text.Text()))
+		} else {
+			block.Find("td").Each(func(_ int, cell *goquery.Selection) {
+				if text := strings.TrimSpace(normalizeText(cell.Text())); len(text) > len(comment.Text) && text != comment.Title {
+					comment.Text = text
+				}
+			})
+		}
+		if comment.Text == "" {
+			return
+		}
+		block.Find("img").Each(func(_ int, star *goquery.Selection) {
+			if src, _ := star.Attr("src"); strings.Contains(src, "stern") && !strings.Contains(src, "grau") {
+				comment.Rating++
+			}
+		})
+		if date := profileDateRegex.FindString(block.Text()); date != "" {
+			comment.WrittenAt, _ = time.ParseInLocation("02.01.2006", date, proxerLocation())
+		}
+		comments = append(comments, comment)
+	})
+	return comments, nil
+}
+
+// RetrieveComments retrieves and parses the given amount of comment pages,
+// stopping early once a page is empty.
+func (cache *Cache) RetrieveComments(profileID string, pages int) ([]Comment, error) {
+	var comments []Comment
+	for page := 1; page <= pages; page++ {
+		reader, _, err := cache.RetrieveCommentsRawData(profileID, page)
+		if err != nil {
+			return comments, err
+		}
+		pageComments, err := ParseComments(reader)
+		reader.Close()
+		if err != nil {
+			return comments, err
+		}
+		if len(pageComments) == 0 {
+			break
+		}
+		comments = append(comments, pageComments...)
+	}
+	return comments, nil
+}
+
+// ApplyComments sets the review of all watchlist entries the user has
+// commented on, so that exports include them. If there are multiple
+// comments on an entry, the latest one is used. Returns the amount of
+// entries updated.
+func ApplyComments(watchlist *Watchlist, comments []Comment) int {
+	latest := make(map[string]*Comment)
+	for index := range comments {
+		comment := &comments[index]
+		if previous, present := latest[comment.ProxerID()]; !present || comment.WrittenAt.After(previous.WrittenAt) {
+			latest[comment.ProxerID()] = comment
+		}
+	}
+
+	var applied int
+	for _, item := range watchlist.All() {
+		if comment, present := latest[item.ProxerID()]; present {
+			item.Review = comment.Text
+			applied++
+		}
+	}
+	return applied
+}
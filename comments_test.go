@@ -0,0 +1,43 @@
+package proxerscrape
+
+import (
+	"strings"
+	"testing"
+)
+
+const commentsPage = `<html><body>
+<div class="comment">
+	<a href="/info/53#top">Naruto</a>
+	<img src="/images/stern.png"><img src="/images/stern.png"><img src="/images/stern_grau.png">
+	<span>Geschrieben am 05.03.2020</span>
+	<div class="comment-text">Old review</div>
+</div>
+<div class="comment">
+	<a href="/info/53#top">Naruto</a>
+	<span>Geschrieben am 06.07.2024</span>
+	<div class="comment-text">Rewatched, still good.</div>
+</div>
+<table><tr><td><a href="/info/9">Other</a></td><td>Written in a table layout instead.</td><td>01.01.2021</td></tr></table>
+</body></html>`
+
+func TestParseComments(t *testing.T) {
+	comments, err := ParseComments(strings.NewReader(commentsPage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comments) != 3 {
+		t.Fatalf("Expected 3 comments, got %d", len(comments))
+	}
+	if first := comments[0]; first.Title != "Naruto" || first.Text != "Old review" || first.Rating != 2 || first.WrittenAt.Year() != 2020 {
+		t.Errorf("Unexpected first comment: %+v", first)
+	}
+	if table := comments[2]; table.Text != "Written in a table layout instead." || table.ProxerID() != "9" {
+		t.Errorf("Unexpected comment from table: %+v", table)
+	}
+
+	watchlist := &Watchlist{}
+	watchlist.Watched.Data = []*Media{{ProxerURL: "/info/53"}, {ProxerURL: "/info/1"}}
+	if applied := ApplyComments(watchlist, comments); applied != 1 || watchlist.Watched.Data[0].Review != "Rewatched, still good." {
+		t.Errorf("Expected latest review to be applied, got %d, %q", applied, watchlist.Watched.Data[0].Review)
+	}
+}
@@ -0,0 +1,70 @@
+package proxerscrape
+
+import "sort"
+
+// SharedEntry is an entry present on both compared watchlists.
+type SharedEntry struct {
+	A, B *Media
+}
+
+// RatingDifference returns how many stars the ratings differ by, 0 if
+// either hasn't rated the entry.
+func (entry SharedEntry) RatingDifference() int {
+	if entry.A.UserRating == 0 || entry.B.UserRating == 0 {
+		return 0
+	}
+	difference := int(entry.A.UserRating) - int(entry.B.UserRating)
+	if difference < 0 {
+		return -difference
+	}
+	return difference
+}
+
+// WatchlistComparison is the result of CompareWatchlists.
+type WatchlistComparison struct {
+	// Shared are the entries present on both watchlists, in any category.
+	Shared []SharedEntry
+	// Disagreements are the shared entries both users rated differently,
+	// ordered by the difference, biggest first.
+	Disagreements []SharedEntry
+	// WatchedOnlyByA are the entries A has watched, but B hasn't, even
+	// though B might plan to.
+	WatchedOnlyByA []*Media
+	// WatchedOnlyByB is the counterpart to WatchedOnlyByA.
+	WatchedOnlyByB []*Media
+}
+
+// CompareWatchlists compares the watchlists of two users. Entries are
+// matched by their proxer ID.
+func CompareWatchlists(a, b Watchlist) WatchlistComparison {
+	var comparison WatchlistComparison
+	bByID := make(map[uint]*Media)
+	for _, item := range b.All() {
+		bByID[item.mediaID()] = item
+	}
+	aByID := make(map[uint]*Media)
+	for _, item := range a.All() {
+		aByID[item.mediaID()] = item
+		other, present := bByID[item.mediaID()]
+		if present {
+			shared := SharedEntry{A: item, B: other}
+			comparison.Shared = append(comparison.Shared, shared)
+			if shared.RatingDifference() > 0 {
+				comparison.Disagreements = append(comparison.Disagreements, shared)
+			}
+		}
+		if item.Category == CategoryWatched && (!present || other.Category != CategoryWatched) {
+			comparison.WatchedOnlyByA = append(comparison.WatchedOnlyByA, item)
+		}
+	}
+	for _, item := range b.Watched.Data {
+		if other, present := aByID[item.mediaID()]; !present || other.Category != CategoryWatched {
+			comparison.WatchedOnlyByB = append(comparison.WatchedOnlyByB, item)
+		}
+	}
+
+	sort.SliceStable(comparison.Disagreements, func(i, j int) bool {
+		return comparison.Disagreements[i].RatingDifference() > comparison.Disagreements[j].RatingDifference()
+	})
+	return comparison
+}
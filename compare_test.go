@@ -0,0 +1,56 @@
+package proxerscrape
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompareWatchlists(t *testing.T) {
+	var a, b Watchlist
+	a.Watched.Data = []*Media{
+		{ProxerURL: "/info/1", Title: "Both", Category: CategoryWatched, UserRating: 5},
+		{ProxerURL: "/info/2", Title: "Only A", Category: CategoryWatched},
+		{ProxerURL: "/info/3", Title: "B plans", Category: CategoryWatched, UserRating: 4},
+	}
+	b.Watched.Data = []*Media{
+		{ProxerURL: "/info/1", Title: "Both", Category: CategoryWatched, UserRating: 1},
+		{ProxerURL: "/info/4", Title: "Only B", Category: CategoryWatched},
+	}
+	b.ToWatch.Data = []*Media{
+		{ProxerURL: "/info/3", Title: "B plans", Category: CategoryToWatch, UserRating: 2},
+	}
+
+	comparison := CompareWatchlists(a, b)
+	if len(comparison.Shared) != 2 {
+		t.Errorf("Expected 2 shared entries, got %d", len(comparison.Shared))
+	}
+	if len(comparison.Disagreements) != 2 || comparison.Disagreements[0].RatingDifference() != 4 {
+		t.Errorf("Unexpected disagreements: %+v", comparison.Disagreements)
+	}
+	titles := func(items []*Media) string {
+		var titles []string
+		for _, item := range items {
+			titles = append(titles, item.Title)
+		}
+		return strings.Join(titles, ",")
+	}
+	if actual := titles(comparison.WatchedOnlyByA); actual != "Only A,B plans" {
+		t.Errorf("Unexpected entries only watched by A: %s", actual)
+	}
+	if actual := titles(comparison.WatchedOnlyByB); actual != "Only B" {
+		t.Errorf("Unexpected entries only watched by B: %s", actual)
+	}
+}
+
+func TestParseFriends(t *testing.T) {
+	page := `<a href="/user/1/overview">Myself</a>
+<a href="/user/2#top"><img src="/avatar/2.jpg"></a><a href="/user/2#top">Alice</a>
+<a href="https://proxer.me/user/3">Bob</a>`
+	friends, err := ParseFriends(strings.NewReader(page), "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(friends) != 2 || friends[0] != (Friend{UserID: "2", Username: "Alice"}) || friends[1].Username != "Bob" {
+		t.Errorf("Unexpected friends: %+v", friends)
+	}
+}
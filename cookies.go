@@ -0,0 +1,115 @@
+package proxerscrape
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// proxerURL is the URL cookies are stored for inside of the jar.
+var proxerURL = &url.URL{Scheme: "https", Host: "proxer.me", Path: "/"}
+
+// cookiesFor returns the URL the given cookies for proxer.me have to be stored
+// for, in order to be sent to the given base URL. For hosts other than
+// proxer.me, such as mirrors, copies bound to that host are returned.
+func cookiesFor(baseURL string, cookies []*http.Cookie) (*url.URL, []*http.Cookie, error) {
+	if baseURL == "" || baseURL == DefaultBaseURL {
+		return proxerURL, cookies, nil
+	}
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	if parsed.Host == "" {
+		return nil, nil, fmt.Errorf("base URL '%s' has no host", baseURL)
+	}
+
+	adapted := make([]*http.Cookie, 0, len(cookies))
+	for _, cookie := range cookies {
+		copied := *cookie
+		copied.Domain = ""
+		// Test servers and local proxies usually don't use HTTPS.
+		copied.Secure = parsed.Scheme == "https"
+		adapted = append(adapted, &copied)
+	}
+	return &url.URL{Scheme: parsed.Scheme, Host: parsed.Host, Path: "/"}, adapted, nil
+}
+
+// NewCookieJar creates a jar containing the given cookies for proxer.me.
+// Cookies set by proxer.me during requests are kept as well.
+func NewCookieJar(cookies ...*http.Cookie) (http.CookieJar, error) {
+	jar, err := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	if err != nil {
+		return nil, err
+	}
+	jar.SetCookies(proxerURL, cookies)
+	return jar, nil
+}
+
+// ParseCookiesTxt parses cookies in the Netscape cookies.txt format, as
+// exported by curl, wget and various browser extensions. Only cookies for
+// proxer.me are returned.
+func ParseCookiesTxt(reader io.Reader) ([]*http.Cookie, error) {
+	var cookies []*http.Cookie
+	scanner := bufio.NewScanner(reader)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		httpOnly := false
+		// curl marks HttpOnly cookies with a prefix, which would otherwise
+		// look like a comment.
+		if strings.HasPrefix(line, "#HttpOnly_") {
+			line = strings.TrimPrefix(line, "#HttpOnly_")
+			httpOnly = true
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("line %d: expected 7 fields, got %d", lineNumber, len(fields))
+		}
+		domain := strings.TrimPrefix(fields[0], ".")
+		if domain != "proxer.me" && !strings.HasSuffix(domain, ".proxer.me") {
+			continue
+		}
+		expiry, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid expiry: %w", lineNumber, err)
+		}
+
+		cookie := &http.Cookie{
+			Domain:   domain,
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			Name:     fields[5],
+			Value:    fields[6],
+			HttpOnly: httpOnly,
+		}
+		// Zero means the cookie only lasts for the session.
+		if expiry > 0 {
+			cookie.Expires = time.Unix(expiry, 0)
+		}
+		cookies = append(cookies, cookie)
+	}
+	return cookies, scanner.Err()
+}
+
+// LoadCookiesTxt reads the proxer.me cookies from the given cookies.txt.
+func LoadCookiesTxt(path string) ([]*http.Cookie, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseCookiesTxt(file)
+}
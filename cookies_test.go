@@ -0,0 +1,47 @@
+package proxerscrape
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestParseCookiesTxt(t *testing.T) {
+	cookiesTxt := strings.Join([]string{
+		"# Netscape HTTP Cookie File",
+		"",
+		".proxer.me\tTRUE\t/\tTRUE\t1893456000\tjoomla_remember_me_abc\tsecret",
+		"#HttpOnly_proxer.me\tFALSE\t/\tTRUE\t0\tsession\tsessionvalue",
+		".example.com\tTRUE\t/\tFALSE\t0\tother\tvalue",
+	}, "\n")
+	cookies, err := ParseCookiesTxt(strings.NewReader(cookiesTxt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cookies) != 2 {
+		t.Fatalf("Parsed %d cookies, instead of 2: %v", len(cookies), cookies)
+	}
+	if cookies[0].Name != "joomla_remember_me_abc" || cookies[0].Value != "secret" || cookies[0].Expires.Unix() != 1893456000 {
+		t.Errorf("Unexpected login cookie: %+v", cookies[0])
+	}
+	if !cookies[1].HttpOnly || !cookies[1].Expires.IsZero() {
+		t.Errorf("Unexpected session cookie: %+v", cookies[1])
+	}
+
+	if _, err := ParseCookiesTxt(strings.NewReader("proxer.me\tTRUE")); err == nil {
+		t.Error("Malformed line was accepted")
+	}
+}
+
+func TestNewCookieJar(t *testing.T) {
+	jar, err := NewCookieJar(
+		NewLoginCookie("joomla_remember_me_abc", "secret"),
+		&http.Cookie{Name: "tags", Value: "1", Domain: "proxer.me", Path: "/"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cookies := jar.Cookies(proxerURL); len(cookies) != 2 {
+		t.Errorf("Cookies weren't added to the jar: %v", cookies)
+	}
+}
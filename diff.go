@@ -0,0 +1,89 @@
+package proxerscrape
+
+// ChangeType tells how an entry differs between two watchlists.
+type ChangeType string
+
+const (
+	// ChangeAdded means the entry wasn't present in the previous watchlist.
+	ChangeAdded ChangeType = "added"
+	// ChangeRemoved means the entry isn't present in the current watchlist.
+	ChangeRemoved ChangeType = "removed"
+	// ChangeMoved means the entry has been moved into another category.
+	ChangeMoved ChangeType = "moved"
+	// ChangeProgress means the amount of watched episodes has changed.
+	ChangeProgress ChangeType = "progress"
+)
+
+// Change is a difference of a single entry between two watchlists.
+type Change struct {
+	Type ChangeType
+	// Previous is nil for added entries and Current is nil for removed
+	// entries.
+	Previous, Current *Media
+}
+
+// Completed checks whether the change means that the entry has been
+// finished, either by moving it into the watched category or by watching
+// the last episode. Entries added straight to the watched category don't
+// count, since those are usually backfilled entries finished long ago.
+func (change Change) Completed() bool {
+	if change.Current == nil || change.Previous == nil {
+		return false
+	}
+	if change.Current.Category == CategoryWatched {
+		return change.Previous.Category != CategoryWatched
+	}
+	return change.Type == ChangeProgress &&
+		change.Current.EpisodeCount > 0 &&
+		change.Current.EpisodesWatched >= change.Current.EpisodeCount &&
+		change.Previous.EpisodesWatched < change.Previous.EpisodeCount
+}
+
+// DiffWatchlists compares two versions of a watchlist and returns all
+// changes. Entries are matched by their proxer ID. An entry that has been
+// moved and whose progress changed is only reported as moved.
+func DiffWatchlists(previous, current Watchlist) []Change {
+	previousByID := make(map[uint]*Media)
+	for _, item := range previous.All() {
+		previousByID[item.mediaID()] = item
+	}
+
+	var changes []Change
+	for _, item := range current.All() {
+		identifier := item.mediaID()
+		old, present := previousByID[identifier]
+		delete(previousByID, identifier)
+		switch {
+		case !present:
+			changes = append(changes, Change{Type: ChangeAdded, Current: item})
+		case old.Category != item.Category:
+			changes = append(changes, Change{Type: ChangeMoved, Previous: old, Current: item})
+		case old.EpisodesWatched != item.EpisodesWatched:
+			changes = append(changes, Change{Type: ChangeProgress, Previous: old, Current: item})
+		}
+	}
+	// Iterating the previous list again keeps the order stable.
+	for _, item := range previous.All() {
+		if _, removed := previousByID[item.mediaID()]; removed {
+			changes = append(changes, Change{Type: ChangeRemoved, Previous: item})
+		}
+	}
+	return changes
+}
+
+// finished checks whether all episodes of the item have been watched.
+func (item *Media) finished() bool {
+	return item.Category == CategoryWatched ||
+		(item.EpisodeCount > 0 && item.EpisodesWatched >= item.EpisodeCount)
+}
+
+// Restarted checks whether the change means that a finished entry is being
+// watched again, since its progress has been reset.
+func (change Change) Restarted() bool {
+	if change.Previous == nil || change.Current == nil {
+		return false
+	}
+	return change.Previous.finished() &&
+		!change.Current.finished() &&
+		change.Current.EpisodesWatched < change.Previous.EpisodesWatched
+}
@@ -0,0 +1,84 @@
+package proxerscrape
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDiffWatchlists(t *testing.T) {
+	previous := Watchlist{}
+	previous.CurrentlyWatching.Data = []*Media{
+		{ProxerURL: "/info/1", Category: CategoryCurrentlyWatching, EpisodesWatched: 11, EpisodeCount: 12},
+		{ProxerURL: "/info/2", Category: CategoryCurrentlyWatching, EpisodesWatched: 3, EpisodeCount: 12},
+		{ProxerURL: "/info/3", Category: CategoryCurrentlyWatching, EpisodesWatched: 5, EpisodeCount: 24},
+	}
+	previous.ToWatch.Data = []*Media{{ProxerURL: "/info/4", Category: CategoryToWatch}}
+
+	current := Watchlist{}
+	current.Watched.Data = []*Media{
+		{ProxerURL: "/info/2", Category: CategoryWatched, EpisodesWatched: 12, EpisodeCount: 12},
+		// Backfilled entries don't count as completed.
+		{ProxerURL: "/info/6", Category: CategoryWatched, EpisodesWatched: 12, EpisodeCount: 12},
+	}
+	current.CurrentlyWatching.Data = []*Media{
+		{ProxerURL: "/info/1", Category: CategoryCurrentlyWatching, EpisodesWatched: 12, EpisodeCount: 12},
+		{ProxerURL: "/info/3", Category: CategoryCurrentlyWatching, EpisodesWatched: 6, EpisodeCount: 24},
+	}
+	current.ToWatch.Data = []*Media{{ProxerURL: "/info/5", Category: CategoryToWatch}}
+
+	changes := DiffWatchlists(previous, current)
+	types := make(map[string]ChangeType)
+	completed := make(map[string]bool)
+	for _, change := range changes {
+		item := change.Current
+		if item == nil {
+			item = change.Previous
+		}
+		types[item.ProxerID()] = change.Type
+		completed[item.ProxerID()] = change.Completed()
+	}
+
+	expected := map[string]ChangeType{"1": ChangeProgress, "2": ChangeMoved, "3": ChangeProgress, "4": ChangeRemoved, "5": ChangeAdded, "6": ChangeAdded}
+	for identifier, changeType := range expected {
+		if types[identifier] != changeType {
+			t.Errorf("Change of %s = %s, instead of %s", identifier, types[identifier], changeType)
+		}
+	}
+	if !completed["1"] || !completed["2"] || completed["3"] || completed["5"] || completed["6"] {
+		t.Errorf("Unexpected completions: %v", completed)
+	}
+
+	userData := &UserData{}
+	winter := time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC)
+	if recorded := userData.RecordCompletions(changes, winter); recorded != 2 {
+		t.Errorf("Recorded %d completions, instead of 2", recorded)
+	}
+	if recorded := userData.RecordCompletions(changes, winter); recorded != 0 {
+		t.Errorf("Completions were recorded twice")
+	}
+	userData.RecordCompletions(changes, winter.AddDate(0, 3, 0))
+
+	totals := userData.SeasonTotals(2025)
+	if len(totals) != 2 || totals[0].String() != "Winter 2025: finished 2" || totals[1].Season != Q2 {
+		t.Errorf("Unexpected totals: %v", totals)
+	}
+}
+
+func TestChange_Restarted(t *testing.T) {
+	previous := Watchlist{}
+	previous.Watched.Data = []*Media{{ProxerURL: "/info/1", Category: CategoryWatched, EpisodesWatched: 12, EpisodeCount: 12}}
+	current := Watchlist{}
+	current.CurrentlyWatching.Data = []*Media{{ProxerURL: "/info/1", Category: CategoryCurrentlyWatching, EpisodesWatched: 1, EpisodeCount: 12}}
+
+	userData := &UserData{}
+	if recorded := userData.RecordRewatches(DiffWatchlists(previous, current)); recorded != 1 {
+		t.Errorf("Recorded %d rewatches, instead of 1", recorded)
+	}
+	// Continuing the rewatch mustn't count again.
+	if recorded := userData.RecordRewatches(DiffWatchlists(current, current)); recorded != 0 {
+		t.Errorf("Recorded %d rewatches, instead of 0", recorded)
+	}
+	if rewatches := userData.Rewatched(current.CurrentlyWatching.Data[0]); rewatches != 1 {
+		t.Errorf("Rewatches = %d, instead of 1", rewatches)
+	}
+}
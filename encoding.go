@@ -0,0 +1,56 @@
+package proxerscrape
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeEncoding converts an HTML page to UTF-8. The encoding is detected
+// via the given Content-Type header, the page's meta tags or its content.
+// Pages that are already valid UTF-8 are returned unchanged.
+func NormalizeEncoding(data []byte, contentType string) []byte {
+	if utf8.Valid(data) {
+		return data
+	}
+
+	encoding, name, _ := charset.DetermineEncoding(data, contentType)
+	// The page claims to be UTF-8, but isn't. Since proxer.me is a German
+	// site, the most likely culprit is windows-1252, a superset of latin1.
+	if name == "utf-8" {
+		encoding = charmap.Windows1252
+	}
+	decoded, err := encoding.NewDecoder().Bytes(data)
+	if err != nil {
+		return data
+	}
+	return decoded
+}
+
+// normalizeText repairs UTF-8 text that has been falsely decoded as
+// windows-1252 and then encoded again, such as "FrÃ¼hling". Additionally,
+// the text is normalized to NFC, so that visually equal titles compare equal.
+func normalizeText(text string) string {
+	if strings.ContainsAny(text, "ÃÂ") {
+		if repaired, err := charmap.Windows1252.NewEncoder().String(text); err == nil && utf8.ValidString(repaired) {
+			text = repaired
+		}
+	}
+	return norm.NFC.String(text)
+}
+
+// newDocument parses an HTML page, converting it to UTF-8 beforehand. This
+// is required, as pages cached by older versions haven't been normalized.
+func newDocument(reader io.Reader) (*goquery.Document, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return goquery.NewDocumentFromReader(bytes.NewReader(NormalizeEncoding(data, "")))
+}
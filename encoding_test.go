@@ -0,0 +1,55 @@
+package proxerscrape
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeEncoding(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		contentType string
+		expected    string
+	}{
+		{"utf8", "Frühling", "", "Frühling"},
+		{"latin1 header", "Fr\xfchling", "text/html; charset=iso-8859-1", "Frühling"},
+		{"latin1 meta", `<meta charset="iso-8859-1">Fr` + "\xfc" + `hling`, "", `<meta charset="iso-8859-1">Frühling`},
+		{"mislabeled utf8", "Gr\xfc\xdfe", "text/html; charset=utf-8", "Grüße"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := string(NormalizeEncoding([]byte(test.data), test.contentType)); result != test.expected {
+				t.Errorf("Result = %q, instead of %q", result, test.expected)
+			}
+		})
+	}
+}
+
+func Test_normalizeText(t *testing.T) {
+	tests := map[string]string{
+		"FrÃ¼hling":          "Frühling",
+		"MÃ¤dchen":           "Mädchen",
+		"GrÃ¼ÃŸe":            "Grüße",
+		"Fru\u0308hling":     "Frühling",
+		"Shingeki no Kyojin": "Shingeki no Kyojin",
+		// Legitimate uppercase letters mustn't be mangled.
+		"SÃO PAULO": "SÃO PAULO",
+	}
+	for input, expected := range tests {
+		if result := normalizeText(input); result != expected {
+			t.Errorf("normalizeText(%q) = %q, instead of %q", input, result, expected)
+		}
+	}
+}
+
+func TestParseRelations_MisEncoded(t *testing.T) {
+	page := "<table id=\"relations\"><tr><td><a href=\"/info/1\">Fr\xfchlingsgef\xfchle</a></td><td>Animeserie</td><td></td></tr></table>"
+	relations, err := ParseRelations(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(relations) != 1 || relations[0].Title != "Frühlingsgefühle" {
+		t.Errorf("Unexpected relations: %+v", relations)
+	}
+}
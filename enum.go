@@ -0,0 +1,258 @@
+package proxerscrape
+
+import "strings"
+
+// MediaType is the normalized type of a media entry. Use ParseMediaType to
+// convert the German strings displayed by proxer.me.
+type MediaType string
+
+const (
+	MediaTypeUnknown MediaType = ""
+
+	MediaTypeSeries  MediaType = "series"
+	MediaTypeSpecial MediaType = "special"
+	MediaTypeMovie   MediaType = "movie"
+	MediaTypeOVA     MediaType = "ova"
+
+	MediaTypeManga     MediaType = "manga"
+	MediaTypeOneShot   MediaType = "oneshot"
+	MediaTypeWebtoon   MediaType = "webtoon"
+	MediaTypeManhwa    MediaType = "manhwa"
+	MediaTypeManhua    MediaType = "manhua"
+	MediaTypeDoujinshi MediaType = "doujinshi"
+
+	MediaTypeLightNovel MediaType = "lightnovel"
+	MediaTypeWebNovel   MediaType = "webnovel"
+)
+
+// Names and values of the media types before they were normalized. Decoding
+// still accepts the old values, see MediaType.UnmarshalText.
+const (
+	// Deprecated: Use MediaTypeSeries.
+	Series = MediaTypeSeries
+	// Deprecated: Use MediaTypeSpecial.
+	Special = MediaTypeSpecial
+	// Deprecated: Use MediaTypeMovie.
+	Movie = MediaTypeMovie
+	// Deprecated: Use MediaTypeManga.
+	Manga = MediaTypeManga
+	// Deprecated: Use MediaTypeWebtoon.
+	Webtoon = MediaTypeWebtoon
+	// Deprecated: Use MediaTypeManhwa.
+	Manhwa = MediaTypeManhwa
+	// Deprecated: Use MediaTypeDoujinshi.
+	Doujinshi = MediaTypeDoujinshi
+)
+
+// UnmarshalText accepts both the normalized types and the ones displayed by
+// proxer.me, which were stored before the types were normalized.
+func (mediaType *MediaType) UnmarshalText(text []byte) error {
+	*mediaType = MediaType(text)
+	if parsed := ParseMediaType(string(text)); parsed != MediaTypeUnknown {
+		*mediaType = parsed
+	}
+	return nil
+}
+
+// ParseMediaType converts the type as displayed by proxer.me into a
+// MediaType. Unknown types result in MediaTypeUnknown.
+func ParseMediaType(raw string) MediaType {
+	switch strings.TrimSpace(raw) {
+	case "Animeserie":
+		return MediaTypeSeries
+	case "Special":
+		return MediaTypeSpecial
+	case "Movie":
+		return MediaTypeMovie
+	case "OVA":
+		return MediaTypeOVA
+	case "Mangaserie":
+		return MediaTypeManga
+	case "One-Shot":
+		return MediaTypeOneShot
+	case "Webtoon":
+		return MediaTypeWebtoon
+	case "Manhwa":
+		return MediaTypeManhwa
+	case "Manhua":
+		return MediaTypeManhua
+	case "Doujinshi":
+		return MediaTypeDoujinshi
+	case "Light-Novel", "Light Novel":
+		return MediaTypeLightNovel
+	case "Web-Novel", "Web Novel":
+		return MediaTypeWebNovel
+	}
+	return MediaTypeUnknown
+}
+
+// IsAnime tells whether the type is one of the anime types, as opposed to
+// manga or anything else.
+func (mediaType MediaType) IsAnime() bool {
+	switch mediaType {
+	case MediaTypeSeries, MediaTypeSpecial, MediaTypeMovie, MediaTypeOVA:
+		return true
+	}
+	return false
+}
+
+// IsNovel tells whether the type is one of the novel types listed in the
+// novel tab of a profile.
+func (mediaType MediaType) IsNovel() bool {
+	return mediaType == MediaTypeLightNovel || mediaType == MediaTypeWebNovel
+}
+
+// IsManga tells whether the type is one of the manga types, such as webtoons
+// or one-shots.
+func (mediaType MediaType) IsManga() bool {
+	switch mediaType {
+	case MediaTypeManga, MediaTypeOneShot, MediaTypeWebtoon, MediaTypeManhwa, MediaTypeManhua, MediaTypeDoujinshi:
+		return true
+	}
+	return false
+}
+
+// Status is the normalized release status of a media entry. Use ParseStatus
+// to convert the German strings displayed by proxer.me.
+type Status string
+
+const (
+	StatusUnknown Status = ""
+	// StatusFinished means all episodes of have been released. This doesn't
+	// imply that all seasons have the same status.
+	StatusFinished Status = "finished"
+	// StatusPreAiring means yet to be released.
+	StatusPreAiring Status = "pre-airing"
+	// StatusAiring means the series has been released, but not all episodes
+	// have been released yet.
+	StatusAiring Status = "airing"
+	// StatusCancelled means the series won't be continued.
+	StatusCancelled Status = "cancelled"
+)
+
+// Names of the states before they were normalized. Decoding still accepts
+// the old values, see Status.UnmarshalText.
+const (
+	// Deprecated: Use StatusFinished.
+	Finished = StatusFinished
+	// Deprecated: Use StatusPreAiring.
+	PreAiring = StatusPreAiring
+	// Deprecated: Use StatusAiring.
+	Airing = StatusAiring
+)
+
+// UnmarshalText accepts both the normalized states and the ones displayed by
+// proxer.me, which were stored before the states were normalized.
+func (status *Status) UnmarshalText(text []byte) error {
+	*status = Status(text)
+	if parsed := ParseStatus(string(text)); parsed != StatusUnknown {
+		*status = parsed
+	}
+	return nil
+}
+
+// ParseStatus converts the status as displayed by proxer.me into a Status.
+// Unknown states result in StatusUnknown.
+func ParseStatus(raw string) Status {
+	switch strings.TrimSpace(raw) {
+	case "Abgeschlossen":
+		return StatusFinished
+	case "Nicht erschienen (Pre-Airing)":
+		return StatusPreAiring
+	case "Airing":
+		return StatusAiring
+	case "Abgebrochen":
+		return StatusCancelled
+	}
+	return StatusUnknown
+}
+
+// Season represents the four seasons of the year. Proxer.me represents these
+// as integers internally.
+type Season string
+
+const (
+	// Winter
+	Q1 Season = "Q1"
+	// Spring
+	Q2 Season = "Q2"
+	// Summer
+	Q3 Season = "Q3"
+	// Autumn
+	Q4 Season = "Q4"
+)
+
+// ParseSeasonName converts the German name of a season, such as "Frühling",
+// into a Season. The second return value is false if the name is unknown.
+func ParseSeasonName(name string) (Season, bool) {
+	switch strings.TrimSpace(name) {
+	case "Winter":
+		return Q1, true
+	case "Frühling":
+		return Q2, true
+	case "Sommer":
+		return Q3, true
+	case "Herbst":
+		return Q4, true
+	}
+	return "", false
+}
+
+// Name returns the English name of the season.
+func (season Season) Name() string {
+	switch season {
+	case Q1:
+		return "Winter"
+	case Q2:
+		return "Spring"
+	case Q3:
+		return "Summer"
+	case Q4:
+		return "Autumn"
+	}
+	return ""
+}
+
+// RelationKind is the normalized kind of relation between two media entries.
+// Use ParseRelationKind to convert the German strings displayed by proxer.me.
+type RelationKind string
+
+const (
+	RelationUnknown     RelationKind = ""
+	RelationSequel      RelationKind = "sequel"
+	RelationPrequel     RelationKind = "prequel"
+	RelationSideStory   RelationKind = "side-story"
+	RelationParentStory RelationKind = "parent-story"
+	RelationSpinOff     RelationKind = "spin-off"
+	RelationSummary     RelationKind = "summary"
+	RelationAlternative RelationKind = "alternative"
+	RelationAdaptation  RelationKind = "adaptation"
+	RelationOther       RelationKind = "other"
+)
+
+// ParseRelationKind converts the kind of relation as displayed by proxer.me
+// into a RelationKind. Unknown kinds result in RelationOther, missing ones in
+// RelationUnknown.
+func ParseRelationKind(raw string) RelationKind {
+	switch strings.TrimSpace(raw) {
+	case "":
+		return RelationUnknown
+	case "Fortsetzung":
+		return RelationSequel
+	case "Vorgeschichte":
+		return RelationPrequel
+	case "Nebengeschichte":
+		return RelationSideStory
+	case "Hauptgeschichte":
+		return RelationParentStory
+	case "Spin-off", "Ableger":
+		return RelationSpinOff
+	case "Zusammenfassung":
+		return RelationSummary
+	case "Alternative Version", "Alternative Fassung":
+		return RelationAlternative
+	case "Adaption", "Umsetzung":
+		return RelationAdaptation
+	}
+	return RelationOther
+}
@@ -0,0 +1,50 @@
+package proxerscrape
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseStatus(t *testing.T) {
+	for raw, expected := range map[string]Status{
+		"Abgeschlossen":                 StatusFinished,
+		"Nicht erschienen (Pre-Airing)": StatusPreAiring,
+		"Airing":                        StatusAiring,
+		"Unbekannt":                     StatusUnknown,
+	} {
+		if result := ParseStatus(raw); result != expected {
+			t.Errorf("ParseStatus(%s) = %s, instead of %s", raw, result, expected)
+		}
+	}
+}
+
+func TestParseMediaType(t *testing.T) {
+	for raw, expected := range map[string]MediaType{
+		"Animeserie":  MediaTypeSeries,
+		"Movie":       MediaTypeMovie,
+		"Mangaserie":  MediaTypeManga,
+		"Manhwa":      MediaTypeManhwa,
+		"Light-Novel": MediaTypeLightNovel,
+		"Web Novel":   MediaTypeWebNovel,
+	} {
+		if result := ParseMediaType(raw); result != expected {
+			t.Errorf("ParseMediaType(%s) = %s, instead of %s", raw, result, expected)
+		}
+	}
+}
+
+func TestUnmarshalLegacyValues(t *testing.T) {
+	var media Media
+	if err := json.Unmarshal([]byte(`{"Type":"Animeserie","Status":"Nicht erschienen (Pre-Airing)"}`), &media); err != nil {
+		t.Fatal(err)
+	}
+	if media.Type != Series || media.Status != PreAiring {
+		t.Errorf("Unexpected legacy type %s or status %s", media.Type, media.Status)
+	}
+	if err := json.Unmarshal([]byte(`{"Type":"movie","Status":"airing"}`), &media); err != nil {
+		t.Fatal(err)
+	}
+	if media.Type != MediaTypeMovie || media.Status != StatusAiring {
+		t.Errorf("Unexpected type %s or status %s", media.Type, media.Status)
+	}
+}
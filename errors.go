@@ -0,0 +1,55 @@
+package proxerscrape
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrRateLimited is returned if proxer.me keeps responding with a
+	// captcha, even after cooling down multiple times.
+	ErrRateLimited = errors.New("proxer.me ratelimit has been hit, captcha required")
+	// ErrLoginRequired is returned for entries that are only visible when
+	// logged in, which is usually the case for 18+ entries. See
+	// WithLoginCookie and WithCookies.
+	ErrLoginRequired = errors.New("proxer.me requires a login for this entry")
+	// ErrDeadLink is returned for entries that don't exist anymore, even
+	// though they are still present in a watchlist.
+	ErrDeadLink = errors.New("entry doesn't exist anymore")
+	// ErrLayoutChanged is returned, wrapped in a ParseError naming the
+	// missing selector, if a page lacks elements that every page of its kind
+	// has. This usually means that proxer.me has been redesigned and the
+	// parsers have to be adjusted.
+	ErrLayoutChanged = errors.New("proxer.me layout has changed")
+	// ErrNotCached is returned in offline mode for pages that would have to
+	// be retrieved from proxer.me, see WithOffline.
+	ErrNotCached = errors.New("page isn't cached and offline mode is enabled")
+	// ErrRequestLimit is returned if more live requests than allowed via
+	// Cache.MaxRequests would be issued and there's no way to confirm them.
+	ErrRequestLimit = errors.New("too many requests to proxer.me")
+	// ErrMissingID is returned when retrieving pages of an entry whose ID is
+	// unknown, for example because its row lacked a link.
+	ErrMissingID = errors.New("entry doesn't have an ID")
+)
+
+// ParseError is returned if a page doesn't have the expected structure.
+type ParseError struct {
+	// Selector is the part of the page that couldn't be found or parsed.
+	Selector string
+	// URL is the page that has been parsed, if known.
+	URL string
+	// Err is the underlying error, if any.
+	Err error
+}
+
+func (err *ParseError) Error() string {
+	message := fmt.Sprintf("error parsing '%s' of '%s'", err.Selector, redactURL(err.URL))
+	if err.Err != nil {
+		message += ": " + err.Err.Error()
+	}
+	return message
+}
+
+func (err *ParseError) Unwrap() error {
+	return err.Err
+}
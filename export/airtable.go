@@ -0,0 +1,122 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Bios-Marcel/proxerscrape"
+)
+
+// AirtableBaseURL is the location of the Airtable API.
+const AirtableBaseURL = "https://api.airtable.com"
+
+// airtableBatchSize is the maximum amount of records per request.
+const airtableBatchSize = 10
+
+// Airtable writes watchlists into a table of an Airtable base. The token has
+// to be a personal access token with the data.records:write scope, see
+// https://airtable.com/create/tokens.
+type Airtable struct {
+	Token  string
+	BaseID string
+	// Table is the name or ID of the table.
+	Table      string
+	HTTPClient *http.Client
+	// BaseURL defaults to AirtableBaseURL.
+	BaseURL string
+	// Limiter keeps us below the ratelimit of Airtable. If nil, requests
+	// aren't limited.
+	Limiter *proxerscrape.Limiter
+}
+
+// NewAirtable creates a client staying below the documented limit of five
+// requests per second.
+func NewAirtable(token, baseID, table string) *Airtable {
+	return &Airtable{
+		Token:   token,
+		BaseID:  baseID,
+		Table:   table,
+		Limiter: proxerscrape.NewLimiter(5, time.Second),
+	}
+}
+
+func (airtable *Airtable) upsert(keyColumn string, records []map[string]any) error {
+	body := map[string]any{
+		"performUpsert": map[string]any{"fieldsToMergeOn": []string{keyColumn}},
+		"records":       records,
+		// Allows writing into select fields without creating every option
+		// beforehand.
+		"typecast": true,
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	baseURL := airtable.BaseURL
+	if baseURL == "" {
+		baseURL = AirtableBaseURL
+	}
+	path := "/v0/" + url.PathEscape(airtable.BaseID) + "/" + url.PathEscape(airtable.Table)
+	request, err := http.NewRequest(http.MethodPatch, baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", "Bearer "+airtable.Token)
+	request.Header.Set("Content-Type", "application/json")
+
+	if airtable.Limiter != nil {
+		airtable.Limiter.Wait()
+	}
+	httpClient := airtable.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		var airtableError struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		json.NewDecoder(response.Body).Decode(&airtableError)
+		return fmt.Errorf("airtable request failed with status %d: %s", response.StatusCode, airtableError.Error.Message)
+	}
+	return nil
+}
+
+// Export writes all entries of the watchlist into the table. Entries that
+// already have a record, identified by their URL or title, are updated
+// instead of being added again. The genres have to be written into a
+// multiple select field, all other fields fit text or number fields. Entries
+// that aren't exportable, see proxerscrape.Media.Exportable, are skipped and
+// returned.
+func (airtable *Airtable) Export(watchlist *proxerscrape.Watchlist, columns Columns) ([]*proxerscrape.Media, error) {
+	_, keyColumn, err := columns.key()
+	if err != nil {
+		return nil, err
+	}
+
+	items, skipped := exportable(watchlist)
+	for start := 0; start < len(items); start += airtableBatchSize {
+		end := start + airtableBatchSize
+		if end > len(items) {
+			end = len(items)
+		}
+		records := make([]map[string]any, 0, end-start)
+		for _, item := range items[start:end] {
+			records = append(records, map[string]any{"fields": columns.row(item)})
+		}
+		if err := airtable.upsert(keyColumn, records); err != nil {
+			return skipped, err
+		}
+	}
+	return skipped, nil
+}
@@ -0,0 +1,63 @@
+package export
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/Bios-Marcel/proxerscrape"
+)
+
+func TestAirtableExport(t *testing.T) {
+	type upsert struct {
+		PerformUpsert struct {
+			FieldsToMergeOn []string `json:"fieldsToMergeOn"`
+		} `json:"performUpsert"`
+		Records []struct {
+			Fields map[string]any `json:"fields"`
+		} `json:"records"`
+	}
+	var requests []upsert
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPatch || request.URL.Path != "/v0/base/My Anime" {
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var body upsert
+		json.NewDecoder(request.Body).Decode(&body)
+		requests = append(requests, body)
+		writer.Write([]byte(`{"records":[]}`))
+	}))
+	defer server.Close()
+
+	watchlist := proxerscrape.Watchlist{}
+	for index := 0; index < 12; index++ {
+		watchlist.Watched.Data = append(watchlist.Watched.Data, &proxerscrape.Media{
+			ProxerURL: "/info/" + strconv.Itoa(index),
+			Title:     "Entry " + strconv.Itoa(index),
+		})
+	}
+
+	watchlist.Watched.Data = append(watchlist.Watched.Data, &proxerscrape.Media{ProxerURL: "/info/99", Title: "Gone", Unavailable: true})
+
+	airtable := &Airtable{Token: "secret", BaseID: "base", Table: "My Anime", BaseURL: server.URL}
+	skipped, err := airtable.Export(&watchlist, Columns{FieldTitle: "Name"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skipped) != 1 || skipped[0].Title != "Gone" {
+		t.Errorf("Expected the dead link to be skipped, got %v", skipped)
+	}
+	// Twelve records have to be split into batches of ten.
+	if len(requests) != 2 || len(requests[0].Records) != 10 || len(requests[1].Records) != 2 {
+		t.Fatalf("Unexpected requests: %+v", requests)
+	}
+	if merge := requests[0].PerformUpsert.FieldsToMergeOn; len(merge) != 1 || merge[0] != "Name" {
+		t.Errorf("Unexpected merge fields: %v", merge)
+	}
+	if requests[1].Records[1].Fields["Name"] != "Entry 11" {
+		t.Errorf("Unexpected record: %v", requests[1].Records[1])
+	}
+}
@@ -0,0 +1,168 @@
+package export
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Bios-Marcel/proxerscrape"
+)
+
+// Field is a property of a watchlist entry that can be exported into a
+// column of a database, such as Notion or Airtable.
+type Field string
+
+const (
+	FieldTitle           Field = "title"
+	FieldEnglishTitle    Field = "english_title"
+	FieldGermanTitle     Field = "german_title"
+	FieldJapaneseTitle   Field = "japanese_title"
+	FieldType            Field = "type"
+	FieldCategory        Field = "category"
+	FieldStatus          Field = "status"
+	FieldEpisodes        Field = "episodes"
+	FieldEpisodesWatched Field = "episodes_watched"
+	FieldUserRating      Field = "user_rating"
+	FieldRating          Field = "rating"
+	FieldGenres          Field = "genres"
+	FieldYear            Field = "year"
+	FieldURL             Field = "url"
+	FieldReview          Field = "review"
+)
+
+// fieldValues extract the value of a field. Values are strings, float64 or
+// []string, nil meaning the value is unknown.
+var fieldValues = map[Field]func(item *proxerscrape.Media) any{
+	FieldTitle:         func(item *proxerscrape.Media) any { return item.Title },
+	FieldEnglishTitle:  func(item *proxerscrape.Media) any { return optionalString(item.EnglishTitle) },
+	FieldGermanTitle:   func(item *proxerscrape.Media) any { return optionalString(item.GermanTitle) },
+	FieldJapaneseTitle: func(item *proxerscrape.Media) any { return optionalString(item.JapaneseTitle) },
+	FieldType:          func(item *proxerscrape.Media) any { return optionalString(string(item.Type)) },
+	FieldCategory:      func(item *proxerscrape.Media) any { return optionalString(string(item.Category)) },
+	FieldStatus:        func(item *proxerscrape.Media) any { return optionalString(string(item.Status)) },
+	FieldEpisodes:      func(item *proxerscrape.Media) any { return optionalNumber(float64(item.EpisodeCount)) },
+	FieldEpisodesWatched: func(item *proxerscrape.Media) any {
+		return float64(item.EpisodesWatched)
+	},
+	FieldUserRating: func(item *proxerscrape.Media) any { return optionalNumber(float64(item.UserRating)) },
+	FieldRating:     func(item *proxerscrape.Media) any { return optionalNumber(item.Rating) },
+	FieldGenres: func(item *proxerscrape.Media) any {
+		if len(item.Generes) == 0 {
+			return nil
+		}
+		return item.Generes
+	},
+	FieldYear:   func(item *proxerscrape.Media) any { return optionalNumber(float64(item.ReleasePeriod.FromYear)) },
+	FieldURL:    func(item *proxerscrape.Media) any { return "https://proxer.me" + item.ProxerURL },
+	FieldReview: func(item *proxerscrape.Media) any { return optionalString(item.Review) },
+}
+
+// extraDataFields are only known once the extra data has been loaded.
+var extraDataFields = map[Field]bool{
+	FieldEnglishTitle:  true,
+	FieldGermanTitle:   true,
+	FieldJapaneseTitle: true,
+	FieldRating:        true,
+	FieldGenres:        true,
+	FieldYear:          true,
+}
+
+func optionalString(value string) any {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+func optionalNumber(value float64) any {
+	if value == 0 {
+		return nil
+	}
+	return value
+}
+
+// Fields returns all fields that can be exported, sorted by name.
+func Fields() []Field {
+	fields := make([]Field, 0, len(fieldValues))
+	for field := range fieldValues {
+		fields = append(fields, field)
+	}
+	sort.Slice(fields, func(a, b int) bool { return fields[a] < fields[b] })
+	return fields
+}
+
+// Columns maps fields to the names of the columns they are written to.
+// Fields without a column aren't exported.
+type Columns map[Field]string
+
+// DefaultColumns are used if the user doesn't configure any columns.
+var DefaultColumns = Columns{
+	FieldTitle:           "Title",
+	FieldType:            "Type",
+	FieldCategory:        "Category",
+	FieldStatus:          "Status",
+	FieldEpisodes:        "Episodes",
+	FieldEpisodesWatched: "Episodes Watched",
+	FieldUserRating:      "My Rating",
+	FieldRating:          "Rating",
+	FieldGenres:          "Genres",
+	FieldYear:            "Year",
+	FieldURL:             "URL",
+}
+
+// ParseColumns parses column mappings of the form field=column, such as
+// "user_rating=Score".
+func ParseColumns(mappings []string) (Columns, error) {
+	columns := make(Columns, len(mappings))
+	for _, mapping := range mappings {
+		field, column, found := strings.Cut(mapping, "=")
+		if !found || column == "" {
+			return nil, fmt.Errorf("invalid column '%s', expected field=column", mapping)
+		}
+		if _, known := fieldValues[Field(field)]; !known {
+			return nil, fmt.Errorf("unknown field '%s'", field)
+		}
+		columns[Field(field)] = column
+	}
+	return columns, nil
+}
+
+// key returns the column identifying the row of an entry, which is the URL
+// if exported, since titles aren't necessarily unique.
+func (columns Columns) key() (Field, string, error) {
+	if column, present := columns[FieldURL]; present {
+		return FieldURL, column, nil
+	}
+	if column, present := columns[FieldTitle]; present {
+		return FieldTitle, column, nil
+	}
+	return "", "", fmt.Errorf("either field '%s' or '%s' has to be exported for identifying rows", FieldURL, FieldTitle)
+}
+
+// row returns the values of all exported fields of the entry, keyed by
+// column. Unknown values are nil, so that updating a row clears them. The
+// extra data of entries with restricted access can't be known, so it's
+// omitted instead, leaving the values of existing rows untouched.
+func (columns Columns) row(item *proxerscrape.Media) map[string]any {
+	row := make(map[string]any, len(columns))
+	for field, column := range columns {
+		if item.RestrictedAccess && extraDataFields[field] {
+			continue
+		}
+		row[column] = fieldValues[field](item)
+	}
+	return row
+}
+
+// exportable splits the entries of the watchlist into those that are
+// exported and those that are skipped, see proxerscrape.Media.Exportable.
+func exportable(watchlist *proxerscrape.Watchlist) (exported, skipped []*proxerscrape.Media) {
+	for _, item := range watchlist.All() {
+		if item.Exportable() {
+			exported = append(exported, item)
+		} else {
+			skipped = append(skipped, item)
+		}
+	}
+	return exported, skipped
+}
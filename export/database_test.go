@@ -0,0 +1,46 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/Bios-Marcel/proxerscrape"
+)
+
+func TestParseColumns(t *testing.T) {
+	columns, err := ParseColumns([]string{"title=Name", "user_rating=My Score"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(columns) != 2 || columns[FieldTitle] != "Name" || columns[FieldUserRating] != "My Score" {
+		t.Errorf("Unexpected columns: %v", columns)
+	}
+	for _, invalid := range []string{"title", "title=", "cover=Image"} {
+		if _, err := ParseColumns([]string{invalid}); err == nil {
+			t.Errorf("Expected an error for '%s'", invalid)
+		}
+	}
+}
+
+func TestColumnsRow(t *testing.T) {
+	item := &proxerscrape.Media{ProxerURL: "/info/1", Title: "Unrated", EpisodesWatched: 3, Generes: []string{"Action"}}
+	row := Columns{FieldTitle: "Name", FieldUserRating: "Score", FieldGenres: "Genres", FieldEpisodesWatched: "Progress"}.row(item)
+	if row["Name"] != "Unrated" || row["Score"] != nil || row["Progress"] != float64(3) {
+		t.Errorf("Unexpected row: %v", row)
+	}
+	if genres, _ := row["Genres"].([]string); len(genres) != 1 {
+		t.Errorf("Unexpected genres: %v", row["Genres"])
+	}
+	if _, present := row["Score"]; !present {
+		t.Error("Unknown values have to be present for clearing them")
+	}
+
+	item.RestrictedAccess = true
+	row = Columns{FieldTitle: "Name", FieldGenres: "Genres", FieldRating: "Rating"}.row(item)
+	if _, present := row["Rating"]; present || row["Name"] != "Unrated" {
+		t.Errorf("Expected the extra data of restricted entries to be left untouched: %v", row)
+	}
+
+	if _, _, err := (Columns{FieldGenres: "Genres"}).key(); err == nil {
+		t.Error("Expected an error for columns without a key")
+	}
+}
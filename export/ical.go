@@ -0,0 +1,69 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Bios-Marcel/proxerscrape"
+)
+
+const icsTimeFormat = "20060102T150405Z"
+
+// icsEscaper escapes text values as required by RFC 5545.
+var icsEscaper = strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, "\n", `\n`)
+
+// WriteICS writes the episodes as an iCalendar, which calendar applications
+// can subscribe to. Each episode is an event lasting its estimated duration,
+// see proxerscrape.UpcomingEpisodes. Episodes without one are assumed to be
+// of an average series.
+func WriteICS(writer io.Writer, episodes []proxerscrape.AiringEpisode, now time.Time) error {
+	buffered := bufio.NewWriter(writer)
+	writeLine := func(line string) {
+		// Lines are limited to 75 octets, continuation lines start with a
+		// space. We don't split inside of multi byte runes.
+		for len(line) > 75 {
+			cut := 75
+			for cut > 0 && !isRuneStart(line[cut]) {
+				cut--
+			}
+			buffered.WriteString(line[:cut] + "\r\n")
+			line = " " + line[cut:]
+		}
+		buffered.WriteString(line + "\r\n")
+	}
+
+	writeLine("BEGIN:VCALENDAR")
+	writeLine("VERSION:2.0")
+	writeLine("PRODID:-//proxerscrape//airing calendar//EN")
+	writeLine("X-WR-CALNAME:Proxer airing calendar")
+	for _, episode := range episodes {
+		summary := episode.Title
+		uid := episode.ProxerID() + "-" + episode.AiresAt.UTC().Format(icsTimeFormat)
+		if episode.Episode > 0 {
+			summary = fmt.Sprintf("%s - Episode %d", episode.Title, episode.Episode)
+			uid = fmt.Sprintf("%s-%d", episode.ProxerID(), episode.Episode)
+		}
+		duration := episode.Duration
+		if duration == 0 {
+			duration = proxerscrape.AverageEpisodeDurations[proxerscrape.MediaTypeSeries]
+		}
+		writeLine("BEGIN:VEVENT")
+		// Stable UIDs allow calendar applications to update moved episodes.
+		writeLine("UID:" + uid + "@proxerscrape")
+		writeLine("DTSTAMP:" + now.UTC().Format(icsTimeFormat))
+		writeLine("DTSTART:" + episode.AiresAt.UTC().Format(icsTimeFormat))
+		writeLine("DTEND:" + episode.AiresAt.Add(duration).UTC().Format(icsTimeFormat))
+		writeLine("SUMMARY:" + icsEscaper.Replace(summary))
+		writeLine("URL:https://proxer.me" + episode.ProxerURL)
+		writeLine("END:VEVENT")
+	}
+	writeLine("END:VCALENDAR")
+	return buffered.Flush()
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -0,0 +1,43 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Bios-Marcel/proxerscrape"
+)
+
+func TestWriteICS(t *testing.T) {
+	airesAt := time.Date(2026, 10, 16, 16, 0, 0, 0, time.UTC)
+	episodes := []proxerscrape.AiringEpisode{
+		{ProxerURL: "/info/1", Title: "Tsurune, Season 2", Episode: 3, AiresAt: airesAt},
+		{ProxerURL: "/info/2", Title: strings.Repeat("Ä", 50), AiresAt: airesAt, Duration: 90 * time.Minute},
+	}
+
+	var buffer bytes.Buffer
+	if err := WriteICS(&buffer, episodes, airesAt); err != nil {
+		t.Fatal(err)
+	}
+	ics := buffer.String()
+	for _, expected := range []string{
+		"UID:1-3@proxerscrape\r\n",
+		"DTSTART:20261016T160000Z\r\n",
+		"DTEND:20261016T162400Z\r\n",
+		"DTEND:20261016T173000Z\r\n",
+		"SUMMARY:Tsurune\\, Season 2 - Episode 3\r\n",
+	} {
+		if !strings.Contains(ics, expected) {
+			t.Errorf("Missing %q in:\n%s", expected, ics)
+		}
+	}
+	for _, line := range strings.Split(ics, "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("Line wasn't folded: %s", line)
+		}
+	}
+	if !strings.Contains(strings.ReplaceAll(ics, "\r\n ", ""), "SUMMARY:"+strings.Repeat("Ä", 50)) {
+		t.Error("Folded line can't be unfolded")
+	}
+}
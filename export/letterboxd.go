@@ -0,0 +1,55 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/Bios-Marcel/proxerscrape"
+)
+
+// letterboxdTitlePolicy prefers the English title, since Letterboxd matches
+// films against TMDb, which mostly lists anime films by those.
+var letterboxdTitlePolicy = proxerscrape.TitlePolicy{proxerscrape.TitleEnglish}
+
+// WriteLetterboxd writes all watched anime movies of the watchlist as CSV
+// that can be imported at https://letterboxd.com/import/. The columns are
+// Title, Year, Rating10 and WatchedDate, where unknown values are left empty.
+// The year and English titles are only known after loading the extra data.
+// proxer.me doesn't track when entries were finished, so the watched date is
+// taken from watchedAt, keyed by proxer ID, see proxerscrape.LastWatched.
+// Movies that aren't exportable, see proxerscrape.Media.Exportable, are
+// skipped and returned.
+func WriteLetterboxd(writer io.Writer, watchlist *proxerscrape.Watchlist, watchedAt map[string]time.Time) ([]*proxerscrape.Media, error) {
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write([]string{"Title", "Year", "Rating10", "WatchedDate"}); err != nil {
+		return nil, err
+	}
+
+	var skipped []*proxerscrape.Media
+	for _, item := range watchlist.All() {
+		if item.Type != proxerscrape.MediaTypeMovie || item.Category != proxerscrape.CategoryWatched {
+			continue
+		}
+		if !item.Exportable() {
+			skipped = append(skipped, item)
+			continue
+		}
+		var year, rating, watchedDate string
+		if item.ReleasePeriod.FromYear > 0 {
+			year = strconv.Itoa(int(item.ReleasePeriod.FromYear))
+		}
+		if item.UserRating > 0 {
+			rating = strconv.Itoa(int(item.UserRating))
+		}
+		if watched, known := watchedAt[item.ProxerID()]; known {
+			watchedDate = watched.Format("2006-01-02")
+		}
+		if err := csvWriter.Write([]string{letterboxdTitlePolicy.Select(item), year, rating, watchedDate}); err != nil {
+			return skipped, err
+		}
+	}
+	csvWriter.Flush()
+	return skipped, csvWriter.Error()
+}
@@ -0,0 +1,36 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/Bios-Marcel/proxerscrape"
+)
+
+func TestWriteLetterboxd(t *testing.T) {
+	watchlist := proxerscrape.Watchlist{}
+	watchlist.Watched.Data = []*proxerscrape.Media{
+		{ProxerURL: "/info/1", Title: "Kimi no Na wa.", EnglishTitle: "Your Name.", Type: proxerscrape.MediaTypeMovie, Category: proxerscrape.CategoryWatched, UserRating: 9, ReleasePeriod: proxerscrape.ReleasePeriod{FromYear: 2016}},
+		{ProxerURL: "/info/2", Title: "Koe no Katachi", Type: proxerscrape.MediaTypeMovie, Category: proxerscrape.CategoryWatched},
+		{ProxerURL: "/info/3", Title: "Series", Type: proxerscrape.MediaTypeSeries, Category: proxerscrape.CategoryWatched},
+		{ProxerURL: "/info/5", Title: "Gone", Type: proxerscrape.MediaTypeMovie, Category: proxerscrape.CategoryWatched, Unavailable: true},
+	}
+	watchlist.ToWatch.Data = []*proxerscrape.Media{
+		{ProxerURL: "/info/4", Title: "Planned", Type: proxerscrape.MediaTypeMovie, Category: proxerscrape.CategoryToWatch},
+	}
+	watchedAt := map[string]time.Time{"1": time.Date(2021, 3, 4, 22, 15, 0, 0, time.UTC)}
+
+	var buffer bytes.Buffer
+	skipped, err := WriteLetterboxd(&buffer, &watchlist, watchedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skipped) != 1 || skipped[0].Title != "Gone" {
+		t.Errorf("Expected the dead link to be skipped, got %v", skipped)
+	}
+	expected := "Title,Year,Rating10,WatchedDate\nYour Name.,2016,9,2021-03-04\nKoe no Katachi,,,\n"
+	if buffer.String() != expected {
+		t.Errorf("Unexpected export:\n%s", buffer.String())
+	}
+}
@@ -0,0 +1,90 @@
+// Package export writes proxer watchlists in the formats of other services,
+// so that they can be imported there.
+package export
+
+import (
+	"encoding/xml"
+	"io"
+
+	"github.com/Bios-Marcel/proxerscrape"
+	"github.com/Bios-Marcel/proxerscrape/mapping"
+)
+
+// malStatus translates proxer categories into the MyAnimeList status.
+var malStatus = map[proxerscrape.ListCategory]string{
+	proxerscrape.CategoryWatched:           "Completed",
+	proxerscrape.CategoryCurrentlyWatching: "Watching",
+	proxerscrape.CategoryToWatch:           "Plan to Watch",
+	proxerscrape.CategoryStoppedWatching:   "Dropped",
+}
+
+type malAnime struct {
+	ID              string `xml:"series_animedb_id"`
+	Title           string `xml:"series_title"`
+	WatchedEpisodes uint16 `xml:"my_watched_episodes"`
+	Score           uint8  `xml:"my_score"`
+	Status          string `xml:"my_status"`
+	TimesWatched    uint16 `xml:"my_times_watched"`
+	Comments        string `xml:"my_comments,omitempty"`
+	// UpdateOnImport makes MyAnimeList overwrite existing entries.
+	UpdateOnImport uint8 `xml:"update_on_import"`
+}
+
+type malExport struct {
+	XMLName xml.Name `xml:"myanimelist"`
+	MyInfo  struct {
+		ExportType uint8 `xml:"user_export_type"`
+	} `xml:"myinfo"`
+	Anime []malAnime `xml:"anime"`
+}
+
+// WriteMAL writes all anime of the watchlist in the XML format of
+// MyAnimeList, which AniList can import as well. Entries without a mapping
+// to MyAnimeList can't be exported and are returned instead, just like those
+// that aren't exportable, see proxerscrape.Media.Exportable. Rewatches are
+// taken from the user data, which may be nil.
+func WriteMAL(writer io.Writer, watchlist *proxerscrape.Watchlist, store *mapping.Store, userData *proxerscrape.UserData) ([]*proxerscrape.Media, error) {
+	export := malExport{}
+	// 1 is the type of anime lists.
+	export.MyInfo.ExportType = 1
+
+	var skipped []*proxerscrape.Media
+	for _, item := range watchlist.All() {
+		if !item.Type.IsAnime() {
+			continue
+		}
+		if !item.Exportable() {
+			skipped = append(skipped, item)
+			continue
+		}
+		malMapping, present := store.Get(item.ProxerID(), mapping.ServiceMyAnimeList)
+		if !present {
+			skipped = append(skipped, item)
+			continue
+		}
+
+		anime := malAnime{
+			ID:              malMapping.ExternalID,
+			Title:           item.Title,
+			WatchedEpisodes: item.EpisodesWatched,
+			Score:           item.UserRating,
+			Status:          malStatus[item.Category],
+			Comments:        item.Review,
+			UpdateOnImport:  1,
+		}
+		if userData != nil {
+			anime.TimesWatched = userData.Rewatched(item)
+		}
+		export.Anime = append(export.Anime, anime)
+	}
+
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return skipped, err
+	}
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "\t")
+	if err := encoder.Encode(export); err != nil {
+		return skipped, err
+	}
+	return skipped, nil
+}
@@ -0,0 +1,48 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Bios-Marcel/proxerscrape"
+	"github.com/Bios-Marcel/proxerscrape/mapping"
+	"github.com/Bios-Marcel/proxerscrape/reconcile"
+)
+
+func TestWriteMAL(t *testing.T) {
+	watchlist := proxerscrape.Watchlist{}
+	watchlist.Watched.Data = []*proxerscrape.Media{
+		{ProxerURL: "/info/296", Title: "Tsurune", Type: proxerscrape.MediaTypeSeries, Category: proxerscrape.CategoryWatched, EpisodesWatched: 13, UserRating: 8, Review: "Great & calm"},
+		{ProxerURL: "/info/297", Title: "Unmapped", Type: proxerscrape.MediaTypeSeries, Category: proxerscrape.CategoryWatched},
+		{ProxerURL: "/info/298", Title: "Gone", Type: proxerscrape.MediaTypeSeries, Category: proxerscrape.CategoryWatched, Unavailable: true},
+	}
+	store := &mapping.Store{}
+	store.Set("296", mapping.ServiceMyAnimeList, mapping.Mapping{ExternalID: "37427"})
+	store.Set("298", mapping.ServiceMyAnimeList, mapping.Mapping{ExternalID: "1"})
+	userData := &proxerscrape.UserData{}
+	userData.SetRewatches(watchlist.Watched.Data[0], 2)
+
+	var buffer bytes.Buffer
+	skipped, err := WriteMAL(&buffer, &watchlist, store, userData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skipped) != 2 || skipped[0].Title != "Unmapped" || skipped[1].Title != "Gone" {
+		t.Errorf("Unexpected skipped entries: %v", skipped)
+	}
+	if !bytes.Contains(buffer.Bytes(), []byte("<my_times_watched>2</my_times_watched>")) {
+		t.Errorf("Rewatches are missing:\n%s", buffer.String())
+	}
+	if !bytes.Contains(buffer.Bytes(), []byte("<my_comments>Great &amp; calm</my_comments>")) {
+		t.Errorf("Review is missing:\n%s", buffer.String())
+	}
+
+	// The export has to be readable by our own import.
+	entries, err := reconcile.ParseMALExport(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].ExternalID != "37427" || entries[0].Category != proxerscrape.CategoryWatched || entries[0].Progress != 13 {
+		t.Errorf("Unexpected entries: %+v", entries)
+	}
+}
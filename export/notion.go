@@ -0,0 +1,256 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Bios-Marcel/proxerscrape"
+)
+
+// NotionBaseURL is the location of the Notion API.
+const NotionBaseURL = "https://api.notion.com"
+
+// Notion writes watchlists into a Notion database. The database has to be
+// shared with the integration the token belongs to, see
+// https://www.notion.so/my-integrations.
+type Notion struct {
+	Token      string
+	DatabaseID string
+	HTTPClient *http.Client
+	// BaseURL defaults to NotionBaseURL.
+	BaseURL string
+	// Limiter keeps us below the ratelimit of Notion. If nil, requests
+	// aren't limited.
+	Limiter *proxerscrape.Limiter
+}
+
+// NewNotion creates a client staying below the documented average of three
+// requests per second.
+func NewNotion(token, databaseID string) *Notion {
+	return &Notion{
+		Token:      token,
+		DatabaseID: databaseID,
+		Limiter:    proxerscrape.NewLimiter(3, time.Second),
+	}
+}
+
+func (notion *Notion) request(method, path string, body, result any) error {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	baseURL := notion.BaseURL
+	if baseURL == "" {
+		baseURL = NotionBaseURL
+	}
+	request, err := http.NewRequest(method, baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", "Bearer "+notion.Token)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Notion-Version", "2022-06-28")
+
+	if notion.Limiter != nil {
+		notion.Limiter.Wait()
+	}
+	httpClient := notion.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		var notionError struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(response.Body).Decode(&notionError)
+		return fmt.Errorf("notion request %s %s failed with status %d: %s", method, path, response.StatusCode, notionError.Message)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(response.Body).Decode(result)
+}
+
+// notionProperty is a property of a Notion page. Only the members matching
+// Type are set.
+type notionProperty struct {
+	Type     string       `json:"type"`
+	Title    []notionText `json:"title"`
+	RichText []notionText `json:"rich_text"`
+	URL      *string      `json:"url"`
+	Number   *float64     `json:"number"`
+	Select   *struct {
+		Name string `json:"name"`
+	} `json:"select"`
+}
+
+type notionText struct {
+	PlainText string `json:"plain_text"`
+}
+
+// text returns the value of the property as displayed, which is used for
+// identifying the rows of existing entries.
+func (property notionProperty) text() string {
+	var builder strings.Builder
+	switch property.Type {
+	case "title", "rich_text":
+		texts := property.Title
+		if property.Type == "rich_text" {
+			texts = property.RichText
+		}
+		for _, text := range texts {
+			builder.WriteString(text.PlainText)
+		}
+	case "url":
+		if property.URL != nil {
+			builder.WriteString(*property.URL)
+		}
+	case "number":
+		if property.Number != nil {
+			builder.WriteString(strconv.FormatFloat(*property.Number, 'f', -1, 64))
+		}
+	case "select":
+		if property.Select != nil {
+			builder.WriteString(property.Select.Name)
+		}
+	}
+	return builder.String()
+}
+
+// notionValue encodes the value of a field for a property of the given type.
+func notionValue(propertyType string, value any) (any, error) {
+	var text string
+	switch typed := value.(type) {
+	case string:
+		text = typed
+	case float64:
+		text = strconv.FormatFloat(typed, 'f', -1, 64)
+	case []string:
+		text = strings.Join(typed, ", ")
+	}
+
+	switch propertyType {
+	case "title", "rich_text":
+		richText := []any{}
+		if value != nil {
+			richText = append(richText, map[string]any{"text": map[string]string{"content": text}})
+		}
+		return map[string]any{propertyType: richText}, nil
+	case "number":
+		if value == nil {
+			return map[string]any{"number": nil}, nil
+		}
+		number, isNumber := value.(float64)
+		if !isNumber {
+			return nil, fmt.Errorf("'%s' isn't a number", text)
+		}
+		return map[string]any{"number": number}, nil
+	case "select":
+		if value == nil {
+			return map[string]any{"select": nil}, nil
+		}
+		return map[string]any{"select": map[string]string{"name": text}}, nil
+	case "multi_select":
+		options := []any{}
+		names, isList := value.([]string)
+		if !isList && value != nil {
+			names = []string{text}
+		}
+		for _, name := range names {
+			options = append(options, map[string]string{"name": name})
+		}
+		return map[string]any{"multi_select": options}, nil
+	case "url":
+		if value == nil {
+			return map[string]any{"url": nil}, nil
+		}
+		return map[string]any{"url": text}, nil
+	}
+	return nil, fmt.Errorf("unsupported property type '%s'", propertyType)
+}
+
+// Export writes all entries of the watchlist into the database. Entries
+// that already have a row, identified by their URL or title, are updated
+// instead of being added again. The columns have to exist in the database,
+// their types decide how values are written. Supported are title, text,
+// number, select, multi-select and URL properties. Entries that aren't
+// exportable, see proxerscrape.Media.Exportable, are skipped and returned.
+func (notion *Notion) Export(watchlist *proxerscrape.Watchlist, columns Columns) ([]*proxerscrape.Media, error) {
+	keyField, keyColumn, err := columns.key()
+	if err != nil {
+		return nil, err
+	}
+
+	var database struct {
+		Properties map[string]struct {
+			Type string `json:"type"`
+		} `json:"properties"`
+	}
+	if err := notion.request(http.MethodGet, "/v1/databases/"+notion.DatabaseID, nil, &database); err != nil {
+		return nil, err
+	}
+	for _, column := range columns {
+		if _, present := database.Properties[column]; !present {
+			return nil, fmt.Errorf("notion database has no column '%s'", column)
+		}
+	}
+
+	pages := make(map[string]string)
+	query := map[string]any{"page_size": 100}
+	for {
+		var result struct {
+			Results []struct {
+				ID         string                    `json:"id"`
+				Properties map[string]notionProperty `json:"properties"`
+			} `json:"results"`
+			HasMore    bool   `json:"has_more"`
+			NextCursor string `json:"next_cursor"`
+		}
+		if err := notion.request(http.MethodPost, "/v1/databases/"+notion.DatabaseID+"/query", query, &result); err != nil {
+			return nil, err
+		}
+		for _, page := range result.Results {
+			pages[page.Properties[keyColumn].text()] = page.ID
+		}
+		if !result.HasMore {
+			break
+		}
+		query["start_cursor"] = result.NextCursor
+	}
+
+	items, skipped := exportable(watchlist)
+	for _, item := range items {
+		properties := make(map[string]any, len(columns))
+		for column, value := range columns.row(item) {
+			if properties[column], err = notionValue(database.Properties[column].Type, value); err != nil {
+				return skipped, fmt.Errorf("error writing column '%s' of '%s': %w", column, item.Title, err)
+			}
+		}
+
+		if pageID, present := pages[fieldValues[keyField](item).(string)]; present {
+			err = notion.request(http.MethodPatch, "/v1/pages/"+pageID, map[string]any{"properties": properties}, nil)
+		} else {
+			err = notion.request(http.MethodPost, "/v1/pages", map[string]any{
+				"parent":     map[string]string{"database_id": notion.DatabaseID},
+				"properties": properties,
+			}, nil)
+		}
+		if err != nil {
+			return skipped, fmt.Errorf("error exporting '%s': %w", item.Title, err)
+		}
+	}
+	return skipped, nil
+}
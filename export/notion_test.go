@@ -0,0 +1,91 @@
+package export
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Bios-Marcel/proxerscrape"
+)
+
+func TestNotionExport(t *testing.T) {
+	var created, updated []map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/databases/db", func(writer http.ResponseWriter, request *http.Request) {
+		if request.Header.Get("Authorization") != "Bearer secret" {
+			writer.WriteHeader(http.StatusUnauthorized)
+			writer.Write([]byte(`{"message":"API token is invalid."}`))
+			return
+		}
+		writer.Write([]byte(`{"properties":{
+			"Name":{"type":"title"},
+			"Link":{"type":"url"},
+			"Score":{"type":"number"},
+			"Genres":{"type":"multi_select"}
+		}}`))
+	})
+	mux.HandleFunc("/v1/databases/db/query", func(writer http.ResponseWriter, request *http.Request) {
+		var query map[string]any
+		json.NewDecoder(request.Body).Decode(&query)
+		// The existing page is only returned on the second page.
+		if query["start_cursor"] == nil {
+			writer.Write([]byte(`{"results":[],"has_more":true,"next_cursor":"next"}`))
+			return
+		}
+		writer.Write([]byte(`{"results":[{"id":"page","properties":{"Link":{"type":"url","url":"https://proxer.me/info/2"}}}],"has_more":false}`))
+	})
+	mux.HandleFunc("/v1/pages", func(writer http.ResponseWriter, request *http.Request) {
+		var page map[string]any
+		json.NewDecoder(request.Body).Decode(&page)
+		created = append(created, page)
+		writer.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("/v1/pages/page", func(writer http.ResponseWriter, request *http.Request) {
+		var page map[string]any
+		json.NewDecoder(request.Body).Decode(&page)
+		updated = append(updated, page)
+		writer.Write([]byte(`{}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	watchlist := proxerscrape.Watchlist{}
+	watchlist.Watched.Data = []*proxerscrape.Media{
+		{ProxerURL: "/info/1", Title: "New", UserRating: 8, Generes: []string{"Action", "Drama"}},
+		{ProxerURL: "/info/2", Title: "Existing"},
+		{ProxerURL: "/info/3", Title: "Gone", Unavailable: true},
+	}
+	columns := Columns{FieldTitle: "Name", FieldURL: "Link", FieldUserRating: "Score", FieldGenres: "Genres"}
+
+	notion := &Notion{Token: "wrong", DatabaseID: "db", BaseURL: server.URL}
+	if _, err := notion.Export(&watchlist, columns); err == nil {
+		t.Error("Expected an error for an invalid token")
+	}
+	notion.Token = "secret"
+	if _, err := notion.Export(&watchlist, Columns{FieldTitle: "Missing"}); err == nil {
+		t.Error("Expected an error for a missing column")
+	}
+	skipped, err := notion.Export(&watchlist, columns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skipped) != 1 || skipped[0].Title != "Gone" {
+		t.Errorf("Expected the dead link to be skipped, got %v", skipped)
+	}
+
+	if len(created) != 1 || len(updated) != 1 {
+		t.Fatalf("Expected one created and one updated page, got %v and %v", created, updated)
+	}
+	properties, _ := created[0]["properties"].(map[string]any)
+	score, _ := properties["Score"].(map[string]any)
+	genres, _ := properties["Genres"].(map[string]any)["multi_select"].([]any)
+	if score["number"] != float64(8) || len(genres) != 2 {
+		t.Errorf("Unexpected created page: %v", created[0])
+	}
+	properties, _ = updated[0]["properties"].(map[string]any)
+	score, _ = properties["Score"].(map[string]any)
+	if value, present := score["number"]; !present || value != nil {
+		t.Errorf("Expected the score of the updated page to be cleared: %v", updated[0])
+	}
+}
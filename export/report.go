@@ -0,0 +1,203 @@
+package export
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Bios-Marcel/proxerscrape"
+)
+
+// ReportOptions configures WriteMarkdownReport and WriteHTMLReport.
+type ReportOptions struct {
+	// Title is the heading of the report.
+	Title string
+	// Covers includes the cover images, which are loaded from proxer.me
+	// when viewing the report.
+	Covers bool
+	// EpisodeDuration is used for calculating the time watched and left. If
+	// 0, the duration of each entry is estimated, see
+	// proxerscrape.Media.EstimatedEpisodeDuration.
+	EpisodeDuration time.Duration
+	// UserData adds the local notes and tags of the entries, see
+	// proxerscrape.UserData.SetNote. If nil, they're omitted.
+	UserData *proxerscrape.UserData
+}
+
+// categoryNames are the headings of the watchlist categories.
+var categoryNames = map[proxerscrape.ListCategory]string{
+	proxerscrape.CategoryWatched:           "Watched",
+	proxerscrape.CategoryCurrentlyWatching: "Watching",
+	proxerscrape.CategoryToWatch:           "Planned",
+	proxerscrape.CategoryStoppedWatching:   "Stopped",
+}
+
+type reportEntry struct {
+	Title    string
+	URL      string
+	CoverURL string
+	Progress string
+	// UserRating and Rating are empty if unknown.
+	UserRating string
+	Rating     string
+	Genres     string
+	// Missing explains why the extra data is missing, such as a dead link.
+	Missing string
+	// Note is the text of the local note, Tags its comma separated tags.
+	Note string
+	Tags string
+}
+
+type reportCategory struct {
+	Name    string
+	Entries []reportEntry
+}
+
+type reportData struct {
+	Title           string
+	GeneratedAt     string
+	Covers          bool
+	Notes           bool
+	Entries         int
+	EpisodesWatched int
+	TimeWatched     string
+	TimeLeft        string
+	Categories      []reportCategory
+}
+
+// missingExtraData explains why the extra data of the entry couldn't be
+// loaded, or returns an empty string if there's no known reason.
+func missingExtraData(item *proxerscrape.Media) string {
+	switch {
+	case item.Unavailable:
+		return "dead link"
+	case item.RestrictedAccess:
+		return "login required"
+	}
+	return ""
+}
+
+func formatHours(duration time.Duration) string {
+	return fmt.Sprintf("%dh", int(duration.Round(time.Hour).Hours()))
+}
+
+func newReportData(watchlist *proxerscrape.Watchlist, options ReportOptions, now time.Time) reportData {
+	episodeDuration := func(item *proxerscrape.Media) time.Duration {
+		if options.EpisodeDuration > 0 {
+			return options.EpisodeDuration
+		}
+		return item.EstimatedEpisodeDuration()
+	}
+	data := reportData{
+		Title:       options.Title,
+		GeneratedAt: now.Format("2006-01-02 15:04"),
+		Covers:      options.Covers,
+		Notes:       options.UserData != nil,
+	}
+	if data.Title == "" {
+		data.Title = "Watchlist"
+	}
+
+	var timeWatched, timeLeft time.Duration
+	for _, category := range proxerscrape.ListCategories {
+		reportCategory := reportCategory{Name: categoryNames[category]}
+		for _, item := range watchlist.Category(category).Data {
+			entry := reportEntry{
+				Title:    item.Title,
+				URL:      "https://proxer.me" + item.ProxerURL,
+				CoverURL: "https://cdn.proxer.me/cover/" + item.ProxerID() + ".jpg",
+				Progress: fmt.Sprintf("%d/%d", item.EpisodesWatched, item.EpisodeCount),
+				Genres:   strings.Join(item.Generes, ", "),
+				Missing:  missingExtraData(item),
+			}
+			if item.UserRating > 0 {
+				entry.UserRating = fmt.Sprint(item.UserRating)
+			}
+			if item.Rating > 0 {
+				entry.Rating = fmt.Sprintf("%.1f", item.Rating)
+			}
+			if options.UserData != nil {
+				if note := options.UserData.Note(item); note != nil {
+					entry.Note = note.Text
+					entry.Tags = strings.Join(note.Tags, ", ")
+				}
+			}
+			reportCategory.Entries = append(reportCategory.Entries, entry)
+
+			if item.Type.IsAnime() {
+				data.EpisodesWatched += int(item.EpisodesWatched)
+				timeWatched += time.Duration(item.EpisodesWatched) * episodeDuration(item)
+				if (category == proxerscrape.CategoryCurrentlyWatching || category == proxerscrape.CategoryToWatch) &&
+					item.EpisodeCount > item.EpisodesWatched {
+					timeLeft += time.Duration(item.EpisodeCount-item.EpisodesWatched) * episodeDuration(item)
+				}
+			}
+		}
+		data.Entries += len(reportCategory.Entries)
+		data.Categories = append(data.Categories, reportCategory)
+	}
+	data.TimeWatched = formatHours(timeWatched)
+	data.TimeLeft = formatHours(timeLeft)
+	return data
+}
+
+// markdownEscaper prevents titles from breaking the table layout.
+var markdownEscaper = strings.NewReplacer("\n", " ", "|", `\|`, "[", `\[`, "]", `\]`, "*", `\*`, "_", `\_`)
+
+var markdownReport = template.Must(template.New("report").Funcs(template.FuncMap{
+	"escape": markdownEscaper.Replace,
+}).Parse(`# {{escape .Title}}
+
+Generated at {{.GeneratedAt}}.
+
+| Entries | Episodes watched | Time watched | Time left |
+| --- | --- | --- | --- |
+| {{.Entries}} | {{.EpisodesWatched}} | {{.TimeWatched}} | {{.TimeLeft}} |
+{{range .Categories}}{{if .Entries}}
+## {{.Name}}
+
+| {{if $.Covers}}Cover | {{end}}Title | Progress | Own rating | Rating | Genres |{{if $.Notes}} Tags | Note |{{end}}
+| {{if $.Covers}}--- | {{end}}--- | --- | --- | --- | --- |{{if $.Notes}} --- | --- |{{end}}
+{{range .Entries}}| {{if $.Covers}}![]({{.CoverURL}}) | {{end}}[{{escape .Title}}]({{.URL}}){{if .Missing}} ({{.Missing}}){{end}} | {{.Progress}} | {{.UserRating}} | {{.Rating}} | {{escape .Genres}} |{{if $.Notes}} {{escape .Tags}} | {{escape .Note}} |{{end}}
+{{end}}{{end}}{{end}}`))
+
+var htmlReport = htmltemplate.Must(htmltemplate.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em auto; max-width: 60em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border-bottom: 1px solid #ddd; padding: 0.3em; text-align: left; }
+img { height: 4em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>Generated at {{.GeneratedAt}}.</p>
+<p>{{.Entries}} entries, {{.EpisodesWatched}} episodes watched ({{.TimeWatched}}), {{.TimeLeft}} left.</p>
+{{range .Categories}}{{if .Entries}}
+<h2>{{.Name}}</h2>
+<table>
+<tr>{{if $.Covers}}<th>Cover</th>{{end}}<th>Title</th><th>Progress</th><th>Own rating</th><th>Rating</th><th>Genres</th>{{if $.Notes}}<th>Tags</th><th>Note</th>{{end}}</tr>
+{{range .Entries}}<tr>{{if $.Covers}}<td><img src="{{.CoverURL}}" alt=""></td>{{end}}<td><a href="{{.URL}}">{{.Title}}</a>{{if .Missing}} ({{.Missing}}){{end}}</td><td>{{.Progress}}</td><td>{{.UserRating}}</td><td>{{.Rating}}</td><td>{{.Genres}}</td>{{if $.Notes}}<td>{{.Tags}}</td><td>{{.Note}}</td>{{end}}</tr>
+{{end}}</table>
+{{end}}{{end}}</body>
+</html>
+`))
+
+// WriteMarkdownReport renders the watchlist as a Markdown document. Ratings
+// and genres are only present if the extra data has been loaded.
+func WriteMarkdownReport(writer io.Writer, watchlist *proxerscrape.Watchlist, options ReportOptions, now time.Time) error {
+	return markdownReport.Execute(writer, newReportData(watchlist, options, now))
+}
+
+// WriteHTMLReport renders the watchlist as a single HTML page without any
+// external resources, except for the covers if enabled.
+func WriteHTMLReport(writer io.Writer, watchlist *proxerscrape.Watchlist, options ReportOptions, now time.Time) error {
+	return htmlReport.Execute(writer, newReportData(watchlist, options, now))
+}
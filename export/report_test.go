@@ -0,0 +1,83 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Bios-Marcel/proxerscrape"
+)
+
+func TestReports(t *testing.T) {
+	watchlist := proxerscrape.Watchlist{}
+	watchlist.Watched.Data = []*proxerscrape.Media{
+		{ProxerURL: "/info/1", Title: "Fate|Zero", Type: proxerscrape.MediaTypeSeries, EpisodesWatched: 25, EpisodeCount: 25, Rating: 8.6, Generes: []string{"Action"}},
+	}
+	watchlist.ToWatch.Data = []*proxerscrape.Media{
+		{ProxerURL: "/info/2", Title: "<script>", Type: proxerscrape.MediaTypeSeries, EpisodeCount: 10},
+		{ProxerURL: "/info/3", Title: "Hidden", Type: proxerscrape.MediaTypeSeries, RestrictedAccess: true},
+	}
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	options := ReportOptions{Covers: true}
+
+	var markdown bytes.Buffer
+	if err := WriteMarkdownReport(&markdown, &watchlist, options, now); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"| 3 | 25 | 10h | 4h |",
+		"[Hidden](https://proxer.me/info/3) (login required) |",
+		`[Fate\|Zero](https://proxer.me/info/1) | 25/25 |  | 8.6 | Action |`,
+		"![](https://cdn.proxer.me/cover/1.jpg)",
+	} {
+		if !strings.Contains(markdown.String(), expected) {
+			t.Errorf("Missing %q in:\n%s", expected, markdown.String())
+		}
+	}
+	if strings.Contains(markdown.String(), "## Watching") {
+		t.Error("Empty categories should be omitted")
+	}
+
+	var html bytes.Buffer
+	if err := WriteHTMLReport(&html, &watchlist, options, now); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(html.String(), "<script>") || !strings.Contains(html.String(), "&lt;script&gt;") {
+		t.Error("Titles aren't escaped in HTML")
+	}
+}
+
+func TestReports_Notes(t *testing.T) {
+	watchlist := proxerscrape.Watchlist{}
+	watchlist.Watched.Data = []*proxerscrape.Media{
+		{ProxerURL: "/info/1", Title: "Fate/Zero", Type: proxerscrape.MediaTypeSeries},
+		{ProxerURL: "/info/2", Title: "Tsurune", Type: proxerscrape.MediaTypeSeries},
+	}
+	userData := &proxerscrape.UserData{}
+	userData.SetNote(watchlist.Watched.Data[0], "Rewatch\nwith friends")
+	userData.AddTag(watchlist.Watched.Data[0], "favourite")
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	var markdown bytes.Buffer
+	if err := WriteMarkdownReport(&markdown, &watchlist, ReportOptions{UserData: userData}, now); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"| Genres | Tags | Note |",
+		"| favourite | Rewatch with friends |",
+		"[Tsurune](https://proxer.me/info/2) | 0/0 |  |  |  |  |  |",
+	} {
+		if !strings.Contains(markdown.String(), expected) {
+			t.Errorf("Missing %q in:\n%s", expected, markdown.String())
+		}
+	}
+
+	markdown.Reset()
+	if err := WriteMarkdownReport(&markdown, &watchlist, ReportOptions{}, now); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(markdown.String(), "Note") {
+		t.Errorf("Expected no notes without user data:\n%s", markdown.String())
+	}
+}
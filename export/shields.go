@@ -0,0 +1,65 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Bios-Marcel/proxerscrape"
+)
+
+// Badge is the endpoint JSON of shields.io, which allows embedding a badge
+// that is updated whenever the file changes. See https://shields.io/endpoint.
+type Badge struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color,omitempty"`
+}
+
+// WatchedBadge shows the amount of finished anime, for example
+// "anime watched: 312".
+func WatchedBadge(watchlist *proxerscrape.Watchlist) Badge {
+	var watched int
+	for _, item := range watchlist.Watched.Data {
+		if item.Type.IsAnime() {
+			watched++
+		}
+	}
+	return Badge{
+		SchemaVersion: 1,
+		Label:         "anime watched",
+		Message:       fmt.Sprint(watched),
+		Color:         "blue",
+	}
+}
+
+// BacklogBadge shows how long it takes to watch the remaining episodes of all
+// anime that are being watched or planned, for example "backlog: 46h". The
+// duration of each entry is estimated, see
+// proxerscrape.Media.EstimatedWatchTimeLeft. Entries with an unknown episode
+// count aren't taken into account.
+func BacklogBadge(watchlist *proxerscrape.Watchlist) Badge {
+	var backlog time.Duration
+	for _, category := range []*proxerscrape.WatchlistCategory{&watchlist.CurrentlyWatching, &watchlist.ToWatch} {
+		for _, item := range category.Data {
+			if item.Type.IsAnime() && item.EpisodeCount > item.EpisodesWatched {
+				backlog += item.EstimatedWatchTimeLeft()
+			}
+		}
+	}
+	return Badge{
+		SchemaVersion: 1,
+		Label:         "backlog",
+		Message:       fmt.Sprintf("%dh", int(backlog.Round(time.Hour).Hours())),
+		Color:         "orange",
+	}
+}
+
+// WriteBadge writes the badge as shields.io endpoint JSON.
+func WriteBadge(writer io.Writer, badge Badge) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(badge)
+}
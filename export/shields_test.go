@@ -0,0 +1,46 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/Bios-Marcel/proxerscrape"
+)
+
+func TestBadges(t *testing.T) {
+	watchlist := proxerscrape.Watchlist{}
+	watchlist.Watched.Data = []*proxerscrape.Media{
+		{Title: "Tsurune", Type: proxerscrape.MediaTypeSeries},
+		{Title: "Berserk", Type: proxerscrape.MediaTypeManga},
+	}
+	watchlist.CurrentlyWatching.Data = []*proxerscrape.Media{
+		{Title: "One Piece", Type: proxerscrape.MediaTypeSeries, EpisodesWatched: 10, EpisodeCount: 110},
+	}
+	watchlist.ToWatch.Data = []*proxerscrape.Media{
+		{Title: "Unknown", Type: proxerscrape.MediaTypeSeries},
+		{Title: "Movie", Type: proxerscrape.MediaTypeMovie, EpisodeCount: 1},
+	}
+
+	if badge := WatchedBadge(&watchlist); badge.Message != "1" {
+		t.Errorf("Watched badge message was %s, instead of 1", badge.Message)
+	}
+	// 100 episodes of 24 minutes and a movie of 90 minutes are roughly 42
+	// hours.
+	badge := BacklogBadge(&watchlist)
+	if badge.Message != "42h" {
+		t.Errorf("Backlog badge message was %s, instead of 42h", badge.Message)
+	}
+
+	var buffer bytes.Buffer
+	if err := WriteBadge(&buffer, badge); err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(buffer.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded["schemaVersion"] != float64(1) || decoded["label"] != "backlog" {
+		t.Errorf("Unexpected endpoint JSON: %s", buffer.String())
+	}
+}
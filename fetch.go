@@ -0,0 +1,129 @@
+package proxerscrape
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// profileFetchWorkers is the amount of requests that may be in flight at once
+// when fetching multiple profiles. The ratelimiter still applies, this only
+// allows us to not wait for one response before sending the next request.
+const profileFetchWorkers = 4
+
+// ProfileFetchResult is the outcome of fetching and parsing a single profile
+// tab.
+type ProfileFetchResult struct {
+	ProfileID string
+	TabType   ProfileTabType
+	Watchlist Watchlist
+	Err       error
+}
+
+// FetchProfiles retrieves and parses the given tabs of all given profiles.
+// Requests are interleaved across profiles, so that the first tab of every
+// profile is available before the second tab of any profile is requested.
+// All requests share the profile tab ratelimiter. Once a request hits the
+// ratelimit, no further requests are started and the remaining tabs are
+// deferred, see Cache.Defer, failing with ErrRateLimited. Results are sent as
+// soon as they are available and the channel is closed once all profiles have
+// been fetched.
+func (cache *Cache) FetchProfiles(profileIDs []string, tabTypes ...ProfileTabType) <-chan ProfileFetchResult {
+	if len(tabTypes) == 0 {
+		tabTypes = []ProfileTabType{ProfileTabAnime}
+	}
+
+	jobs := make(chan ProfileFetchResult, len(profileIDs)*len(tabTypes))
+	for _, tabType := range tabTypes {
+		for _, profileID := range profileIDs {
+			jobs <- ProfileFetchResult{ProfileID: profileID, TabType: tabType}
+		}
+	}
+	close(jobs)
+
+	results := make(chan ProfileFetchResult, len(profileIDs)*len(tabTypes))
+	var rateLimited int32
+	var waitGroup sync.WaitGroup
+	for i := 0; i < profileFetchWorkers && i < len(profileIDs)*len(tabTypes); i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for job := range jobs {
+				if atomic.LoadInt32(&rateLimited) != 0 {
+					job.Err = ErrRateLimited
+				} else {
+					job.Watchlist, job.Err = cache.fetchProfile(job.ProfileID, job.TabType)
+					if errors.Is(job.Err, ErrRateLimited) {
+						atomic.StoreInt32(&rateLimited, 1)
+					}
+				}
+				job.Err = cache.Defer(DeferProfileTab(job.ProfileID, job.TabType), job.Err)
+				results <- job
+			}
+		}()
+	}
+	go func() {
+		waitGroup.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+func (cache *Cache) fetchProfile(profileID string, tabType ProfileTabType) (Watchlist, error) {
+	reader, _, err := cache.RetrieveProfileTabRawData(profileID, tabType)
+	if err != nil {
+		return Watchlist{}, err
+	}
+	defer reader.Close()
+
+	return cache.parseProfileTab(reader)
+}
+
+// parseProfileTab parses a profile tab, logging the rows that were skipped.
+func (cache *Cache) parseProfileTab(reader io.Reader) (Watchlist, error) {
+	watchlist, warnings, err := ParseProfileMediaTabWithOptions(reader, ParseOptions{})
+	cache.logWarnings(warnings)
+	return watchlist, err
+}
+
+func (cache *Cache) logWarnings(warnings []ParseWarning) {
+	for _, warning := range warnings {
+		cache.logf("%s", warning)
+	}
+}
+
+// RefreshWatchlist revalidates the given profile tab, even if the cached
+// version hasn't expired yet, see RetrieveProfileTabIfChanged. The previously
+// cached version is returned as well, so that both can be compared via
+// DiffWatchlists. If nothing was cached, the previous watchlist is empty. If
+// fetching fails, the previous version stays cached. In offline mode,
+// ErrNotCached is returned.
+func (cache *Cache) RefreshWatchlist(profileID string, tabType ProfileTabType) (Watchlist, Watchlist, error) {
+	if cache.Offline {
+		return Watchlist{}, Watchlist{}, ErrNotCached
+	}
+
+	var previous Watchlist
+	reader, _, err := cache.Store.Get(profileTabCacheKey(profileID, tabType))
+	if err == nil {
+		previous, err = cache.parseProfileTab(reader)
+		reader.Close()
+		if err != nil {
+			return previous, Watchlist{}, err
+		}
+	} else if !errors.Is(err, ErrCacheMiss) {
+		return previous, Watchlist{}, err
+	}
+
+	// Even if the tab didn't change, it's parsed again, so that the two
+	// watchlists don't share their entries.
+	reader, _, err = cache.RetrieveProfileTabIfChanged(profileID, tabType)
+	if err != nil {
+		return previous, Watchlist{}, err
+	}
+	defer reader.Close()
+	current, err := cache.parseProfileTab(reader)
+	return previous, current, err
+}
@@ -0,0 +1,94 @@
+package proxerscrape
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCache_FetchProfiles(t *testing.T) {
+	var queries int32
+	captcha := false
+	cache := &Cache{
+		Store: NewMemoryStore(),
+		QueryProfileTab: func(profileID string, tabType ProfileTabType, header http.Header) (*http.Response, error) {
+			atomic.AddInt32(&queries, 1)
+			page := profileTabPage("Watched by " + profileID)
+			if captcha {
+				page = `<script src="//www.google.com/recaptcha/api.js"></script>`
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(page))}, nil
+		},
+	}
+
+	titles := make(map[string]string)
+	for result := range cache.FetchProfiles([]string{"1", "2"}, ProfileTabAnime, ProfileTabManga) {
+		if result.Err != nil {
+			t.Fatal(result.Err)
+		}
+		titles[result.ProfileID+"/"+string(result.TabType)] = result.Watchlist.Watched.Data[0].Title
+	}
+	if len(titles) != 4 || titles["2/manga"] != "Watched by 2" {
+		t.Errorf("Unexpected results: %v", titles)
+	}
+
+	// Once the ratelimit is hit, the remaining tabs are deferred without
+	// querying them.
+	queue, err := LoadFetchQueue(filepath.Join(t.TempDir(), "queue.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.Queue = queue
+	captcha = true
+	atomic.StoreInt32(&queries, 0)
+	profileIDs := []string{"3", "4", "5", "6", "7", "8", "9", "10"}
+	for result := range cache.FetchProfiles(profileIDs) {
+		if !errors.Is(result.Err, ErrRateLimited) {
+			t.Errorf("Expected ErrRateLimited for %s, got %v", result.ProfileID, result.Err)
+		}
+	}
+	if queries := atomic.LoadInt32(&queries); queries > profileFetchWorkers || queue.Len() != len(profileIDs) {
+		t.Errorf("Expected at most one query per worker, got %d with %d deferred", queries, queue.Len())
+	}
+}
+
+func TestCache_RefreshWatchlist(t *testing.T) {
+	pages := map[string]string{"/user/1/anime": profileTabPage("Watched")}
+	cache, queries := newTestCache(pages)
+
+	previous, current, err := cache.RefreshWatchlist("1", ProfileTabAnime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(previous.All()) != 0 || len(current.All()) != 2 {
+		t.Errorf("Unexpected watchlists %+v and %+v", previous, current)
+	}
+
+	// The unchanged tab is only revalidated, instead of downloaded again.
+	previous, current, err = cache.RefreshWatchlist("1", ProfileTabAnime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes := DiffWatchlists(previous, current); len(changes) != 0 || *queries != 2 {
+		t.Errorf("Unexpected changes %v after %d queries", changes, *queries)
+	}
+	if previous.Watched.Data[0] == current.Watched.Data[0] {
+		t.Error("Expected the watchlists not to share their entries")
+	}
+	if stats, _ := cache.Stats(); stats.Misses != 2 {
+		t.Errorf("Expected both refreshes to query proxer.me, got %+v", stats)
+	}
+
+	pages["/user/1/anime"] = profileTabPage("Renamed")
+	previous, current, err = cache.RefreshWatchlist("1", ProfileTabAnime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if previous.Watched.Data[0].Title != "Watched" || current.Watched.Data[0].Title != "Renamed" {
+		t.Errorf("Unexpected titles %s and %s", previous.Watched.Data[0].Title, current.Watched.Data[0].Title)
+	}
+}
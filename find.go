@@ -0,0 +1,134 @@
+package proxerscrape
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// minWordSimilarity is how similar a typed word has to be to a word of a
+// title, for it to be considered a typo of that word.
+const minWordSimilarity = 0.7
+
+// Find returns the entries whose titles match the query, best matches first.
+// All titles known for an entry are considered, including the English and
+// Japanese titles and synonyms, which are only present after loading the
+// extra data. Matching ignores case and punctuation and tolerates typos and
+// incomplete words, so that titles can be typed by hand.
+func (watchlist *Watchlist) Find(query string) []*Media {
+	queryWords := strings.Fields(normalizeSearchText(query))
+	if len(queryWords) == 0 {
+		return nil
+	}
+
+	type match struct {
+		item  *Media
+		score float64
+	}
+	var matches []match
+	for _, item := range watchlist.All() {
+		var best float64
+		for _, title := range append([]string{item.Title, item.EnglishTitle, item.GermanTitle, item.JapaneseTitle}, item.Synonyms...) {
+			if score := matchTitle(queryWords, title); score > best {
+				best = score
+			}
+		}
+		if best > 0 {
+			matches = append(matches, match{item, best})
+		}
+	}
+	sort.SliceStable(matches, func(a, b int) bool {
+		return matches[a].score > matches[b].score
+	})
+
+	results := make([]*Media, 0, len(matches))
+	for _, match := range matches {
+		results = append(results, match.item)
+	}
+	return results
+}
+
+// matchTitle scores how well the query matches the title, from 0 for no match
+// to 1 for an exact match. Every word of the query has to match a word of the
+// title, either exactly, as prefix or with a few typos.
+func matchTitle(queryWords []string, title string) float64 {
+	normalized := normalizeSearchText(title)
+	if normalized == "" {
+		return 0
+	}
+	query := strings.Join(queryWords, " ")
+	if normalized == query {
+		return 1
+	}
+	if strings.Contains(normalized, query) {
+		return 0.9
+	}
+
+	titleWords := strings.Fields(normalized)
+	var total float64
+	for _, queryWord := range queryWords {
+		var best float64
+		for _, titleWord := range titleWords {
+			var similarity float64
+			if strings.HasPrefix(titleWord, queryWord) {
+				similarity = 1
+			} else {
+				similarity = wordSimilarity(queryWord, titleWord)
+			}
+			if similarity > best {
+				best = similarity
+			}
+		}
+		if best < minWordSimilarity {
+			return 0
+		}
+		total += best
+	}
+	// Matching words individually is weaker than matching the whole phrase.
+	return 0.8 * total / float64(len(queryWords))
+}
+
+// wordSimilarity returns 1 for equal words and approaches 0 the more edits are
+// needed to turn one into the other.
+func wordSimilarity(a, b string) float64 {
+	runesA, runesB := []rune(a), []rune(b)
+	longest := len(runesA)
+	if len(runesB) > longest {
+		longest = len(runesB)
+	}
+	if longest == 0 {
+		return 1
+	}
+
+	previous := make([]int, len(runesB)+1)
+	current := make([]int, len(runesB)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(runesA); i++ {
+		current[0] = i
+		for j := 1; j <= len(runesB); j++ {
+			cost := 1
+			if runesA[i-1] == runesB[j-1] {
+				cost = 0
+			}
+			current[j] = previous[j-1] + cost
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous, current = current, previous
+	}
+	return 1 - float64(previous[len(runesB)])/float64(longest)
+}
+
+// normalizeSearchText lowercases the text and replaces all punctuation with
+// single spaces.
+func normalizeSearchText(text string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
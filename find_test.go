@@ -0,0 +1,46 @@
+package proxerscrape
+
+import "testing"
+
+func TestWatchlist_Find(t *testing.T) {
+	watchlist := &Watchlist{
+		Watched: WatchlistCategory{Data: []*Media{
+			{Title: "Tsurune", EnglishTitle: "Tsurune: Kazemai High School Kyudo Club", Synonyms: []string{"Take Yumi"}},
+			{Title: "Shingeki no Kyojin", EnglishTitle: "Attack on Titan"},
+			{Title: "Shingeki no Kyojin Season 2", EnglishTitle: "Attack on Titan Season 2"},
+		}},
+		ToWatch: WatchlistCategory{Data: []*Media{
+			{Title: "Steins;Gate"},
+		}},
+	}
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"take yumi", []string{"Tsurune"}},
+		{"kyudo", []string{"Tsurune"}},
+		{"steins gate", []string{"Steins;Gate"}},
+		{"attack on titan", []string{"Shingeki no Kyojin", "Shingeki no Kyojin Season 2"}},
+		{"atack titan 2", []string{"Shingeki no Kyojin Season 2"}},
+		{"shing kyo", []string{"Shingeki no Kyojin", "Shingeki no Kyojin Season 2"}},
+		{"one piece", nil},
+		{"  ", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			var titles []string
+			for _, item := range watchlist.Find(test.query) {
+				titles = append(titles, item.Title)
+			}
+			if len(titles) != len(test.expected) {
+				t.Fatalf("Got %v, expected %v", titles, test.expected)
+			}
+			for index := range titles {
+				if titles[index] != test.expected[index] {
+					t.Errorf("Got %v, expected %v", titles, test.expected)
+				}
+			}
+		})
+	}
+}
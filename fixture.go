@@ -0,0 +1,43 @@
+package proxerscrape
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// recordedMarker starts the comment RecordFixture puts in front of every
+// fixture, telling recorded pages apart from hand-written ones.
+const recordedMarker = "<!-- recorded from "
+
+// RecordFixture downloads the given page, such as `/info/296`, and writes it
+// sanitized, see Sanitize, so that it can be used as a test fixture. Full
+// URLs are accepted as well. Unlike the Retrieve methods, error
+// pages such as 404s, login walls and captchas are recorded as is, since
+// they're needed as fixtures as well. Nothing is cached. The page is
+// preceded by a comment stating where and when it was recorded.
+func (cache *Cache) RecordFixture(path string, writer io.Writer) error {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, cache.BaseURL()), DefaultBaseURL)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	session := cache.Session()
+	session.wait()
+	response, err := session.Get(path)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(writer, "%s%s on %s -->\n", recordedMarker, path, time.Now().Format("2006-01-02")); err != nil {
+		return err
+	}
+	return Sanitize(bytes.NewReader(NormalizeEncoding(data, response.Header.Get("Content-Type"))), writer)
+}
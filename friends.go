@@ -0,0 +1,54 @@
+package proxerscrape
+
+import (
+	"io"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Friend is a user listed as friend on a profile.
+type Friend struct {
+	UserID   string
+	Username string
+}
+
+// RetrieveFriendsRawData retrieves the friend list of the given user. Since
+// friend lists are user pages, the profile tab ratelimiter applies.
+func (cache *Cache) RetrieveFriendsRawData(profileID string) (io.ReadCloser, CacheInvalidator, error) {
+	return cache.retrievePage(CacheEntryProfileTab, "profile/"+profileID+"/friends", "/user/"+profileID+"/friends", cache.ProfileTabQueryRatelimiter)
+}
+
+// ParseFriends parses the friend list of the given user. Every link to
+// another user's profile with a name is considered a friend, links to the
+// user themselves, such as the navigation, are skipped.
+func ParseFriends(reader io.Reader, profileID string) ([]Friend, error) {
+	document, err := newDocument(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{profileID: true}
+	var friends []Friend
+	document.Find("a[href*='/user/']").Each(func(_ int, link *goquery.Selection) {
+		href, _ := link.Attr("href")
+		userID := userIDRegex.FindStringSubmatch(href)
+		username := strings.TrimSpace(normalizeText(link.Text()))
+		if userID == nil || username == "" || seen[userID[1]] {
+			return
+		}
+		seen[userID[1]] = true
+		friends = append(friends, Friend{UserID: userID[1], Username: username})
+	})
+	return friends, nil
+}
+
+// RetrieveFriends retrieves and parses the friend list of the given user.
+func (cache *Cache) RetrieveFriends(profileID string) ([]Friend, error) {
+	reader, _, err := cache.RetrieveFriendsRawData(profileID)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ParseFriends(reader, profileID)
+}
@@ -0,0 +1,129 @@
+package proxerscrape
+
+import (
+	"math"
+	"sort"
+)
+
+// GenrePreference is how much a user likes a single genre.
+type GenrePreference struct {
+	// Genre is the genre ID, or the display name for entries cached before
+	// IDs were parsed, see Media.GenreIDs.
+	Genre string
+	Name  string
+	// Entries is the amount of finished entries with this genre.
+	Entries int
+	// AverageRating is the average of the user's own ratings of these
+	// entries, 0 if none of them have been rated.
+	AverageRating float64
+	// Affinity is the preference relative to the most liked genre, which
+	// has an affinity of 1, and the least liked one, which has 0.
+	Affinity float64
+}
+
+// GenreProfile holds the genre preferences of a user, keyed by genre.
+type GenreProfile map[string]*GenrePreference
+
+// unratedDeviation is how much better than their average rating the user is
+// assumed to like unrated entries, since they were finished after all.
+const unratedDeviation = 0.5
+
+// NewGenreProfile computes the genre preferences from the entries the user
+// has finished. Each entry counts with how much its rating deviates from the
+// user's average rating, so that genres the user rates badly are disliked,
+// no matter how many entries of them were finished. The deviations are
+// averaged per genre, together with one neutral entry, so that a single great
+// entry doesn't outweigh many good ones. Only entries with loaded extra data
+// have genres.
+func NewGenreProfile(watchlist *Watchlist) GenreProfile {
+	var meanRating float64
+	var rated int
+	for _, item := range watchlist.Watched.Data {
+		if item.UserRating > 0 {
+			meanRating += float64(item.UserRating)
+			rated++
+		}
+	}
+	if rated > 0 {
+		meanRating /= float64(rated)
+	}
+
+	profile := make(GenreProfile)
+	deviations := make(map[string]float64)
+	ratingSums := make(map[string]float64)
+	ratingCounts := make(map[string]int)
+	for _, item := range watchlist.Watched.Data {
+		deviation := unratedDeviation
+		if item.UserRating > 0 {
+			deviation = float64(item.UserRating) - meanRating
+		}
+		for index, genre := range genreKeys(item) {
+			preference, present := profile[genre]
+			if !present {
+				preference = &GenrePreference{Genre: genre, Name: genre}
+				if index < len(item.Generes) {
+					preference.Name = item.Generes[index]
+				}
+				profile[genre] = preference
+			}
+			preference.Entries++
+			deviations[genre] += deviation
+			if item.UserRating > 0 {
+				ratingSums[genre] += float64(item.UserRating)
+				ratingCounts[genre]++
+			}
+		}
+	}
+
+	scores := make(map[string]float64, len(profile))
+	minScore, maxScore := math.Inf(1), math.Inf(-1)
+	for genre, preference := range profile {
+		score := deviations[genre] / float64(preference.Entries+1)
+		scores[genre] = score
+		minScore = math.Min(minScore, score)
+		maxScore = math.Max(maxScore, score)
+	}
+	for genre, preference := range profile {
+		// The least liked genre has an affinity of 0, unless all genres are
+		// liked equally.
+		preference.Affinity = 1
+		if maxScore > minScore {
+			preference.Affinity = (scores[genre] - minScore) / (maxScore - minScore)
+		}
+		if ratingCounts[genre] > 0 {
+			preference.AverageRating = ratingSums[genre] / float64(ratingCounts[genre])
+		}
+	}
+	return profile
+}
+
+// Preferences returns all preferences, most liked first.
+func (profile GenreProfile) Preferences() []GenrePreference {
+	preferences := make([]GenrePreference, 0, len(profile))
+	for _, preference := range profile {
+		preferences = append(preferences, *preference)
+	}
+	sort.Slice(preferences, func(a, b int) bool {
+		if preferences[a].Affinity != preferences[b].Affinity {
+			return preferences[a].Affinity > preferences[b].Affinity
+		}
+		return preferences[a].Genre < preferences[b].Genre
+	})
+	return preferences
+}
+
+// Match predicts how much the user likes the entry, based on the average
+// affinity of its genres, from 0 to 1.
+func (profile GenreProfile) Match(item *Media) float64 {
+	genres := genreKeys(item)
+	if len(genres) == 0 {
+		return 0
+	}
+	var score float64
+	for _, genre := range genres {
+		if preference, present := profile[genre]; present {
+			score += preference.Affinity
+		}
+	}
+	return score / float64(len(genres))
+}
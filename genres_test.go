@@ -0,0 +1,53 @@
+package proxerscrape
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNewGenreProfile(t *testing.T) {
+	watchlist := &Watchlist{Watched: WatchlistCategory{Data: []*Media{
+		{UserRating: 5, Generes: []string{"Action", "Komödie"}, GenreIDs: []string{"Action", "Comedy"}},
+		{UserRating: 3, Generes: []string{"Action"}, GenreIDs: []string{"Action"}},
+		{Generes: []string{"Romanze"}, GenreIDs: []string{"Romance"}},
+	}}}
+	profile := NewGenreProfile(watchlist)
+
+	// The average rating is 4, so Action deviates by 0 on average, Comedy
+	// by 1 and the unrated Romance entry by 0.5, each halved by the neutral
+	// entry.
+	preferences := profile.Preferences()
+	if len(preferences) != 3 {
+		t.Fatalf("Expected 3 genres, got %+v", preferences)
+	}
+	if preferences[0].Name != "Komödie" || preferences[0].Affinity != 1 {
+		t.Errorf("Unexpected preference for Comedy: %+v", preferences[0])
+	}
+	if preferences[1].Genre != "Romance" || math.Abs(preferences[1].Affinity-0.5) > 0.0001 || preferences[1].AverageRating != 0 {
+		t.Errorf("Unexpected preference for Romance: %+v", preferences[1])
+	}
+	action := preferences[2]
+	if action.Genre != "Action" || action.Entries != 2 || action.AverageRating != 4 || action.Affinity != 0 {
+		t.Errorf("Unexpected preference for Action: %+v", action)
+	}
+
+	candidate := &Media{GenreIDs: []string{"Comedy", "Horror"}}
+	if match := profile.Match(candidate); match != 0.5 {
+		t.Errorf("Match = %f, expected 0.5", match)
+	}
+}
+
+func TestNewGenreProfile_BadlyRated(t *testing.T) {
+	// Many badly rated entries must not outweigh a few great ones.
+	var watchlist Watchlist
+	for i := 0; i < 20; i++ {
+		watchlist.Watched.Data = append(watchlist.Watched.Data, &Media{UserRating: 2, GenreIDs: []string{"Horror"}})
+	}
+	for i := 0; i < 3; i++ {
+		watchlist.Watched.Data = append(watchlist.Watched.Data, &Media{UserRating: 10, GenreIDs: []string{"Romance"}})
+	}
+	preferences := NewGenreProfile(&watchlist).Preferences()
+	if preferences[0].Genre != "Romance" || preferences[1].Affinity != 0 {
+		t.Errorf("Expected Romance to be preferred, got %+v", preferences)
+	}
+}
@@ -0,0 +1,132 @@
+package proxerscrape
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// updateGolden rewrites the golden files instead of comparing against them:
+//
+//	go test -run TestGolden -update
+//
+// New fixtures can be recorded via `proxercli devtools record`.
+var updateGolden = flag.Bool("update", false, "Rewrite the golden files of the fixtures in testdata/fixtures.")
+
+// goldenParsers parse a fixture, depending on the prefix of its name, into
+// a value that is compared against the golden file.
+var goldenParsers = map[string]func(page []byte) (any, error){
+	"profile_": func(page []byte) (any, error) {
+		watchlist, warnings, err := ParseProfileMediaTabWithOptions(bytes.NewReader(page), ParseOptions{})
+		if err != nil {
+			return nil, err
+		}
+		messages := make([]string, 0, len(warnings))
+		for _, warning := range warnings {
+			messages = append(messages, warning.String())
+		}
+		return struct {
+			Entries  []*Media
+			Warnings []string
+		}{watchlist.All(), messages}, nil
+	},
+	"info_": func(page []byte) (any, error) {
+		item := &Media{ProxerURL: "/info/296"}
+		category := WatchlistCategory{Data: []*Media{item}}
+		var message string
+		if err := category.LoadExtraData(retrieveStatic(string(page))); err != nil {
+			var extraDataError ExtraDataError
+			if !errors.As(err, &extraDataError) {
+				return nil, err
+			}
+			message = extraDataError[0].Err.Error()
+		}
+		return struct {
+			Media *Media
+			Error string
+		}{item, message}, nil
+	},
+}
+
+func TestGolden(t *testing.T) {
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "fixtures", "*.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatal("No fixtures found")
+	}
+
+	for _, fixture := range fixtures {
+		fixture := fixture
+		name := strings.TrimSuffix(filepath.Base(fixture), ".html")
+		t.Run(name, func(t *testing.T) {
+			var parse func([]byte) (any, error)
+			for prefix, parser := range goldenParsers {
+				if strings.HasPrefix(name, prefix) {
+					parse = parser
+				}
+			}
+			if parse == nil {
+				t.Fatalf("No parser for fixture %s", name)
+			}
+
+			page, err := os.ReadFile(fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := parse(page)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual, err := json.MarshalIndent(result, "", "\t")
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual = append(actual, '\n')
+
+			goldenPath := strings.TrimSuffix(fixture, ".html") + ".golden.json"
+			if *updateGolden {
+				if err := os.WriteFile(goldenPath, actual, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			expected, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("Missing golden file, run with -update: %s", err)
+			}
+			if !bytes.Equal(actual, expected) {
+				t.Errorf("Result differs from %s, run with -update if this is intended:\n%s", goldenPath, actual)
+			}
+		})
+	}
+}
+
+// TestFixturesRecorded reports fixtures that weren't recorded via
+// RecordFixture. Hand-written pages only cover what the parsers look at, so
+// they can't detect changes of proxer.me's markup.
+func TestFixturesRecorded(t *testing.T) {
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "fixtures", "*.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var handWritten []string
+	for _, fixture := range fixtures {
+		page, err := os.ReadFile(fixture)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.HasPrefix(page, []byte(recordedMarker)) {
+			handWritten = append(handWritten, filepath.Base(fixture))
+		}
+	}
+	if len(handWritten) > 0 {
+		t.Skipf("Fixtures aren't recorded, see testdata/fixtures/README.md: %s", strings.Join(handWritten, ", "))
+	}
+}
@@ -0,0 +1,81 @@
+package proxerscrape
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
+// GzipStore wraps another CacheStore, compressing all entries before they are
+// written and decompressing them on read. Entries that were written
+// uncompressed, for example by an older version, are still readable.
+type GzipStore struct {
+	Store CacheStore
+}
+
+// NewGzipStore wraps the given store.
+func NewGzipStore(store CacheStore) *GzipStore {
+	return &GzipStore{Store: store}
+}
+
+type gzipReadCloser struct {
+	*gzip.Reader
+	underlying io.Closer
+}
+
+func (reader *gzipReadCloser) Close() error {
+	reader.Reader.Close()
+	return reader.underlying.Close()
+}
+
+type bufferedReadCloser struct {
+	*bufio.Reader
+	io.Closer
+}
+
+func (store *GzipStore) Get(key string) (io.ReadCloser, CacheMetadata, error) {
+	reader, metadata, err := store.Store.Get(key)
+	if err != nil {
+		return nil, metadata, err
+	}
+
+	bufferedReader := bufio.NewReader(reader)
+	magic, err := bufferedReader.Peek(2)
+	if err != nil || magic[0] != 0x1f || magic[1] != 0x8b {
+		return &bufferedReadCloser{Reader: bufferedReader, Closer: reader}, metadata, nil
+	}
+
+	gzipReader, err := gzip.NewReader(bufferedReader)
+	if err != nil {
+		reader.Close()
+		return nil, metadata, err
+	}
+	return &gzipReadCloser{Reader: gzipReader, underlying: reader}, metadata, nil
+}
+
+func (store *GzipStore) Put(key string, data []byte, metadata CacheMetadata) error {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	if _, err := writer.Write(data); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return store.Store.Put(key, buffer.Bytes(), metadata)
+}
+
+func (store *GzipStore) Delete(key string) error {
+	return store.Store.Delete(key)
+}
+
+// Stats returns the stats of the underlying store, meaning the size is the
+// compressed size.
+func (store *GzipStore) Stats() (CacheStats, error) {
+	return store.Store.Stats()
+}
+
+func (store *GzipStore) Walk(callback func(key string, metadata CacheMetadata) error) error {
+	return store.Store.Walk(callback)
+}
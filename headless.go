@@ -0,0 +1,135 @@
+package proxerscrape
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Renderer loads a page in a headless browser, so that content which is only
+// present after executing javascript is available. Since this is expensive,
+// it should only be used for pages that are incomplete otherwise, see
+// CommandRenderer.
+type Renderer interface {
+	Render(url string) ([]byte, error)
+}
+
+// CommandRenderer renders pages via the headless mode of a locally installed
+// Chrome or Chromium, which prints the page once its javascript has run.
+type CommandRenderer struct {
+	// Command is the browser executable, such as "chromium".
+	Command string
+	// UserDataDir is the browser profile to use, for example one that is
+	// logged in to proxer.me, since the browser doesn't share the cookies
+	// of the cache. If empty, a fresh profile is used.
+	UserDataDir string
+	// Timeout limits how long rendering a single page may take. If 0,
+	// DefaultTimeout is used.
+	Timeout time.Duration
+}
+
+// Render runs the browser and returns the rendered page.
+func (renderer *CommandRenderer) Render(url string) ([]byte, error) {
+	timeout := renderer.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	args := []string{"--headless", "--disable-gpu", "--dump-dom"}
+	if renderer.UserDataDir != "" {
+		args = append(args, "--user-data-dir="+renderer.UserDataDir)
+	}
+	var stderr bytes.Buffer
+	command := exec.CommandContext(ctx, renderer.Command, append(args, url)...)
+	command.Stderr = &stderr
+	output, err := command.Output()
+	if err != nil {
+		return nil, fmt.Errorf("rendering via %s failed: %w: %s", renderer.Command, err, strings.TrimSpace(stderr.String()))
+	}
+	return output, nil
+}
+
+// MediaPageComplete checks whether a media info page contains all the data
+// required by LoadExtraData, including the tags, which are only added by
+// javascript. Entries without any tags can't be told apart from pages that
+// haven't been rendered, so they're considered incomplete as well. Pages that
+// can't be fixed by rendering, such as dead links, login walls or captchas,
+// are considered complete.
+func MediaPageComplete(data []byte) bool {
+	document, err := newDocument(bytes.NewReader(data))
+	if err != nil {
+		return true
+	}
+
+	if title := document.Find("title").First(); strings.Contains(title.Text(), "404") {
+		return true
+	}
+	if strings.HasPrefix(strings.TrimSpace(document.Find("h3").First().Text()), "Bitte logge dich ein") {
+		return true
+	}
+	if isCaptchaPage(data) {
+		return true
+	}
+
+	if requireSelectors(document, "", detailPageSelectors...) != nil {
+		return false
+	}
+	complete := false
+	document.Find("table[class=details]").First().Find("tbody > tr").EachWithBreak(func(_ int, row *goquery.Selection) bool {
+		cell := row.Find("td").First()
+		if strings.TrimSpace(cell.Find("b").First().Text()) != "Tags" {
+			return true
+		}
+		complete = tagLinks(cell.Next()).Length() > 0
+		return false
+	})
+	return complete
+}
+
+// HybridQuery wraps a media query, so that pages are retrieved via plain HTTP
+// first and only escalated to the renderer if they are incomplete. If the
+// renderer fails, the incomplete page is returned and the failure is passed
+// to the logger, which may be nil.
+func HybridQuery(
+	query func(*Media, http.Header) (*http.Response, error),
+	renderer Renderer,
+	complete func([]byte) bool,
+	logger Logger,
+) func(*Media, http.Header) (*http.Response, error) {
+	logger = loggerOrDiscard(logger)
+	return func(item *Media, header http.Header) (*http.Response, error) {
+		response, err := query(item, header)
+		if err != nil || response.StatusCode != http.StatusOK {
+			return response, err
+		}
+
+		data, err := io.ReadAll(response.Body)
+		response.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		if !complete(data) && response.Request != nil && response.Request.URL != nil {
+			url := response.Request.URL.String()
+			rendered, err := renderer.Render(url)
+			if err != nil {
+				logger.Printf("Error rendering '%s', falling back to plain page: %s\n", redactURL(url), RedactText(err.Error()))
+			} else {
+				data = rendered
+			}
+		}
+
+		response.Body = io.NopCloser(bytes.NewReader(data))
+		response.ContentLength = int64(len(data))
+		return response, nil
+	}
+}
@@ -0,0 +1,86 @@
+package proxerscrape
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+type fakeRenderer struct {
+	page    string
+	renders int
+}
+
+func (renderer *fakeRenderer) Render(url string) ([]byte, error) {
+	renderer.renders++
+	return []byte(renderer.page), nil
+}
+
+func TestHybridQuery(t *testing.T) {
+	complete := `<table class="details"><tbody><tr><td><b>Tags</b></td><td><a href="/tag/7">Schule</a></td></tr></tbody></table><span class="average">8.5</span>`
+	tests := []struct {
+		name    string
+		page    string
+		renders int
+	}{
+		{"complete", complete, 0},
+		{"incomplete", `<div id="main"></div>`, 1},
+		{"without tags", `<table class="details"><tbody><tr><td><b>Tags</b></td><td></td></tr></tbody></table><span class="average">8.5</span>`, 1},
+		{"login", `<h3>Bitte logge dich ein</h3>`, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			renderer := &fakeRenderer{page: complete}
+			query := HybridQuery(func(item *Media, header http.Header) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Request:    &http.Request{URL: &url.URL{Scheme: "https", Host: "proxer.me", Path: item.ProxerURL}},
+					Body:       io.NopCloser(strings.NewReader(test.page)),
+				}, nil
+			}, renderer, MediaPageComplete, nil)
+
+			response, err := query(&Media{ProxerURL: "/info/296"}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, _ := io.ReadAll(response.Body)
+			if renderer.renders != test.renders {
+				t.Errorf("Renders = %d, instead of %d", renderer.renders, test.renders)
+			}
+			if test.renders > 0 && string(data) != complete {
+				t.Errorf("Rendered page wasn't used: %s", data)
+			}
+		})
+	}
+}
+
+func TestCommandRenderer(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a shell")
+	}
+	// The fake browser prints its arguments instead of the rendered page.
+	browser := filepath.Join(t.TempDir(), "browser")
+	if err := os.WriteFile(browser, []byte("#!/bin/sh\necho \"$@\"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	renderer := &CommandRenderer{Command: browser, UserDataDir: "/profile"}
+	page, err := renderer.Render("https://proxer.me/info/296")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "--headless --disable-gpu --dump-dom --user-data-dir=/profile https://proxer.me/info/296\n"
+	if string(page) != expected {
+		t.Errorf("Unexpected arguments: %s", page)
+	}
+
+	renderer.Command = filepath.Join(t.TempDir(), "missing")
+	if _, err := renderer.Render("https://proxer.me/info/296"); err == nil {
+		t.Error("Expected missing browser to fail")
+	}
+}
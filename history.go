@@ -0,0 +1,239 @@
+package proxerscrape
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// WatchEvent is a single episode or chapter in the history of a user.
+type WatchEvent struct {
+	ProxerURL string
+	Title     string
+	Episode   int
+	// Language is the language of the episode as used in proxer.me URLs,
+	// for example "engsub". Empty if unknown.
+	Language  string
+	WatchedAt time.Time
+}
+
+// ProxerID returns the ID of the entry the episode belongs to, or an empty
+// string if unknown.
+func (event *WatchEvent) ProxerID() string {
+	return formatMediaID(event.ProxerURL)
+}
+
+// historyEpisodeRegex matches links to episodes and chapters, such as
+// `/watch/53/1/engsub` or `/read/1234/5/de`.
+var historyEpisodeRegex = regexp.MustCompile(`/(?:watch|read)/(\d+)/(\d+)(?:/(\w+))?`)
+
+// historyDateRegex matches the time of events, the time of day is optional.
+var historyDateRegex = regexp.MustCompile(`\d{2}\.\d{2}\.\d{4}(?:\s+\d{2}:\d{2})?`)
+
+// RetrieveHistoryRawData retrieves a page of the history of the given user,
+// starting at 1.
+func (cache *Cache) RetrieveHistoryRawData(profileID string, page int) (io.ReadCloser, CacheInvalidator, error) {
+	cacheKey := fmt.Sprintf("profile/%s/history/%d", profileID, page)
+	path := fmt.Sprintf("/user/%s/chronik?p=%d", profileID, page)
+	return cache.retrievePage(CacheEntryProfileTab, cacheKey, path, cache.ProfileTabQueryRatelimiter)
+}
+
+// ParseHistory parses a page of the history. Every row linking an episode and
+// containing a date is considered an event. The events are ordered from
+// oldest to newest.
+func ParseHistory(reader io.Reader) ([]WatchEvent, error) {
+	document, err := newDocument(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	location := proxerLocation()
+	var events []WatchEvent
+	document.Find("tr").Each(func(_ int, row *goquery.Selection) {
+		var match []string
+		var title string
+		row.Find("a[href]").EachWithBreak(func(_ int, link *goquery.Selection) bool {
+			href, _ := link.Attr("href")
+			if match = historyEpisodeRegex.FindStringSubmatch(href); match != nil {
+				title = strings.TrimSpace(normalizeText(link.Text()))
+				return false
+			}
+			return true
+		})
+		if match == nil {
+			return
+		}
+		watchedAt, found := parseEventTime(row.Text(), location)
+		if !found {
+			return
+		}
+
+		// The episode link usually only shows the number, while the title is
+		// shown via a separate link to the entry.
+		if info := row.Find("a[href*='/info/']"); info.Length() > 0 {
+			title = strings.TrimSpace(normalizeText(info.First().Text()))
+		}
+		episode, _ := strconv.Atoi(match[2])
+		events = append(events, WatchEvent{
+			ProxerURL: "/info/" + match[1],
+			Title:     title,
+			Episode:   episode,
+			Language:  match[3],
+			WatchedAt: watchedAt,
+		})
+	})
+
+	sort.SliceStable(events, func(a, b int) bool {
+		return events[a].WatchedAt.Before(events[b].WatchedAt)
+	})
+	return events, nil
+}
+
+// parseEventTime finds the first date in the text, with an optional time of
+// day, as shown in the history and the notifications.
+func parseEventTime(text string, location *time.Location) (time.Time, bool) {
+	date := historyDateRegex.FindString(text)
+	if date == "" {
+		return time.Time{}, false
+	}
+	layout := "02.01.2006"
+	if len(date) > len(layout) {
+		date = strings.Join(strings.Fields(date), " ")
+		layout = "02.01.2006 15:04"
+	}
+	parsed, err := time.ParseInLocation(layout, date, location)
+	return parsed, err == nil
+}
+
+// RetrieveHistory retrieves and parses the given amount of history pages,
+// stopping early once a page is empty. The events are ordered from oldest
+// to newest.
+func (cache *Cache) RetrieveHistory(profileID string, pages int) ([]WatchEvent, error) {
+	var events []WatchEvent
+	for page := 1; page <= pages; page++ {
+		reader, _, err := cache.RetrieveHistoryRawData(profileID, page)
+		if err != nil {
+			return events, err
+		}
+		pageEvents, err := ParseHistory(reader)
+		reader.Close()
+		if err != nil {
+			return events, err
+		}
+		if len(pageEvents) == 0 {
+			break
+		}
+		events = append(events, pageEvents...)
+	}
+	sort.SliceStable(events, func(a, b int) bool {
+		return events[a].WatchedAt.Before(events[b].WatchedAt)
+	})
+	return events, nil
+}
+
+// HistoryPeriod is the granularity of EpisodesPerPeriod.
+type HistoryPeriod string
+
+const (
+	HistoryWeek  HistoryPeriod = "week"
+	HistoryMonth HistoryPeriod = "month"
+)
+
+// periodStart returns the start of the period containing the given time.
+// Weeks start on monday.
+func (period HistoryPeriod) periodStart(t time.Time) time.Time {
+	year, month, day := t.Date()
+	if period == HistoryMonth {
+		return time.Date(year, month, 1, 0, 0, 0, 0, t.Location())
+	}
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(year, month, day-offset, 0, 0, 0, 0, t.Location())
+}
+
+// PeriodCount is the amount of events in a period.
+type PeriodCount struct {
+	Start time.Time
+	Count int
+}
+
+// EpisodesPerPeriod counts the events per week or month. Periods without
+// events are included, so that the result can be plotted directly.
+func EpisodesPerPeriod(events []WatchEvent, period HistoryPeriod) []PeriodCount {
+	if len(events) == 0 {
+		return nil
+	}
+	counts := make(map[time.Time]int)
+	first, last := events[0].WatchedAt, events[0].WatchedAt
+	for _, event := range events {
+		counts[period.periodStart(event.WatchedAt)]++
+		if event.WatchedAt.Before(first) {
+			first = event.WatchedAt
+		}
+		if event.WatchedAt.After(last) {
+			last = event.WatchedAt
+		}
+	}
+
+	var result []PeriodCount
+	for start := period.periodStart(first); !start.After(last); {
+		result = append(result, PeriodCount{Start: start, Count: counts[start]})
+		if period == HistoryMonth {
+			start = start.AddDate(0, 1, 0)
+		} else {
+			start = start.AddDate(0, 0, 7)
+		}
+	}
+	return result
+}
+
+// DetectRewatches returns how often each entry has been rewatched according
+// to the history, keyed by proxer ID. An entry counts as rewatched once for
+// every time its most watched episode has been watched again. Entries that
+// haven't been rewatched are omitted.
+func DetectRewatches(events []WatchEvent) map[string]int {
+	episodeCounts := make(map[string]map[int]int)
+	for _, event := range events {
+		id := event.ProxerID()
+		if id == "" {
+			continue
+		}
+		if episodeCounts[id] == nil {
+			episodeCounts[id] = make(map[int]int)
+		}
+		episodeCounts[id][event.Episode]++
+	}
+
+	rewatches := make(map[string]int)
+	for id, episodes := range episodeCounts {
+		var maxCount int
+		for _, count := range episodes {
+			if count > maxCount {
+				maxCount = count
+			}
+		}
+		if maxCount > 1 {
+			rewatches[id] = maxCount - 1
+		}
+	}
+	return rewatches
+}
+
+// LastWatched returns when each entry was last watched according to the
+// history, keyed by proxer ID. This is the closest proxer.me gets to a
+// modification time of a watchlist entry.
+func LastWatched(events []WatchEvent) map[string]time.Time {
+	lastWatched := make(map[string]time.Time)
+	for _, event := range events {
+		id := event.ProxerID()
+		if id != "" && event.WatchedAt.After(lastWatched[id]) {
+			lastWatched[id] = event.WatchedAt
+		}
+	}
+	return lastWatched
+}
@@ -0,0 +1,49 @@
+package proxerscrape
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const historyPage = `<html><body><table>
+<tr><th>Titel</th><th>Episode</th><th>Datum</th></tr>
+<tr><td><a href="/info/53">Naruto</a></td><td><a href="/watch/53/2/engsub">2</a></td><td>03.10.2026 20:15</td></tr>
+<tr><td><a href="/info/53">Naruto</a></td><td><a href="/watch/53/1/engsub">1</a></td><td>02.10.2026 19:00</td></tr>
+<tr><td><a href="/info/53">Naruto</a></td><td><a href="/watch/53/1/gersub">1</a></td><td>14.10.2026</td></tr>
+<tr><td><a href="/info/7">Manga</a></td><td><a href="/read/7/5/de">5</a></td><td>20.09.2026 10:00</td></tr>
+<tr><td>Kein Link</td><td>01.01.2026</td></tr>
+</table></body></html>`
+
+func TestParseHistory(t *testing.T) {
+	events, err := ParseHistory(strings.NewReader(historyPage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 4 {
+		t.Fatalf("Expected 4 events, got %d", len(events))
+	}
+	if first := events[0]; first.Title != "Manga" || first.Episode != 5 || first.Language != "de" {
+		t.Errorf("Unexpected first event: %+v", first)
+	}
+	if second := events[1]; second.ProxerID() != "53" || second.Episode != 1 || second.WatchedAt.Hour() != 19 {
+		t.Errorf("Unexpected second event: %+v", second)
+	}
+
+	weeks := EpisodesPerPeriod(events, HistoryWeek)
+	// 14.09. until 12.10.2026, since the 20.09.2026 is a sunday.
+	if len(weeks) != 5 || weeks[0].Start.Day() != 14 || weeks[0].Count != 1 || weeks[2].Count != 2 || weeks[1].Count != 0 {
+		t.Errorf("Unexpected weeks: %+v", weeks)
+	}
+	months := EpisodesPerPeriod(events, HistoryMonth)
+	if len(months) != 2 || months[0].Start.Month() != time.September || months[1].Count != 3 {
+		t.Errorf("Unexpected months: %+v", months)
+	}
+
+	if rewatches := DetectRewatches(events); len(rewatches) != 1 || rewatches["53"] != 1 {
+		t.Errorf("Unexpected rewatches: %v", rewatches)
+	}
+	if lastWatched := LastWatched(events); len(lastWatched) != 2 || lastWatched["53"].Day() != 14 {
+		t.Errorf("Unexpected last watched: %v", lastWatched)
+	}
+}
@@ -1,28 +1,134 @@
 package proxerscrape
 
 import (
+	"io"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 )
 
-func QueryDirectly(url string) (*http.Response, error) {
-	request, err := http.NewRequest(http.MethodGet, url, nil)
+// DefaultUserAgent identifies the scraper, so that proxer.me can tell it
+// apart from regular browsers.
+const DefaultUserAgent = "proxerscrape (+https://github.com/Bios-Marcel/proxerscrape)"
+
+// DefaultTimeout is the timeout of the http.Client created by NewHTTPClient
+// if none is specified.
+const DefaultTimeout = 30 * time.Second
+
+// DefaultBaseURL is the URL of proxer.me, which all paths are relative to,
+// unless a Client is configured otherwise.
+const DefaultBaseURL = "https://proxer.me"
+
+// Client performs requests against proxer.me.
+type Client struct {
+	// BaseURL is prepended to all paths, such as `/info/296`. This allows
+	// using a mirror, a local proxy or a test server. If empty,
+	// DefaultBaseURL is used.
+	BaseURL string
+	// HTTPClient performs the actual requests. Cookies, such as the login,
+	// are taken from its jar. If nil, a client with DefaultTimeout and
+	// without cookies is used.
+	HTTPClient *http.Client
+	// UserAgent is sent with every request. If empty, DefaultUserAgent is
+	// used.
+	UserAgent string
+	// LoginCookie is sent with every request if set.
+	//
+	// Deprecated: Add the cookie to the jar of HTTPClient instead, see
+	// NewLoginCookie and NewCookieJar.
+	LoginCookie *http.Cookie
+}
+
+var defaultHTTPClient = &http.Client{Timeout: DefaultTimeout}
+
+// NewHTTPClient creates an http.Client with the given timeout, which
+// optionally routes all requests through a proxy. The proxy is a URL, such
+// as `http://localhost:8080` or `socks5://localhost:1080`.
+func NewHTTPClient(timeout time.Duration, proxy string) (*http.Client, error) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if proxy != "" {
+		proxyURL, err := url.Parse(proxy)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+	}, nil
+}
+
+// NewLoginCookie creates the cookie proxer.me uses for remembering a login,
+// which is usually called `joomla_remember_me_XXX`.
+func NewLoginCookie(key, value string) *http.Cookie {
+	return &http.Cookie{
+		Name:     key,
+		Value:    value,
+		Path:     "/",
+		Domain:   "proxer.me",
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	}
+}
+
+// QueryDirectly performs a GET request against the given URL without any
+// login, sending the given additional headers, which may be nil.
+func QueryDirectly(url string, header http.Header) (*http.Response, error) {
+	return (&Client{}).Query(url, header)
+}
+
+// URL returns the absolute URL of the given path, such as `/info/296`.
+func (client *Client) URL(path string) string {
+	baseURL := client.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return strings.TrimSuffix(baseURL, "/") + path
+}
+
+// Query performs a GET request against the given URL, sending the given
+// additional headers, which may be nil.
+func (client *Client) Query(url string, header http.Header) (*http.Response, error) {
+	return client.do(http.MethodGet, url, header, nil)
+}
+
+// PostForm performs a POST request against the given URL, sending the form
+// URL encoded.
+func (client *Client) PostForm(url string, form url.Values) (*http.Response, error) {
+	header := http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}}
+	return client.do(http.MethodPost, url, header, strings.NewReader(form.Encode()))
+}
+
+func (client *Client) do(method, url string, header http.Header, body io.Reader) (*http.Response, error) {
+	request, err := http.NewRequest(method, url, body)
 	if err != nil {
 		return nil, err
 	}
-
-	if loginCookieKey != "" && loginCookieValue != "" {
-		request.AddCookie(&http.Cookie{
-			Name:     loginCookieKey,
-			Value:    loginCookieValue,
-			Path:     "/",
-			Domain:   "proxer.me",
-			HttpOnly: true,
-			Secure:   true,
-			SameSite: http.SameSiteStrictMode,
-		})
+	for key, values := range header {
+		request.Header[key] = values
 	}
 
-	//NOTE Adding the cookies for showing tags here doesn't work.
+	userAgent := client.UserAgent
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+	request.Header.Set("User-Agent", userAgent)
 
-	return http.DefaultClient.Do(request)
+	// Apart from the deprecated LoginCookie, cookies aren't added to the
+	// request manually, as cookies set by proxer.me, for example for showing
+	// tags, would otherwise get lost.
+	if client.LoginCookie != nil {
+		request.AddCookie(client.LoginCookie)
+	}
+	httpClient := client.HTTPClient
+	if httpClient == nil {
+		httpClient = defaultHTTPClient
+	}
+	return httpClient.Do(request)
 }
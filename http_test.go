@@ -0,0 +1,46 @@
+package proxerscrape
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_UserAgent(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		received = request.UserAgent()
+	}))
+	defer server.Close()
+
+	for _, userAgent := range []string{"", "custom"} {
+		client := &Client{HTTPClient: server.Client(), UserAgent: userAgent}
+		response, err := client.Query(server.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+
+		expected := userAgent
+		if expected == "" {
+			expected = DefaultUserAgent
+		}
+		if received != expected {
+			t.Errorf("User-Agent = %s, instead of %s", received, expected)
+		}
+	}
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	client, err := NewHTTPClient(0, "socks5://localhost:1080")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.Timeout != DefaultTimeout {
+		t.Errorf("Timeout = %s, instead of %s", client.Timeout, DefaultTimeout)
+	}
+
+	if _, err := NewHTTPClient(0, "://invalid"); err == nil {
+		t.Error("Invalid proxy was accepted")
+	}
+}
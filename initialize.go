@@ -0,0 +1,45 @@
+package proxerscrape
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var (
+	initializeLock sync.Mutex
+	// initializedDirs are the cache directories the shared state has
+	// already been set up for.
+	initializedDirs = make(map[string]bool)
+)
+
+// Initialize sets up the state shared by all caches using the given cache
+// directory. The directory is created and the ratelimits of previous runs are
+// restored, since restarting right after hitting the limit would otherwise
+// trip proxers captcha wall immediately.
+//
+// Importing the package has no side effects, instead NewCache and
+// CreateDefaultCache call Initialize lazily. Calling it multiple times, even
+// concurrently, is safe and only does the work once per directory.
+func Initialize(cacheDir string) error {
+	initializeLock.Lock()
+	defer initializeLock.Unlock()
+
+	if initializedDirs[cacheDir] {
+		return nil
+	}
+	if err := os.MkdirAll(cacheDir, os.ModePerm); err != nil {
+		return err
+	}
+	for name, limiter := range map[string]*Limiter{
+		"anime": animeRateLimiter,
+		"manga": mangaRateLImiter,
+		"user":  userRateLImiter,
+	} {
+		if err := limiter.Persist(filepath.Join(cacheDir, "ratelimit", name+".json")); err != nil {
+			return err
+		}
+	}
+	initializedDirs[cacheDir] = true
+	return nil
+}
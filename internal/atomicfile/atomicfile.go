@@ -0,0 +1,81 @@
+// Package atomicfile writes files in a way that a crash or kill in the middle
+// of writing never leaves a partially written file behind.
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// tempPattern returns the pattern used for temporary files of the given
+// path. Temporary files are hidden and in the same directory, since renaming
+// is only atomic within the same file system.
+func tempPattern(path string) string {
+	return "." + filepath.Base(path) + ".tmp-*"
+}
+
+// WriteFile writes the data to a temporary file next to the given path and
+// then renames it to the given path. Readers therefore either see the old or
+// the new content, but never a mix of both. Missing directories are created.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	directory := filepath.Dir(path)
+	if err := os.MkdirAll(directory, os.ModePerm); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(directory, tempPattern(path))
+	if err != nil {
+		return err
+	}
+	// On success, the file has already been renamed and this is a no-op.
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	// Without syncing, the rename could be persisted before the data.
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(file.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// leftoverAge is how old a temporary file has to be to count as left behind.
+// Younger ones might still be written by another process, such as a daemon
+// running next to a command.
+const leftoverAge = 10 * time.Minute
+
+// Recover removes temporary files left behind by a WriteFile call for the
+// given path that was interrupted. The file at path itself is untouched, as
+// it still contains the last complete write.
+func Recover(path string) error {
+	leftovers, err := filepath.Glob(filepath.Join(filepath.Dir(path), tempPattern(path)))
+	if err != nil {
+		return err
+	}
+	for _, leftover := range leftovers {
+		info, err := os.Stat(leftover)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if time.Since(info.ModTime()) < leftoverAge {
+			continue
+		}
+		if err := os.Remove(leftover); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
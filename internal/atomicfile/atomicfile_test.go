@@ -0,0 +1,51 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "data.json")
+	if err := WriteFile(path, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(path, []byte("new"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new" {
+		t.Errorf("Content = %s, instead of new", data)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("Temporary files have been left behind: %v", entries)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	os.WriteFile(path, []byte("complete"), 0o644)
+	leftover := filepath.Join(filepath.Dir(path), ".data.json.tmp-123")
+	os.WriteFile(leftover, []byte("parti"), 0o644)
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(leftover, old, old)
+	// Another process might still be writing this one.
+	inFlight := filepath.Join(filepath.Dir(path), ".data.json.tmp-456")
+	os.WriteFile(inFlight, []byte("writ"), 0o644)
+
+	if err := Recover(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+		t.Error("Leftover wasn't removed")
+	}
+	if _, err := os.Stat(inFlight); err != nil {
+		t.Errorf("Temporary file in use was removed: %s", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "complete" {
+		t.Errorf("Content = %s, instead of complete", data)
+	}
+}
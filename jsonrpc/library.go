@@ -0,0 +1,153 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/Bios-Marcel/proxerscrape"
+	"github.com/Bios-Marcel/proxerscrape/mapping"
+)
+
+// Entry is the JSON representation of a watchlist entry.
+type Entry struct {
+	ID              string                    `json:"id"`
+	Title           string                    `json:"title"`
+	Type            proxerscrape.MediaType    `json:"type"`
+	Status          proxerscrape.Status       `json:"status"`
+	Category        proxerscrape.ListCategory `json:"category"`
+	EpisodesWatched uint16                    `json:"episodesWatched"`
+	EpisodeCount    uint16                    `json:"episodeCount"`
+	UserRating      uint8                     `json:"userRating,omitempty"`
+	Rating          float64                   `json:"rating,omitempty"`
+	// Unavailable and RestrictedAccess are only known once the extra data
+	// has been loaded, see proxerscrape.Media.
+	Unavailable      bool `json:"unavailable,omitempty"`
+	RestrictedAccess bool `json:"restrictedAccess,omitempty"`
+}
+
+func newEntry(item *proxerscrape.Media) Entry {
+	return Entry{
+		ID:               item.ProxerID(),
+		Title:            item.Title,
+		Type:             item.Type,
+		Status:           item.Status,
+		Category:         item.Category,
+		EpisodesWatched:  item.EpisodesWatched,
+		EpisodeCount:     item.EpisodeCount,
+		UserRating:       item.UserRating,
+		Rating:           item.Rating,
+		Unavailable:      item.Unavailable,
+		RestrictedAccess: item.RestrictedAccess,
+	}
+}
+
+// Library provides the data the library methods operate on.
+type Library struct {
+	// Watchlist returns the current watchlist of the user.
+	Watchlist func() (proxerscrape.Watchlist, error)
+	// RetrieveRawData is used for loading ratings when recommending.
+	RetrieveRawData proxerscrape.MediaRawDataRetriever
+	// Recommender scores the candidates for `getRecommendations`. If nil,
+	// candidates are ranked by their rating.
+	Recommender proxerscrape.Recommender
+}
+
+// RegisterLibrary registers the query methods `searchList`, `getStats` and
+// `getRecommendations` on the server.
+func RegisterLibrary(server *Server, library *Library) {
+	server.Register("searchList", library.searchList)
+	server.Register("getStats", library.getStats)
+	server.Register("getRecommendations", library.getRecommendations)
+}
+
+type searchParams struct {
+	Query    string                    `json:"query"`
+	Category proxerscrape.ListCategory `json:"category,omitempty"`
+}
+
+func (library *Library) searchList(params json.RawMessage) (any, error) {
+	var search searchParams
+	if err := DecodeParams(params, &search); err != nil {
+		return nil, err
+	}
+	watchlist, err := library.Watchlist()
+	if err != nil {
+		return nil, err
+	}
+
+	query := mapping.NormalizeTitle(search.Query)
+	entries := []Entry{}
+	for _, item := range watchlist.All() {
+		if search.Category != "" && item.Category != search.Category {
+			continue
+		}
+		if strings.Contains(mapping.NormalizeTitle(item.Title), query) {
+			entries = append(entries, newEntry(item))
+		}
+	}
+	return entries, nil
+}
+
+// Stats summarises the watchlist.
+type Stats struct {
+	Entries         map[proxerscrape.ListCategory]int `json:"entries"`
+	EpisodesWatched uint64                            `json:"episodesWatched"`
+}
+
+func (library *Library) getStats(params json.RawMessage) (any, error) {
+	watchlist, err := library.Watchlist()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := Stats{Entries: make(map[proxerscrape.ListCategory]int)}
+	for _, category := range proxerscrape.ListCategories {
+		stats.Entries[category] = len(watchlist.Category(category).Data)
+	}
+	for _, item := range watchlist.All() {
+		stats.EpisodesWatched += uint64(item.EpisodesWatched)
+	}
+	return stats, nil
+}
+
+type recommendationParams struct {
+	Limit int `json:"limit,omitempty"`
+}
+
+// getRecommendations returns the best scored entries of the to-watch list,
+// that have already started airing.
+func (library *Library) getRecommendations(params json.RawMessage) (any, error) {
+	recommendation := recommendationParams{Limit: 5}
+	if err := DecodeParams(params, &recommendation); err != nil {
+		return nil, err
+	}
+	watchlist, err := library.Watchlist()
+	if err != nil {
+		return nil, err
+	}
+	// Entries that failed to load, for example because they require a
+	// login, simply have no rating and thus end up last.
+	var extraDataError proxerscrape.ExtraDataError
+	if err := watchlist.ToWatch.LoadExtraData(library.RetrieveRawData); err != nil && !errors.As(err, &extraDataError) {
+		return nil, err
+	}
+
+	recommender := library.Recommender
+	if recommender == nil {
+		recommender = proxerscrape.RatingRecommender{}
+	}
+	recommendations, err := proxerscrape.Recommend(&watchlist, recommender)
+	if err != nil {
+		return nil, err
+	}
+	if recommendation.Limit > 0 && len(recommendations) > recommendation.Limit {
+		recommendations = recommendations[:recommendation.Limit]
+	}
+
+	entries := make([]Entry, 0, len(recommendations))
+	for _, candidate := range recommendations {
+		entries = append(entries, newEntry(candidate.Media))
+	}
+	return entries, nil
+}
@@ -0,0 +1,158 @@
+// Package jsonrpc exposes the user's library via JSON-RPC 2.0, so that
+// automation tools and assistants can query it programmatically. Requests
+// can either be sent via HTTP or as newline delimited JSON via a stream,
+// such as stdin and stdout.
+package jsonrpc
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// Error codes as defined by the JSON-RPC 2.0 specification.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// Request is a single JSON-RPC call. Requests without an ID are
+// notifications and don't receive a response.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is the answer to a Request, either holding a result or an error.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error is a JSON-RPC error object.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (err *Error) Error() string {
+	return err.Message
+}
+
+// Method handles the params of a call and returns its result.
+type Method func(params json.RawMessage) (any, error)
+
+// Server dispatches calls to its registered methods.
+type Server struct {
+	methods map[string]Method
+}
+
+// NewServer creates a server without any methods, see Register.
+func NewServer() *Server {
+	return &Server{methods: make(map[string]Method)}
+}
+
+// Register makes the method callable under the given name.
+func (server *Server) Register(name string, method Method) {
+	server.methods[name] = method
+}
+
+// Handle performs a single call. Nil is returned for notifications.
+func (server *Server) Handle(request Request) *Response {
+	response := &Response{JSONRPC: "2.0", ID: request.ID}
+	if request.JSONRPC != "2.0" || request.Method == "" {
+		response.Error = &Error{Code: CodeInvalidRequest, Message: "invalid request"}
+		return response
+	}
+
+	method, present := server.methods[request.Method]
+	if !present {
+		response.Error = &Error{Code: CodeMethodNotFound, Message: "method '" + request.Method + "' not found"}
+	} else if result, err := method(request.Params); err != nil {
+		var rpcError *Error
+		if errors.As(err, &rpcError) {
+			response.Error = rpcError
+		} else {
+			response.Error = &Error{Code: CodeInternalError, Message: err.Error()}
+		}
+	} else {
+		response.Result = result
+	}
+
+	if request.ID == nil {
+		return nil
+	}
+	return response
+}
+
+// ServeHTTP handles a single call per POST request.
+func (server *Server) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		writer.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var response *Response
+	var rpcRequest Request
+	if err := json.NewDecoder(request.Body).Decode(&rpcRequest); err != nil {
+		response = &Response{JSONRPC: "2.0", Error: &Error{Code: CodeParseError, Message: err.Error()}}
+	} else {
+		response = server.Handle(rpcRequest)
+	}
+
+	if response == nil {
+		writer.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(writer).Encode(response)
+}
+
+// ServeStream reads one call per line and writes one response per line,
+// until the reader is exhausted.
+func (server *Server) ServeStream(reader io.Reader, writer io.Writer) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	encoder := json.NewEncoder(writer)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var response *Response
+		var request Request
+		if err := json.Unmarshal(scanner.Bytes(), &request); err != nil {
+			response = &Response{JSONRPC: "2.0", Error: &Error{Code: CodeParseError, Message: err.Error()}}
+		} else {
+			response = server.Handle(request)
+		}
+		if response == nil {
+			continue
+		}
+		if err := encoder.Encode(response); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// DecodeParams unmarshals the params, treating missing params as empty.
+// Invalid params result in an Error with CodeInvalidParams.
+func DecodeParams(params json.RawMessage, target any) error {
+	if len(params) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(params, target); err != nil {
+		return &Error{Code: CodeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Bios-Marcel/proxerscrape"
+)
+
+const detailPage = `<html><head><title>Detail</title></head><body><table class="details"><tbody></tbody></table><span class="average">%s</span></body></html>`
+
+func newTestServer() *Server {
+	server := NewServer()
+	RegisterLibrary(server, &Library{
+		Watchlist: func() (proxerscrape.Watchlist, error) {
+			watchlist := proxerscrape.Watchlist{}
+			watchlist.Watched.Data = []*proxerscrape.Media{
+				{ProxerURL: "/info/1", Title: "Shingeki no Kyojin", EpisodesWatched: 25, Category: proxerscrape.CategoryWatched},
+			}
+			watchlist.ToWatch.Data = []*proxerscrape.Media{
+				{ProxerURL: "/info/2", Title: "Tsurune", Category: proxerscrape.CategoryToWatch},
+				{ProxerURL: "/info/3", Title: "Vinland Saga", Category: proxerscrape.CategoryToWatch},
+				{ProxerURL: "/info/4", Title: "Upcoming", Category: proxerscrape.CategoryToWatch, Status: proxerscrape.StatusPreAiring},
+			}
+			return watchlist, nil
+		},
+		RetrieveRawData: func(item *proxerscrape.Media) (io.ReadCloser, proxerscrape.CacheInvalidator, error) {
+			rating := "7.5"
+			if item.ProxerURL == "/info/3" {
+				rating = "8.9"
+			}
+			page := strings.Replace(detailPage, "%s", rating, 1)
+			return io.NopCloser(strings.NewReader(page)), func() error { return nil }, nil
+		},
+	})
+	return server
+}
+
+func TestServeStream(t *testing.T) {
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"searchList","params":{"query":"kyojin"}}`,
+		`{"jsonrpc":"2.0","method":"getStats"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"unknown"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"getRecommendations","params":{"limit":1}}`,
+		`not json`,
+	}, "\n")
+	var output bytes.Buffer
+	if err := newTestServer().ServeStream(strings.NewReader(input), &output); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected 4 responses, since notifications aren't answered, got:\n%s", output.String())
+	}
+
+	var search struct{ Result []Entry }
+	json.Unmarshal([]byte(lines[0]), &search)
+	if len(search.Result) != 1 || search.Result[0].ID != "1" {
+		t.Errorf("Unexpected search result: %s", lines[0])
+	}
+
+	var unknown Response
+	json.Unmarshal([]byte(lines[1]), &unknown)
+	if unknown.Error == nil || unknown.Error.Code != CodeMethodNotFound {
+		t.Errorf("Unexpected response for unknown method: %s", lines[1])
+	}
+
+	var recommendations struct{ Result []Entry }
+	json.Unmarshal([]byte(lines[2]), &recommendations)
+	if len(recommendations.Result) != 1 || recommendations.Result[0].Title != "Vinland Saga" {
+		t.Errorf("Unexpected recommendations: %s", lines[2])
+	}
+
+	var parseError Response
+	json.Unmarshal([]byte(lines[3]), &parseError)
+	if parseError.Error == nil || parseError.Error.Code != CodeParseError {
+		t.Errorf("Unexpected response for invalid JSON: %s", lines[3])
+	}
+}
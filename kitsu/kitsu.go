@@ -0,0 +1,379 @@
+// Package kitsu pushes proxer.me watchlists to the Kitsu library of a user via
+// the Kitsu JSON:API, without the detour through an XML export.
+package kitsu
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Bios-Marcel/proxerscrape"
+	"github.com/Bios-Marcel/proxerscrape/mapping"
+	"github.com/Bios-Marcel/proxerscrape/reconcile"
+)
+
+// BaseURL is the location of the Kitsu API.
+const BaseURL = "https://kitsu.app/api"
+
+const mediaType = "application/vnd.api+json"
+
+// ErrUnauthorized is returned if the login failed or the token has expired.
+var ErrUnauthorized = errors.New("kitsu login is invalid or expired")
+
+// Client talks to the Kitsu API on behalf of a user. Use Login to obtain a
+// token.
+type Client struct {
+	// Token is the OAuth access token of the user.
+	Token      string
+	HTTPClient *http.Client
+	// BaseURL defaults to BaseURL.
+	BaseURL string
+	// Limiter keeps us from hammering Kitsu, which doesn't document its
+	// ratelimit. If nil, requests aren't limited.
+	Limiter *proxerscrape.Limiter
+
+	userID string
+	// entryIDs are the library entry IDs by anime ID, filled by List.
+	entryIDs map[string]string
+}
+
+// NewClient creates a client with a conservative limit of one request per
+// second.
+func NewClient() *Client {
+	return &Client{
+		Limiter: proxerscrape.NewLimiter(60, time.Minute),
+	}
+}
+
+func (client *Client) baseURL() string {
+	if client.BaseURL == "" {
+		return BaseURL
+	}
+	return client.BaseURL
+}
+
+func (client *Client) do(request *http.Request) (*http.Response, error) {
+	if client.Limiter != nil {
+		client.Limiter.Wait()
+	}
+	httpClient := client.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode == http.StatusUnauthorized {
+		response.Body.Close()
+		return nil, ErrUnauthorized
+	}
+	return response, nil
+}
+
+// Login obtains an access token via the OAuth password grant.
+func (client *Client) Login(username, password string) error {
+	form := url.Values{}
+	form.Set("grant_type", "password")
+	form.Set("username", username)
+	form.Set("password", password)
+	request, err := http.NewRequest(http.MethodPost, client.baseURL()+"/oauth/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	response, err := client.do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	// Invalid credentials are reported as bad request.
+	if response.StatusCode == http.StatusBadRequest {
+		return ErrUnauthorized
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("kitsu login failed with status %d", response.StatusCode)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&token); err != nil {
+		return err
+	}
+	client.Token = token.AccessToken
+	client.userID = ""
+	client.entryIDs = nil
+	return nil
+}
+
+// resource is a JSON:API resource object.
+type resource struct {
+	ID            string                  `json:"id,omitempty"`
+	Type          string                  `json:"type"`
+	Attributes    map[string]any          `json:"attributes,omitempty"`
+	Relationships map[string]relationship `json:"relationships,omitempty"`
+}
+
+type relationship struct {
+	Data resource `json:"data"`
+}
+
+// document is a JSON:API document listing resources.
+type document struct {
+	Data     []resource `json:"data"`
+	Included []resource `json:"included"`
+	Links    struct {
+		Next string `json:"next"`
+	} `json:"links"`
+}
+
+// request performs an API request, encoding and decoding the JSON:API
+// document. Both body and result may be nil. The result is usually a
+// document, unless a single resource is returned.
+func (client *Client) request(method, path string, body *resource, result any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(map[string]any{"data": body})
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	request, err := http.NewRequest(method, client.baseURL()+"/edge"+path, reader)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", mediaType)
+	request.Header.Set("Content-Type", mediaType)
+	request.Header.Set("Authorization", "Bearer "+client.Token)
+	response, err := client.do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("kitsu request %s %s failed with status %d", method, path, response.StatusCode)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(response.Body).Decode(result)
+}
+
+// UserID returns the ID of the logged in user.
+func (client *Client) UserID() (string, error) {
+	if client.userID != "" {
+		return client.userID, nil
+	}
+	var users document
+	if err := client.request(http.MethodGet, "/users?filter[self]=true&fields[users]=id", nil, &users); err != nil {
+		return "", err
+	}
+	if len(users.Data) == 0 {
+		return "", ErrUnauthorized
+	}
+	client.userID = users.Data[0].ID
+	return client.userID, nil
+}
+
+// Status is the status of a library entry on Kitsu.
+type Status string
+
+const (
+	StatusCurrent   Status = "current"
+	StatusPlanned   Status = "planned"
+	StatusCompleted Status = "completed"
+	StatusDropped   Status = "dropped"
+)
+
+// statuses translates proxer categories into the Kitsu status.
+var statuses = map[proxerscrape.ListCategory]Status{
+	proxerscrape.CategoryWatched:           StatusCompleted,
+	proxerscrape.CategoryCurrentlyWatching: StatusCurrent,
+	proxerscrape.CategoryToWatch:           StatusPlanned,
+	proxerscrape.CategoryStoppedWatching:   StatusDropped,
+}
+
+// Entry is the state of a library entry on Kitsu.
+type Entry struct {
+	Status   Status
+	Progress uint16
+	// Score is the rating on a scale from 1 to 10, 0 meaning unrated.
+	// Unrated entries keep their rating on Kitsu.
+	Score uint8
+	// ReconsumeCount is how often the entry has been rewatched. 0 keeps the
+	// count on Kitsu.
+	ReconsumeCount uint16
+	// Notes are the user's notes on the entry. Empty notes are left
+	// untouched on Kitsu.
+	Notes string
+}
+
+func (entry Entry) attributes() map[string]any {
+	attributes := map[string]any{
+		"status":   entry.Status,
+		"progress": entry.Progress,
+	}
+	// Omitted attributes are left untouched, while null or 0 would clear
+	// them. Kitsu rates from 2 to 20.
+	if entry.Score > 0 {
+		attributes["ratingTwenty"] = int(entry.Score) * 2
+	}
+	if entry.ReconsumeCount > 0 {
+		attributes["reconsumeCount"] = entry.ReconsumeCount
+	}
+	if entry.Notes != "" {
+		attributes["notes"] = entry.Notes
+	}
+	return attributes
+}
+
+// entryID returns the ID of the library entry of the given Kitsu anime, or
+// an empty string if it isn't in the library. Once the library was
+// retrieved via List, no further requests are needed.
+func (client *Client) entryID(animeID string) (string, error) {
+	if client.entryIDs != nil {
+		return client.entryIDs[animeID], nil
+	}
+	userID, err := client.UserID()
+	if err != nil {
+		return "", err
+	}
+
+	var existing document
+	query := url.Values{}
+	query.Set("filter[userId]", userID)
+	query.Set("filter[animeId]", animeID)
+	query.Set("fields[libraryEntries]", "id")
+	if err := client.request(http.MethodGet, "/library-entries?"+query.Encode(), nil, &existing); err != nil {
+		return "", err
+	}
+	if len(existing.Data) == 0 {
+		return "", nil
+	}
+	return existing.Data[0].ID, nil
+}
+
+// update changes the given attributes of the library entry of the given
+// Kitsu anime, leaving all others untouched.
+func (client *Client) update(animeID string, attributes map[string]any) error {
+	id, err := client.entryID(animeID)
+	if err != nil {
+		return err
+	}
+	if id == "" {
+		return fmt.Errorf("anime %s isn't in the kitsu library", animeID)
+	}
+	return client.request(http.MethodPatch, "/library-entries/"+id, &resource{
+		ID:         id,
+		Type:       "libraryEntries",
+		Attributes: attributes,
+	}, nil)
+}
+
+// SaveEntry creates or updates the library entry of the given Kitsu anime.
+func (client *Client) SaveEntry(animeID string, entry Entry) error {
+	id, err := client.entryID(animeID)
+	if err != nil {
+		return err
+	}
+	if id != "" {
+		return client.update(animeID, entry.attributes())
+	}
+
+	userID, err := client.UserID()
+	if err != nil {
+		return err
+	}
+	var created struct {
+		Data resource `json:"data"`
+	}
+	if err := client.request(http.MethodPost, "/library-entries", &resource{
+		Type:       "libraryEntries",
+		Attributes: entry.attributes(),
+		Relationships: map[string]relationship{
+			"user":  {Data: resource{ID: userID, Type: "users"}},
+			"anime": {Data: resource{ID: animeID, Type: "anime"}},
+		},
+	}, &created); err != nil {
+		return err
+	}
+	if client.entryIDs != nil {
+		client.entryIDs[animeID] = created.Data.ID
+	}
+	return nil
+}
+
+// Sync pushes the status, progress and score of all anime in the watchlist
+// to Kitsu. The library is retrieved once and the changes are planned just
+// like a push via reconcile.PlanSync, so only differing fields are changed
+// and ratings are never cleared. Entries without a Kitsu mapping and those
+// that aren't exportable, see proxerscrape.Media.Exportable, are skipped and
+// returned. Reviews and rewatches, which the plan doesn't cover, are set
+// afterwards for entries where they differ from Kitsu. Rewatches are taken
+// from the user data, which may be nil.
+func (client *Client) Sync(watchlist *proxerscrape.Watchlist, store *mapping.Store, userData *proxerscrape.UserData) ([]*proxerscrape.Media, error) {
+	var skipped []*proxerscrape.Media
+	var synced proxerscrape.Watchlist
+	for _, category := range proxerscrape.ListCategories {
+		target := synced.Category(category)
+		for _, item := range watchlist.Category(category).Data {
+			if !item.Type.IsAnime() {
+				continue
+			}
+			if !item.Exportable() {
+				skipped = append(skipped, item)
+				continue
+			}
+			target.Data = append(target.Data, item)
+		}
+	}
+
+	remote, err := client.List()
+	if err != nil {
+		return skipped, err
+	}
+	report := reconcile.Reconcile(&synced, remote, store, mapping.ServiceKitsu)
+	skipped = append(skipped, report.Unmapped...)
+	// Pushing never changes proxer.me, so there's no need for a writer.
+	plan := reconcile.PlanSync(report, store, reconcile.DirectionPush)
+	if _, err := plan.Execute(nil, client); err != nil {
+		return skipped, err
+	}
+
+	remoteByID := make(map[string]reconcile.RemoteEntry, len(remote))
+	for _, entry := range remote {
+		remoteByID[entry.ExternalID] = entry
+	}
+	for _, item := range synced.All() {
+		kitsuMapping, present := store.Get(item.ProxerID(), mapping.ServiceKitsu)
+		if !present {
+			continue
+		}
+		// Like SaveEntry, zero values keep what's on Kitsu.
+		current := remoteByID[kitsuMapping.ExternalID]
+		attributes := make(map[string]any)
+		if userData != nil {
+			if rewatched := userData.Rewatched(item); rewatched > 0 && rewatched != current.Repeat {
+				attributes["reconsumeCount"] = rewatched
+			}
+		}
+		if item.Review != "" && item.Review != current.Notes {
+			attributes["notes"] = item.Review
+		}
+		if len(attributes) == 0 {
+			continue
+		}
+		if err := client.update(kitsuMapping.ExternalID, attributes); err != nil {
+			return skipped, fmt.Errorf("error syncing '%s': %w", item.Title, err)
+		}
+	}
+	return skipped, nil
+}
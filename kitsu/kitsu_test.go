@@ -0,0 +1,125 @@
+package kitsu
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Bios-Marcel/proxerscrape"
+	"github.com/Bios-Marcel/proxerscrape/mapping"
+)
+
+func TestSync(t *testing.T) {
+	var created map[string]any
+	var updated []map[string]any
+	var serverURL string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth/token", func(writer http.ResponseWriter, request *http.Request) {
+		if request.FormValue("grant_type") != "password" || request.FormValue("password") != "secret" {
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		writer.Write([]byte(`{"access_token":"token"}`))
+	})
+	mux.HandleFunc("/edge/users", func(writer http.ResponseWriter, request *http.Request) {
+		if request.Header.Get("Authorization") != "Bearer token" {
+			writer.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writer.Write([]byte(`{"data":[{"id":"7","type":"users"}]}`))
+	})
+	mux.HandleFunc("/edge/library-entries", func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method == http.MethodPost {
+			var document struct {
+				Data map[string]any `json:"data"`
+			}
+			json.NewDecoder(request.Body).Decode(&document)
+			created = document.Data
+			writer.WriteHeader(http.StatusCreated)
+			writer.Write([]byte(`{"data":{"id":"101","type":"libraryEntries"}}`))
+			return
+		}
+		if request.URL.Query().Get("filter[animeId]") != "" {
+			t.Errorf("Unexpected lookup of a single entry: %s", request.URL)
+			return
+		}
+		if request.URL.Query().Get("filter[userId]") != "7" {
+			writer.Write([]byte(`{"data":[]}`))
+			return
+		}
+		// The library is split into two pages.
+		if request.URL.Query().Get("page[offset]") == "" {
+			writer.Write([]byte(`{
+				"data":[{"id":"99","type":"libraryEntries","attributes":{"status":"completed","progress":12,"ratingTwenty":null},"relationships":{"anime":{"data":{"id":"2","type":"anime"}}}}],
+				"included":[{"id":"2","type":"anime","attributes":{"canonicalTitle":"Existing"}}],
+				"links":{"next":"` + serverURL + `/edge/library-entries?filter%5BuserId%5D=7&page%5Boffset%5D=1"}}`))
+			return
+		}
+		writer.Write([]byte(`{
+			"data":[{"id":"100","type":"libraryEntries","attributes":{"status":"completed","progress":10,"ratingTwenty":14,"notes":"Fine"},"relationships":{"anime":{"data":{"id":"5","type":"anime"}}}}],
+			"included":[{"id":"5","type":"anime","attributes":{"canonicalTitle":"Same"}}]}`))
+	})
+	mux.HandleFunc("/edge/library-entries/", func(writer http.ResponseWriter, request *http.Request) {
+		var document struct {
+			Data map[string]any `json:"data"`
+		}
+		json.NewDecoder(request.Body).Decode(&document)
+		updated = append(updated, document.Data)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	serverURL = server.URL
+
+	client := &Client{BaseURL: server.URL}
+	if err := client.Login("user", "wrong"); err != ErrUnauthorized {
+		t.Errorf("Expected ErrUnauthorized, got %v", err)
+	}
+	if err := client.Login("user", "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	watchlist := proxerscrape.Watchlist{}
+	watchlist.Watched.Data = []*proxerscrape.Media{
+		{ProxerURL: "/info/1", Title: "New", Type: proxerscrape.MediaTypeSeries, Category: proxerscrape.CategoryWatched, EpisodesWatched: 12, UserRating: 9, Review: "Good"},
+		{ProxerURL: "/info/2", Title: "Existing", Type: proxerscrape.MediaTypeSeries, Category: proxerscrape.CategoryWatched, EpisodesWatched: 24},
+		{ProxerURL: "/info/3", Title: "Unmapped", Type: proxerscrape.MediaTypeSeries},
+		{ProxerURL: "/info/4", Title: "Gone", Type: proxerscrape.MediaTypeSeries, Unavailable: true},
+		{ProxerURL: "/info/5", Title: "Same", Type: proxerscrape.MediaTypeSeries, Category: proxerscrape.CategoryWatched, EpisodesWatched: 10, UserRating: 7, Review: "Fine"},
+	}
+	store := &mapping.Store{}
+	store.Set("1", mapping.ServiceKitsu, mapping.Mapping{ExternalID: "1"})
+	store.Set("2", mapping.ServiceKitsu, mapping.Mapping{ExternalID: "2"})
+	store.Set("4", mapping.ServiceKitsu, mapping.Mapping{ExternalID: "4"})
+	store.Set("5", mapping.ServiceKitsu, mapping.Mapping{ExternalID: "5"})
+
+	skipped, err := client.Sync(&watchlist, store, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skipped) != 2 || skipped[0].Title != "Gone" || skipped[1].Title != "Unmapped" {
+		t.Errorf("Unexpected skipped entries: %v", skipped)
+	}
+
+	attributes, _ := created["attributes"].(map[string]any)
+	if attributes["status"] != "completed" || attributes["progress"] != float64(12) || attributes["ratingTwenty"] != float64(18) {
+		t.Errorf("Unexpected created entry: %v", created)
+	}
+	if created["relationships"] == nil {
+		t.Error("Created entry is missing its relationships")
+	}
+	// Only the progress of the existing entry differs and the new entry's
+	// review is set after creating it. The unchanged entry mustn't be
+	// written at all.
+	if len(updated) != 2 {
+		t.Fatalf("Expected two updates, got %v", updated)
+	}
+	attributes, _ = updated[0]["attributes"].(map[string]any)
+	if updated[0]["id"] != "99" || len(attributes) != 1 || attributes["progress"] != float64(24) {
+		t.Errorf("Unexpected updated entry: %v", updated[0])
+	}
+	attributes, _ = updated[1]["attributes"].(map[string]any)
+	if updated[1]["id"] != "101" || len(attributes) != 1 || attributes["notes"] != "Good" {
+		t.Errorf("Unexpected review update: %v", updated[1])
+	}
+}
@@ -0,0 +1,121 @@
+package kitsu
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Bios-Marcel/proxerscrape"
+	"github.com/Bios-Marcel/proxerscrape/mapping"
+	"github.com/Bios-Marcel/proxerscrape/reconcile"
+)
+
+// StatusOnHold has no equivalent on proxer.me, so it's treated as dropped.
+const StatusOnHold Status = "on_hold"
+
+// categories translates the Kitsu status into proxer categories.
+var categories = map[Status]proxerscrape.ListCategory{
+	StatusCompleted: proxerscrape.CategoryWatched,
+	StatusCurrent:   proxerscrape.CategoryCurrentlyWatching,
+	StatusPlanned:   proxerscrape.CategoryToWatch,
+	StatusDropped:   proxerscrape.CategoryStoppedWatching,
+	StatusOnHold:    proxerscrape.CategoryStoppedWatching,
+}
+
+// number reads a numeric attribute, treating null as 0.
+func number(attributes map[string]any, name string) float64 {
+	value, _ := attributes[name].(float64)
+	return value
+}
+
+// List retrieves the anime library of the logged in user, following all
+// pages.
+func (client *Client) List() ([]reconcile.RemoteEntry, error) {
+	userID, err := client.UserID()
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("filter[userId]", userID)
+	query.Set("filter[kind]", "anime")
+	query.Set("include", "anime")
+	query.Set("fields[libraryEntries]", "status,progress,ratingTwenty,reconsumeCount,notes,updatedAt,anime")
+	query.Set("fields[anime]", "canonicalTitle")
+	query.Set("page[limit]", "500")
+	path := "/library-entries?" + query.Encode()
+
+	entryIDs := make(map[string]string)
+	var entries []reconcile.RemoteEntry
+	for path != "" {
+		var page document
+		if err := client.request(http.MethodGet, path, nil, &page); err != nil {
+			return nil, err
+		}
+
+		titles := make(map[string]string)
+		for _, included := range page.Included {
+			if included.Type == "anime" {
+				titles[included.ID], _ = included.Attributes["canonicalTitle"].(string)
+			}
+		}
+		for _, entry := range page.Data {
+			animeID := entry.Relationships["anime"].Data.ID
+			if animeID == "" {
+				continue
+			}
+			entryIDs[animeID] = entry.ID
+
+			status, _ := entry.Attributes["status"].(string)
+			notes, _ := entry.Attributes["notes"].(string)
+			remote := reconcile.RemoteEntry{
+				Service:    mapping.ServiceKitsu,
+				ExternalID: animeID,
+				Title:      titles[animeID],
+				Category:   categories[Status(status)],
+				Progress:   uint16(number(entry.Attributes, "progress")),
+				// Kitsu rates from 2 to 20.
+				Score:  uint8(math.Round(number(entry.Attributes, "ratingTwenty") / 2)),
+				Repeat: uint16(number(entry.Attributes, "reconsumeCount")),
+				Notes:  notes,
+			}
+			if updatedAt, ok := entry.Attributes["updatedAt"].(string); ok {
+				remote.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+			}
+			entries = append(entries, remote)
+		}
+
+		path = ""
+		if next := page.Links.Next; next != "" {
+			path = strings.TrimPrefix(next, client.baseURL()+"/edge")
+			if path == next {
+				return nil, fmt.Errorf("unexpected kitsu page link '%s'", next)
+			}
+		}
+	}
+	client.entryIDs = entryIDs
+	return entries, nil
+}
+
+// Apply changes only the attributes of the library entry affected by the
+// given sync operation, which allows using the client as a reconcile.Writer.
+func (client *Client) Apply(operation reconcile.Operation) error {
+	switch operation.Type {
+	case reconcile.OperationAdd:
+		return client.SaveEntry(operation.ExternalID, Entry{
+			Status:   statuses[operation.Category],
+			Progress: operation.Progress,
+			Score:    operation.Score,
+		})
+	case reconcile.OperationSetProgress:
+		return client.update(operation.ExternalID, map[string]any{"progress": operation.Progress})
+	case reconcile.OperationSetScore:
+		return client.update(operation.ExternalID, map[string]any{"ratingTwenty": int(operation.Score) * 2})
+	case reconcile.OperationSetStatus:
+		return client.update(operation.ExternalID, map[string]any{"status": statuses[operation.Category]})
+	}
+	return fmt.Errorf("unknown operation '%s'", operation.Type)
+}
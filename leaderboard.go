@@ -0,0 +1,94 @@
+package proxerscrape
+
+import (
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// LeaderboardType is the kind of ranking proxer.me offers for its users.
+type LeaderboardType string
+
+const (
+	// LeaderboardEpisodes ranks users by the amount of episodes watched.
+	LeaderboardEpisodes LeaderboardType = "episodes"
+	// LeaderboardActivity ranks users by their activity points.
+	LeaderboardActivity LeaderboardType = "activity"
+)
+
+// LeaderboardEntry is a single row of a leaderboard.
+type LeaderboardEntry struct {
+	Rank     uint
+	UserID   string
+	Username string
+	// Value is whatever the leaderboard is ranking by, for example the
+	// amount of episodes watched.
+	Value uint64
+}
+
+// Leaderboard holds all entries found on a single leaderboard page.
+type Leaderboard struct {
+	Type    LeaderboardType
+	Entries []*LeaderboardEntry
+}
+
+// RankOf returns the entry of the given user, if the user is present on the
+// leaderboard at all.
+func (leaderboard *Leaderboard) RankOf(userID string) (*LeaderboardEntry, bool) {
+	for _, entry := range leaderboard.Entries {
+		if entry.UserID == userID {
+			return entry, true
+		}
+	}
+	return nil, false
+}
+
+var userIDRegex = regexp.MustCompile(`/user/(\d+)`)
+
+// ParseLeaderboard takes an HTML dump of a leaderboard page and parses the
+// contained ranking table. Rows that can't be parsed are skipped.
+func ParseLeaderboard(reader io.Reader, leaderboardType LeaderboardType) (Leaderboard, error) {
+	leaderboard := Leaderboard{Type: leaderboardType}
+	document, parseError := newDocument(reader)
+	if parseError != nil {
+		return leaderboard, parseError
+	}
+
+	document.Find("table#ranking tr").Each(func(i int, s *goquery.Selection) {
+		cells := s.Find("td")
+		// Header rows only contain `th` elements.
+		if cells.Length() < 3 {
+			return
+		}
+
+		rank, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimSpace(cells.Eq(0).Text()), "."), 10, 32)
+		if err != nil {
+			return
+		}
+
+		link := cells.Eq(1).Find("a").First()
+		href, _ := link.Attr("href")
+		userIDMatch := userIDRegex.FindStringSubmatch(href)
+		if userIDMatch == nil {
+			return
+		}
+
+		valueString := strings.NewReplacer(".", "", ",", "", " ", "").Replace(strings.TrimSpace(cells.Eq(2).Text()))
+		value, err := strconv.ParseUint(valueString, 10, 64)
+		if err != nil {
+			return
+		}
+
+		leaderboard.Entries = append(leaderboard.Entries, &LeaderboardEntry{
+			Rank:     uint(rank),
+			UserID:   userIDMatch[1],
+			Username: normalizeText(strings.TrimSpace(link.Text())),
+			Value:    value,
+		})
+	})
+
+	return leaderboard, nil
+}
@@ -0,0 +1,30 @@
+package proxerscrape
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseLeaderboard(t *testing.T) {
+	page := `<html><body><table id="ranking">
+<tr><th>#</th><th>User</th><th>Episoden</th></tr>
+<tr><td>1.</td><td><a href="/user/1337#top">Someone</a></td><td>12.345</td></tr>
+<tr><td>2.</td><td><a href="/user/252835#top">Other</a></td><td>9.001</td></tr>
+</table></body></html>`
+
+	leaderboard, err := ParseLeaderboard(strings.NewReader(page), LeaderboardEpisodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(leaderboard.Entries) != 2 {
+		t.Fatalf("Found %d entries instead of 2", len(leaderboard.Entries))
+	}
+
+	entry, found := leaderboard.RankOf("252835")
+	if !found {
+		t.Fatal("User 252835 not found")
+	}
+	if entry.Rank != 2 || entry.Value != 9001 || entry.Username != "Other" {
+		t.Errorf("Unexpected entry: %+v", entry)
+	}
+}
@@ -0,0 +1,96 @@
+package proxerscrape
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Completion records that an entry has been finished.
+type Completion struct {
+	ID    string    `json:"id"`
+	Title string    `json:"title"`
+	Type  MediaType `json:"type"`
+	At    time.Time `json:"at"`
+}
+
+// SeasonOf returns the season and year the given time falls into.
+func SeasonOf(at time.Time) (Season, uint) {
+	seasons := []Season{Q1, Q2, Q3, Q4}
+	return seasons[(at.Month()-1)/3], uint(at.Year())
+}
+
+// SeasonTotal is the amount of entries finished within a season.
+type SeasonTotal struct {
+	Season      Season
+	Year        uint
+	Completions []Completion
+}
+
+func (total SeasonTotal) String() string {
+	return fmt.Sprintf("%s %d: finished %d", total.Season.Name(), total.Year, len(total.Completions))
+}
+
+// RecordCompletions adds all completions among the changes to the ledger.
+// Entries that have already been recorded in the same season are skipped,
+// so running the same diff twice has no effect. The amount of recorded
+// completions is returned.
+func (userData *UserData) RecordCompletions(changes []Change, at time.Time) int {
+	season, year := SeasonOf(at)
+	recorded := 0
+	for _, change := range changes {
+		if !change.Completed() {
+			continue
+		}
+		identifier := change.Current.ProxerID()
+		if userData.completedIn(identifier, season, year) {
+			continue
+		}
+		userData.Completions = append(userData.Completions, Completion{
+			ID:    identifier,
+			Title: change.Current.Title,
+			Type:  change.Current.Type,
+			At:    at,
+		})
+		recorded++
+	}
+	return recorded
+}
+
+func (userData *UserData) completedIn(identifier string, season Season, year uint) bool {
+	for _, completion := range userData.Completions {
+		completionSeason, completionYear := SeasonOf(completion.At)
+		if completion.ID == identifier && completionSeason == season && completionYear == year {
+			return true
+		}
+	}
+	return false
+}
+
+// SeasonTotals groups the ledger by season, ordered chronologically. If year
+// is not zero, only seasons of that year are returned.
+func (userData *UserData) SeasonTotals(year uint) []SeasonTotal {
+	var totals []SeasonTotal
+	indices := make(map[string]int)
+	for _, completion := range userData.Completions {
+		season, completionYear := SeasonOf(completion.At)
+		if year != 0 && completionYear != year {
+			continue
+		}
+		key := fmt.Sprintf("%d%s", completionYear, season)
+		index, present := indices[key]
+		if !present {
+			index = len(totals)
+			indices[key] = index
+			totals = append(totals, SeasonTotal{Season: season, Year: completionYear})
+		}
+		totals[index].Completions = append(totals[index].Completions, completion)
+	}
+	sort.Slice(totals, func(a, b int) bool {
+		if totals[a].Year != totals[b].Year {
+			return totals[a].Year < totals[b].Year
+		}
+		return totals[a].Season < totals[b].Season
+	})
+	return totals
+}
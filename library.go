@@ -0,0 +1,61 @@
+package proxerscrape
+
+import "fmt"
+
+// Library holds the watchlists of all profile tabs of a user.
+type Library struct {
+	Anime  Watchlist
+	Manga  Watchlist
+	Novels Watchlist
+}
+
+// ProfileTabTypes are all profile tabs containing a watchlist.
+var ProfileTabTypes = []ProfileTabType{ProfileTabAnime, ProfileTabManga, ProfileTabNovel}
+
+// Tab returns the watchlist of the given profile tab, or nil if the tab is
+// unknown.
+func (library *Library) Tab(tabType ProfileTabType) *Watchlist {
+	switch tabType {
+	case ProfileTabAnime:
+		return &library.Anime
+	case ProfileTabManga:
+		return &library.Manga
+	case ProfileTabNovel:
+		return &library.Novels
+	}
+	return nil
+}
+
+// All returns the entries of all tabs, see Watchlist.All.
+func (library *Library) All() []*Media {
+	var all []*Media
+	for _, tabType := range ProfileTabTypes {
+		all = append(all, library.Tab(tabType).All()...)
+	}
+	return all
+}
+
+// RetrieveAllProfileTabs retrieves and parses the anime, manga and novel tabs
+// of the given profile concurrently, see FetchProfiles. All requests share
+// the profile tab ratelimiter. If a tab fails, the other tabs are still
+// returned, along with the error of the first failed tab.
+func (cache *Cache) RetrieveAllProfileTabs(profileID string) (Library, error) {
+	var library Library
+	failed := make(map[ProfileTabType]error)
+	for result := range cache.FetchProfiles([]string{profileID}, ProfileTabTypes...) {
+		if result.Err != nil {
+			failed[result.TabType] = result.Err
+			continue
+		}
+		*library.Tab(result.TabType) = result.Watchlist
+	}
+
+	// The results arrive in any order, so the order of the tabs decides
+	// which error is returned.
+	for _, tabType := range ProfileTabTypes {
+		if err := failed[tabType]; err != nil {
+			return library, fmt.Errorf("error retrieving %s tab: %w", tabType, err)
+		}
+	}
+	return library, nil
+}
@@ -0,0 +1,27 @@
+package proxerscrape
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCache_RetrieveAllProfileTabs(t *testing.T) {
+	cache, queries := newTestCache(map[string]string{
+		"/user/1/anime": profileTabPage("Anime"),
+		"/user/1/novel": profileTabPage("Novel"),
+	})
+
+	library, err := cache.RetrieveAllProfileTabs("1")
+	if !errors.Is(err, ErrLayoutChanged) {
+		t.Errorf("Expected missing manga tab to fail, got %v", err)
+	}
+	if *queries != 3 {
+		t.Errorf("Expected 3 queries, got %d", *queries)
+	}
+	if library.Anime.Watched.Data[0].Title != "Anime" || library.Novels.Watched.Data[0].Title != "Novel" {
+		t.Errorf("Unexpected library: %+v", library)
+	}
+	if len(library.Manga.All()) != 0 || len(library.All()) != 4 {
+		t.Errorf("Expected 4 entries, got %d", len(library.All()))
+	}
+}
@@ -0,0 +1,83 @@
+package proxerscrape
+
+import (
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// listingRatingRegex matches average ratings such as "8.53" or "8.53 / 10".
+var listingRatingRegex = regexp.MustCompile(`^(\d{1,2}\.\d+)(?:\s*/\s*10)?$`)
+
+// parseMediaListing parses pages listing multiple entries, such as search
+// results or toplists. These pages differ in their layout, so every row
+// linking an entry is considered a result and the type, status, rating and
+// genres are picked up from whichever cells contain them. Each entry is only
+// returned once, in the order of the page. If rowHook is set, it's called for
+// every row, with item being nil for rows that don't add an entry.
+func parseMediaListing(reader io.Reader, rowHook func(row *goquery.Selection, item *Media)) ([]*Media, error) {
+	document, err := newDocument(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var entries []*Media
+	document.Find("tr").Each(func(_ int, row *goquery.Selection) {
+		// Rows of nested tables would otherwise be visited twice.
+		if row.Find("tr").Length() > 0 {
+			return
+		}
+		link := row.Find("a[href*='/info/']").FilterFunction(func(_ int, link *goquery.Selection) bool {
+			return strings.TrimSpace(link.Text()) != ""
+		}).First()
+		href, _ := link.Attr("href")
+		id := proxerIDRegex.FindStringSubmatch(href)
+		if id == nil || seen[id[1]] {
+			if rowHook != nil {
+				rowHook(row, nil)
+			}
+			return
+		}
+		seen[id[1]] = true
+
+		item := &Media{
+			ID:        parseMediaID("/info/" + id[1]),
+			ProxerURL: "/info/" + id[1],
+			Title:     strings.TrimSpace(normalizeText(link.Text())),
+		}
+		row.Find("td").Each(func(_ int, cell *goquery.Selection) {
+			text := strings.TrimSpace(cell.Text())
+			if mediaType := ParseMediaType(text); mediaType != MediaTypeUnknown {
+				item.RawType = text
+				item.Type = mediaType
+			} else if status := ParseStatus(text); status != StatusUnknown {
+				item.RawStatus = text
+				item.Status = status
+			} else if match := listingRatingRegex.FindStringSubmatch(text); match != nil {
+				item.Rating, _ = strconv.ParseFloat(match[1], 64)
+			}
+		})
+		row.Find("img[title]").Each(func(_ int, image *goquery.Selection) {
+			title, _ := image.Attr("title")
+			if status := ParseStatus(title); status != StatusUnknown {
+				item.RawStatus = title
+				item.Status = status
+			}
+		})
+		row.Find("a[href*='genre']").Each(func(_ int, genreLink *goquery.Selection) {
+			name := strings.TrimSpace(normalizeText(genreLink.Text()))
+			genreHref, _ := genreLink.Attr("href")
+			item.Generes = append(item.Generes, name)
+			item.GenreIDs = append(item.GenreIDs, parseLinkID(genreHref, "genre", name))
+		})
+		if rowHook != nil {
+			rowHook(row, item)
+		}
+		entries = append(entries, item)
+	})
+	return entries, nil
+}
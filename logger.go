@@ -0,0 +1,21 @@
+package proxerscrape
+
+// Logger receives diagnostic messages that aren't errors, for example about
+// skipped entries. *log.Logger satisfies this interface, so log.Default() can
+// be used to restore the old behaviour of logging to stderr.
+type Logger interface {
+	Printf(format string, args ...any)
+}
+
+type discardLogger struct{}
+
+func (discardLogger) Printf(string, ...any) {}
+
+// loggerOrDiscard allows leaving loggers unset, in which case nothing is
+// logged.
+func loggerOrDiscard(logger Logger) Logger {
+	if logger == nil {
+		return discardLogger{}
+	}
+	return logger
+}
@@ -0,0 +1,102 @@
+package proxerscrape
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AverageChapterReadingTimes are used for estimating the reading time of
+// manga, since proxer.me doesn't state the length of chapters.
+var AverageChapterReadingTimes = map[MediaType]time.Duration{
+	MediaTypeManga:     8 * time.Minute,
+	MediaTypeOneShot:   20 * time.Minute,
+	MediaTypeWebtoon:   5 * time.Minute,
+	MediaTypeManhwa:    6 * time.Minute,
+	MediaTypeManhua:    6 * time.Minute,
+	MediaTypeDoujinshi: 10 * time.Minute,
+}
+
+// MangaView is a view on a manga entry, naming the fields the way they're
+// meant for manga. The profile states read chapters in the same columns as
+// watched episodes, which is why Media only has the anime specific names.
+type MangaView struct {
+	*Media
+}
+
+// AsManga returns the manga view of the given entry. false is returned for
+// anything that isn't a manga, so that anime aren't accidentally treated as
+// such.
+func AsManga(item *Media) (MangaView, bool) {
+	if item == nil || !item.Type.IsManga() {
+		return MangaView{}, false
+	}
+	return MangaView{Media: item}, true
+}
+
+// ChaptersRead is the amount of chapters read according to the profile.
+func (manga MangaView) ChaptersRead() uint16 {
+	return manga.EpisodesWatched
+}
+
+// ChapterCount is the amount of chapters released, 0 if unknown.
+func (manga MangaView) ChapterCount() uint16 {
+	return manga.EpisodeCount
+}
+
+// EstimatedChapterReadingTime returns the given reading time per chapter if
+// set and the average reading time for the type otherwise.
+func (manga MangaView) EstimatedChapterReadingTime(perChapter time.Duration) time.Duration {
+	if perChapter > 0 {
+		return perChapter
+	}
+	if average, ok := AverageChapterReadingTimes[manga.Type]; ok {
+		return average
+	}
+	return AverageChapterReadingTimes[MediaTypeManga]
+}
+
+// EstimatedReadingTimeLeft returns how long reading the remaining chapters
+// takes at the given reading time per chapter, see
+// EstimatedChapterReadingTime. Analogous to Media.EstimatedWatchTimeLeft, an
+// unknown chapter count is treated as a single chapter.
+func (manga MangaView) EstimatedReadingTimeLeft(perChapter time.Duration) time.Duration {
+	return manga.EstimatedReadingTimeAfter(manga.ChaptersRead(), perChapter)
+}
+
+// EstimatedReadingTimeAfter is like EstimatedReadingTimeLeft, but allows
+// using progress that isn't tracked by the profile.
+func (manga MangaView) EstimatedReadingTimeAfter(chaptersRead uint16, perChapter time.Duration) time.Duration {
+	chapterCount := manga.ChapterCount()
+	if chapterCount == 0 {
+		chapterCount = 1
+	}
+	if chaptersRead >= chapterCount {
+		return 0
+	}
+	return time.Duration(chapterCount-chaptersRead) * manga.EstimatedChapterReadingTime(perChapter)
+}
+
+var volumesRegex = regexp.MustCompile(`\d+`)
+
+// parseVolumes parses the volume count as displayed on the detail page, such
+// as "12 Bände". 0 is returned if the text contains no number.
+func parseVolumes(text string) uint16 {
+	volumes, err := strconv.ParseUint(volumesRegex.FindString(text), 10, 16)
+	if err != nil {
+		return 0
+	}
+	return uint16(volumes)
+}
+
+// parseScanlationStatus converts the translation status of the detail page
+// into a Status. Besides the release states, proxer.me uses "Laufend" for
+// ongoing translations.
+func parseScanlationStatus(raw string) Status {
+	switch strings.TrimSpace(raw) {
+	case "Laufend", "In Bearbeitung":
+		return StatusAiring
+	}
+	return ParseStatus(raw)
+}
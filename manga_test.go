@@ -0,0 +1,50 @@
+package proxerscrape
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAsManga(t *testing.T) {
+	if _, ok := AsManga(&Media{Type: MediaTypeSeries}); ok {
+		t.Error("Anime treated as manga")
+	}
+	if _, ok := AsManga(&Media{}); ok {
+		t.Error("Unknown type treated as manga")
+	}
+	manga, ok := AsManga(&Media{Type: MediaTypeWebtoon, EpisodesWatched: 3, EpisodeCount: 10})
+	if !ok || manga.ChaptersRead() != 3 || manga.ChapterCount() != 10 {
+		t.Errorf("Unexpected view: %+v", manga)
+	}
+}
+
+func TestEstimatedReadingTimeLeft(t *testing.T) {
+	manga, _ := AsManga(&Media{Type: MediaTypeManga, EpisodesWatched: 2, EpisodeCount: 12})
+	if left := manga.EstimatedReadingTimeLeft(0); left != 10*AverageChapterReadingTimes[MediaTypeManga] {
+		t.Errorf("Unexpected estimate: %s", left)
+	}
+	if left := manga.EstimatedReadingTimeLeft(3 * time.Minute); left != 30*time.Minute {
+		t.Errorf("Reading speed not used: %s", left)
+	}
+	if left := manga.EstimatedReadingTimeAfter(12, 0); left != 0 {
+		t.Errorf("Read manga has time left: %s", left)
+	}
+}
+
+func TestLoadExtraData_Manga(t *testing.T) {
+	page := `<html><head><title>Berserk - Manga - Proxer.Me</title></head><body>
+<table class="details"><tbody>
+<tr><td><b>Bände</b></td><td>41 Bände</td></tr>
+<tr><td><b>Übersetzungsstatus</b></td><td>Laufend</td></tr>
+</tbody></table>
+<span class="average">9.1</span>
+</body></html>`
+	item := &Media{Type: MediaTypeManga, ProxerURL: "/info/1"}
+	category := WatchlistCategory{Data: []*Media{item}}
+	if err := category.LoadExtraData(retrieveStatic(page)); err != nil {
+		t.Fatal(err)
+	}
+	if item.Volumes != 41 || item.ScanlationStatus != StatusAiring {
+		t.Errorf("Unexpected result: %+v", item)
+	}
+}
@@ -0,0 +1,96 @@
+package mapping
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/xml"
+	"io"
+	"os"
+)
+
+// AniDBTitlesURL is where AniDB publishes the titles of all anime. AniDB
+// doesn't offer a search API and bans clients downloading the dump more than
+// once a day, so it has to be downloaded manually.
+const AniDBTitlesURL = "https://anidb.net/api/anime-titles.xml.gz"
+
+// aniDBMinimumSimilarity is the similarity a title needs for being returned
+// as a candidate at all, since the dump contains every title of AniDB.
+const aniDBMinimumSimilarity = 0.6
+
+type aniDBTitle struct {
+	aid   string
+	title string
+}
+
+// AniDBTitles is a CandidateSource searching the title dump of AniDB, see
+// AniDBTitlesURL.
+type AniDBTitles struct {
+	titles []aniDBTitle
+}
+
+// LoadAniDBTitles reads the title dump of AniDB, which may be gzipped.
+func LoadAniDBTitles(reader io.Reader) (*AniDBTitles, error) {
+	buffered := bufio.NewReader(reader)
+	// Gzip streams always start with these two bytes.
+	if magic, err := buffered.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gzipReader, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	} else {
+		reader = buffered
+	}
+
+	var dump struct {
+		Anime []struct {
+			AID    string `xml:"aid,attr"`
+			Titles []struct {
+				Type  string `xml:"type,attr"`
+				Title string `xml:",chardata"`
+			} `xml:"title"`
+		} `xml:"anime"`
+	}
+	if err := xml.NewDecoder(reader).Decode(&dump); err != nil {
+		return nil, err
+	}
+
+	titles := &AniDBTitles{}
+	for _, anime := range dump.Anime {
+		for _, title := range anime.Titles {
+			// Short titles are abbreviations, which match way too often.
+			if title.Type != "short" && title.Title != "" {
+				titles.titles = append(titles.titles, aniDBTitle{aid: anime.AID, title: title.Title})
+			}
+		}
+	}
+	return titles, nil
+}
+
+// LoadAniDBTitlesFile reads the title dump from the given file, see
+// LoadAniDBTitles.
+func LoadAniDBTitlesFile(path string) (*AniDBTitles, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LoadAniDBTitles(file)
+}
+
+// Search returns all titles of the dump similar enough to the given one.
+// Titles of the same anime are returned as separate candidates.
+func (titles *AniDBTitles) Search(title string) ([]Candidate, error) {
+	var candidates []Candidate
+	for _, aniDBTitle := range titles.titles {
+		if Similarity(title, aniDBTitle.title) >= aniDBMinimumSimilarity {
+			candidates = append(candidates, Candidate{
+				Service: ServiceAniDB,
+				ID:      aniDBTitle.aid,
+				Title:   aniDBTitle.title,
+			})
+		}
+	}
+	return candidates, nil
+}
@@ -0,0 +1,44 @@
+package mapping
+
+import (
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+)
+
+const aniDBTitles = `<?xml version="1.0" encoding="UTF-8"?>
+<animetitles>
+	<anime aid="13861">
+		<title type="main" xml:lang="x-jat">Tsurune: Kazemai Koukou Kyuudoubu</title>
+		<title type="official" xml:lang="en">Tsurune</title>
+		<title type="short" xml:lang="x-jat">TR</title>
+	</anime>
+	<anime aid="1">
+		<title type="main" xml:lang="x-jat">Seikai no Monshou</title>
+	</anime>
+</animetitles>`
+
+func TestAniDBTitles(t *testing.T) {
+	var compressed bytes.Buffer
+	gzipWriter := gzip.NewWriter(&compressed)
+	gzipWriter.Write([]byte(aniDBTitles))
+	gzipWriter.Close()
+
+	for name, data := range map[string]string{"plain": aniDBTitles, "gzip": compressed.String()} {
+		titles, err := LoadAniDBTitles(strings.NewReader(data))
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		candidates, err := titles.Search("Tsurune")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(candidates) != 1 || candidates[0].ID != "13861" || candidates[0].Service != ServiceAniDB {
+			t.Errorf("%s: unexpected candidates: %+v", name, candidates)
+		}
+		if candidates, _ := titles.Search("TR"); len(candidates) != 0 {
+			t.Errorf("%s: short titles matched: %+v", name, candidates)
+		}
+	}
+}
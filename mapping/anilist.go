@@ -0,0 +1,113 @@
+package mapping
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+const aniListSearchQuery = `query ($search: String) {
+	Page(perPage: 10) {
+		media(search: $search, type: ANIME) {
+			id
+			idMal
+			title { romaji english }
+			format
+			episodes
+			seasonYear
+		}
+	}
+}`
+
+// AniListSearch is a CandidateSource using the public AniList GraphQL API.
+// Since AniList also knows the MyAnimeList IDs of its entries, it can provide
+// candidates for both services.
+type AniListSearch struct {
+	Client *http.Client
+	// Service decides which IDs are returned.
+	Service Service
+}
+
+func (search *AniListSearch) Search(title string) ([]Candidate, error) {
+	body, err := json.Marshal(map[string]any{
+		"query":     aniListSearchQuery,
+		"variables": map[string]string{"search": title},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	client := search.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	request, err := http.NewRequest(http.MethodPost, "https://graphql.anilist.co", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("anilist search failed with status %d", response.StatusCode)
+	}
+
+	var result struct {
+		Data struct {
+			Page struct {
+				Media []struct {
+					ID    int `json:"id"`
+					IDMal int `json:"idMal"`
+					Title struct {
+						Romaji  string `json:"romaji"`
+						English string `json:"english"`
+					} `json:"title"`
+					Format     string `json:"format"`
+					Episodes   int    `json:"episodes"`
+					SeasonYear int    `json:"seasonYear"`
+				} `json:"media"`
+			} `json:"Page"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	var candidates []Candidate
+	for _, media := range result.Data.Page.Media {
+		candidate := Candidate{
+			Service:  search.Service,
+			Title:    media.Title.Romaji,
+			Movie:    knownFormat(media.Format == "MOVIE"),
+			Episodes: media.Episodes,
+			Year:     media.SeasonYear,
+		}
+		switch search.Service {
+		case ServiceMyAnimeList:
+			if media.IDMal == 0 {
+				continue
+			}
+			candidate.ID = strconv.Itoa(media.IDMal)
+		default:
+			candidate.Service = ServiceAniList
+			candidate.ID = strconv.Itoa(media.ID)
+		}
+		if candidate.Title == "" {
+			candidate.Title = media.Title.English
+		}
+		candidates = append(candidates, candidate)
+		// The English title is also a candidate, as the proxer entry may
+		// only match on that.
+		if media.Title.English != "" && media.Title.English != candidate.Title {
+			candidate.Title = media.Title.English
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates, nil
+}
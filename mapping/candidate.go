@@ -0,0 +1,66 @@
+package mapping
+
+import (
+	"sort"
+
+	"github.com/Bios-Marcel/proxerscrape"
+)
+
+// Candidate is an entry of an external service that might be the same as a
+// proxer entry.
+type Candidate struct {
+	Service Service
+	ID      string
+	Title   string
+	// Similarity is the best similarity between any title of the proxer
+	// entry and the candidates title.
+	Similarity float64
+
+	// Optional metadata, used for telling apart entries with similar
+	// titles, such as sequels. Zero values mean unknown.
+
+	// Movie is nil if the source doesn't know the format of the entry.
+	Movie    *bool
+	Episodes int
+	Year     int
+}
+
+// knownFormat returns a value for Candidate.Movie, for sources that know
+// whether an entry is a movie.
+func knownFormat(movie bool) *bool {
+	return &movie
+}
+
+// CandidateSource searches an external service for entries matching a title.
+type CandidateSource interface {
+	Search(title string) ([]Candidate, error)
+}
+
+// Titles returns all known titles of the entry, which are worth comparing
+// against titles of external services.
+func Titles(item *proxerscrape.Media) []string {
+	titles := []string{item.Title}
+	for _, title := range append([]string{item.JapaneseTitle, item.EnglishTitle, item.GermanTitle}, item.Synonyms...) {
+		if title != "" {
+			titles = append(titles, title)
+		}
+	}
+	return titles
+}
+
+// Rank calculates the similarity of each candidate to the given entry and
+// sorts the candidates by it, most similar first.
+func Rank(item *proxerscrape.Media, candidates []Candidate) {
+	titles := Titles(item)
+	for index := range candidates {
+		candidates[index].Similarity = 0
+		for _, title := range titles {
+			if similarity := Similarity(title, candidates[index].Title); similarity > candidates[index].Similarity {
+				candidates[index].Similarity = similarity
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(a, b int) bool {
+		return candidates[a].Similarity > candidates[b].Similarity
+	})
+}
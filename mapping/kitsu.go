@@ -0,0 +1,79 @@
+package mapping
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// KitsuSearch is a CandidateSource using the public Kitsu JSON:API.
+type KitsuSearch struct {
+	Client *http.Client
+}
+
+func (search *KitsuSearch) Search(title string) ([]Candidate, error) {
+	client := search.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	query := url.Values{}
+	query.Set("filter[text]", title)
+	query.Set("fields[anime]", "canonicalTitle,titles,subtype,episodeCount,startDate")
+	query.Set("page[limit]", "10")
+	request, err := http.NewRequest(http.MethodGet, "https://kitsu.app/api/edge/anime?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/vnd.api+json")
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("kitsu search failed with status %d", response.StatusCode)
+	}
+
+	var result struct {
+		Data []struct {
+			ID         string `json:"id"`
+			Attributes struct {
+				CanonicalTitle string            `json:"canonicalTitle"`
+				Titles         map[string]string `json:"titles"`
+				Subtype        string            `json:"subtype"`
+				EpisodeCount   int               `json:"episodeCount"`
+				StartDate      string            `json:"startDate"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	var candidates []Candidate
+	for _, anime := range result.Data {
+		candidate := Candidate{
+			Service:  ServiceKitsu,
+			ID:       anime.ID,
+			Title:    anime.Attributes.CanonicalTitle,
+			Movie:    knownFormat(anime.Attributes.Subtype == "movie"),
+			Episodes: anime.Attributes.EpisodeCount,
+		}
+		// The start date is of the form 2018-10-22.
+		if len(anime.Attributes.StartDate) >= 4 {
+			candidate.Year, _ = strconv.Atoi(anime.Attributes.StartDate[:4])
+		}
+		candidates = append(candidates, candidate)
+		// Alternative titles are candidates as well, as the proxer entry may
+		// only match on one of those.
+		for _, alternative := range anime.Attributes.Titles {
+			if alternative != "" && alternative != anime.Attributes.CanonicalTitle {
+				candidate.Title = alternative
+				candidates = append(candidates, candidate)
+			}
+		}
+	}
+	return candidates, nil
+}
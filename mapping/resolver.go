@@ -0,0 +1,153 @@
+package mapping
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/Bios-Marcel/proxerscrape"
+)
+
+// DefaultThreshold is the confidence a candidate needs for being accepted
+// without asking the user.
+const DefaultThreshold = 0.9
+
+// DefaultMargin is how much more confident than the runner-up a candidate
+// has to be for being accepted without asking the user.
+const DefaultMargin = 0.05
+
+var (
+	// ErrNoCandidates is returned if the service knows no entry with a
+	// similar title.
+	ErrNoCandidates = errors.New("no candidates found")
+	// ErrAmbiguous is returned if no candidate is certain enough, in which
+	// case the user has to decide.
+	ErrAmbiguous = errors.New("no candidate is certain enough")
+)
+
+// Confidence combines the title similarity of the candidate with its
+// metadata, since sequels and movies often have almost the same title as the
+// series. Metadata unknown to either side doesn't change the confidence.
+// Rank has to be called first.
+func Confidence(item *proxerscrape.Media, candidate Candidate) float64 {
+	confidence := candidate.Similarity
+	if candidate.Episodes > 0 && item.EpisodeCount > 0 && candidate.Episodes != int(item.EpisodeCount) {
+		confidence *= 0.85
+	}
+	if candidate.Year > 0 && item.ReleasePeriod.FromYear > 0 && candidate.Year != int(item.ReleasePeriod.FromYear) {
+		confidence *= 0.85
+	}
+	if item.Type != proxerscrape.MediaTypeUnknown && candidate.Movie != nil && *candidate.Movie != (item.Type == proxerscrape.MediaTypeMovie) {
+		confidence *= 0.85
+	}
+	return confidence
+}
+
+// Resolver maps proxer entries to external services, asking the user only
+// for entries that can't be mapped with certainty.
+type Resolver struct {
+	// Sources are searched for candidates per service.
+	Sources map[Service]CandidateSource
+	// Store caches the resolved mappings.
+	Store *Store
+	// Overrides are mappings chosen by the user, which always take
+	// precedence. May be nil.
+	Overrides *Store
+	// Threshold is the minimum confidence for accepting a candidate, see
+	// DefaultThreshold.
+	Threshold float64
+	// Margin is the minimum lead of the best candidate over the runner-up,
+	// see DefaultMargin.
+	Margin float64
+}
+
+// Resolve returns the mapping of the entry for the given service. If none
+// is known yet, the service is searched for all titles of the entry. If no
+// candidate is good enough, or the best one isn't clearly ahead of the
+// runner-up, ErrAmbiguous is returned along with the candidates, ordered by
+// confidence, so that the user can choose via Accept.
+func (resolver *Resolver) Resolve(item *proxerscrape.Media, service Service) (Mapping, []Candidate, error) {
+	proxerID := item.ProxerID()
+	if resolver.Overrides != nil {
+		if override, present := resolver.Overrides.Get(proxerID, service); present {
+			return override, nil, nil
+		}
+	}
+	if known, present := resolver.Store.Get(proxerID, service); present {
+		return known, nil, nil
+	}
+
+	source, present := resolver.Sources[service]
+	if !present {
+		return Mapping{}, nil, fmt.Errorf("no candidate source for service '%s'", service)
+	}
+	// The original and English titles are the most likely to be known by
+	// other services, the German ones are rather specific to proxer.
+	var candidates []Candidate
+	searched := make(map[string]bool)
+	for _, title := range []string{item.Title, item.JapaneseTitle, item.EnglishTitle} {
+		if title == "" || searched[NormalizeTitle(title)] {
+			continue
+		}
+		searched[NormalizeTitle(title)] = true
+		found, err := source.Search(title)
+		if err != nil {
+			return Mapping{}, nil, err
+		}
+		candidates = append(candidates, found...)
+	}
+	if len(candidates) == 0 {
+		return Mapping{}, nil, ErrNoCandidates
+	}
+
+	candidates = rankByConfidence(item, candidates)
+	threshold := resolver.Threshold
+	if threshold == 0 {
+		threshold = DefaultThreshold
+	}
+	margin := resolver.Margin
+	if margin == 0 {
+		margin = DefaultMargin
+	}
+	if candidates[0].Similarity < threshold {
+		return Mapping{}, candidates, ErrAmbiguous
+	}
+	// Entries with the same title and no telling metadata, such as remakes,
+	// are equally likely.
+	if len(candidates) > 1 && candidates[0].Similarity-candidates[1].Similarity < margin {
+		return Mapping{}, candidates, ErrAmbiguous
+	}
+	mapping := Mapping{ExternalID: candidates[0].ID, Confidence: candidates[0].Similarity}
+	resolver.Store.Set(proxerID, service, mapping)
+	return mapping, candidates, nil
+}
+
+// Accept stores the candidate chosen by the user.
+func (resolver *Resolver) Accept(item *proxerscrape.Media, candidate Candidate) Mapping {
+	mapping := Mapping{ExternalID: candidate.ID, Confidence: 1, Manual: true}
+	resolver.Store.Set(item.ProxerID(), candidate.Service, mapping)
+	return mapping
+}
+
+// rankByConfidence ranks the candidates and only keeps the best match per
+// ID. The similarity of the returned candidates is their confidence.
+func rankByConfidence(item *proxerscrape.Media, candidates []Candidate) []Candidate {
+	Rank(item, candidates)
+	best := make(map[string]int)
+	var unique []Candidate
+	for _, candidate := range candidates {
+		candidate.Similarity = Confidence(item, candidate)
+		if index, present := best[candidate.ID]; present {
+			if candidate.Similarity > unique[index].Similarity {
+				unique[index] = candidate
+			}
+			continue
+		}
+		best[candidate.ID] = len(unique)
+		unique = append(unique, candidate)
+	}
+	sort.SliceStable(unique, func(a, b int) bool {
+		return unique[a].Similarity > unique[b].Similarity
+	})
+	return unique
+}
@@ -0,0 +1,84 @@
+package mapping
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Bios-Marcel/proxerscrape"
+)
+
+type fakeSource []Candidate
+
+func (source fakeSource) Search(string) ([]Candidate, error) {
+	return append([]Candidate(nil), source...), nil
+}
+
+func TestResolver(t *testing.T) {
+	source := fakeSource{
+		{Service: ServiceMyAnimeList, ID: "1", Title: "Tsurune", Episodes: 13, Year: 2018},
+		{Service: ServiceMyAnimeList, ID: "2", Title: "Tsurune", Episodes: 1, Year: 2022, Movie: knownFormat(true)},
+		{Service: ServiceMyAnimeList, ID: "3", Title: "Tsurune: Tsunagari no Issha", Episodes: 13, Year: 2023},
+	}
+	resolver := &Resolver{
+		Sources: map[Service]CandidateSource{ServiceMyAnimeList: source},
+		Store:   &Store{},
+	}
+
+	// The movie has the same title, but the metadata tells them apart.
+	series := &proxerscrape.Media{ProxerURL: "/info/1", Title: "Tsurune", Type: proxerscrape.MediaTypeSeries, EpisodeCount: 13}
+	mapping, _, err := resolver.Resolve(series, ServiceMyAnimeList)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mapping.ExternalID != "1" {
+		t.Errorf("Series was mapped to %s", mapping.ExternalID)
+	}
+	if _, present := resolver.Store.Get("1", ServiceMyAnimeList); !present {
+		t.Error("Mapping wasn't stored")
+	}
+
+	sequel := &proxerscrape.Media{ProxerURL: "/info/2", Title: "Tsurune Season 2", Type: proxerscrape.MediaTypeSeries}
+	_, candidates, err := resolver.Resolve(sequel, ServiceMyAnimeList)
+	if !errors.Is(err, ErrAmbiguous) || len(candidates) != 3 {
+		t.Fatalf("Expected ambiguous result, got %v with %d candidates", err, len(candidates))
+	}
+	resolver.Accept(sequel, candidates[2])
+	if mapping, present := resolver.Store.Get("2", ServiceMyAnimeList); !present || !mapping.Manual {
+		t.Error("Accepted mapping wasn't stored as manual")
+	}
+
+	resolver.Overrides = &Store{}
+	resolver.Overrides.Set("1", ServiceMyAnimeList, Mapping{ExternalID: "42", Manual: true})
+	if mapping, _, _ := resolver.Resolve(series, ServiceMyAnimeList); mapping.ExternalID != "42" {
+		t.Errorf("Override wasn't used, got %s", mapping.ExternalID)
+	}
+}
+
+func TestResolver_UnknownFormat(t *testing.T) {
+	// AniDB doesn't tell whether an entry is a movie.
+	source := fakeSource{{Service: ServiceAniDB, ID: "1", Title: "Tsurune Movie"}}
+	resolver := &Resolver{
+		Sources: map[Service]CandidateSource{ServiceAniDB: source},
+		Store:   &Store{},
+	}
+	movie := &proxerscrape.Media{ProxerURL: "/info/1", Title: "Tsurune Movie", Type: proxerscrape.MediaTypeMovie}
+	if mapping, _, err := resolver.Resolve(movie, ServiceAniDB); err != nil || mapping.ExternalID != "1" {
+		t.Errorf("Movie wasn't mapped: %v %v", mapping, err)
+	}
+}
+
+func TestResolver_Tie(t *testing.T) {
+	// A remake with the same title and no metadata to tell them apart.
+	source := fakeSource{
+		{Service: ServiceMyAnimeList, ID: "1", Title: "Hunter x Hunter"},
+		{Service: ServiceMyAnimeList, ID: "2", Title: "Hunter x Hunter"},
+	}
+	resolver := &Resolver{
+		Sources: map[Service]CandidateSource{ServiceMyAnimeList: source},
+		Store:   &Store{},
+	}
+	item := &proxerscrape.Media{ProxerURL: "/info/1", Title: "Hunter x Hunter"}
+	if _, candidates, err := resolver.Resolve(item, ServiceMyAnimeList); !errors.Is(err, ErrAmbiguous) || len(candidates) != 2 {
+		t.Errorf("Expected ambiguous result, got %v with %d candidates", err, len(candidates))
+	}
+}
@@ -0,0 +1,106 @@
+package mapping
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// shareFormatVersion is increased whenever the share format changes in an
+// incompatible way.
+const shareFormatVersion = 1
+
+// SharedMapping is a single entry of the share format.
+type SharedMapping struct {
+	ProxerID   string  `json:"proxerId"`
+	Service    Service `json:"service"`
+	ExternalID string  `json:"externalId"`
+	Confidence float64 `json:"confidence"`
+	Manual     bool    `json:"manual,omitempty"`
+}
+
+// SharedMappings is the portable format for sharing mappings between users.
+// Unlike the Store, it's a flat list, which is easier to diff and merge.
+type SharedMappings struct {
+	Version  int             `json:"version"`
+	Mappings []SharedMapping `json:"mappings"`
+}
+
+// Conflict is a mapping that couldn't be imported, since the store already
+// contains a different mapping for the same entry and service.
+type Conflict struct {
+	Existing SharedMapping
+	Imported SharedMapping
+}
+
+// Export writes all mappings in the share format. If verifiedOnly is set,
+// only manually chosen mappings are exported.
+func (store *Store) Export(writer io.Writer, verifiedOnly bool) error {
+	shared := SharedMappings{Version: shareFormatVersion, Mappings: []SharedMapping{}}
+	for proxerID, services := range store.Mappings {
+		for service, mapping := range services {
+			if verifiedOnly && !mapping.Manual {
+				continue
+			}
+			shared.Mappings = append(shared.Mappings, SharedMapping{
+				ProxerID:   proxerID,
+				Service:    service,
+				ExternalID: mapping.ExternalID,
+				Confidence: mapping.Confidence,
+				Manual:     mapping.Manual,
+			})
+		}
+	}
+	// Stable order, so that exports can be diffed.
+	sort.Slice(shared.Mappings, func(a, b int) bool {
+		if shared.Mappings[a].ProxerID != shared.Mappings[b].ProxerID {
+			return shared.Mappings[a].ProxerID < shared.Mappings[b].ProxerID
+		}
+		return shared.Mappings[a].Service < shared.Mappings[b].Service
+	})
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(shared)
+}
+
+// Import reads mappings in the share format and adds them to the store.
+// Mappings that contradict an existing mapping are only imported if overwrite
+// is set; either way they are returned as conflicts.
+func (store *Store) Import(reader io.Reader, overwrite bool) ([]Conflict, error) {
+	var shared SharedMappings
+	if err := json.NewDecoder(reader).Decode(&shared); err != nil {
+		return nil, err
+	}
+	if shared.Version != shareFormatVersion {
+		return nil, fmt.Errorf("unsupported mapping format version %d", shared.Version)
+	}
+
+	var conflicts []Conflict
+	for _, imported := range shared.Mappings {
+		existing, present := store.Get(imported.ProxerID, imported.Service)
+		if present && existing.ExternalID != imported.ExternalID {
+			conflicts = append(conflicts, Conflict{
+				Existing: SharedMapping{
+					ProxerID:   imported.ProxerID,
+					Service:    imported.Service,
+					ExternalID: existing.ExternalID,
+					Confidence: existing.Confidence,
+					Manual:     existing.Manual,
+				},
+				Imported: imported,
+			})
+			if !overwrite {
+				continue
+			}
+		}
+
+		store.Set(imported.ProxerID, imported.Service, Mapping{
+			ExternalID: imported.ExternalID,
+			Confidence: imported.Confidence,
+			Manual:     imported.Manual,
+		})
+	}
+	return conflicts, nil
+}
@@ -0,0 +1,38 @@
+package mapping
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestStore_ExportImport(t *testing.T) {
+	source := &Store{}
+	source.Set("296", ServiceMyAnimeList, Mapping{ExternalID: "35958", Confidence: 1, Manual: true})
+	source.Set("297", ServiceMyAnimeList, Mapping{ExternalID: "1", Confidence: 0.8})
+	source.Set("298", ServiceMyAnimeList, Mapping{ExternalID: "2", Confidence: 1, Manual: true})
+
+	var buffer bytes.Buffer
+	if err := source.Export(&buffer, true); err != nil {
+		t.Fatal(err)
+	}
+
+	target := &Store{}
+	target.Set("298", ServiceMyAnimeList, Mapping{ExternalID: "3", Confidence: 1, Manual: true})
+	conflicts, err := target.Import(&buffer, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(conflicts) != 1 || conflicts[0].Existing.ExternalID != "3" || conflicts[0].Imported.ExternalID != "2" {
+		t.Errorf("Unexpected conflicts: %+v", conflicts)
+	}
+	if mapping, _ := target.Get("296", ServiceMyAnimeList); mapping.ExternalID != "35958" {
+		t.Errorf("Mapping wasn't imported: %+v", mapping)
+	}
+	if _, present := target.Get("297", ServiceMyAnimeList); present {
+		t.Error("Unverified mapping was exported")
+	}
+	if mapping, _ := target.Get("298", ServiceMyAnimeList); mapping.ExternalID != "3" {
+		t.Errorf("Conflicting mapping was overwritten: %+v", mapping)
+	}
+}
@@ -0,0 +1,73 @@
+package mapping
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Levenshtein returns the edit distance between the two strings, counting
+// runes rather than bytes.
+func Levenshtein(a, b string) int {
+	runesA, runesB := []rune(a), []rune(b)
+	previous := make([]int, len(runesB)+1)
+	current := make([]int, len(runesB)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(runesA); i++ {
+		current[0] = i
+		for j := 1; j <= len(runesB); j++ {
+			cost := 1
+			if runesA[i-1] == runesB[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(runesB)]
+}
+
+func min(values ...int) int {
+	result := values[0]
+	for _, value := range values[1:] {
+		if value < result {
+			result = value
+		}
+	}
+	return result
+}
+
+// NormalizeTitle lowercases the title and removes all punctuation and
+// redundant whitespace, so that titles differing only in notation compare
+// equal.
+func NormalizeTitle(title string) string {
+	var builder strings.Builder
+	lastWasSpace := true
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			builder.WriteRune(r)
+			lastWasSpace = false
+		} else if !lastWasSpace {
+			builder.WriteRune(' ')
+			lastWasSpace = true
+		}
+	}
+	return strings.TrimSpace(builder.String())
+}
+
+// Similarity returns a score between 0 and 1, where 1 means the normalized
+// titles are equal.
+func Similarity(a, b string) float64 {
+	a, b = NormalizeTitle(a), NormalizeTitle(b)
+	maxLength := len([]rune(a))
+	if length := len([]rune(b)); length > maxLength {
+		maxLength = length
+	}
+	if maxLength == 0 {
+		return 1
+	}
+	return 1 - float64(Levenshtein(a, b))/float64(maxLength)
+}
@@ -0,0 +1,34 @@
+package mapping
+
+import (
+	"testing"
+
+	"github.com/Bios-Marcel/proxerscrape"
+)
+
+func TestLevenshtein(t *testing.T) {
+	for _, testCase := range []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"kitten", "sitting", 3},
+		{"Frühling", "Fruhling", 1},
+	} {
+		if result := Levenshtein(testCase.a, testCase.b); result != testCase.expected {
+			t.Errorf("Levenshtein(%s, %s) = %d, instead of %d", testCase.a, testCase.b, result, testCase.expected)
+		}
+	}
+}
+
+func TestRank(t *testing.T) {
+	candidates := []Candidate{
+		{ID: "1", Title: "Tsurune: Tsunagari no Issha"},
+		{ID: "2", Title: "Tsurune"},
+	}
+	Rank(&proxerscrape.Media{Title: "Tsurune: Kazemai Koukou Kyuudoubu"}, candidates)
+	Rank(&proxerscrape.Media{Title: "TSURUNE!"}, candidates)
+	if candidates[0].ID != "2" || candidates[0].Similarity != 1 {
+		t.Errorf("Unexpected ranking: %+v", candidates)
+	}
+}
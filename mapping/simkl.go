@@ -0,0 +1,72 @@
+package mapping
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// SimklSearch is a CandidateSource using the anime search of the Simkl API,
+// which requires the client ID of a registered application, see
+// https://simkl.com/settings/developer/.
+type SimklSearch struct {
+	Client   *http.Client
+	ClientID string
+	// BaseURL defaults to https://api.simkl.com.
+	BaseURL string
+}
+
+func (search *SimklSearch) Search(title string) ([]Candidate, error) {
+	client := search.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	baseURL := search.BaseURL
+	if baseURL == "" {
+		baseURL = "https://api.simkl.com"
+	}
+	query := url.Values{}
+	query.Set("q", title)
+	query.Set("limit", "10")
+	query.Set("client_id", search.ClientID)
+	request, err := http.NewRequest(http.MethodGet, baseURL+"/search/anime?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("simkl search failed with status %d", response.StatusCode)
+	}
+
+	var results []struct {
+		Title         string `json:"title"`
+		Year          int    `json:"year"`
+		AnimeType     string `json:"anime_type"`
+		TotalEpisodes int    `json:"total_episodes"`
+		IDs           struct {
+			Simkl int `json:"simkl_id"`
+		} `json:"ids"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&results); err != nil {
+		return nil, err
+	}
+
+	candidates := make([]Candidate, 0, len(results))
+	for _, result := range results {
+		candidates = append(candidates, Candidate{
+			Service:  ServiceSimkl,
+			ID:       strconv.Itoa(result.IDs.Simkl),
+			Title:    result.Title,
+			Movie:    knownFormat(result.AnimeType == "movie"),
+			Episodes: result.TotalEpisodes,
+			Year:     result.Year,
+		})
+	}
+	return candidates, nil
+}
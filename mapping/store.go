@@ -0,0 +1,124 @@
+package mapping
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/Bios-Marcel/proxerscrape/internal/atomicfile"
+)
+
+// Service is an external database media can be mapped to.
+type Service string
+
+const (
+	ServiceMyAnimeList Service = "mal"
+	ServiceAniList     Service = "anilist"
+	ServiceKitsu       Service = "kitsu"
+	ServiceAniDB       Service = "anidb"
+	// ServiceTrakt IDs are of the form "show:<id>", optionally followed by
+	// ":<season>", or "movie:<id>", since Trakt numbers both separately.
+	ServiceTrakt Service = "trakt"
+	ServiceSimkl Service = "simkl"
+)
+
+// Mapping links a proxer entry to an entry of an external service.
+type Mapping struct {
+	ExternalID string `json:"externalId"`
+	// Confidence is the similarity score that lead to this mapping. Manual
+	// mappings always have a confidence of 1.
+	Confidence float64 `json:"confidence"`
+	// Manual tells whether the mapping was chosen by the user.
+	Manual bool `json:"manual,omitempty"`
+}
+
+// Store holds all known mappings, keyed by proxer ID and service.
+type Store struct {
+	Mappings map[string]map[Service]Mapping `json:"mappings"`
+}
+
+// DefaultStorePath returns the path to the mapping store inside of the user
+// config directory.
+func DefaultStorePath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "proxerscrape", "mappings.json"), nil
+}
+
+// DefaultOverridesPath returns the path to the manual override file inside of
+// the user config directory. It has the same format as the store, but is
+// only ever written by the user and always takes precedence.
+func DefaultOverridesPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "proxerscrape", "mapping-overrides.json"), nil
+}
+
+// LoadStore reads the store from the given file. If the file doesn't exist,
+// an empty store is returned.
+func LoadStore(path string) (*Store, error) {
+	store := &Store{}
+	// A previous save might have been interrupted.
+	if err := atomicfile.Recover(path); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Save atomically writes the store to the given file, creating missing
+// directories.
+func (store *Store) Save(path string) error {
+	data, err := json.MarshalIndent(store, "", "\t")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(path, data, 0o644)
+}
+
+// Get returns the mapping of the proxer entry for the given service.
+func (store *Store) Get(proxerID string, service Service) (Mapping, bool) {
+	mapping, present := store.Mappings[proxerID][service]
+	return mapping, present
+}
+
+// Set stores a mapping, replacing any existing one.
+func (store *Store) Set(proxerID string, service Service, mapping Mapping) {
+	if store.Mappings == nil {
+		store.Mappings = make(map[string]map[Service]Mapping)
+	}
+	if store.Mappings[proxerID] == nil {
+		store.Mappings[proxerID] = make(map[Service]Mapping)
+	}
+	store.Mappings[proxerID][service] = mapping
+}
+
+// ProxerID returns the proxer entry mapped to the given external entry. If
+// multiple entries are mapped to it, the lowest ID is returned, so that the
+// result is stable.
+func (store *Store) ProxerID(service Service, externalID string) (string, bool) {
+	var result string
+	for proxerID, mappings := range store.Mappings {
+		mapping, present := mappings[service]
+		if !present || mapping.ExternalID != externalID {
+			continue
+		}
+		if result == "" || len(proxerID) < len(result) || (len(proxerID) == len(result) && proxerID < result) {
+			result = proxerID
+		}
+	}
+	return result, result != ""
+}
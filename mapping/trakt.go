@@ -0,0 +1,82 @@
+package mapping
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// TraktSearch is a CandidateSource using the Trakt API, which requires the
+// client ID of a registered application, see https://trakt.tv/oauth/applications.
+type TraktSearch struct {
+	Client   *http.Client
+	ClientID string
+	// BaseURL defaults to https://api.trakt.tv.
+	BaseURL string
+}
+
+func (search *TraktSearch) Search(title string) ([]Candidate, error) {
+	client := search.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	baseURL := search.BaseURL
+	if baseURL == "" {
+		baseURL = "https://api.trakt.tv"
+	}
+	query := url.Values{}
+	query.Set("query", title)
+	query.Set("limit", "10")
+	request, err := http.NewRequest(http.MethodGet, baseURL+"/search/show,movie?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("trakt-api-version", "2")
+	request.Header.Set("trakt-api-key", search.ClientID)
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("trakt search failed with status %d", response.StatusCode)
+	}
+
+	type traktMedia struct {
+		Title string `json:"title"`
+		Year  int    `json:"year"`
+		IDs   struct {
+			Trakt int `json:"trakt"`
+		} `json:"ids"`
+	}
+	var results []struct {
+		Type  string      `json:"type"`
+		Show  *traktMedia `json:"show"`
+		Movie *traktMedia `json:"movie"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&results); err != nil {
+		return nil, err
+	}
+
+	var candidates []Candidate
+	for _, result := range results {
+		media := result.Show
+		if result.Type == "movie" {
+			media = result.Movie
+		}
+		if media == nil {
+			continue
+		}
+		candidates = append(candidates, Candidate{
+			Service: ServiceTrakt,
+			ID:      result.Type + ":" + strconv.Itoa(media.IDs.Trakt),
+			Title:   media.Title,
+			Movie:   knownFormat(result.Type == "movie"),
+			Year:    media.Year,
+		})
+	}
+	return candidates, nil
+}
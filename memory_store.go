@@ -0,0 +1,74 @@
+package proxerscrape
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// MemoryStore is a CacheStore that only keeps entries in memory. It's mainly
+// meant for tests and environments where writing to disk isn't possible.
+type MemoryStore struct {
+	lock    sync.RWMutex
+	entries map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	data     []byte
+	metadata CacheMetadata
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]memoryEntry)}
+}
+
+func (store *MemoryStore) Get(key string) (io.ReadCloser, CacheMetadata, error) {
+	store.lock.RLock()
+	defer store.lock.RUnlock()
+
+	entry, present := store.entries[key]
+	if !present {
+		return nil, CacheMetadata{}, ErrCacheMiss
+	}
+	return io.NopCloser(bytes.NewReader(entry.data)), entry.metadata, nil
+}
+
+func (store *MemoryStore) Put(key string, data []byte, metadata CacheMetadata) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	store.entries[key] = memoryEntry{data: data, metadata: metadata}
+	return nil
+}
+
+func (store *MemoryStore) Delete(key string) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	delete(store.entries, key)
+	return nil
+}
+
+func (store *MemoryStore) Stats() (CacheStats, error) {
+	store.lock.RLock()
+	defer store.lock.RUnlock()
+
+	var stats CacheStats
+	for _, entry := range store.entries {
+		stats.addEntry(int64(len(entry.data)), entry.metadata.FetchedAt)
+	}
+	return stats, nil
+}
+
+func (store *MemoryStore) Walk(callback func(key string, metadata CacheMetadata) error) error {
+	store.lock.RLock()
+	defer store.lock.RUnlock()
+
+	for key, entry := range store.entries {
+		if err := callback(key, entry.metadata); err != nil {
+			return err
+		}
+	}
+	return nil
+}
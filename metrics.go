@@ -0,0 +1,114 @@
+package proxerscrape
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics summarises the work a Cache has done since its creation.
+type Metrics struct {
+	// Requests is the amount of requests sent to proxer.me, including
+	// retries and revalidations.
+	Requests uint64
+	// CacheHits and CacheMisses count how many pages were served from the
+	// store and how many had to be fetched.
+	CacheHits, CacheMisses uint64
+	// Blocked is the total time spent waiting on ratelimiters.
+	Blocked time.Duration
+	// Warnings counts recoverable problems, such as retried requests and
+	// captcha cooldowns.
+	Warnings uint64
+	// Errors counts pages that couldn't be retrieved, even after retrying.
+	Errors uint64
+}
+
+// Metric names a counter of Metrics.
+type Metric string
+
+const (
+	MetricRequests    Metric = "requests"
+	MetricCacheHits   Metric = "cache_hits"
+	MetricCacheMisses Metric = "cache_misses"
+	// MetricBlocked is reported in seconds.
+	MetricBlocked  Metric = "ratelimit_blocked_seconds"
+	MetricWarnings Metric = "warnings"
+	MetricErrors   Metric = "errors"
+)
+
+// MetricHook receives by how much a metric has just increased.
+type MetricHook func(metric Metric, delta float64)
+
+// count increments the counter and reports it to the hook.
+func (cache *Cache) count(metric Metric, counter *uint64) {
+	atomic.AddUint64(counter, 1)
+	if cache.OnMetric != nil {
+		cache.OnMetric(metric, 1)
+	}
+}
+
+// Metrics returns a snapshot of the cache's metrics.
+func (cache *Cache) Metrics() Metrics {
+	return Metrics{
+		Requests:    atomic.LoadUint64(&cache.requests),
+		CacheHits:   atomic.LoadUint64(&cache.hits),
+		CacheMisses: atomic.LoadUint64(&cache.misses),
+		Blocked:     time.Duration(atomic.LoadInt64(&cache.blocked)),
+		Warnings:    atomic.LoadUint64(&cache.warnings),
+		Errors:      atomic.LoadUint64(&cache.failures),
+	}
+}
+
+// String formats the metrics as a single line, suitable for printing after
+// a command has finished.
+func (metrics Metrics) String() string {
+	return fmt.Sprintf("%d requests, %d cache hits, %s blocked on ratelimits, %d warnings, %d errors",
+		metrics.Requests, metrics.CacheHits, metrics.Blocked.Round(time.Millisecond), metrics.Warnings, metrics.Errors)
+}
+
+// wait blocks on the limiter and records the time spent doing so.
+func (cache *Cache) wait(limiter *Limiter) {
+	if limiter == nil {
+		return
+	}
+	if cache.OnProgress != nil {
+		if delay := limiter.Delay(); delay > 0 {
+			cache.OnProgress(ProgressEvent{Type: ProgressWaiting, Wait: delay})
+		}
+	}
+	start := time.Now()
+	limiter.Wait()
+	cache.keepInterval()
+	blocked := time.Since(start)
+	atomic.AddInt64(&cache.blocked, int64(blocked))
+	if cache.OnMetric != nil {
+		cache.OnMetric(MetricBlocked, blocked.Seconds())
+	}
+}
+
+// WritePrometheus writes the metrics and the store statistics in the text
+// exposition format of Prometheus.
+func WritePrometheus(writer io.Writer, metrics Metrics, stats CacheStats) error {
+	samples := []struct {
+		name, kind, help string
+		value            float64
+	}{
+		{"requests_total", "counter", "Requests sent to proxer.me, including retries.", float64(metrics.Requests)},
+		{"cache_hits_total", "counter", "Pages served from the cache.", float64(metrics.CacheHits)},
+		{"cache_misses_total", "counter", "Pages that had to be fetched.", float64(metrics.CacheMisses)},
+		{"ratelimit_blocked_seconds_total", "counter", "Time spent waiting on ratelimiters.", metrics.Blocked.Seconds()},
+		{"warnings_total", "counter", "Recoverable problems, such as retries and captcha cooldowns.", float64(metrics.Warnings)},
+		{"errors_total", "counter", "Pages that couldn't be retrieved.", float64(metrics.Errors)},
+		{"cache_entries", "gauge", "Entries in the cache.", float64(stats.Entries)},
+		{"cache_size_bytes", "gauge", "Total size of all cache entries.", float64(stats.Size)},
+	}
+	for _, sample := range samples {
+		name := "proxerscrape_" + sample.name
+		if _, err := fmt.Fprintf(writer, "# HELP %s %s\n# TYPE %s %s\n%s %g\n",
+			name, sample.help, name, sample.kind, name, sample.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
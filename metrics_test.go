@@ -0,0 +1,77 @@
+package proxerscrape
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCache_Metrics(t *testing.T) {
+	cache, _ := newTestCache(map[string]string{"/info/296": "page"})
+	cache.AnimeQueryRatelimiter = NewLimiter(1, 50*time.Millisecond)
+	cache.RetrieveAnimeRawData(&Media{ProxerURL: "/info/296"})
+	cache.RetrieveAnimeRawData(&Media{ProxerURL: "/info/296"})
+	cache.Store = NewMemoryStore()
+	cache.RetrieveAnimeRawData(&Media{ProxerURL: "/info/296"})
+
+	metrics := cache.Metrics()
+	if metrics.Requests != 2 || metrics.CacheHits != 1 || metrics.CacheMisses != 2 {
+		t.Errorf("Unexpected metrics: %+v", metrics)
+	}
+	if metrics.Blocked < 25*time.Millisecond {
+		t.Errorf("Blocked = %s, expected the second request to wait", metrics.Blocked)
+	}
+	if metrics.String() == "" {
+		t.Error("Summary is empty")
+	}
+}
+
+func TestCache_OnMetric(t *testing.T) {
+	cache, _ := newTestCache(map[string]string{"/info/296": "page"})
+	reported := make(map[Metric]float64)
+	cache.OnMetric = func(metric Metric, delta float64) {
+		reported[metric] += delta
+	}
+	cache.RetrieveAnimeRawData(&Media{ProxerURL: "/info/296"})
+	cache.RetrieveAnimeRawData(&Media{ProxerURL: "/info/296"})
+	cache.QueryMedia = func(*Media, http.Header) (*http.Response, error) {
+		return nil, errors.New("offline")
+	}
+	cache.RetrieveAnimeRawData(&Media{ProxerURL: "/info/1"})
+
+	if reported[MetricRequests] != 2 || reported[MetricCacheHits] != 1 || reported[MetricCacheMisses] != 2 || reported[MetricErrors] != 1 {
+		t.Errorf("Unexpected reported metrics: %v", reported)
+	}
+	if metrics := cache.Metrics(); metrics.Errors != 1 {
+		t.Errorf("Errors = %d, expected 1", metrics.Errors)
+	}
+
+	var builder strings.Builder
+	if err := WritePrometheus(&builder, cache.Metrics(), CacheStats{Entries: 1, Size: 4}); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"proxerscrape_requests_total 2", "proxerscrape_errors_total 1", "proxerscrape_cache_size_bytes 4", "# TYPE proxerscrape_cache_entries gauge"} {
+		if !strings.Contains(builder.String(), line+"\n") {
+			t.Errorf("Missing %q in:\n%s", line, builder.String())
+		}
+	}
+}
+
+func TestCache_OnProgress(t *testing.T) {
+	cache, _ := newTestCache(map[string]string{"/info/1": "one", "/info/2": "two"})
+	cache.AnimeQueryRatelimiter = NewLimiter(1, 20*time.Millisecond)
+	var waits []time.Duration
+	cache.OnProgress = func(event ProgressEvent) {
+		if event.Type == ProgressWaiting {
+			waits = append(waits, event.Wait)
+		}
+	}
+	cache.RetrieveAnimeRawData(&Media{ProxerURL: "/info/1"})
+	cache.RetrieveAnimeRawData(&Media{ProxerURL: "/info/2"})
+
+	if len(waits) != 1 || waits[0] <= 0 {
+		t.Errorf("Expected a single wait, got %v", waits)
+	}
+}
@@ -0,0 +1,109 @@
+package proxerscrape
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// NotificationKind is the type of event a notification is about.
+type NotificationKind string
+
+const (
+	NotificationNewEpisode NotificationKind = "episode"
+	NotificationNewChapter NotificationKind = "chapter"
+	// NotificationOther covers everything else, such as news or messages.
+	NotificationOther NotificationKind = "other"
+)
+
+// Notification is an entry of the notification center of proxer.me.
+type Notification struct {
+	Kind NotificationKind
+	// ProxerURL is the entry the notification is about, empty for
+	// NotificationOther.
+	ProxerURL string
+	Title     string
+	Episode   uint16
+	Language  Language
+	// Text is the full text of the notification.
+	Text string
+	// ReceivedAt is zero if no date is shown.
+	ReceivedAt time.Time
+}
+
+// ProxerID returns the ID of the entry the notification is about, or an
+// empty string for notifications not about an entry.
+func (notification *Notification) ProxerID() string {
+	return formatMediaID(notification.ProxerURL)
+}
+
+// parseNotifications parses the notification center. Notifications are
+// either table rows or list items, an episode or chapter link makes them a
+// new release.
+func parseNotifications(document *goquery.Document) []Notification {
+	location := proxerLocation()
+	var notifications []Notification
+	document.Find("tr, li, .notification").Each(func(_ int, element *goquery.Selection) {
+		// Only the innermost element holds a single notification.
+		if element.Find("tr, li, .notification").Length() > 0 {
+			return
+		}
+		text := strings.Join(strings.Fields(normalizeText(element.Text())), " ")
+		if text == "" {
+			return
+		}
+
+		notification := Notification{Kind: NotificationOther, Text: text}
+		notification.ReceivedAt, _ = parseEventTime(text, location)
+		element.Find("a[href]").EachWithBreak(func(_ int, link *goquery.Selection) bool {
+			href, _ := link.Attr("href")
+			match := historyEpisodeRegex.FindStringSubmatch(href)
+			if match == nil {
+				return true
+			}
+			episode, _ := strconv.ParseUint(match[2], 10, 16)
+			notification.Kind = NotificationNewEpisode
+			if strings.Contains(href, "/read/") {
+				notification.Kind = NotificationNewChapter
+			}
+			notification.ProxerURL = "/info/" + match[1]
+			notification.Title = strings.TrimSpace(normalizeText(link.Text()))
+			notification.Episode = uint16(episode)
+			notification.Language = Language(match[3])
+			return false
+		})
+		if info := element.Find("a[href*='/info/']"); notification.ProxerURL != "" && info.Length() > 0 {
+			notification.Title = strings.TrimSpace(normalizeText(info.First().Text()))
+		}
+		notifications = append(notifications, notification)
+	})
+	return notifications
+}
+
+// LoadNotifications retrieves the notifications of the logged in user.
+// ErrLoginRequired is returned if the session isn't logged in.
+func (session *Session) LoadNotifications() ([]Notification, error) {
+	document, err := session.getDocument("/notifications?s=notifications")
+	if err != nil {
+		return nil, err
+	}
+	return parseNotifications(document), nil
+}
+
+// NewEpisodes returns the notifications about new episodes or chapters of
+// entries the user is currently watching.
+func NewEpisodes(notifications []Notification, watchlist *Watchlist) []Notification {
+	watching := make(map[string]bool)
+	for _, item := range watchlist.CurrentlyWatching.Data {
+		watching[item.ProxerID()] = true
+	}
+	var releases []Notification
+	for _, notification := range notifications {
+		if notification.Kind != NotificationOther && watching[notification.ProxerID()] {
+			releases = append(releases, notification)
+		}
+	}
+	return releases
+}
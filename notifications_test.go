@@ -0,0 +1,41 @@
+package proxerscrape
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSession_LoadNotifications(t *testing.T) {
+	session := newTestSession(map[string]string{
+		"/notifications?s=notifications": `<html><body><ul>
+<li><a href="/info/53">One Piece</a>: <a href="/watch/53/1001/gersub">Episode 1001</a> ist verfügbar. 03.02.2022 18:30</li>
+<li><a href="/read/7/80/de">Kapitel 80</a> von Berserk ist verfügbar. 01.02.2022</li>
+<li>Du hast eine neue Freundschaftsanfrage. 01.02.2022</li>
+</ul></body></html>`,
+	})
+	notifications, err := session.LoadNotifications()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notifications) != 3 {
+		t.Fatalf("Expected 3 notifications, got %d", len(notifications))
+	}
+
+	episode := notifications[0]
+	receivedAt := time.Date(2022, 2, 3, 18, 30, 0, 0, proxerLocation())
+	if episode.Kind != NotificationNewEpisode || episode.Title != "One Piece" || episode.Episode != 1001 ||
+		episode.Language != LanguageGermanSub || !episode.ReceivedAt.Equal(receivedAt) {
+		t.Errorf("Unexpected episode notification: %+v", episode)
+	}
+	if notifications[1].Kind != NotificationNewChapter || notifications[1].ProxerID() != "7" {
+		t.Errorf("Unexpected chapter notification: %+v", notifications[1])
+	}
+	if notifications[2].Kind != NotificationOther || notifications[2].ProxerID() != "" {
+		t.Errorf("Unexpected other notification: %+v", notifications[2])
+	}
+
+	watchlist := &Watchlist{CurrentlyWatching: WatchlistCategory{Data: []*Media{{ProxerURL: "/info/53"}}}}
+	if releases := NewEpisodes(notifications, watchlist); len(releases) != 1 || releases[0].ProxerID() != "53" {
+		t.Errorf("Expected only the watched entry, got %+v", releases)
+	}
+}
@@ -0,0 +1,150 @@
+// Package notify delivers short messages, such as new episodes of tracked
+// entries, to the user, for example via a desktop notification or a Discord
+// channel.
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"runtime"
+)
+
+// Message is a single notification.
+type Message struct {
+	Title string
+	Text  string
+	// URL links further information, such as the proxer.me page of an
+	// entry. May be empty.
+	URL string
+}
+
+// String returns the message as single line.
+func (message Message) String() string {
+	text := message.Text
+	if message.Title != "" {
+		text = message.Title + ": " + text
+	}
+	if message.URL != "" {
+		text += " (" + message.URL + ")"
+	}
+	return text
+}
+
+// Notifier delivers messages.
+type Notifier interface {
+	Notify(message Message) error
+}
+
+// Multi delivers messages via all notifiers. A failing notifier doesn't keep
+// the others from being notified, the first error is returned.
+type Multi []Notifier
+
+func (notifiers Multi) Notify(message Message) error {
+	var firstErr error
+	for _, notifier := range notifiers {
+		if err := notifier.Notify(message); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Writer writes each message as a single line.
+type Writer struct {
+	Writer io.Writer
+}
+
+func (notifier *Writer) Notify(message Message) error {
+	_, err := fmt.Fprintln(notifier.Writer, message)
+	return err
+}
+
+// Desktop shows desktop notifications via notify-send on Linux and the BSDs
+// and osascript on macOS.
+type Desktop struct{}
+
+func (Desktop) Notify(message Message) error {
+	title := message.Title
+	if title == "" {
+		title = "proxerscrape"
+	}
+	switch runtime.GOOS {
+	case "linux", "freebsd", "openbsd":
+		return exec.Command("notify-send", title, message.Text).Run()
+	case "darwin":
+		script := fmt.Sprintf("display notification %q with title %q", message.Text, title)
+		return exec.Command("osascript", "-e", script).Run()
+	}
+	return fmt.Errorf("desktop notifications aren't supported on %s", runtime.GOOS)
+}
+
+// Webhook posts messages as JSON object with the fields `title`, `text` and
+// `url` to a URL. The field `message` holds the whole message as a single
+// line, as posted by the first version of the watch daemon.
+type Webhook struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+func (notifier *Webhook) Notify(message Message) error {
+	return postJSON(notifier.HTTPClient, notifier.URL, map[string]string{
+		"title":   message.Title,
+		"text":    message.Text,
+		"url":     message.URL,
+		"message": message.String(),
+	})
+}
+
+// Discord posts messages to a Discord channel via a webhook, which can be
+// created in the settings of the channel.
+type Discord struct {
+	WebhookURL string
+	// Username overrides the name the webhook posts with. May be empty.
+	Username   string
+	HTTPClient *http.Client
+}
+
+type discordEmbed struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description"`
+	URL         string `json:"url,omitempty"`
+}
+
+type discordPayload struct {
+	Username string         `json:"username,omitempty"`
+	Embeds   []discordEmbed `json:"embeds"`
+}
+
+func (notifier *Discord) Notify(message Message) error {
+	return postJSON(notifier.HTTPClient, notifier.WebhookURL, discordPayload{
+		Username: notifier.Username,
+		Embeds: []discordEmbed{{
+			Title:       message.Title,
+			Description: message.Text,
+			URL:         message.URL,
+		}},
+	})
+}
+
+func postJSON(httpClient *http.Client, url string, payload any) error {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	response, err := httpClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with %s", response.Status)
+	}
+	return nil
+}
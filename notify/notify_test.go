@@ -0,0 +1,48 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDiscord(t *testing.T) {
+	var payload discordPayload
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if err := json.NewDecoder(request.Body).Decode(&payload); err != nil {
+			t.Error(err)
+		}
+		writer.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier := &Discord{WebhookURL: server.URL, Username: "proxer"}
+	err := notifier.Notify(Message{Title: "One Piece", Text: "Episode 1001 is out", URL: "https://proxer.me/info/53"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payload.Username != "proxer" || len(payload.Embeds) != 1 ||
+		payload.Embeds[0].Description != "Episode 1001 is out" || payload.Embeds[0].URL != "https://proxer.me/info/53" {
+		t.Errorf("Unexpected payload: %+v", payload)
+	}
+}
+
+type failingNotifier struct{}
+
+func (failingNotifier) Notify(Message) error {
+	return errors.New("failed")
+}
+
+func TestMulti(t *testing.T) {
+	var builder strings.Builder
+	notifier := Multi{failingNotifier{}, &Writer{Writer: &builder}}
+	if err := notifier.Notify(Message{Title: "A", Text: "B"}); err == nil {
+		t.Error("Expected error of the failing notifier")
+	}
+	if builder.String() != "A: B\n" {
+		t.Errorf("Expected remaining notifiers to be notified, got %q", builder.String())
+	}
+}
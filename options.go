@@ -0,0 +1,198 @@
+package proxerscrape
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+type cacheOptions struct {
+	cacheDir    string
+	store       CacheStore
+	loginCookie *cookieOption
+	cookies     []*http.Cookie
+	renderer    Renderer
+	httpClient  *http.Client
+	userAgent   string
+	logger      Logger
+	profile     *RequestProfile
+	baseURL     string
+	offline     bool
+}
+
+type cookieOption struct {
+	key, value string
+}
+
+// CacheOption configures a Cache created by NewCache.
+type CacheOption func(*cacheOptions)
+
+// WithCacheDir sets the directory pages are cached in. By default,
+// DefaultCacheDir is used.
+func WithCacheDir(cacheDir string) CacheOption {
+	return func(options *cacheOptions) {
+		options.cacheDir = cacheDir
+	}
+}
+
+// WithStore sets the store pages are cached in. This takes precedence over
+// WithCacheDir, which is then only used for state such as the ratelimits.
+func WithStore(store CacheStore) CacheOption {
+	return func(options *cacheOptions) {
+		options.store = store
+	}
+}
+
+// WithLoginCookie sets the cookie used for authenticating with proxer.me.
+func WithLoginCookie(key, value string) CacheOption {
+	return func(options *cacheOptions) {
+		options.loginCookie = &cookieOption{key: key, value: value}
+	}
+}
+
+// WithLoginCookieFromEnv reads the login cookie from the environment variables
+// `LOGIN_COOKIE_KEY` and `LOGIN_COOKIE_VALUE`. NewCache doesn't look at the
+// environment unless this option is given.
+func WithLoginCookieFromEnv() CacheOption {
+	return WithLoginCookie(os.Getenv("LOGIN_COOKIE_KEY"), os.Getenv("LOGIN_COOKIE_VALUE"))
+}
+
+// WithCookies adds the given cookies for proxer.me, for example those read
+// via LoadCookiesTxt. They're added in addition to the login cookie.
+func WithCookies(cookies ...*http.Cookie) CacheOption {
+	return func(options *cacheOptions) {
+		options.cookies = append(options.cookies, cookies...)
+	}
+}
+
+// WithHTTPClient sets the http.Client used for all requests. This allows
+// configuring timeouts and proxies, see NewHTTPClient. If the client has no
+// cookie jar, a copy with a jar containing the configured cookies is used.
+func WithHTTPClient(httpClient *http.Client) CacheOption {
+	return func(options *cacheOptions) {
+		options.httpClient = httpClient
+	}
+}
+
+// WithUserAgent sets the User-Agent sent with every request. By default,
+// DefaultUserAgent is used.
+func WithUserAgent(userAgent string) CacheOption {
+	return func(options *cacheOptions) {
+		options.userAgent = userAgent
+	}
+}
+
+// WithHeadlessFallback escalates media pages that are missing required
+// content to the given renderer. Pages are still retrieved via plain HTTP
+// first, in order to keep resource usage low.
+func WithHeadlessFallback(renderer Renderer) CacheOption {
+	return func(options *cacheOptions) {
+		options.renderer = renderer
+	}
+}
+
+// WithLogger sets the logger used for diagnostic messages, such as fallbacks
+// and cooldowns. By default, nothing is logged.
+func WithLogger(logger Logger) CacheOption {
+	return func(options *cacheOptions) {
+		options.logger = logger
+	}
+}
+
+// WithRequestProfile sets how aggressively proxer.me is queried. By default,
+// PoliteProfile is used.
+func WithRequestProfile(profile RequestProfile) CacheOption {
+	return func(options *cacheOptions) {
+		options.profile = &profile
+	}
+}
+
+// WithBaseURL sets the URL all pages are retrieved from, such as a mirror,
+// a local proxy or a test server. By default, DefaultBaseURL is used. The
+// cookies are sent to the host of the given URL instead of proxer.me.
+func WithBaseURL(baseURL string) CacheOption {
+	return func(options *cacheOptions) {
+		options.baseURL = baseURL
+	}
+}
+
+// WithOffline prevents all requests, so that only cached pages are used, see
+// Cache.Offline.
+func WithOffline(offline bool) CacheOption {
+	return func(options *cacheOptions) {
+		options.offline = offline
+	}
+}
+
+// NewCache creates a cache querying proxer.me. Unless configured otherwise,
+// pages are cached inside of DefaultCacheDir, which is created if necessary.
+func NewCache(options ...CacheOption) (*Cache, error) {
+	var resolved cacheOptions
+	for _, option := range options {
+		option(&resolved)
+	}
+
+	if resolved.store == nil {
+		if resolved.cacheDir == "" {
+			defaultCacheDir, err := DefaultCacheDir()
+			if err != nil {
+				return nil, err
+			}
+			resolved.cacheDir = defaultCacheDir
+		}
+		resolved.store = NewGzipStore(NewFileStore(resolved.cacheDir))
+	}
+
+	if resolved.cacheDir != "" {
+		if err := Initialize(resolved.cacheDir); err != nil {
+			return nil, err
+		}
+	}
+
+	cookies := resolved.cookies
+	if resolved.loginCookie != nil && resolved.loginCookie.key != "" && resolved.loginCookie.value != "" {
+		cookies = append(cookies, NewLoginCookie(resolved.loginCookie.key, resolved.loginCookie.value))
+	}
+	httpClient := resolved.httpClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: DefaultTimeout}
+	}
+	cookieURL, cookies, err := cookiesFor(resolved.baseURL, cookies)
+	if err != nil {
+		return nil, err
+	}
+	if httpClient.Jar == nil {
+		jar, err := NewCookieJar()
+		if err != nil {
+			return nil, err
+		}
+		withJar := *httpClient
+		withJar.Jar = jar
+		httpClient = &withJar
+	}
+	httpClient.Jar.SetCookies(cookieURL, cookies)
+	client := &Client{
+		HTTPClient: httpClient,
+		UserAgent:  resolved.userAgent,
+		BaseURL:    resolved.baseURL,
+	}
+
+	cache := newCache(resolved.store, client)
+	cache.Logger = resolved.logger
+	cache.Offline = resolved.offline
+	cache.Profile = PoliteProfile
+	if resolved.profile != nil {
+		cache.Profile = *resolved.profile
+	}
+	if resolved.cacheDir != "" {
+		queue, err := LoadFetchQueue(filepath.Join(resolved.cacheDir, "queue.json"))
+		if err != nil {
+			return nil, err
+		}
+		cache.Queue = queue
+	}
+	if resolved.renderer != nil {
+		cache.QueryMedia = HybridQuery(cache.QueryMedia, resolved.renderer, MediaPageComplete, resolved.logger)
+	}
+	return cache, nil
+}
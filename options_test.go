@@ -0,0 +1,107 @@
+package proxerscrape
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestNewCache(t *testing.T) {
+	cacheDir := filepath.Join(t.TempDir(), "nested", "cache")
+	cache, err := NewCache(WithCacheDir(cacheDir), WithLoginCookie("key", "value"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(cacheDir); err != nil {
+		t.Errorf("Cache directory wasn't created: %v", err)
+	}
+	if cache.Store == nil || cache.QueryMedia == nil {
+		t.Errorf("Cache isn't fully initialised: %+v", cache)
+	}
+
+	store := NewMemoryStore()
+	cache, err = NewCache(WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cache.Store != store {
+		t.Error("Custom store wasn't used")
+	}
+}
+
+func TestCreateDefaultCache_Unusable(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the user cache directory is only configurable on Linux")
+	}
+	// The cache directory can't be created inside of a file.
+	file := filepath.Join(t.TempDir(), "file")
+	os.WriteFile(file, nil, 0o644)
+	t.Setenv("XDG_CACHE_HOME", file)
+
+	if _, err := CreateDefaultCache(); err == nil {
+		t.Error("Expected an unusable cache directory to fail")
+	}
+}
+
+func TestInitialize_Concurrent(t *testing.T) {
+	cacheDir := filepath.Join(t.TempDir(), "cache")
+	var waitGroup sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			errs <- Initialize(cacheDir)
+		}()
+	}
+	waitGroup.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := os.Stat(cacheDir); err != nil {
+		t.Errorf("Cache directory wasn't created: %v", err)
+	}
+}
+
+func TestNewCache_BaseURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if cookie, err := request.Cookie("key"); err != nil || cookie.Value != "value" {
+			writer.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(writer, "<html><body>%s</body></html>", request.URL.Path)
+	}))
+	defer server.Close()
+
+	cache, err := NewCache(WithStore(NewMemoryStore()), WithBaseURL(server.URL+"/"), WithLoginCookie("key", "value"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cache.BaseURL() != server.URL {
+		t.Errorf("Unexpected base URL %s", cache.BaseURL())
+	}
+
+	response, err := cache.QueryMedia(&Media{ProxerURL: "/info/296"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	body, _ := io.ReadAll(response.Body)
+	if response.StatusCode != http.StatusOK || !strings.Contains(string(body), "/info/296") {
+		t.Errorf("Test server wasn't queried properly: %d %s", response.StatusCode, body)
+	}
+
+	if _, err := NewCache(WithStore(NewMemoryStore()), WithBaseURL("localhost")); err == nil {
+		t.Error("Expected base URL without host to fail")
+	}
+}
@@ -1,98 +1,154 @@
 package proxerscrape
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"golang.org/x/net/html"
 )
 
-type MediaType string
-
-const (
-	Series  MediaType = "Animeserie"
-	Special MediaType = "Special"
-	Movie   MediaType = "Movie"
-
-	Manga     MediaType = "Mangaserie"
-	Webtoon   MediaType = "Webtoon"
-	Manhwa    MediaType = "Manhwa"
-	Doujinshi MediaType = "Doujinshi"
-)
-
-type Status string
-
-const (
-	// Finished means all episodes of have been released. This doesn't imply
-	// that all seasons have the same status.
-	Finished Status = "Abgeschlossen"
-	// PreAiring means yet to be released.
-	PreAiring Status = "Nicht erschienen (Pre-Airing)"
-	// Airing means the series has been released, but not all episodes have
-	// been released yet.
-	Airing Status = "Airing"
-)
-
-// Season represents the four seasons of the year. Proxer.me represents these
-// as integers internally.
-type Season string
-
-const (
-	// Winter
-	Q1 Season = "Q1"
-	// Spring
-	Q2 Season = "Q2"
-	// Summer
-	Q3 Season = "Q3"
-	// Autumn
-	Q4 Season = "Q4"
-)
-
 type ReleasePeriod struct {
-	FromSeason Season
-	FromYear   uint
+	FromSeason Season `json:"fromSeason,omitempty"`
+	FromYear   uint   `json:"fromYear,omitempty"`
 
-	ToSeason Season
-	ToYear   uint
+	ToSeason Season `json:"toSeason,omitempty"`
+	ToYear   uint   `json:"toYear,omitempty"`
 }
 
-// Media is the base for different types of media, such as Media or Manga.
+// Media is the base for different types of media, such as anime or manga.
 // Note that names such as `EpisodesWatched` are anime specific, but work for
-// Manga chapters as well. While use of interface would make the API more
-// clean, I simply don't care ;).
+// Manga chapters as well. Use AsManga for accessing them by their manga
+// names.
 type Media struct {
 	// Data present in profile
 
-	EpisodesWatched uint16
-	EpisodeCount    uint16
-	Title           string
-	Type            MediaType
-	ProxerURL       string
-	Status          Status
+	// ID is the numeric ID of the entry as used in proxer.me URLs, which is
+	// extracted from ProxerURL while parsing. 0 if unknown.
+	ID              uint      `json:"id,omitempty"`
+	EpisodesWatched uint16    `json:"episodesWatched"`
+	EpisodeCount    uint16    `json:"episodeCount"`
+	Title           string    `json:"title"`
+	Type            MediaType `json:"type"`
+	ProxerURL       string    `json:"proxerUrl"`
+	Status          Status    `json:"status"`
+	// RawType is the type as displayed by proxer.me, for example
+	// "Animeserie". Type is the normalized form of this.
+	RawType string `json:"rawType,omitempty"`
+	// RawStatus is the status as displayed by proxer.me, for example
+	// "Nicht erschienen (Pre-Airing)". Status is the normalized form of this.
+	RawStatus string `json:"rawStatus,omitempty"`
+	// UserRating is the amount of stars the user rated the entry with, 0
+	// meaning it hasn't been rated.
+	UserRating uint8 `json:"userRating,omitempty"`
+	// Category is the watchlist category the entry is in.
+	Category ListCategory `json:"category"`
+	// Review is the comment the user has written about the entry. It's not
+	// part of the profile, see ApplyComments.
+	Review string `json:"review,omitempty"`
 
 	// Lazy data
 
-	EnglishTitle  string
-	GermanTitle   string
-	JapaneseTitle string
-	Synonyms      []string
-	Rating        float64
-	ReleasePeriod ReleasePeriod
-	Generes       []string
+	EnglishTitle  string   `json:"englishTitle,omitempty"`
+	GermanTitle   string   `json:"germanTitle,omitempty"`
+	JapaneseTitle string   `json:"japaneseTitle,omitempty"`
+	Synonyms      []string `json:"synonyms,omitempty"`
+	Rating        float64  `json:"rating,omitempty"`
+	// Votes is the amount of ratings the average Rating is based on, 0 if
+	// unknown.
+	Votes         uint          `json:"votes,omitempty"`
+	ReleasePeriod ReleasePeriod `json:"releasePeriod"`
+	Generes       []string      `json:"genres,omitempty"`
+	// GenreIDs are the identifiers proxer.me uses for the genres in its
+	// links, in the same order as Generes. Unlike the display names, these
+	// can be used for building search filters.
+	GenreIDs []string `json:"genreIds,omitempty"`
+	// Tags are only part of pages rendered with javascript, see
+	// WithHeadlessFallback, and are empty otherwise. TagIDs holds their
+	// identifiers in the same order.
+	Tags   []string `json:"tags,omitempty"`
+	TagIDs []string `json:"tagIds,omitempty"`
+	// EpisodeDuration is the runtime of a single episode as stated on the
+	// detail page, 0 if unknown. See EstimatedEpisodeDuration.
+	EpisodeDuration time.Duration `json:"episodeDuration,omitempty"`
+	// Volumes is the amount of volumes of a manga or novel as stated on the
+	// detail page, 0 if unknown. See MangaView for the chapter counts.
+	Volumes uint16 `json:"volumes,omitempty"`
+	// ScanlationStatus is the state of the translation of a manga, which
+	// may differ from the release Status.
+	ScanlationStatus Status `json:"scanlationStatus,omitempty"`
+	// Publisher is the publisher of a novel or manga as stated on the detail
+	// page, for example "Kadokawa".
+	Publisher string `json:"publisher,omitempty"`
+	// Notes are hints given on the detail page, such as where to start
+	// watching or the reading order.
+	Notes []string `json:"notes,omitempty"`
+	// Unavailable indicates that the detail page doesn't exist anymore, even
+	// though the entry is still listed in the profile.
+	Unavailable bool `json:"unavailable,omitempty"`
+	// RestrictedAccess indicates that the detail page can only be viewed when
+	// logged in, which is usually the case for 18+ entries.
+	RestrictedAccess bool `json:"restrictedAccess,omitempty"`
+}
+
+// ProxerID returns the ID of the entry as used in proxer.me URLs, or an
+// empty string if unknown.
+func (item *Media) ProxerID() string {
+	return formatID(item.mediaID())
+}
+
+// Exportable reports whether the entry should be exported to or synced with
+// other services. Unavailable entries aren't, since they don't exist on
+// proxer.me anymore, so their mappings can't be verified. Entries with
+// RestrictedAccess are, since only their extra data is missing, while the
+// data of the watchlist is complete.
+func (item *Media) Exportable() bool {
+	return !item.Unavailable
+}
+
+// mediaID returns the ID of the entry. Entries that weren't parsed, such as
+// those created from an ID passed on the command line, only have a URL, which
+// the ID is extracted from instead.
+func (item *Media) mediaID() uint {
+	if item.ID != 0 {
+		return item.ID
+	}
+	return parseMediaID(item.ProxerURL)
+}
 
-	// Tags can't be parsed, since they aren't displayed on initial pageload.
-	// FIXME A potential rework would be the use of:
-	// https://pkg.go.dev/github.com/chromedp/chromedp
-	// Tags            []string
-	// SpoilerTags     []string
-	// UnconfirmedTags []string
+// parseMediaID extracts the ID from a link to an info page, such as
+// "/info/296#top". 0 is returned if the link doesn't contain an ID.
+func parseMediaID(proxerURL string) uint {
+	match := proxerIDRegex.FindStringSubmatch(proxerURL)
+	if match == nil {
+		return 0
+	}
+	id, err := strconv.ParseUint(match[1], 10, 0)
+	if err != nil {
+		return 0
+	}
+	return uint(id)
+}
+
+// formatMediaID returns the ID of a link to an info page as used in proxer.me
+// URLs, or an empty string if the link doesn't contain an ID.
+func formatMediaID(proxerURL string) string {
+	return formatID(parseMediaID(proxerURL))
+}
+
+func formatID(id uint) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.FormatUint(uint64(id), 10)
 }
 
 type WatchlistCategory struct {
@@ -112,7 +168,7 @@ func (wc *WatchlistCategory) populateMediaWithExtraData(retrieveRawData MediaRaw
 	//Make sure reader is being closed, even on panic or early return.
 	defer reader.Close()
 
-	document, errParse := goquery.NewDocumentFromReader(reader)
+	document, errParse := newDocument(reader)
 	if errParse != nil {
 		return errParse
 	}
@@ -124,149 +180,316 @@ func (wc *WatchlistCategory) populateMediaWithExtraData(retrieveRawData MediaRaw
 	// indicator.
 	// FIXME If this happens again, I should check whether the "state"
 	// field is relevant.
-	title := document.Find("title").First().Get(0).FirstChild.Data
-	if strings.Contains(title, "404") {
-		log.Printf("Entry for '%s'(%s) is a dead link.\n", item.Title, item.ProxerURL)
+	titleNode := document.Find("title").First()
+	if titleNode.Length() == 0 {
+		return &ParseError{Selector: "title", URL: item.ProxerURL}
+	}
+	if strings.Contains(titleNode.Text(), "404") {
 		// Since we don't want to cache a 404 page, we need to invoke
 		// the invalidator.
 		if errInvalidate := cacheInvalidator(); errInvalidate != nil {
-			log.Printf("Error invalidating cache entry for '%s': %s.\n", item.Title, errInvalidate)
+			return errInvalidate
 		}
-		// We don't want to error here, as we want to proceed parsing the
-		// other entries, since there hasn't been an actual error here.
-		return nil
+		item.Unavailable = true
+		return ErrDeadLink
 	}
 
-	//FIXME Provide way to login.
-	potentialPleaseLoginTitle := document.Find("h3").First()
-	if potentialPleaseLoginTitle.Length() == 1 &&
-		strings.HasPrefix(
-			strings.TrimSpace(potentialPleaseLoginTitle.Get(0).FirstChild.Data),
-			"Bitte logge dich ein",
-		) {
-		log.Printf("Entry for '%s'(%s) requries a login, since the rating is most likeky 18+.\n", item.Title, item.ProxerURL)
-		log.Println("If you wish to be able to retrieve these entries, please set the environment variables `LOGIN_COOKIE_KEY` and `LOGIN_COOKIE_VALUE` to `joomla_remember_me_XXX=XXX`.")
+	if isLoginPage(document) {
 		// Since we don't want to cache a "please login ..." page, we need
 		// to invoke the invalidator.
 		if errInvalidate := cacheInvalidator(); errInvalidate != nil {
-			log.Printf("Error invalidating cache entry for '%s': %s.\n", item.Title, errInvalidate)
+			return errInvalidate
 		}
-		// We don't want to error here, as we want to proceed parsing the
-		// other entries, since there hasn't been an actual error here.
-		return nil
+		item.RestrictedAccess = true
+		return ErrLoginRequired
 	}
 
-	// Ratelimited, this is a coding error.
+	// Ratelimited, the cache should've already cooled down and retried.
 	if document.Find("script[src='//www.google.com/recaptcha/api.js']").Length() > 0 {
-		return errors.New("proxer.me ratelimit has been hit, captcha required")
+		return ErrRateLimited
 	}
 
-	document.Find("table[class=details]").First().Find("tbody > tr").Each(func(i int, s *goquery.Selection) {
-		cell := s.Find("td").First()
-		key := cell.Find("b").First().Get(0).FirstChild.Data
-		cell = cell.Next()
-		switch key {
-		case "Englischer Titel":
-			{
-				item.EnglishTitle = cell.Get(0).FirstChild.Data
-			}
-		case "Deutscher Titel":
-			{
-				item.GermanTitle = cell.Get(0).FirstChild.Data
-			}
-		case "Japanischer Titel":
-			{
-				item.JapaneseTitle = cell.Get(0).FirstChild.Data
-			}
-		case "Synonym":
-			{
-				item.Synonyms = append(item.Synonyms, cell.Get(0).FirstChild.Data)
-			}
-		case "Genres":
-			{
-				for _, genreNode := range cell.Find("a[class=genreTag]").Nodes {
-					item.Generes = append(item.Generes, genreNode.FirstChild.Data)
-				}
-			}
-		case "Season":
-			{
-				children := cell.Find("a").Nodes
-				if len(children) >= 1 {
-					season, year, err := parseSeason(children[0].FirstChild.Data)
-					if err != nil {
-						//TODO Handle properly; Can't return outer function or use error channel here.
-						//FIXME Make custom loop, see impl of Each(...).
-						return
-					}
+	if err := requireSelectors(document, item.ProxerURL, detailPageSelectors...); err != nil {
+		return err
+	}
 
-					item.ReleasePeriod.FromSeason = season
-					item.ReleasePeriod.FromYear = year
-					if len(children) >= 2 {
-						season, year, err := parseSeason(children[1].FirstChild.Data)
-						if err != nil {
-							//TODO Handle properly; Can't return outer function or use error channel here.
-							//FIXME Make custom loop, see impl of Each(...).
-							return
-						}
-
-						item.ReleasePeriod.ToSeason = season
-						item.ReleasePeriod.ToYear = year
-					}
-				}
-			}
+	rows := document.Find("table[class=details]").First().Find("tbody > tr")
+	for index := range rows.Nodes {
+		if err := parseDetailRow(item, rows.Eq(index)); err != nil {
+			return &ParseError{Selector: "table[class=details]", URL: item.ProxerURL, Err: err}
 		}
-	})
+	}
 
 	//Rating
 	avgMatches := document.Find(".average").First()
-	ratingString := avgMatches.Get(0).FirstChild.Data
-	ratingFloat, errParse := strconv.ParseFloat(ratingString, 64)
+	ratingFloat, errParse := strconv.ParseFloat(strings.TrimSpace(avgMatches.Text()), 64)
 	if errParse != nil {
-		return errParse
+		return &ParseError{Selector: ".average", URL: item.ProxerURL, Err: errParse}
 	}
 
 	item.Rating = ratingFloat
+	// The vote count is optional, as it's missing for entries without votes.
+	votes := document.Find("[itemprop=ratingCount], .count").First()
+	if count, err := strconv.ParseUint(strings.TrimSpace(votes.Text()), 10, 0); err == nil {
+		item.Votes = uint(count)
+	}
 	return nil
 }
 
+// parseDetailRow parses a single row of the details table of a detail page.
+// Rows without a key or value, such as separators, are skipped.
+func parseDetailRow(item *Media, row *goquery.Selection) error {
+	cell := row.Find("td").First()
+	key := strings.TrimSpace(cell.Find("b").First().Text())
+	cell = cell.Next()
+	value := textOf(cell)
+	if key == "" || cell.Length() == 0 {
+		return nil
+	}
+
+	switch key {
+	case "Englischer Titel":
+		item.EnglishTitle = normalizeText(value)
+	case "Deutscher Titel":
+		item.GermanTitle = normalizeText(value)
+	case "Japanischer Titel":
+		item.JapaneseTitle = normalizeText(value)
+	case "Synonym":
+		item.Synonyms = append(item.Synonyms, normalizeText(value))
+	case "Empfohlene Startzeitpunkte", "Startzeitpunkt", "Hinweis", "Leserichtung", "Reihenfolge":
+		if note := strings.Join(strings.Fields(cell.Text()), " "); note != "" {
+			item.Notes = append(item.Notes, normalizeText(note))
+		}
+	case "Episodenlänge", "Laufzeit", "Dauer":
+		item.EpisodeDuration = parseEpisodeDuration(cell.Text())
+	case "Bände", "Volumes":
+		item.Volumes = parseVolumes(value)
+	case "Übersetzungsstatus", "Scanlation":
+		item.ScanlationStatus = parseScanlationStatus(value)
+	case "Verlag", "Publisher":
+		// The publisher is usually linked, so the nested text is used.
+		item.Publisher = normalizeText(strings.Join(strings.Fields(cell.Text()), " "))
+	case "Genres":
+		cell.Find("a[class=genreTag]").Each(func(_ int, genreLink *goquery.Selection) {
+			name := normalizeText(textOf(genreLink))
+			item.Generes = append(item.Generes, name)
+			id, _ := genreLink.Attr("href")
+			item.GenreIDs = append(item.GenreIDs, parseLinkID(id, "genre", name))
+		})
+	case "Tags":
+		tagLinks(cell).Each(func(_ int, tagLink *goquery.Selection) {
+			name := normalizeText(textOf(tagLink))
+			item.Tags = append(item.Tags, name)
+			id, _ := tagLink.Attr("href")
+			item.TagIDs = append(item.TagIDs, parseLinkID(id, "tag", name))
+		})
+	case "Season":
+		links := cell.Find("a")
+		if links.Length() >= 1 {
+			season, year, err := parseSeason(textOf(links.Eq(0)))
+			if err != nil {
+				return fmt.Errorf("invalid season: %w", err)
+			}
+			item.ReleasePeriod.FromSeason = season
+			item.ReleasePeriod.FromYear = year
+		}
+		if links.Length() >= 2 {
+			season, year, err := parseSeason(textOf(links.Eq(1)))
+			if err != nil {
+				return fmt.Errorf("invalid season: %w", err)
+			}
+			item.ReleasePeriod.ToSeason = season
+			item.ReleasePeriod.ToYear = year
+		}
+	}
+	return nil
+}
+
+// ItemError is the error that occurred while loading the extra data of a
+// single item.
+type ItemError struct {
+	Item *Media
+	Err  error
+}
+
+func (err *ItemError) Error() string {
+	return fmt.Sprintf("error loading %s: %s", describeMedia(err.Item), RedactText(err.Err.Error()))
+}
+
+func (err *ItemError) Unwrap() error {
+	return err.Err
+}
+
+// ExtraDataError holds the errors of all items that failed to load.
+type ExtraDataError []*ItemError
+
+func (err ExtraDataError) Error() string {
+	messages := make([]string, 0, len(err))
+	for _, itemError := range err {
+		messages = append(messages, itemError.Error())
+	}
+	return fmt.Sprintf("%d items failed: %s", len(err), strings.Join(messages, "; "))
+}
+
+// Is reports whether any of the item errors matches the target, so that
+// errors.Is can be used on the aggregate.
+func (err ExtraDataError) Is(target error) bool {
+	for _, itemError := range err {
+		if errors.Is(itemError, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first item error that matches the target, analogous to Is.
+func (err ExtraDataError) As(target any) bool {
+	for _, itemError := range err {
+		if errors.As(itemError, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultExtraDataWorkers is the amount of items LoadExtraData retrieves at
+// once. Since all retrievals share a ratelimiter, more workers rarely help.
+const DefaultExtraDataWorkers = 4
+
+// LoadOptions configures LoadExtraDataContext.
+type LoadOptions struct {
+	// Workers is the amount of items retrieved at once. Defaults to
+	// DefaultExtraDataWorkers.
+	Workers int
+	// Progress is called for every item that is started, done or failed.
+	// It's called from multiple goroutines, but never concurrently.
+	Progress ProgressFunc
+	// Logger receives a message for every item that failed. If nil,
+	// nothing is logged.
+	Logger Logger
+}
+
 // LoadExtraData will retrieve additional information for all animes in this
 // category and load it into the respective *Anime. Calling this a second time
 // will not have an effect.
 func (wc *WatchlistCategory) LoadExtraData(retrieveRawData MediaRawDataRetriever) error {
+	return wc.LoadExtraDataContext(context.Background(), retrieveRawData, LoadOptions{})
+}
+
+// LoadExtraDataContext is like LoadExtraData, but configurable via the given
+// options. Once the context is cancelled or an item hits the ratelimit, no
+// further items are retrieved, while already running retrievals are
+// finished. If items fail, all others are still loaded and an
+// ExtraDataError is returned. Items that were never retrieved are part of
+// it as well, carrying the reason the run stopped.
+func (wc *WatchlistCategory) LoadExtraDataContext(ctx context.Context, retrieveRawData MediaRawDataRetriever, options LoadOptions) error {
 	if wc.extraDataLoaded {
 		return nil
 	}
-
+	workers := options.Workers
+	if workers < 1 {
+		workers = DefaultExtraDataWorkers
+	}
+	progress := newProgressReporter(options.Progress, len(wc.Data))
+	logger := loggerOrDiscard(options.Logger)
+
+	// Workers only return an error if we run into an error that's not
+	// related to data, but something that's most likely a coding
+	// error / feature not implemented. A failing item doesn't stop the
+	// others from being loaded.
+	var errorsLock sync.Mutex
+	var itemErrors ExtraDataError
+	// Once the captcha wall has been hit, every further item would sit
+	// through the same cooldowns, so we stop feeding new items.
+	rateLimited := make(chan struct{})
+	var rateLimitedOnce sync.Once
+	jobs := make(chan *Media)
 	var waitGroup sync.WaitGroup
-	errChannel := make(chan error, 1)
-	doneChannel := make(chan struct{}, 1)
-	go func() {
-		waitGroup.Wait()
-		doneChannel <- struct{}{}
-	}()
-
-	// This loop only returns an error if we run into an error that's not
-	//related to data, but something that's most likely a coding
-	//error / feature not implemented.
-	for _, item := range wc.Data {
+	for i := 0; i < workers && i < len(wc.Data); i++ {
 		waitGroup.Add(1)
-		go func(item *Media) {
+		go func() {
 			defer waitGroup.Done()
-			if err := wc.populateMediaWithExtraData(retrieveRawData, item); err != nil {
-				//FIXME The early exit here will cause the background routine
-				//to run forever, since the waitGroup isn't done.
-				errChannel <- err
+			for item := range jobs {
+				progress.report(ProgressEvent{Type: ProgressStarted, Item: item})
+				if err := wc.populateMediaWithExtraData(retrieveRawData, item); err != nil {
+					errorsLock.Lock()
+					itemErrors = append(itemErrors, &ItemError{Item: item, Err: err})
+					errorsLock.Unlock()
+					logItemError(logger, item, err)
+					progress.report(ProgressEvent{Type: ProgressFailed, Item: item, Err: err})
+					if errors.Is(err, ErrRateLimited) {
+						rateLimitedOnce.Do(func() { close(rateLimited) })
+					}
+				} else {
+					progress.report(ProgressEvent{Type: ProgressDone, Item: item})
+				}
 			}
-		}(item)
+		}()
 	}
 
-	select {
-	case err := <-errChannel:
-		return err
-	case <-doneChannel:
-		wc.extraDataLoaded = true
-		return nil
+	var skipped []*Media
+	var skipReason error
+feedLoop:
+	for index, item := range wc.Data {
+		select {
+		case jobs <- item:
+			continue
+		case <-ctx.Done():
+			skipReason = ctx.Err()
+		case <-rateLimited:
+			skipReason = ErrRateLimited
+		}
+		skipped = wc.Data[index:]
+		break feedLoop
+	}
+	close(jobs)
+	waitGroup.Wait()
+
+	if len(skipped) > 0 {
+		logger.Printf("Stopped loading, skipping %d items: %s\n", len(skipped), skipReason)
+		for _, item := range skipped {
+			itemErrors = append(itemErrors, &ItemError{Item: item, Err: skipReason})
+		}
+	}
+	if len(itemErrors) > 0 {
+		return itemErrors
+	}
+	wc.extraDataLoaded = true
+	return nil
+}
+
+func logItemError(logger Logger, item *Media, err error) {
+	switch {
+	case item.Unavailable:
+		logger.Printf("Entry for %s is a dead link.\n", describeMedia(item))
+	case item.RestrictedAccess:
+		logger.Printf("Entry for %s requires a login, since the rating is most likely 18+.\n", describeMedia(item))
+	default:
+		logger.Printf("Error loading %s: %s\n", describeMedia(item), RedactText(err.Error()))
+	}
+}
+
+// tagLinks returns the links of the tags cell of the details table, which
+// javascript fills after the page has loaded.
+func tagLinks(cell *goquery.Selection) *goquery.Selection {
+	return cell.Find("a[href]")
+}
+
+// parseLinkID extracts the ID from genre or tag links, which are either of
+// the form `/genre/Action` or `/search?genre=Action`. If no ID is found, the
+// fallback is returned.
+func parseLinkID(href, parameter, fallback string) string {
+	link, err := url.Parse(href)
+	if err != nil {
+		return fallback
 	}
+	if id := link.Query().Get(parameter); id != "" {
+		return id
+	}
+	if segments := strings.Split(strings.Trim(link.Path, "/"), "/"); len(segments) == 2 && segments[0] == parameter {
+		return segments[1]
+	}
+	return fallback
 }
 
 func parseSeason(seasonRaw string) (Season, uint, error) {
@@ -275,20 +498,23 @@ func parseSeason(seasonRaw string) (Season, uint, error) {
 	if _, err := fmt.Sscanf(seasonRaw, "%s %d", &seasonString, &year); err != nil {
 		return "", 0, nil
 	}
-	var season Season
-	switch seasonString {
-	case "Winter":
-		season = Q1
-	case "Frühling":
-		season = Q2
-	case "Sommer":
-		season = Q3
-	case "Herbst":
-		season = Q4
-	}
+	season, _ := ParseSeasonName(seasonString)
 	return season, year, nil
 }
 
+// ListCategory is one of the four categories of a watchlist.
+type ListCategory string
+
+const (
+	CategoryWatched           ListCategory = "watched"
+	CategoryCurrentlyWatching ListCategory = "watching"
+	CategoryToWatch           ListCategory = "planned"
+	CategoryStoppedWatching   ListCategory = "stopped"
+)
+
+// ListCategories contains all categories in the order displayed by proxer.me.
+var ListCategories = []ListCategory{CategoryWatched, CategoryCurrentlyWatching, CategoryToWatch, CategoryStoppedWatching}
+
 // Watchlist holds the different types of watchlists for a profile.
 type Watchlist struct {
 	Watched           WatchlistCategory
@@ -297,93 +523,322 @@ type Watchlist struct {
 	StoppedWatching   WatchlistCategory
 }
 
+// Category returns the watchlist category of the given type.
+func (watchlist *Watchlist) Category(category ListCategory) *WatchlistCategory {
+	switch category {
+	case CategoryWatched:
+		return &watchlist.Watched
+	case CategoryCurrentlyWatching:
+		return &watchlist.CurrentlyWatching
+	case CategoryToWatch:
+		return &watchlist.ToWatch
+	case CategoryStoppedWatching:
+		return &watchlist.StoppedWatching
+	}
+	return nil
+}
+
+// All returns the entries of all categories.
+func (watchlist *Watchlist) All() []*Media {
+	var all []*Media
+	for _, category := range ListCategories {
+		all = append(all, watchlist.Category(category).Data...)
+	}
+	return all
+}
+
+// LoadExtraData loads the extra data of all categories at once, see
+// WatchlistCategory.LoadExtraData. Entries present in multiple categories
+// are only retrieved once, see Registry. Anime and manga are retrieved via their
+// respective ratelimiter, novels share the one of manga.
+func (watchlist *Watchlist) LoadExtraData(cache *Cache) error {
+	return watchlist.LoadExtraDataContext(context.Background(), cache, LoadOptions{})
+}
+
+// LoadExtraDataContext is like LoadExtraData, but configurable via the
+// given options, see WatchlistCategory.LoadExtraDataContext.
+func (watchlist *Watchlist) LoadExtraDataContext(ctx context.Context, cache *Cache, options LoadOptions) error {
+	registry := NewRegistry()
+	for _, category := range ListCategories {
+		if !watchlist.Category(category).extraDataLoaded {
+			registry.Add(watchlist.Category(category).Data...)
+		}
+	}
+	err := registry.LoadExtraDataContext(ctx, cache, options)
+	if err != nil {
+		return err
+	}
+	for _, category := range ListCategories {
+		watchlist.Category(category).extraDataLoaded = true
+	}
+	return nil
+}
+
+// copyExtraData copies all data loaded by LoadExtraData from the source.
+func (item *Media) copyExtraData(source *Media) {
+	item.EnglishTitle = source.EnglishTitle
+	item.GermanTitle = source.GermanTitle
+	item.JapaneseTitle = source.JapaneseTitle
+	item.Synonyms = source.Synonyms
+	item.Rating = source.Rating
+	item.Votes = source.Votes
+	item.ReleasePeriod = source.ReleasePeriod
+	item.Generes = source.Generes
+	item.GenreIDs = source.GenreIDs
+	item.Tags = source.Tags
+	item.TagIDs = source.TagIDs
+	item.EpisodeDuration = source.EpisodeDuration
+	item.Volumes = source.Volumes
+	item.ScanlationStatus = source.ScanlationStatus
+	item.Publisher = source.Publisher
+	item.Notes = source.Notes
+	item.Unavailable = source.Unavailable
+	item.RestrictedAccess = source.RestrictedAccess
+}
+
 // ParseProfileMediaTab takes an HTML dump any type of `Media` tab, such as
 // `Anime` of a profile and parses the contained watchlists. Note that the
 // resulting Watchlist only contains  certaindata. You'll have to call
 // WatchlistCategory.LoadExtraData on the respective lists if you require
-// additional data.
+// additional data. Rows that can't be parsed are skipped, see
+// ParseProfileMediaTabWithOptions.
 func ParseProfileMediaTab(reader io.Reader) (Watchlist, error) {
+	watchlist, _, err := ParseProfileMediaTabWithOptions(reader, ParseOptions{})
+	return watchlist, err
+}
+
+// ParseOptions configures ParseProfileMediaTabWithOptions.
+type ParseOptions struct {
+	// Logger receives a debug message for every parsed entry. If nil,
+	// nothing is logged.
+	Logger Logger
+}
+
+// ParseWarning reports a row of a profile tab that has been skipped, since
+// it couldn't be parsed.
+type ParseWarning struct {
+	Category ListCategory
+	// Row is the number of the row within the category, starting at 1.
+	Row int
+	// Title is the title of the entry, if it could be parsed.
+	Title string
+	Err   error
+}
+
+func (warning ParseWarning) String() string {
+	if warning.Title == "" {
+		return fmt.Sprintf("skipped row %d of %s: %s", warning.Row, warning.Category, warning.Err)
+	}
+	return fmt.Sprintf("skipped row %d of %s ('%s'): %s", warning.Row, warning.Category, warning.Title, warning.Err)
+}
+
+// ParseProfileMediaTabWithOptions is ParseProfileMediaTab, but allows
+// debugging the parser via the given options. Additionally, the rows that
+// have been skipped are returned as warnings.
+func ParseProfileMediaTabWithOptions(reader io.Reader, options ParseOptions) (Watchlist, []ParseWarning, error) {
 	watchlist := Watchlist{}
-	document, parseError := goquery.NewDocumentFromReader(reader)
+	document, parseError := newDocument(reader)
 	if parseError != nil {
-		return watchlist, parseError
+		return watchlist, nil, parseError
+	}
+	if err := requireSelectors(document, "", profileTabSelector); err != nil {
+		return watchlist, nil, err
 	}
 
-	watchlist.Watched = WatchlistCategory{Data: parseProfileTabMediaTable(document.Find("a[name=state0]").Next())}
-	watchlist.CurrentlyWatching = WatchlistCategory{Data: parseProfileTabMediaTable(document.Find("a[name=state1]").Next())}
-	watchlist.ToWatch = WatchlistCategory{Data: parseProfileTabMediaTable(document.Find("a[name=state2]").Next())}
-	watchlist.StoppedWatching = WatchlistCategory{Data: parseProfileTabMediaTable(document.Find("a[name=state3]").Next())}
+	var warnings []ParseWarning
+	// The anchors are named after the category index, starting at state0.
+	for index, category := range ListCategories {
+		data, categoryWarnings := parseProfileTabMediaTable(document.Find(fmt.Sprintf("a[name=state%d]", index)).Next(), loggerOrDiscard(options.Logger))
+		for _, item := range data {
+			item.Category = category
+		}
+		for _, warning := range categoryWarnings {
+			warning.Category = category
+			warnings = append(warnings, warning)
+		}
+		*watchlist.Category(category) = WatchlistCategory{Data: data}
+	}
+
+	return watchlist, warnings, nil
+}
+
+// textOf returns the first text node directly below the first element of the
+// selection, such as "Mangaserie" for `<td>Mangaserie<br>Manhwa</td>`. Unlike
+// accessing FirstChild directly, this can't panic on unexpected markup.
+func textOf(selection *goquery.Selection) string {
+	if texts := textNodesOf(selection); len(texts) > 0 {
+		return texts[0]
+	}
+	return ""
+}
+
+// textNodesOf returns all text nodes directly below the first element of the
+// selection.
+func textNodesOf(selection *goquery.Selection) []string {
+	node := firstNode(selection)
+	if node == nil {
+		return nil
+	}
+	var texts []string
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.TextNode {
+			texts = append(texts, child.Data)
+		}
+	}
+	return texts
+}
 
-	return watchlist, nil
+// firstNode returns the first node of the selection, or nil if it's empty.
+// Unlike Get(0), this doesn't panic.
+func firstNode(selection *goquery.Selection) *html.Node {
+	if selection.Length() == 0 {
+		return nil
+	}
+	return selection.Get(0)
 }
 
-func getAttribute(node *html.Node, name string) string {
+// attrOf returns the value of the given attribute, ignoring its case. The
+// node may be nil.
+func attrOf(node *html.Node, key string) (string, bool) {
+	if node == nil {
+		return "", false
+	}
 	for _, attr := range node.Attr {
-		if strings.EqualFold(attr.Key, name) {
-			return attr.Val
+		if strings.EqualFold(attr.Key, key) {
+			return attr.Val, true
 		}
 	}
+	return "", false
+}
 
-	return ""
+// detailPageSelectors are the elements every detail page has, see
+// requireSelectors.
+var detailPageSelectors = []string{".average", "table[class=details]"}
+
+// profileTabSelector matches the tables of the categories, of which at least
+// one is expected to be present in every profile tab.
+const profileTabSelector = "a[name^=state] + table"
+
+// requireSelectors checks whether each selector matches at least one element.
+// Otherwise, a ParseError wrapping ErrLayoutChanged is returned for the first
+// missing one, instead of failing somewhere deep inside the parser.
+func requireSelectors(document *goquery.Document, url string, selectors ...string) error {
+	for _, selector := range selectors {
+		if document.Find(selector).Length() == 0 {
+			return &ParseError{Selector: selector, URL: url, Err: ErrLayoutChanged}
+		}
+	}
+	return nil
 }
 
-func parseProfileTabMediaTable(table *goquery.Selection) []*Media {
-	spaceCleaner := regexp.MustCompile(`\s{2,}`)
+// titleSpaceRegex matches the line breaks and indentation within titles.
+var titleSpaceRegex = regexp.MustCompile(`\s{2,}`)
+
+// parseProfileTabMediaTable parses the rows of a category table. Rows that
+// don't have the expected structure are skipped and reported as warnings.
+func parseProfileTabMediaTable(table *goquery.Selection, logger Logger) ([]*Media, []ParseWarning) {
 	rows := table.Children().Children()
-	entries := make([]*Media, 0, rows.Size()-2)
-	rows.Each(func(i int, s *goquery.Selection) {
-		if i >= 2 {
-			item := Media{}
-
-			cells := s.Children()
-			cell := cells.First()
-
-			//Status
-			status, present := cell.Find("img").First().Attr("title")
-			if !present {
-				log.Panicf("Anime '%s' doesn't have a status.", item.Title)
-			}
-			item.Status = Status(status)
-
-			//URL to info page
-			cell = cell.Next()
-			link := cell.Find("a").Get(0)
-			item.ProxerURL = getAttribute(link, "href")
-
-			//Name
-			item.Title = spaceCleaner.ReplaceAllString(link.FirstChild.Data, " ")
-
-			//Type of Media
-			cell = cell.Next()
-			baseType := cell.Get(0).FirstChild.Data
-			// We don't wanna use the concrete types for anime, since they
-			// don't provide value. This is different for manga, since there's
-			// Manhwa, Webtoon and more.
-			if baseType == string(Series) || baseType == string(Movie) || baseType == string(Special) {
-				item.Type = MediaType(baseType)
-			} else {
-				if cell.Get(0).FirstChild.NextSibling != nil && cell.Get(0).FirstChild.NextSibling.NextSibling != nil {
-					concreteType := cell.Get(0).FirstChild.NextSibling.NextSibling.Data
-					item.Type = MediaType(concreteType)
-				} else {
-					item.Type = MediaType(baseType)
-				}
-			}
-			fmt.Println(item.Type)
+	// The first two rows are the header and the filter.
+	if rows.Length() < 2 {
+		return []*Media{}, nil
+	}
+	rows = rows.Slice(2, goquery.ToEnd)
+	entries := make([]*Media, 0, rows.Length())
+	var warnings []ParseWarning
+	rows.Each(func(i int, row *goquery.Selection) {
+		item, err := parseProfileTabRow(row, logger)
+		if err != nil {
+			warnings = append(warnings, ParseWarning{Row: i + 1, Title: item.Title, Err: err})
+			return
+		}
+		entries = append(entries, item)
+	})
 
-			//Skip review
-			cell = cell.Next()
+	return entries, warnings
+}
 
-			//Episodecounts
-			cell = cell.Next()
-			_, scanError := fmt.Sscanf(cell.Find("span").Get(0).FirstChild.Data, "%d / %d", &item.EpisodesWatched, &item.EpisodeCount)
-			if scanError != nil {
-				panic(scanError)
-			}
+// parseProfileTabRow parses a single row of a category table. On error, the
+// returned entry contains everything parsed up until then.
+func parseProfileTabRow(row *goquery.Selection, logger Logger) (*Media, error) {
+	item := &Media{}
 
-			entries = append(entries, &item)
+	cells := row.Children()
+
+	//URL to info page and name, parsed first, so that warnings about the
+	//other cells can name the entry.
+	link := cells.Eq(1).Find("a").First()
+	if link.Length() == 0 {
+		return item, errors.New("missing link to info page")
+	}
+	item.ProxerURL, _ = link.Attr("href")
+	item.ID = parseMediaID(item.ProxerURL)
+	item.Title = normalizeText(titleSpaceRegex.ReplaceAllString(link.Text(), " "))
+
+	//Status
+	status, present := attrOf(firstNode(cells.First().Find("img")), "title")
+	if !present {
+		return item, errors.New("missing status")
+	}
+	item.RawStatus = status
+	item.Status = ParseStatus(status)
+
+	//Type of Media
+	cell := cells.Eq(2)
+	types := textNodesOf(cell)
+	if len(types) == 0 {
+		return item, errors.New("missing type")
+	}
+	baseType := types[0]
+	// We don't wanna use the concrete types for anime, since they
+	// don't provide value. This is different for manga, since there's
+	// Manhwa, Webtoon and more.
+	item.RawType = baseType
+	if !ParseMediaType(baseType).IsAnime() && len(types) > 1 {
+		item.RawType = types[1]
+	}
+	item.Type = ParseMediaType(item.RawType)
+	logger.Printf("parsed type of '%s': %s (%s)", item.Title, item.Type, item.RawType)
+
+	//Own rating, displayed as stars, where grey stars are unset.
+	cell = cell.Next()
+	cell.Find("img").Each(func(i int, star *goquery.Selection) {
+		if src, _ := star.Attr("src"); strings.Contains(src, "stern") && !strings.Contains(src, "grau") {
+			item.UserRating++
 		}
 	})
 
-	return entries
+	//Episodecounts
+	cell = cell.Next()
+	progress := cell.Find("span").First()
+	if progress.Length() == 0 {
+		return item, errors.New("missing progress")
+	}
+	var err error
+	if item.EpisodesWatched, item.EpisodeCount, err = parseProgress(progress.Text()); err != nil {
+		return item, err
+	}
+
+	return item, nil
+}
+
+// parseProgress parses progress such as "3 / 12". Unknown counts, displayed
+// as "?", are treated as 0.
+func parseProgress(text string) (uint16, uint16, error) {
+	watchedRaw, countRaw, found := strings.Cut(text, "/")
+	if !found {
+		return 0, 0, fmt.Errorf("invalid progress '%s'", strings.TrimSpace(text))
+	}
+	var values [2]uint16
+	for index, raw := range []string{watchedRaw, countRaw} {
+		raw = strings.TrimSpace(raw)
+		if raw == "?" {
+			continue
+		}
+		value, err := strconv.ParseUint(raw, 10, 16)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid progress '%s': %w", strings.TrimSpace(text), err)
+		}
+		values[index] = uint16(value)
+	}
+	return values[0], values[1], nil
 }
@@ -0,0 +1,370 @@
+package proxerscrape
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const detailPage = `<html><head><title>Tsurune - Anime - Proxer.Me</title></head><body>
+<table class="details"><tbody>
+<tr><td><b>Englischer Titel</b></td><td>Tsurune: Kazemai High School Kyudo Club</td></tr>
+<tr><td><b>Synonym</b></td><td>Tsurune</td></tr>
+<tr><td><b>Genres</b></td><td><a class="genreTag" href="/genre/Drama">Drama</a><a class="genreTag" href="/genre/Sport">Sport</a></td></tr>
+<tr><td><b>Tags</b></td><td><a href="/search?tag=41">Bogenschießen</a> <a href="/tag/7">Schule</a></td></tr>
+<tr><td><b>Season</b></td><td><a href="/season/1">Herbst 2018</a><a href="/season/2">Winter 2019</a></td></tr>
+<tr><td><b>Empfohlene Startzeitpunkte</b></td><td>Episode 1</td></tr>
+</tbody></table>
+<span class="average">8.12</span> (<span class="count">1337</span> Stimmen)
+</body></html>`
+
+func retrieveStatic(page string) MediaRawDataRetriever {
+	return func(*Media) (io.ReadCloser, CacheInvalidator, error) {
+		return io.NopCloser(strings.NewReader(page)), func() error { return nil }, nil
+	}
+}
+
+func TestLoadExtraData(t *testing.T) {
+	item := &Media{ProxerURL: "/info/296"}
+	category := WatchlistCategory{Data: []*Media{item}}
+	if err := category.LoadExtraData(retrieveStatic(detailPage)); err != nil {
+		t.Fatal(err)
+	}
+
+	if item.EnglishTitle != "Tsurune: Kazemai High School Kyudo Club" {
+		t.Errorf("EnglishTitle = %s", item.EnglishTitle)
+	}
+	if item.Rating != 8.12 || item.Votes != 1337 {
+		t.Errorf("Rating = %f (%d votes), instead of 8.12 (1337 votes)", item.Rating, item.Votes)
+	}
+	if strings.Join(item.Generes, ",") != "Drama,Sport" {
+		t.Errorf("Generes = %v", item.Generes)
+	}
+	if strings.Join(item.GenreIDs, ",") != "Drama,Sport" {
+		t.Errorf("GenreIDs = %v", item.GenreIDs)
+	}
+	if strings.Join(item.Tags, ",") != "Bogenschießen,Schule" || strings.Join(item.TagIDs, ",") != "41,7" {
+		t.Errorf("Tags = %v (%v)", item.Tags, item.TagIDs)
+	}
+	if item.ReleasePeriod != (ReleasePeriod{FromSeason: Q4, FromYear: 2018, ToSeason: Q1, ToYear: 2019}) {
+		t.Errorf("ReleasePeriod = %+v", item.ReleasePeriod)
+	}
+	if len(item.Notes) != 1 || item.Notes[0] != "Episode 1" {
+		t.Errorf("Notes = %v", item.Notes)
+	}
+}
+
+func TestLoadExtraDataContext_Bounded(t *testing.T) {
+	var lock sync.Mutex
+	running, maxRunning, retrieved := 0, 0, 0
+	retrieve := func(item *Media) (io.ReadCloser, CacheInvalidator, error) {
+		lock.Lock()
+		running++
+		retrieved++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		lock.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		lock.Lock()
+		running--
+		lock.Unlock()
+		return retrieveStatic(detailPage)(item)
+	}
+
+	category := WatchlistCategory{}
+	for i := 0; i < 20; i++ {
+		category.Data = append(category.Data, &Media{ProxerURL: "/info/296"})
+	}
+	if err := category.LoadExtraDataContext(context.Background(), retrieve, LoadOptions{Workers: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if maxRunning > 3 || retrieved != 20 {
+		t.Errorf("%d retrievals ran at once, %d retrieved in total", maxRunning, retrieved)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	category = WatchlistCategory{Data: category.Data}
+	err := category.LoadExtraDataContext(ctx, retrieve, LoadOptions{Workers: 3})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	var extraDataError ExtraDataError
+	if !errors.As(err, &extraDataError) || len(extraDataError) != len(category.Data) {
+		t.Errorf("Expected an item error per item, got %v", err)
+	}
+}
+
+func TestLoadExtraData_StopsOnRateLimit(t *testing.T) {
+	var lock sync.Mutex
+	retrieved := 0
+	retrieve := func(item *Media) (io.ReadCloser, CacheInvalidator, error) {
+		lock.Lock()
+		retrieved++
+		lock.Unlock()
+		return nil, nil, ErrRateLimited
+	}
+
+	category := WatchlistCategory{}
+	for i := 0; i < 50; i++ {
+		category.Data = append(category.Data, &Media{ProxerURL: fmt.Sprintf("/info/%d", i)})
+	}
+	err := category.LoadExtraDataContext(context.Background(), retrieve, LoadOptions{Workers: 1})
+	var extraDataError ExtraDataError
+	if !errors.As(err, &extraDataError) || len(extraDataError) != 50 || !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected all items to be reported as ratelimited, got %v", err)
+	}
+	// The first failure has to stop the feed, only items that were already
+	// handed to the worker may still be retrieved.
+	if retrieved > 2 {
+		t.Errorf("Retrieved %d items after hitting the ratelimit", retrieved)
+	}
+}
+
+func TestLoadExtraData_AggregatesErrors(t *testing.T) {
+	failure := errors.New("connection refused")
+	retrieve := func(item *Media) (io.ReadCloser, CacheInvalidator, error) {
+		if item.ProxerURL != "/info/296" {
+			return nil, nil, failure
+		}
+		return retrieveStatic(detailPage)(item)
+	}
+
+	working := &Media{ProxerURL: "/info/296"}
+	category := WatchlistCategory{Data: []*Media{
+		{ProxerURL: "/info/1"},
+		working,
+		{ProxerURL: "/info/2"},
+	}}
+	err := category.LoadExtraData(retrieve)
+
+	var extraDataError ExtraDataError
+	if !errors.As(err, &extraDataError) || len(extraDataError) != 2 {
+		t.Fatalf("Expected two item errors, got %v", err)
+	}
+	if !errors.Is(err, failure) {
+		t.Error("Cause isn't accessible via errors.Is")
+	}
+	for _, itemError := range extraDataError {
+		if itemError.Item == working {
+			t.Error("Working item was reported as failed")
+		}
+	}
+	if working.Rating != 8.12 {
+		t.Error("Working item wasn't loaded")
+	}
+}
+
+func TestLoadExtraData_Progress(t *testing.T) {
+	failing := &Media{ProxerURL: "/info/1"}
+	retrieve := func(item *Media) (io.ReadCloser, CacheInvalidator, error) {
+		if item == failing {
+			return nil, nil, errors.New("failure")
+		}
+		return retrieveStatic(detailPage)(item)
+	}
+
+	counts := make(map[ProgressEventType]int)
+	var last ProgressEvent
+	category := WatchlistCategory{Data: []*Media{failing, {ProxerURL: "/info/2"}, {ProxerURL: "/info/3"}}}
+	category.LoadExtraDataContext(context.Background(), retrieve, LoadOptions{
+		Progress: func(event ProgressEvent) {
+			counts[event.Type]++
+			last = event
+		},
+	})
+
+	if counts[ProgressStarted] != 3 || counts[ProgressDone] != 2 || counts[ProgressFailed] != 1 {
+		t.Errorf("Unexpected events: %v", counts)
+	}
+	if last.Finished != 3 || last.Total != 3 {
+		t.Errorf("Last event = %+v", last)
+	}
+}
+
+func TestWatchlist_LoadExtraData(t *testing.T) {
+	cache, queries := newTestCache(map[string]string{"/info/296": detailPage, "/info/297": detailPage})
+	watchlist := Watchlist{}
+	watchlist.Watched.Data = []*Media{{ProxerURL: "/info/296", Type: MediaTypeSeries}}
+	watchlist.CurrentlyWatching.Data = []*Media{{ProxerURL: "/info/296", Type: MediaTypeSeries}}
+	watchlist.ToWatch.Data = []*Media{{ProxerURL: "/info/297", Type: MediaTypeManga}}
+
+	if err := watchlist.LoadExtraData(cache); err != nil {
+		t.Fatal(err)
+	}
+	if *queries != 2 {
+		t.Errorf("Duplicate entry was retrieved again, %d queries", *queries)
+	}
+	for _, item := range watchlist.All() {
+		if item.Rating != 8.12 {
+			t.Errorf("Entry %s wasn't loaded", item.ProxerURL)
+		}
+	}
+
+	if err := watchlist.LoadExtraData(cache); err != nil || *queries != 2 {
+		t.Errorf("Loading again had an effect: %v, %d queries", err, *queries)
+	}
+}
+
+func TestLoadExtraData_TypedErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		page        string
+		check       func(error) bool
+		invalidated bool
+	}{
+		{"dead link", `<html><head><title>404 - Proxer.Me</title></head></html>`, func(err error) bool { return errors.Is(err, ErrDeadLink) }, true},
+		{"login", `<html><head><title>Login</title></head><body><h3>Bitte logge dich ein</h3></body></html>`, func(err error) bool { return errors.Is(err, ErrLoginRequired) }, true},
+		{"layout", `<html><head><title>Detail</title></head><body></body></html>`, func(err error) bool {
+			var parseError *ParseError
+			return errors.As(err, &parseError) && parseError.Selector == ".average" && parseError.URL == "/info/296" &&
+				errors.Is(err, ErrLayoutChanged)
+		}, false},
+		{"layout without details", `<html><head><title>Detail</title></head><body><span class="average">8.12</span></body></html>`, func(err error) bool {
+			var parseError *ParseError
+			return errors.As(err, &parseError) && parseError.Selector == "table[class=details]" && errors.Is(err, ErrLayoutChanged)
+		}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			invalidated := false
+			retrieve := func(*Media) (io.ReadCloser, CacheInvalidator, error) {
+				return io.NopCloser(strings.NewReader(test.page)), func() error {
+					invalidated = true
+					return nil
+				}, nil
+			}
+			category := WatchlistCategory{Data: []*Media{{ProxerURL: "/info/296"}}}
+			err := category.LoadExtraData(retrieve)
+			if !test.check(err) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			item := category.Data[0]
+			if item.Unavailable != errors.Is(err, ErrDeadLink) || item.RestrictedAccess != errors.Is(err, ErrLoginRequired) {
+				t.Errorf("Unavailable = %v, RestrictedAccess = %v don't match error %v", item.Unavailable, item.RestrictedAccess, err)
+			}
+			if invalidated != test.invalidated {
+				t.Errorf("Invalidated = %v, instead of %v", invalidated, test.invalidated)
+			}
+		})
+	}
+}
+
+func TestParseLinkID(t *testing.T) {
+	tests := []struct {
+		href, expected string
+	}{
+		{"/genre/Slice_of_Life", "Slice_of_Life"},
+		{"/search?s=search&name=&genre=Slice_of_Life#top", "Slice_of_Life"},
+		{"/search?s=search&tags=158", "fallback"},
+		{"", "fallback"},
+	}
+	for _, test := range tests {
+		if id := parseLinkID(test.href, "genre", "fallback"); id != test.expected {
+			t.Errorf("ID of '%s' was '%s', instead of '%s'", test.href, id, test.expected)
+		}
+	}
+}
+
+func TestParseProfileMediaTabWithOptions_Logger(t *testing.T) {
+	var buffer bytes.Buffer
+	watchlist, _, err := ParseProfileMediaTabWithOptions(strings.NewReader(profileTabPage("Watched")), ParseOptions{Logger: log.New(&buffer, "", 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(watchlist.All()) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(watchlist.All()))
+	}
+	if lines := strings.Count(buffer.String(), "\n"); lines != 2 || !strings.Contains(buffer.String(), "'Watched'") {
+		t.Errorf("Expected a debug message per entry, got %q", buffer.String())
+	}
+}
+
+func TestParseProfileMediaTab_LayoutChanged(t *testing.T) {
+	page := `<html><body><div class="watchlist"><table><tr><td>Tsurune</td></tr></table></div></body></html>`
+	if _, err := ParseProfileMediaTab(strings.NewReader(page)); !errors.Is(err, ErrLayoutChanged) {
+		t.Errorf("Expected ErrLayoutChanged, got %v", err)
+	}
+	if _, err := StreamProfileMediaTab(strings.NewReader(page), func(*Media) error { return nil }); !errors.Is(err, ErrLayoutChanged) {
+		t.Errorf("Expected ErrLayoutChanged while streaming, got %v", err)
+	}
+}
+
+func TestLoadExtraData_UnexpectedMarkup(t *testing.T) {
+	page := `<html><head><title>Odd - Anime - Proxer.Me</title></head><body>
+<table class="details"><tbody>
+<tr><td colspan="2"><hr></td></tr>
+<tr><td><b>Englischer Titel</b></td><td></td></tr>
+<tr><td><b>Synonym</b></td><td><i>Italic</i>Plain</td></tr>
+<tr><td><b>Genres</b></td><td><a class="genreTag" href="/genre/Drama"></a></td></tr>
+<tr><td><b>Season</b></td><td><a href="/season/1"><span>Herbst 2018</span></a></td></tr>
+</tbody></table>
+<span class="average">7</span>
+</body></html>`
+	item := &Media{ProxerURL: "/info/1"}
+	category := WatchlistCategory{Data: []*Media{item}}
+	if err := category.LoadExtraData(retrieveStatic(page)); err != nil {
+		t.Fatal(err)
+	}
+	if item.EnglishTitle != "" || len(item.Synonyms) != 1 || item.Synonyms[0] != "Plain" || item.Rating != 7 {
+		t.Errorf("Unexpected result: %+v", item)
+	}
+}
+
+func TestTextOf(t *testing.T) {
+	document, err := newDocument(strings.NewReader(`<table><tr><td id="type">Mangaserie<br>Manhwa</td><td id="empty"></td></tr></table>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := textOf(document.Find("#type")); text != "Mangaserie" {
+		t.Errorf("Got %q", text)
+	}
+	if texts := textNodesOf(document.Find("#type")); len(texts) != 2 || texts[1] != "Manhwa" {
+		t.Errorf("Got %q", texts)
+	}
+	if text := textOf(document.Find("#empty")); text != "" {
+		t.Errorf("Got %q", text)
+	}
+	if text := textOf(document.Find("#missing")); text != "" {
+		t.Errorf("Got %q", text)
+	}
+	if _, present := attrOf(firstNode(document.Find("#missing")), "id"); present {
+		t.Error("Attribute of missing node is present")
+	}
+	if id, _ := attrOf(firstNode(document.Find("td")), "ID"); id != "type" {
+		t.Errorf("Got %q", id)
+	}
+}
+
+func TestParseMediaID(t *testing.T) {
+	for proxerURL, expected := range map[string]uint{
+		"/info/296#top":             296,
+		"https://proxer.me/info/53": 53,
+		"/watch/53/1/engsub":        0,
+		"":                          0,
+	} {
+		if id := parseMediaID(proxerURL); id != expected {
+			t.Errorf("parseMediaID(%q) = %d, expected %d", proxerURL, id, expected)
+		}
+	}
+
+	if id := (&Media{ID: 7, ProxerURL: "/info/8"}).ProxerID(); id != "7" {
+		t.Errorf("Parsed ID not preferred: %s", id)
+	}
+	if id := (&Media{ProxerURL: "/info/8"}).ProxerID(); id != "8" {
+		t.Errorf("URL not used as fallback: %s", id)
+	}
+	if id := (&Media{}).ProxerID(); id != "" {
+		t.Errorf("Unexpected ID for entry without URL: %s", id)
+	}
+}
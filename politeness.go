@@ -0,0 +1,159 @@
+package proxerscrape
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RequestProfile decides how aggressively proxer.me is queried, on top of
+// the ratelimits, which only prevent hitting the captcha wall.
+type RequestProfile struct {
+	// MinInterval is the minimum time between two live requests.
+	MinInterval time.Duration
+	// Workers is the amount of entries retrieved at once when loading extra
+	// data, unless overridden via LoadOptions.
+	Workers int
+}
+
+var (
+	// PoliteProfile is the default profile used by NewCache. It spreads
+	// requests out, so that new users don't accidentally hammer proxer.me.
+	PoliteProfile = RequestProfile{MinInterval: 3 * time.Second, Workers: 1}
+	// FastProfile only relies on the ratelimits.
+	FastProfile = RequestProfile{Workers: DefaultExtraDataWorkers}
+)
+
+// RequestProfiles are the builtin profiles by name.
+var RequestProfiles = map[string]RequestProfile{
+	"polite": PoliteProfile,
+	"fast":   FastProfile,
+}
+
+// keepInterval blocks until at least MinInterval has passed since the last
+// live request.
+func (cache *Cache) keepInterval() {
+	if cache.Profile.MinInterval <= 0 {
+		return
+	}
+	cache.intervalLock.Lock()
+	defer cache.intervalLock.Unlock()
+	if wait := time.Until(cache.lastRequest.Add(cache.Profile.MinInterval)); wait > 0 {
+		time.Sleep(wait)
+	}
+	cache.lastRequest = time.Now()
+}
+
+// Reserve announces that the given amount of live requests is about to be
+// issued, so that bulk operations ask ConfirmRequests once upfront, instead
+// of failing midway. If the requests fit into MaxRequests, nothing is asked.
+func (cache *Cache) Reserve(pending int) error {
+	cache.budgetLock.Lock()
+	defer cache.budgetLock.Unlock()
+	if cache.Offline || cache.MaxRequests <= 0 || cache.unlimited || pending <= 0 {
+		return nil
+	}
+	exceeding := cache.issued + pending - cache.MaxRequests - cache.granted
+	if exceeding <= 0 {
+		return nil
+	}
+	if err := cache.confirm(pending); err != nil {
+		return err
+	}
+	cache.granted += exceeding
+	return nil
+}
+
+// confirm asks ConfirmRequests, remembering a refusal, so that the user
+// isn't asked again for every remaining request.
+func (cache *Cache) confirm(pending int) error {
+	if cache.refused != nil {
+		return cache.refused
+	}
+	if cache.ConfirmRequests == nil {
+		cache.refused = fmt.Errorf("%w, more than %d aren't allowed", ErrRequestLimit, cache.MaxRequests)
+	} else {
+		cache.refused = cache.ConfirmRequests(pending)
+	}
+	return cache.refused
+}
+
+// admitRequest counts a live request against MaxRequests. Requests that
+// weren't reserved upfront require confirmation once the limit is reached.
+func (cache *Cache) admitRequest() error {
+	cache.budgetLock.Lock()
+	defer cache.budgetLock.Unlock()
+	if cache.MaxRequests > 0 && !cache.unlimited && cache.issued >= cache.MaxRequests+cache.granted {
+		if err := cache.confirm(0); err != nil {
+			return err
+		}
+		cache.unlimited = true
+	}
+	cache.issued++
+	return nil
+}
+
+// PendingMediaRequests returns how many of the given entries aren't cached or
+// are stale, meaning that loading their extra data requires live requests.
+func (cache *Cache) PendingMediaRequests(items []*Media) (int, error) {
+	var pending int
+	for _, item := range items {
+		required, err := cache.requiresMediaRequest(item)
+		if err != nil {
+			return 0, err
+		}
+		if required {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+// PendingRelationRequests returns how many of the given entries don't have
+// their relations cached or have stale ones, meaning that building their
+// relation graphs requires live requests.
+func (cache *Cache) PendingRelationRequests(items []*Media) (int, error) {
+	var pending int
+	for _, item := range items {
+		required, err := cache.requiresRequest(CacheEntryRelations, item, getCacheIdentifier(item)+"_relation")
+		if err != nil {
+			return 0, err
+		}
+		if required {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+// requiresMediaRequest checks whether the info page of the given item isn't
+// cached or is stale.
+func (cache *Cache) requiresMediaRequest(item *Media) (bool, error) {
+	return cache.requiresRequest(CacheEntryMedia, item, getCacheIdentifier(item))
+}
+
+// requiresRequest checks whether the given cache entry is missing or stale.
+func (cache *Cache) requiresRequest(kind CacheEntryKind, item *Media, cacheKey string) (bool, error) {
+	reader, metadata, err := cache.Store.Get(cacheKey)
+	if errors.Is(err, ErrCacheMiss) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	reader.Close()
+	return cache.isStale(kind, item, metadata), nil
+}
+
+// EstimateDuration returns roughly how long the given amount of live media
+// requests take, considering the anime ratelimit and the request profile.
+func (cache *Cache) EstimateDuration(requests int) time.Duration {
+	var estimate time.Duration
+	if cache.AnimeQueryRatelimiter != nil {
+		estimate = cache.AnimeQueryRatelimiter.Estimate(requests)
+	}
+	if interval := time.Duration(requests) * cache.Profile.MinInterval; interval > estimate {
+		estimate = interval
+	}
+	return estimate
+}
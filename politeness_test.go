@@ -0,0 +1,88 @@
+package proxerscrape
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPendingMediaRequests(t *testing.T) {
+	cache := newCache(NewMemoryStore(), &Client{})
+	cache.MaxAge = func(CacheEntryKind, *Media) time.Duration { return time.Hour }
+	cache.Store.Put("1", []byte("fresh"), CacheMetadata{FetchedAt: time.Now()})
+	cache.Store.Put("2", []byte("stale"), CacheMetadata{FetchedAt: time.Now().Add(-2 * time.Hour)})
+
+	pending, err := cache.PendingMediaRequests([]*Media{
+		{ProxerURL: "/info/1"},
+		{ProxerURL: "/info/2"},
+		{ProxerURL: "/info/3"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending != 2 {
+		t.Errorf("Expected stale and missing entries to be pending, got %d", pending)
+	}
+}
+
+func TestCache_RequestLimit(t *testing.T) {
+	pages := map[string]string{"/info/1": "1", "/info/2": "2", "/info/3": "3", "/info/4": "4"}
+	cache, queries := newTestCache(pages)
+	cache.MaxRequests = 1
+	if _, _, err := cache.RetrieveAnimeRawData(&Media{ProxerURL: "/info/1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := cache.RetrieveAnimeRawData(&Media{ProxerURL: "/info/2"}); !errors.Is(err, ErrRequestLimit) {
+		t.Errorf("Expected the limit to be enforced without confirmation, got %v", err)
+	}
+	if *queries != 1 {
+		t.Errorf("Expected a single request, got %d", *queries)
+	}
+
+	cache, queries = newTestCache(pages)
+	cache.MaxRequests = 1
+	var asked []int
+	cache.ConfirmRequests = func(pending int) error {
+		asked = append(asked, pending)
+		return nil
+	}
+	if err := cache.Reserve(2); err != nil {
+		t.Fatal(err)
+	}
+	for _, url := range []string{"/info/1", "/info/2", "/info/3", "/info/4"} {
+		if _, _, err := cache.RetrieveAnimeRawData(&Media{ProxerURL: url}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(asked) != 2 || asked[0] != 2 || asked[1] != 0 {
+		t.Errorf("Expected to be asked for the reservation and once more for unreserved requests, got %v", asked)
+	}
+
+	cache, queries = newTestCache(pages)
+	cache.MaxRequests = 1
+	asked = nil
+	cache.ConfirmRequests = func(pending int) error {
+		asked = append(asked, pending)
+		return errors.New("declined")
+	}
+	if err := cache.Reserve(3); err == nil {
+		t.Error("Expected the declined reservation to fail")
+	}
+	for _, url := range []string{"/info/1", "/info/2"} {
+		cache.RetrieveAnimeRawData(&Media{ProxerURL: url})
+	}
+	if len(asked) != 1 || *queries != 1 {
+		t.Errorf("Expected a refusal to be remembered, asked %v, %d requests", asked, *queries)
+	}
+}
+
+func TestKeepInterval(t *testing.T) {
+	cache := newCache(NewMemoryStore(), &Client{})
+	cache.Profile = RequestProfile{MinInterval: 50 * time.Millisecond}
+	start := time.Now()
+	cache.keepInterval()
+	cache.keepInterval()
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Requests weren't spread out, took %s", elapsed)
+	}
+}
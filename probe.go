@@ -0,0 +1,22 @@
+package proxerscrape
+
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
+// RetrieveProfileTabIfChanged revalidates the cached profile tab, even if it
+// hasn't expired yet, and reports whether it changed. Revalidating sends the
+// ETag and Last-Modified of the cached version along, so unchanged tabs
+// aren't downloaded again and cost a single request. A tab is only considered
+// unchanged if proxer.me confirms this, uncached tabs and responses without
+// validators always count as changed. In offline mode, the cached tab is
+// returned and considered unchanged.
+func (cache *Cache) RetrieveProfileTabIfChanged(profileID string, tabType ProfileTabType) (reader io.ReadCloser, changed bool, err error) {
+	cacheKey := profileTabCacheKey(profileID, tabType)
+	reader, _, changed, err = cache.retrieveEntry(CacheEntryProfileTab, nil, cacheKey, cache.ProfileTabQueryRatelimiter, func(header http.Header) (*http.Response, error) {
+		return cache.QueryProfileTab(profileID, tabType, header)
+	}, true, time.Time{})
+	return reader, changed, err
+}
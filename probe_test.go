@@ -0,0 +1,59 @@
+package proxerscrape
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCache_RetrieveProfileTabIfChanged(t *testing.T) {
+	page := "3 / 12"
+	etag := "v1"
+	queries := 0
+	cache, _ := newTestCache(nil)
+	cache.QueryProfileTab = func(profileID string, tabType ProfileTabType, header http.Header) (*http.Response, error) {
+		queries++
+		if etag != "" && header.Get("If-None-Match") == etag {
+			return &http.Response{StatusCode: http.StatusNotModified, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}
+		response := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(page))}
+		if etag != "" {
+			response.Header.Set("ETag", etag)
+		}
+		return response, nil
+	}
+
+	retrieve := func() (string, bool) {
+		t.Helper()
+		reader, changed, err := cache.RetrieveProfileTabIfChanged("1", ProfileTabAnime)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer reader.Close()
+		data, _ := io.ReadAll(reader)
+		return string(data), changed
+	}
+
+	if _, changed := retrieve(); !changed {
+		t.Error("Uncached tab should be changed")
+	}
+	if data, changed := retrieve(); changed || data != "3 / 12" {
+		t.Errorf("Tab with equal ETag should be unchanged: %v %s", changed, data)
+	}
+
+	// The size stays the same, only the validator tells the change.
+	page, etag = "4 / 12", "v2"
+	if data, changed := retrieve(); !changed || data != "4 / 12" {
+		t.Errorf("Tab with different ETag should be changed: %v %s", changed, data)
+	}
+
+	// Without validators, proxer.me can't confirm anything.
+	etag = ""
+	if _, changed := retrieve(); !changed {
+		t.Error("Tab without validators should be changed")
+	}
+	if queries != 4 {
+		t.Errorf("Expected one request per check, got %d", queries)
+	}
+}
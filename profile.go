@@ -0,0 +1,122 @@
+package proxerscrape
+
+import (
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Profile is the overview of a user as shown on the main profile page.
+type Profile struct {
+	UserID   string
+	Username string
+	// AvatarURL is the absolute URL of the avatar, empty if the user hasn't
+	// set one.
+	AvatarURL string
+	// Rank is the title the user has earned through points, for example
+	// "Senpai".
+	Rank         string
+	AnimePoints  uint64
+	MangaPoints  uint64
+	UploadPoints uint64
+	RegisteredAt time.Time
+}
+
+// Points returns the sum of all points.
+func (profile *Profile) Points() uint64 {
+	return profile.AnimePoints + profile.MangaPoints + profile.UploadPoints
+}
+
+// RetrieveProfileRawData retrieves the overview page of the given user.
+func (cache *Cache) RetrieveProfileRawData(profileID string) (io.ReadCloser, CacheInvalidator, error) {
+	return cache.retrievePage(CacheEntryProfileTab, "profile/"+profileID+"/overview", "/user/"+profileID+"/overview", cache.ProfileTabQueryRatelimiter)
+}
+
+var profileDateRegex = regexp.MustCompile(`\d{2}\.\d{2}\.\d{4}`)
+
+// parsePoints parses numbers such as "12.345 Punkte".
+func parsePoints(text string) uint64 {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return 0
+	}
+	points, _ := strconv.ParseUint(strings.NewReplacer(".", "", ",", "").Replace(fields[0]), 10, 64)
+	return points
+}
+
+// ParseProfile parses the overview page of a user. The page consists of rows
+// of labels and values, unknown rows are ignored. Fields that can't be found
+// are left empty. Relative avatar URLs are resolved against DefaultBaseURL.
+func ParseProfile(reader io.Reader, profileID string) (Profile, error) {
+	return parseProfile(reader, profileID, DefaultBaseURL)
+}
+
+// parseProfile parses the overview page of a user, see ParseProfile,
+// resolving relative avatar URLs against the given base URL.
+func parseProfile(reader io.Reader, profileID, baseURL string) (Profile, error) {
+	profile := Profile{UserID: profileID}
+	document, err := newDocument(reader)
+	if err != nil {
+		return profile, err
+	}
+
+	document.Find("tr").Each(func(_ int, row *goquery.Selection) {
+		cells := row.Find("td")
+		if cells.Length() < 2 {
+			return
+		}
+		key := strings.TrimSuffix(strings.TrimSpace(cells.Eq(0).Text()), ":")
+		value := strings.TrimSpace(normalizeText(cells.Eq(1).Text()))
+		if value == "" {
+			return
+		}
+		switch key {
+		case "Benutzername", "Username":
+			profile.Username = value
+		case "Rang":
+			profile.Rank = value
+		case "Animepunkte", "Anime":
+			profile.AnimePoints = parsePoints(value)
+		case "Mangapunkte", "Manga":
+			profile.MangaPoints = parsePoints(value)
+		case "Uploadpunkte", "Uploads":
+			profile.UploadPoints = parsePoints(value)
+		case "Registriert", "Registriert seit", "Mitglied seit":
+			if date := profileDateRegex.FindString(value); date != "" {
+				profile.RegisteredAt, _ = time.ParseInLocation("02.01.2006", date, proxerLocation())
+			}
+		}
+	})
+
+	document.Find("img").EachWithBreak(func(_ int, image *goquery.Selection) bool {
+		src, _ := image.Attr("src")
+		if !strings.Contains(src, "/avatar") {
+			return true
+		}
+		if strings.HasPrefix(src, "//") {
+			src = "https:" + src
+		} else if strings.HasPrefix(src, "/") {
+			src = strings.TrimSuffix(baseURL, "/") + src
+		}
+		profile.AvatarURL = src
+		if profile.Username == "" {
+			profile.Username, _ = image.Attr("alt")
+		}
+		return false
+	})
+	return profile, nil
+}
+
+// RetrieveProfile retrieves and parses the overview of the given user.
+func (cache *Cache) RetrieveProfile(profileID string) (Profile, error) {
+	reader, _, err := cache.RetrieveProfileRawData(profileID)
+	if err != nil {
+		return Profile{}, err
+	}
+	defer reader.Close()
+	return parseProfile(reader, profileID, cache.BaseURL())
+}
@@ -0,0 +1,136 @@
+package proxerscrape
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// CategorySection is the HTML of a single watchlist category of a profile
+// tab. Since proxer.me only serves the whole tab, the sections are cut out
+// of it, which allows detecting changes per category.
+type CategorySection struct {
+	Category ListCategory
+	Data     []byte
+	// ETag is a hash of the data, which changes whenever the category does.
+	ETag string
+}
+
+// SplitProfileTab splits a profile tab, such as `anime`, into its categories.
+// The sections are split by the anchors preceding each category table.
+func SplitProfileTab(reader io.Reader) ([]CategorySection, error) {
+	document, err := newDocument(reader)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireSelectors(document, "", profileTabSelector); err != nil {
+		return nil, err
+	}
+
+	sections := make([]CategorySection, 0, len(ListCategories))
+	for index, category := range ListCategories {
+		section := CategorySection{Category: category}
+		// The anchors are named after the category index, starting at state0.
+		table := document.Find(fmt.Sprintf("a[name=state%d]", index)).Next()
+		if table.Length() > 0 {
+			html, err := goquery.OuterHtml(table)
+			if err != nil {
+				return nil, err
+			}
+			section.Data = []byte(html)
+		}
+		section.ETag = sectionETag(section.Data)
+		sections = append(sections, section)
+	}
+	return sections, nil
+}
+
+func sectionETag(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:16])
+}
+
+// Parse parses the entries of the section. Rows that can't be parsed are
+// skipped and returned as warnings.
+func (section CategorySection) Parse() (WatchlistCategory, []ParseWarning, error) {
+	if len(section.Data) == 0 {
+		return WatchlistCategory{}, nil, nil
+	}
+	document, err := newDocument(bytes.NewReader(section.Data))
+	if err != nil {
+		return WatchlistCategory{}, nil, err
+	}
+	data, warnings := parseProfileTabMediaTable(document.Find("table").First(), discardLogger{})
+	for _, item := range data {
+		item.Category = section.Category
+	}
+	for index := range warnings {
+		warnings[index].Category = section.Category
+	}
+	return WatchlistCategory{Data: data}, warnings, nil
+}
+
+func sectionCacheKey(profileID string, tabType ProfileTabType, category ListCategory) string {
+	return profileTabCacheKey(profileID, tabType) + "/" + string(category)
+}
+
+// RetrieveWatchlistIncremental retrieves the given profile tab, but only
+// parses the categories that changed since the last call. Unchanged
+// categories are taken from the previous watchlist, including their extra
+// data. If previous is nil, all categories are parsed. The categories that
+// changed are returned as well.
+//
+// The sections of each category are cached separately, their ETag being a
+// hash of the content.
+func (cache *Cache) RetrieveWatchlistIncremental(profileID string, tabType ProfileTabType, previous *Watchlist) (Watchlist, []ListCategory, error) {
+	var watchlist Watchlist
+	reader, _, err := cache.RetrieveProfileTabRawData(profileID, tabType)
+	if err != nil {
+		return watchlist, nil, err
+	}
+	sections, err := SplitProfileTab(reader)
+	reader.Close()
+	if err != nil {
+		return watchlist, nil, err
+	}
+
+	var changed []ListCategory
+	for _, section := range sections {
+		cacheKey := sectionCacheKey(profileID, tabType, section.Category)
+		sectionReader, metadata, err := cache.Store.Get(cacheKey)
+		if err == nil {
+			sectionReader.Close()
+		} else if !errors.Is(err, ErrCacheMiss) {
+			return watchlist, nil, err
+		}
+
+		if err == nil && metadata.ETag == section.ETag {
+			if previous != nil {
+				*watchlist.Category(section.Category) = *previous.Category(section.Category)
+				continue
+			}
+		} else {
+			changed = append(changed, section.Category)
+			if err := cache.Store.Put(cacheKey, section.Data, CacheMetadata{
+				FetchedAt: time.Now(),
+				ETag:      section.ETag,
+			}); err != nil {
+				return watchlist, nil, err
+			}
+		}
+
+		category, warnings, err := section.Parse()
+		if err != nil {
+			return watchlist, nil, err
+		}
+		cache.logWarnings(warnings)
+		*watchlist.Category(section.Category) = category
+	}
+	return watchlist, changed, nil
+}
@@ -0,0 +1,64 @@
+package proxerscrape
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func profileTabPage(watchedTitle string) string {
+	row := func(url, title string) string {
+		return fmt.Sprintf(`<tr><td><img title="Abgeschlossen"></td><td><a href="%s">%s</a></td><td>Animeserie</td><td></td><td><span>1 / 12</span></td></tr>`, url, title)
+	}
+	table := func(rows ...string) string {
+		return `<table><tr><th>Name</th></tr><tr><th>Filter</th></tr>` + strings.Join(rows, "") + `</table>`
+	}
+	return `<html><body>` +
+		`<a name="state0"></a>` + table(row("/info/1", watchedTitle)) +
+		`<a name="state1"></a>` + table(row("/info/2", "Watching")) +
+		`<a name="state2"></a>` + table() +
+		`<a name="state3"></a>` + table() +
+		`</body></html>`
+}
+
+func TestRetrieveWatchlistIncremental(t *testing.T) {
+	page := profileTabPage("Watched")
+	cache := newCache(NewMemoryStore(), &Client{})
+	cache.ProfileTabQueryRatelimiter = nil
+	cache.QueryProfileTab = func(string, ProfileTabType, http.Header) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(page)), Header: http.Header{}}, nil
+	}
+
+	watchlist, changed, err := cache.RetrieveWatchlistIncremental("1", ProfileTabAnime, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != len(ListCategories) {
+		t.Errorf("Expected all categories to be new, got %v", changed)
+	}
+	if len(watchlist.Watched.Data) != 1 || watchlist.CurrentlyWatching.Data[0].Category != CategoryCurrentlyWatching {
+		t.Fatalf("Unexpected watchlist: %+v", watchlist)
+	}
+
+	// Only the watched section changes, so the other categories must be
+	// reused as is.
+	page = profileTabPage("Watched again")
+	if err := cache.Store.Delete(profileTabCacheKey("1", ProfileTabAnime)); err != nil {
+		t.Fatal(err)
+	}
+	next, changed, err := cache.RetrieveWatchlistIncremental("1", ProfileTabAnime, &watchlist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 1 || changed[0] != CategoryWatched {
+		t.Errorf("Expected only watched to change, got %v", changed)
+	}
+	if next.Watched.Data[0].Title != "Watched again" {
+		t.Errorf("Watched wasn't parsed again: %s", next.Watched.Data[0].Title)
+	}
+	if next.CurrentlyWatching.Data[0] != watchlist.CurrentlyWatching.Data[0] {
+		t.Error("Unchanged category was parsed again")
+	}
+}
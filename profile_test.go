@@ -0,0 +1,48 @@
+package proxerscrape
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const profilePage = `<html><body>
+<img src="//cdn.proxer.me/avatar/252835_abc.jpg" alt="Marcel">
+<table>
+<tr><td>Rang:</td><td>Senpai</td></tr>
+<tr><td>Animepunkte:</td><td>12.345 Punkte</td></tr>
+<tr><td>Mangapunkte:</td><td>67</td></tr>
+<tr><td>Uploadpunkte:</td><td>0</td></tr>
+<tr><td>Registriert seit:</td><td>01.02.2015</td></tr>
+<tr><td>Status:</td><td>Online</td></tr>
+</table></body></html>`
+
+func TestParseProfile(t *testing.T) {
+	profile, err := ParseProfile(strings.NewReader(profilePage), "252835")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.Username != "Marcel" || profile.Rank != "Senpai" || profile.AvatarURL != "https://cdn.proxer.me/avatar/252835_abc.jpg" {
+		t.Errorf("Unexpected profile: %+v", profile)
+	}
+	if profile.AnimePoints != 12345 || profile.MangaPoints != 67 || profile.Points() != 12412 {
+		t.Errorf("Unexpected points: %+v", profile)
+	}
+	if profile.RegisteredAt.Year() != 2015 || profile.RegisteredAt.Month() != time.February {
+		t.Errorf("Unexpected registration date %s", profile.RegisteredAt)
+	}
+}
+
+func TestCache_RetrieveProfile_BaseURL(t *testing.T) {
+	cache, _ := newTestCache(map[string]string{
+		"/user/1/overview": `<html><body><img src="/images/avatar/1.jpg" alt="Mirror"></body></html>`,
+	})
+	cache.client = &Client{BaseURL: "http://mirror.test/"}
+	profile, err := cache.RetrieveProfile("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.AvatarURL != "http://mirror.test/images/avatar/1.jpg" {
+		t.Errorf("Avatar wasn't resolved against the base URL: %s", profile.AvatarURL)
+	}
+}
@@ -0,0 +1,66 @@
+package proxerscrape
+
+import (
+	"sync"
+	"time"
+)
+
+// ProgressEventType tells what happened in a ProgressEvent.
+type ProgressEventType string
+
+const (
+	// ProgressStarted is sent before an item is retrieved.
+	ProgressStarted ProgressEventType = "started"
+	// ProgressDone is sent once an item has been loaded.
+	ProgressDone ProgressEventType = "done"
+	// ProgressFailed is sent if an item couldn't be loaded, Err is set.
+	ProgressFailed ProgressEventType = "failed"
+	// ProgressWaiting is sent if a request has to wait for the ratelimit,
+	// Wait is set. These events are sent by the Cache, see Cache.OnProgress.
+	ProgressWaiting ProgressEventType = "waiting"
+)
+
+// ProgressEvent informs about the progress of a bulk load, which can take a
+// long time due to ratelimits.
+type ProgressEvent struct {
+	Type ProgressEventType
+	Item *Media
+	Err  error
+	// Wait is how long a request is blocked by the ratelimit.
+	Wait time.Duration
+	// Finished and Total are the amount of items that are done or failed
+	// and the amount of items in total. They're not set for
+	// ProgressWaiting.
+	Finished, Total int
+}
+
+// ProgressFunc receives progress events.
+type ProgressFunc func(ProgressEvent)
+
+// progressReporter serialises calls to a ProgressFunc and counts finished
+// items.
+type progressReporter struct {
+	lock     sync.Mutex
+	progress ProgressFunc
+	finished int
+	total    int
+}
+
+func newProgressReporter(progress ProgressFunc, total int) *progressReporter {
+	return &progressReporter{progress: progress, total: total}
+}
+
+func (reporter *progressReporter) report(event ProgressEvent) {
+	if reporter.progress == nil {
+		return
+	}
+
+	reporter.lock.Lock()
+	defer reporter.lock.Unlock()
+	if event.Type == ProgressDone || event.Type == ProgressFailed {
+		reporter.finished++
+	}
+	event.Finished = reporter.finished
+	event.Total = reporter.total
+	reporter.progress(event)
+}
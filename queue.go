@@ -0,0 +1,184 @@
+package proxerscrape
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/Bios-Marcel/proxerscrape/internal/atomicfile"
+)
+
+// DeferredFetch is a retrieval that couldn't be performed, for example
+// because the ratelimit stopped the run. Depending on the Kind, only some
+// of the fields are set.
+type DeferredFetch struct {
+	Kind CacheEntryKind `json:"kind"`
+	// ProxerURL and Type identify media and relation pages.
+	ProxerURL string    `json:"proxerUrl,omitempty"`
+	Type      MediaType `json:"type,omitempty"`
+	// ProfileID and TabType identify profile tabs.
+	ProfileID string         `json:"profileId,omitempty"`
+	TabType   ProfileTabType `json:"tabType,omitempty"`
+	// LeaderboardType identifies leaderboards.
+	LeaderboardType LeaderboardType `json:"leaderboardType,omitempty"`
+}
+
+// DeferMedia creates a DeferredFetch for the info page of the given item.
+func DeferMedia(item *Media) DeferredFetch {
+	return DeferredFetch{Kind: CacheEntryMedia, ProxerURL: item.ProxerURL, Type: item.Type}
+}
+
+// DeferProfileTab creates a DeferredFetch for the given profile tab.
+func DeferProfileTab(profileID string, tabType ProfileTabType) DeferredFetch {
+	return DeferredFetch{Kind: CacheEntryProfileTab, ProfileID: profileID, TabType: tabType}
+}
+
+// FetchQueue holds deferred fetches, so that they can be resumed by the next
+// run. The queue is saved after every change.
+type FetchQueue struct {
+	lock    sync.Mutex
+	path    string
+	pending []DeferredFetch
+}
+
+// LoadFetchQueue loads the queue from the given file. If the file doesn't
+// exist, the queue is empty.
+func LoadFetchQueue(path string) (*FetchQueue, error) {
+	queue := &FetchQueue{path: path}
+	if err := atomicfile.Recover(path); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return queue, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &queue.pending); err != nil {
+		return nil, err
+	}
+	return queue, nil
+}
+
+// Push appends the given fetches, skipping those that are already queued.
+func (queue *FetchQueue) Push(fetches ...DeferredFetch) error {
+	queue.lock.Lock()
+	defer queue.lock.Unlock()
+
+	for _, fetch := range fetches {
+		if !queue.contains(fetch) {
+			queue.pending = append(queue.pending, fetch)
+		}
+	}
+	return queue.save()
+}
+
+// Len returns the amount of queued fetches.
+func (queue *FetchQueue) Len() int {
+	queue.lock.Lock()
+	defer queue.lock.Unlock()
+	return len(queue.pending)
+}
+
+// Pending returns a copy of all queued fetches in order.
+func (queue *FetchQueue) Pending() []DeferredFetch {
+	queue.lock.Lock()
+	defer queue.lock.Unlock()
+	return append([]DeferredFetch(nil), queue.pending...)
+}
+
+// remove removes the given fetch from the queue.
+func (queue *FetchQueue) remove(fetch DeferredFetch) error {
+	queue.lock.Lock()
+	defer queue.lock.Unlock()
+
+	for index, pending := range queue.pending {
+		if pending == fetch {
+			queue.pending = append(queue.pending[:index], queue.pending[index+1:]...)
+			break
+		}
+	}
+	return queue.save()
+}
+
+// contains checks whether the fetch is queued. The lock has to be held.
+func (queue *FetchQueue) contains(fetch DeferredFetch) bool {
+	for _, pending := range queue.pending {
+		if pending == fetch {
+			return true
+		}
+	}
+	return false
+}
+
+// save writes the queue to its file. The lock has to be held.
+func (queue *FetchQueue) save() error {
+	if queue.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(queue.pending)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(queue.path, data, 0o644)
+}
+
+// ResumeQueue performs all fetches in the cache's queue, removing each one
+// once it has been cached. If the ratelimit stops the run again, the
+// remaining fetches stay queued. The amount of completed fetches is
+// returned.
+func (cache *Cache) ResumeQueue() (int, error) {
+	if cache.Queue == nil {
+		return 0, nil
+	}
+
+	completed := 0
+	for _, fetch := range cache.Queue.Pending() {
+		if err := cache.retrieveDeferred(fetch); err != nil {
+			return completed, err
+		}
+		if err := cache.Queue.remove(fetch); err != nil {
+			return completed, err
+		}
+		completed++
+	}
+	return completed, nil
+}
+
+// Defer queues the fetch if the error indicates that it should be retried
+// in a later run. The error is returned unchanged.
+func (cache *Cache) Defer(fetch DeferredFetch, err error) error {
+	if cache.Queue != nil && errors.Is(err, ErrRateLimited) {
+		if errQueue := cache.Queue.Push(fetch); errQueue != nil {
+			return errQueue
+		}
+	}
+	return err
+}
+
+func (cache *Cache) retrieveDeferred(fetch DeferredFetch) error {
+	var reader io.Closer
+	var err error
+	switch fetch.Kind {
+	case CacheEntryProfileTab:
+		reader, _, err = cache.RetrieveProfileTabRawData(fetch.ProfileID, fetch.TabType)
+	case CacheEntryLeaderboard:
+		reader, _, err = cache.RetrieveLeaderboardRawData(fetch.LeaderboardType)
+	case CacheEntryRelations:
+		reader, _, err = cache.RetrieveRelationsRawData(&Media{ProxerURL: fetch.ProxerURL, Type: fetch.Type})
+	default:
+		item := &Media{ProxerURL: fetch.ProxerURL, Type: fetch.Type}
+		if item.Type.IsAnime() {
+			reader, _, err = cache.RetrieveAnimeRawData(item)
+		} else {
+			reader, _, err = cache.RetrieveMangaRawData(item)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return reader.Close()
+}
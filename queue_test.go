@@ -0,0 +1,44 @@
+package proxerscrape
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFetchQueue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	queue, err := LoadFetchQueue(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache, queries := newTestCache(map[string]string{"/info/1": "one", "/user/2/anime": "two"})
+	cache.Queue = queue
+
+	if err := cache.Defer(DeferMedia(&Media{ProxerURL: "/info/1", Type: MediaTypeSeries}), ErrRateLimited); err != ErrRateLimited {
+		t.Errorf("Error wasn't passed through: %v", err)
+	}
+	cache.Defer(DeferProfileTab("2", ProfileTabAnime), ErrRateLimited)
+	cache.Defer(DeferProfileTab("2", ProfileTabAnime), ErrRateLimited)
+	cache.Defer(DeferProfileTab("3", ProfileTabAnime), nil)
+
+	// The next run has to pick up where the previous one stopped.
+	restarted, err := LoadFetchQueue(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restarted.Len() != 2 {
+		t.Fatalf("Queue has %d entries, instead of 2: %+v", restarted.Len(), restarted.Pending())
+	}
+	cache.Queue = restarted
+
+	completed, err := cache.ResumeQueue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if completed != 2 || *queries != 2 || restarted.Len() != 0 {
+		t.Errorf("Completed %d with %d queries, %d remaining", completed, *queries, restarted.Len())
+	}
+	if _, _, err := cache.Store.Get("profile/2/anime"); err != nil {
+		t.Errorf("Deferred profile tab wasn't cached: %v", err)
+	}
+}
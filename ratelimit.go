@@ -1,47 +1,245 @@
 package proxerscrape
 
 import (
+	"encoding/json"
+	"os"
+	"sort"
 	"sync"
 	"time"
+
+	"github.com/Bios-Marcel/proxerscrape/internal/atomicfile"
 )
 
+// Limiter allows a certain amount of tries within a sliding time window. It
+// remembers the time of each request, which allows persisting its state
+// across process restarts.
 type Limiter struct {
-	triesLeft int
-	locked    bool
-	lock      *sync.Mutex
+	tries int
+	per   time.Duration
+
+	lock     sync.Mutex
+	requests []time.Time
+	// cooldownUntil pauses all requests until the given point in time. This
+	// is set once the captcha wall has been hit.
+	cooldownUntil time.Time
+	// strikes is the amount of consecutive backoffs, used to grow the
+	// cooldown exponentially.
+	strikes int
+	// persistPath is where the state is saved after each request. If empty,
+	// the state isn't persisted.
+	persistPath string
+	// baseCooldown and maxCooldown bound the cooldown of Backoff.
+	baseCooldown, maxCooldown time.Duration
+}
+
+const (
+	baseCooldown = 5 * time.Minute
+	maxCooldown  = 2 * time.Hour
+)
+
+// limiterState is the persisted form of a Limiter.
+type limiterState struct {
+	Requests      []time.Time `json:"requests"`
+	CooldownUntil time.Time   `json:"cooldownUntil,omitempty"`
+	Strikes       int         `json:"strikes,omitempty"`
 }
 
 func NewLimiter(tries int, per time.Duration) *Limiter {
-	limiter := &Limiter{
-		triesLeft: tries,
-		lock:      &sync.Mutex{},
-	}
-
-	resetTicker := time.NewTicker(per)
-	go func() {
-		for {
-			<-resetTicker.C
-			limiter.triesLeft = tries
-			if limiter.locked {
-				limiter.locked = false
-				limiter.lock.Unlock()
-			}
-		}
-	}()
+	return &Limiter{
+		tries:        tries,
+		per:          per,
+		baseCooldown: baseCooldown,
+		maxCooldown:  maxCooldown,
+	}
+}
+
+// prune removes all requests that are outside of the time window. The lock
+// has to be held.
+func (limiter *Limiter) prune(now time.Time) {
+	threshold := now.Add(-limiter.per)
+	index := 0
+	for index < len(limiter.requests) && !limiter.requests[index].After(threshold) {
+		index++
+	}
+	limiter.requests = limiter.requests[index:]
+}
+
+// Delay returns how long Wait would currently block.
+func (limiter *Limiter) Delay() time.Duration {
+	limiter.lock.Lock()
+	defer limiter.lock.Unlock()
+
+	now := time.Now()
+	if delay := limiter.cooldownUntil.Sub(now); delay > 0 {
+		return delay
+	}
+	limiter.prune(now)
+	if len(limiter.requests) >= limiter.tries {
+		return limiter.requests[0].Add(limiter.per).Sub(now)
+	}
+	return 0
+}
+
+// Estimate returns roughly how long the given amount of tries takes, assuming
+// no other tries happen in the meantime.
+func (limiter *Limiter) Estimate(tries int) time.Duration {
+	limiter.lock.Lock()
+	defer limiter.lock.Unlock()
 
-	return limiter
+	now := time.Now()
+	limiter.prune(now)
+	var estimate time.Duration
+	if wait := limiter.cooldownUntil.Sub(now); wait > 0 {
+		estimate = wait
+	}
+	// Tries beyond the ones still available in the current window have to
+	// wait for the following windows.
+	overflow := tries + len(limiter.requests) - limiter.tries
+	if overflow > 0 {
+		windows := (overflow + limiter.tries - 1) / limiter.tries
+		estimate += time.Duration(windows) * limiter.per
+	}
+	return estimate
 }
 
+// Wait blocks until another try is allowed and then counts the try. The
+// lock isn't held while sleeping, so that the limiter can still be inspected
+// or put into cooldown in the meantime.
 func (limiter *Limiter) Wait() {
+	for {
+		wait := limiter.tryAcquire()
+		if wait <= 0 {
+			return
+		}
+		time.Sleep(wait)
+	}
+}
+
+// tryAcquire counts a try if one is allowed right now. Otherwise it returns
+// how long to wait before trying again.
+func (limiter *Limiter) tryAcquire() time.Duration {
 	limiter.lock.Lock()
-	limiter.locked = true
-	defer func() {
-		limiter.locked = false
-		limiter.lock.Unlock()
-	}()
-	if limiter.triesLeft <= 0 {
-		limiter.lock.Lock()
+	defer limiter.lock.Unlock()
+
+	now := time.Now()
+	if wait := limiter.cooldownUntil.Sub(now); wait > 0 {
+		return wait
+	}
+	limiter.prune(now)
+	if len(limiter.requests) >= limiter.tries {
+		return limiter.requests[0].Add(limiter.per).Sub(now)
 	}
 
-	limiter.triesLeft--
+	limiter.requests = append(limiter.requests, now)
+	if limiter.persistPath != "" {
+		// Failing to persist isn't fatal, at worst we risk hitting the
+		// limit after a restart.
+		limiter.save()
+	}
+	return 0
+}
+
+// Backoff puts the limiter into cooldown, pausing all requests. Each
+// consecutive call doubles the cooldown, up to a maximum of two hours. The
+// end of the cooldown is returned.
+func (limiter *Limiter) Backoff() time.Time {
+	limiter.lock.Lock()
+	defer limiter.lock.Unlock()
+
+	cooldown := limiter.baseCooldown
+	for i := 0; i < limiter.strikes && cooldown < limiter.maxCooldown; i++ {
+		cooldown *= 2
+	}
+	if cooldown > limiter.maxCooldown {
+		cooldown = limiter.maxCooldown
+	}
+	limiter.strikes++
+	limiter.cooldownUntil = time.Now().Add(cooldown)
+	if limiter.persistPath != "" {
+		limiter.save()
+	}
+	return limiter.cooldownUntil
+}
+
+// ResetBackoff resets the exponential growth of the cooldown. This should be
+// called after a successful request.
+func (limiter *Limiter) ResetBackoff() {
+	limiter.lock.Lock()
+	defer limiter.lock.Unlock()
+
+	if limiter.strikes == 0 {
+		return
+	}
+	limiter.strikes = 0
+	if limiter.persistPath != "" {
+		limiter.save()
+	}
+}
+
+// CooldownUntil returns the end of the current cooldown. If the limiter
+// isn't in cooldown, the time lies in the past.
+func (limiter *Limiter) CooldownUntil() time.Time {
+	limiter.lock.Lock()
+	defer limiter.lock.Unlock()
+	return limiter.cooldownUntil
+}
+
+// Persist loads the state from the given file, if present, and saves the
+// state to it after every request from now on.
+func (limiter *Limiter) Persist(path string) error {
+	limiter.lock.Lock()
+	defer limiter.lock.Unlock()
+
+	limiter.persistPath = path
+	if err := atomicfile.Recover(path); err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var state limiterState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	if state.CooldownUntil.After(limiter.cooldownUntil) {
+		limiter.cooldownUntil = state.CooldownUntil
+	}
+	if state.Strikes > limiter.strikes {
+		limiter.strikes = state.Strikes
+	}
+
+	// The same limiter may be persisted multiple times, so we mustn't count
+	// requests twice.
+	known := make(map[int64]bool, len(limiter.requests))
+	for _, request := range limiter.requests {
+		known[request.UnixNano()] = true
+	}
+	for _, request := range state.Requests {
+		if !known[request.UnixNano()] {
+			limiter.requests = append(limiter.requests, request)
+		}
+	}
+	sort.Slice(limiter.requests, func(a, b int) bool {
+		return limiter.requests[a].Before(limiter.requests[b])
+	})
+	limiter.prune(time.Now())
+	return nil
+}
+
+// save writes the state to the persist path. The lock has to be held.
+func (limiter *Limiter) save() error {
+	data, err := json.Marshal(limiterState{
+		Requests:      limiter.requests,
+		CooldownUntil: limiter.cooldownUntil,
+		Strikes:       limiter.strikes,
+	})
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(limiter.persistPath, data, 0o644)
 }
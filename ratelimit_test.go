@@ -0,0 +1,80 @@
+package proxerscrape
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	limiter := NewLimiter(2, 100*time.Millisecond)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		limiter.Wait()
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("Third try wasn't limited, took %s", elapsed)
+	}
+}
+
+func TestLimiter_Persist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "limiter.json")
+	limiter := NewLimiter(2, 200*time.Millisecond)
+	if err := limiter.Persist(path); err != nil {
+		t.Fatal(err)
+	}
+	limiter.Wait()
+	limiter.Wait()
+
+	// A new limiter, as created after a restart, has to know about the
+	// previous requests.
+	restarted := NewLimiter(2, 200*time.Millisecond)
+	if err := restarted.Persist(path); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	restarted.Wait()
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("Restarted limiter didn't wait, took %s", elapsed)
+	}
+}
+
+func TestLimiter_Backoff(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "limiter.json")
+	limiter := NewLimiter(2, time.Minute)
+	if err := limiter.Persist(path); err != nil {
+		t.Fatal(err)
+	}
+
+	first := time.Until(limiter.Backoff())
+	second := time.Until(limiter.Backoff())
+	if first > baseCooldown || second <= baseCooldown {
+		t.Errorf("Cooldown didn't grow: %s, %s", first, second)
+	}
+
+	restarted := NewLimiter(2, time.Minute)
+	if err := restarted.Persist(path); err != nil {
+		t.Fatal(err)
+	}
+	if !restarted.CooldownUntil().Equal(limiter.CooldownUntil()) {
+		t.Errorf("Cooldown wasn't persisted: %s", restarted.CooldownUntil())
+	}
+
+	limiter.ResetBackoff()
+	if cooldown := time.Until(limiter.Backoff()); cooldown > baseCooldown {
+		t.Errorf("Cooldown wasn't reset: %s", cooldown)
+	}
+}
+
+func TestLimiter_Estimate(t *testing.T) {
+	limiter := NewLimiter(10, time.Minute)
+	if estimate := limiter.Estimate(10); estimate != 0 {
+		t.Errorf("Tries within the window shouldn't wait, got %s", estimate)
+	}
+	if estimate := limiter.Estimate(11); estimate != time.Minute {
+		t.Errorf("Expected one more window, got %s", estimate)
+	}
+	if estimate := limiter.Estimate(35); estimate != 3*time.Minute {
+		t.Errorf("Expected three more windows, got %s", estimate)
+	}
+}
@@ -0,0 +1,230 @@
+package proxerscrape
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Recommender scores a candidate for the given watchlist. Scores are
+// expected to be in the range of 0 to 1, so that multiple recommenders can be
+// blended. Alternative engines, for example ones backed by an external
+// service, only have to implement this interface.
+type Recommender interface {
+	Score(watchlist *Watchlist, candidate *Media) (float64, error)
+}
+
+// RecommenderFunc allows using a plain function as a Recommender.
+type RecommenderFunc func(watchlist *Watchlist, candidate *Media) (float64, error)
+
+func (recommend RecommenderFunc) Score(watchlist *Watchlist, candidate *Media) (float64, error) {
+	return recommend(watchlist, candidate)
+}
+
+// RatingRecommender scores candidates by their average rating on proxer.me.
+// This requires the extra data of the candidates to be loaded.
+type RatingRecommender struct{}
+
+func (RatingRecommender) Score(_ *Watchlist, candidate *Media) (float64, error) {
+	return candidate.Rating / 10, nil
+}
+
+// GenreRecommender scores candidates by how well their genres match the
+// genres of the entries the user has finished, see NewGenreProfile. Only
+// entries with loaded extra data are taken into account.
+type GenreRecommender struct{}
+
+func (GenreRecommender) Score(watchlist *Watchlist, candidate *Media) (float64, error) {
+	return NewGenreProfile(watchlist).Match(candidate), nil
+}
+
+// genreKeys prefers the stable genre IDs, but falls back to the names for
+// entries cached before IDs were parsed.
+func genreKeys(item *Media) []string {
+	if len(item.GenreIDs) > 0 {
+		return item.GenreIDs
+	}
+	return item.Generes
+}
+
+// Recommenders are the builtin recommenders, which can be referenced by name
+// in RecommenderWeights.
+var Recommenders = map[string]Recommender{
+	"rating": RatingRecommender{},
+	"votes":  VoteRecommender{},
+	"genre":  GenreRecommender{},
+	"length": LengthRecommender{},
+	"sequel": SequelRecommender{},
+	"airing": AiringRecommender{},
+}
+
+// DefaultRecommenderWeights mostly relies on the rating and the user's genre
+// preferences, while strongly discouraging sequels of unfinished entries.
+var DefaultRecommenderWeights = map[string]float64{
+	"rating": 3,
+	"votes":  1,
+	"genre":  2,
+	"length": 1,
+	"sequel": 3,
+	"airing": 1,
+}
+
+// WeightedRecommender is a recommender that's part of a BlendedRecommender.
+type WeightedRecommender struct {
+	Recommender Recommender
+	Weight      float64
+}
+
+// BlendedRecommender combines multiple recommenders by calculating the
+// weighted average of their scores.
+type BlendedRecommender []WeightedRecommender
+
+// NewBlendedRecommender creates a recommender from the weights of named
+// recommenders, see Recommenders. Unknown names cause an error.
+func NewBlendedRecommender(weights map[string]float64) (BlendedRecommender, error) {
+	names := make([]string, 0, len(weights))
+	for name := range weights {
+		names = append(names, name)
+	}
+	// Stable order, so that floating point results are reproducible.
+	sort.Strings(names)
+
+	blended := make(BlendedRecommender, 0, len(weights))
+	for _, name := range names {
+		recommender, ok := Recommenders[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown recommender '%s'", name)
+		}
+		blended = append(blended, WeightedRecommender{Recommender: recommender, Weight: weights[name]})
+	}
+	return blended, nil
+}
+
+func (blended BlendedRecommender) Score(watchlist *Watchlist, candidate *Media) (float64, error) {
+	var score, totalWeight float64
+	for _, weighted := range blended {
+		if weighted.Weight <= 0 {
+			continue
+		}
+		partial, err := weighted.Recommender.Score(watchlist, candidate)
+		if err != nil {
+			return 0, err
+		}
+		score += partial * weighted.Weight
+		totalWeight += weighted.Weight
+	}
+	if totalWeight == 0 {
+		return 0, nil
+	}
+	return score / totalWeight, nil
+}
+
+// Recommendation is a candidate along with its score.
+type Recommendation struct {
+	Media *Media
+	Score float64
+}
+
+// Recommend scores all entries on the to-watch list that have already started
+// airing and returns them ordered by score, best first. Unavailable entries
+// are skipped, as they can't be watched on proxer.me anymore.
+func Recommend(watchlist *Watchlist, recommender Recommender) ([]Recommendation, error) {
+	var recommendations []Recommendation
+	for _, item := range watchlist.ToWatch.Data {
+		if item.Status == StatusPreAiring || item.Unavailable {
+			continue
+		}
+		score, err := recommender.Score(watchlist, item)
+		if err != nil {
+			return nil, fmt.Errorf("error scoring %s: %w", describeMedia(item), err)
+		}
+		recommendations = append(recommendations, Recommendation{Media: item, Score: score})
+	}
+	sort.SliceStable(recommendations, func(a, b int) bool {
+		return recommendations[a].Score > recommendations[b].Score
+	})
+	return recommendations, nil
+}
+
+// RecommendationFilter restricts which entries are recommended. Zero values
+// don't filter anything.
+type RecommendationFilter struct {
+	// IncludeGenres have to all be present. Genres can be given by their
+	// display name or ID, both case insensitive.
+	IncludeGenres []string
+	// ExcludeGenres must not be present.
+	ExcludeGenres []string
+	// MaxEpisodes is the maximum episode count. Entries with an unknown
+	// episode count are kept.
+	MaxEpisodes uint16
+	// MinRating is the minimum average rating on proxer.me.
+	MinRating float64
+	// Types are the allowed media types.
+	Types []MediaType
+	// Tags have to all be present on the entry's note in UserData, see
+	// UserData.AddTag.
+	Tags     []string
+	UserData *UserData
+}
+
+func (filter *RecommendationFilter) hasGenre(item *Media, genre string) bool {
+	for index, name := range item.Generes {
+		if strings.EqualFold(name, genre) ||
+			(index < len(item.GenreIDs) && strings.EqualFold(item.GenreIDs[index], genre)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches reports whether the entry passes the filter. Genre and rating
+// filters require the extra data to be loaded.
+func (filter *RecommendationFilter) Matches(item *Media) bool {
+	for _, genre := range filter.IncludeGenres {
+		if !filter.hasGenre(item, genre) {
+			return false
+		}
+	}
+	for _, genre := range filter.ExcludeGenres {
+		if filter.hasGenre(item, genre) {
+			return false
+		}
+	}
+	if filter.MaxEpisodes > 0 && item.EpisodeCount > filter.MaxEpisodes {
+		return false
+	}
+	if item.Rating < filter.MinRating {
+		return false
+	}
+	if len(filter.Tags) > 0 {
+		var note *EntryNote
+		if filter.UserData != nil {
+			note = filter.UserData.Note(item)
+		}
+		for _, tag := range filter.Tags {
+			if !note.HasTag(tag) {
+				return false
+			}
+		}
+	}
+	if len(filter.Types) > 0 {
+		for _, mediaType := range filter.Types {
+			if item.Type == mediaType {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Filter returns the recommendations passing the filter, keeping their order.
+func (filter *RecommendationFilter) Filter(recommendations []Recommendation) []Recommendation {
+	var filtered []Recommendation
+	for _, recommendation := range recommendations {
+		if filter.Matches(recommendation.Media) {
+			filtered = append(filtered, recommendation)
+		}
+	}
+	return filtered
+}
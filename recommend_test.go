@@ -0,0 +1,96 @@
+package proxerscrape
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestBlendedRecommender(t *testing.T) {
+	watchlist := &Watchlist{
+		Watched: WatchlistCategory{Data: []*Media{
+			{Title: "A", UserRating: 5, Generes: []string{"Action", "Comedy"}},
+			{Title: "B", UserRating: 1, Generes: []string{"Romance"}},
+		}},
+		ToWatch: WatchlistCategory{Data: []*Media{
+			{Title: "Romance", Rating: 9, Generes: []string{"Romance"}},
+			{Title: "Action", Rating: 7, Generes: []string{"Action"}},
+			{Title: "Upcoming", Rating: 10, Status: StatusPreAiring},
+			{Title: "Dead", Rating: 10, Unavailable: true},
+		}},
+	}
+
+	tests := []struct {
+		name    string
+		weights map[string]float64
+		first   string
+		score   float64
+	}{
+		{"rating", map[string]float64{"rating": 1}, "Romance", 0.9},
+		{"genre", map[string]float64{"genre": 1}, "Action", 1},
+		{"blended", map[string]float64{"rating": 1, "genre": 1}, "Action", 0.85},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recommender, err := NewBlendedRecommender(test.weights)
+			if err != nil {
+				t.Fatal(err)
+			}
+			recommendations, err := Recommend(watchlist, recommender)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(recommendations) != 2 {
+				t.Fatalf("Expected pre-airing and unavailable entries to be skipped, got %d recommendations", len(recommendations))
+			}
+			if recommendations[0].Media.Title != test.first || math.Abs(recommendations[0].Score-test.score) > 0.0001 {
+				t.Errorf("Got %s (%f), instead of %s (%f)", recommendations[0].Media.Title, recommendations[0].Score, test.first, test.score)
+			}
+		})
+	}
+
+	if _, err := NewBlendedRecommender(map[string]float64{"unknown": 1}); err == nil {
+		t.Error("Expected error for unknown recommender")
+	}
+}
+
+func TestRecommendationFilter(t *testing.T) {
+	items := []*Media{
+		{ProxerURL: "/info/1", Title: "Long", Type: MediaTypeSeries, EpisodeCount: 500, Rating: 8, Generes: []string{"Action"}, GenreIDs: []string{"Action"}},
+		{ProxerURL: "/info/2", Title: "Movie", Type: MediaTypeMovie, EpisodeCount: 1, Rating: 8.5, Generes: []string{"Romanze"}, GenreIDs: []string{"Romance"}},
+		{ProxerURL: "/info/3", Title: "Bad", Type: MediaTypeSeries, EpisodeCount: 12, Rating: 5, Generes: []string{"Action"}, GenreIDs: []string{"Action"}},
+	}
+	userData := &UserData{}
+	userData.AddTag(items[0], "binge")
+	userData.AddTag(items[2], "binge")
+	userData.AddTag(items[2], "dub")
+	tests := []struct {
+		name     string
+		filter   RecommendationFilter
+		expected []string
+	}{
+		{"none", RecommendationFilter{}, []string{"Long", "Movie", "Bad"}},
+		{"include by ID", RecommendationFilter{IncludeGenres: []string{"romance"}}, []string{"Movie"}},
+		{"exclude by name", RecommendationFilter{ExcludeGenres: []string{"action"}}, []string{"Movie"}},
+		{"max episodes", RecommendationFilter{MaxEpisodes: 24}, []string{"Movie", "Bad"}},
+		{"min rating", RecommendationFilter{MinRating: 7}, []string{"Long", "Movie"}},
+		{"type", RecommendationFilter{Types: []MediaType{MediaTypeSeries}}, []string{"Long", "Bad"}},
+		{"tags", RecommendationFilter{Tags: []string{"binge", "dub"}, UserData: userData}, []string{"Bad"}},
+		{"tags without user data", RecommendationFilter{Tags: []string{"binge"}}, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var recommendations []Recommendation
+			for _, item := range items {
+				recommendations = append(recommendations, Recommendation{Media: item})
+			}
+			var titles []string
+			for _, recommendation := range test.filter.Filter(recommendations) {
+				titles = append(titles, recommendation.Media.Title)
+			}
+			if strings.Join(titles, ",") != strings.Join(test.expected, ",") {
+				t.Errorf("Got %v, expected %v", titles, test.expected)
+			}
+		})
+	}
+}
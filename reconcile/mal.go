@@ -0,0 +1,61 @@
+package reconcile
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Bios-Marcel/proxerscrape"
+	"github.com/Bios-Marcel/proxerscrape/mapping"
+)
+
+// malCategories translates the MyAnimeList status into proxer categories.
+// Since proxer doesn't have "On-Hold", it's treated as stopped.
+var malCategories = map[string]proxerscrape.ListCategory{
+	"Completed":     proxerscrape.CategoryWatched,
+	"Watching":      proxerscrape.CategoryCurrentlyWatching,
+	"Plan to Watch": proxerscrape.CategoryToWatch,
+	"Dropped":       proxerscrape.CategoryStoppedWatching,
+	"On-Hold":       proxerscrape.CategoryStoppedWatching,
+}
+
+// ParseMALExport parses the XML list export of MyAnimeList, which can also
+// be generated by AniList.
+func ParseMALExport(reader io.Reader) ([]RemoteEntry, error) {
+	var export struct {
+		Anime []struct {
+			ID              string `xml:"series_animedb_id"`
+			Title           string `xml:"series_title"`
+			WatchedEpisodes uint16 `xml:"my_watched_episodes"`
+			Score           uint8  `xml:"my_score"`
+			Status          string `xml:"my_status"`
+			FinishDate      string `xml:"my_finish_date"`
+		} `xml:"anime"`
+	}
+	if err := xml.NewDecoder(reader).Decode(&export); err != nil {
+		return nil, err
+	}
+
+	entries := make([]RemoteEntry, 0, len(export.Anime))
+	for _, anime := range export.Anime {
+		entry := RemoteEntry{
+			Service:    mapping.ServiceMyAnimeList,
+			ExternalID: strings.TrimSpace(anime.ID),
+			Title:      strings.TrimSpace(anime.Title),
+			Category:   malCategories[strings.TrimSpace(anime.Status)],
+			Progress:   anime.WatchedEpisodes,
+			Score:      anime.Score,
+		}
+		// MAL uses 0000-00-00 for unset dates.
+		if finishDate, err := time.Parse("2006-01-02", anime.FinishDate); err == nil {
+			entry.UpdatedAt = finishDate
+		}
+		if _, err := strconv.Atoi(entry.ExternalID); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
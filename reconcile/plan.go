@@ -0,0 +1,242 @@
+package reconcile
+
+import (
+	"fmt"
+
+	"github.com/Bios-Marcel/proxerscrape"
+	"github.com/Bios-Marcel/proxerscrape/mapping"
+)
+
+// Direction decides which sides a sync plan changes.
+type Direction string
+
+const (
+	// DirectionBoth changes both sides, the side that is ahead wins.
+	DirectionBoth Direction = "both"
+	// DirectionPush only changes the remote list, proxer always wins.
+	DirectionPush Direction = "push"
+	// DirectionPull only changes the proxer list, the remote list always
+	// wins.
+	DirectionPull Direction = "pull"
+)
+
+// Side is one of the two lists being synced.
+type Side string
+
+const (
+	SideProxer Side = "proxer"
+	SideRemote Side = "remote"
+)
+
+// OperationType is the kind of change an Operation makes.
+type OperationType string
+
+const (
+	// OperationAdd adds an entry that's missing on the target side, along
+	// with its status, progress and score.
+	OperationAdd         OperationType = "add"
+	OperationSetProgress OperationType = "progress"
+	OperationSetScore    OperationType = "score"
+	OperationSetStatus   OperationType = "status"
+)
+
+// Operation is a single change to one of the two lists.
+type Operation struct {
+	Type OperationType
+	// Target is the side that's changed.
+	Target Side
+	// ProxerID and ExternalID identify the entry on both sides.
+	ProxerID   string
+	ExternalID string
+	Title      string
+	// Media is nil for entries that are only on the remote list.
+	Media *proxerscrape.Media
+	// Remote is nil for entries that are only on the proxer list.
+	Remote *RemoteEntry
+
+	// The new values, depending on the type. OperationAdd sets all of them.
+	Category proxerscrape.ListCategory
+	Progress uint16
+	Score    uint8
+}
+
+func (operation Operation) String() string {
+	switch operation.Type {
+	case OperationAdd:
+		return fmt.Sprintf("%s: add '%s' as %s, progress %d, score %d", operation.Target, operation.Title, operation.Category, operation.Progress, operation.Score)
+	case OperationSetProgress:
+		return fmt.Sprintf("%s: set progress of '%s' to %d", operation.Target, operation.Title, operation.Progress)
+	case OperationSetScore:
+		return fmt.Sprintf("%s: set score of '%s' to %d", operation.Target, operation.Title, operation.Score)
+	case OperationSetStatus:
+		return fmt.Sprintf("%s: set status of '%s' to %s", operation.Target, operation.Title, operation.Category)
+	}
+	return fmt.Sprintf("%s: %s '%s'", operation.Target, operation.Type, operation.Title)
+}
+
+// Conflict is a difference that can't be decided automatically, since both
+// sides changed the same field, such as two different scores.
+type Conflict struct {
+	Field      OperationType
+	Difference *Difference
+}
+
+func (conflict Conflict) String() string {
+	difference := conflict.Difference
+	switch conflict.Field {
+	case OperationSetScore:
+		return fmt.Sprintf("'%s': score is %d on proxer, but %d on remote", difference.Media.Title, difference.Media.UserRating, difference.Remote.Score)
+	case OperationSetStatus:
+		return fmt.Sprintf("'%s': status is %s on proxer, but %s on remote", difference.Media.Title, difference.Media.Category, difference.Remote.Category)
+	}
+	return fmt.Sprintf("'%s': %s differs", difference.Media.Title, conflict.Field)
+}
+
+// Resolve returns the operation applying the value of the other side to the
+// given target side.
+func (conflict Conflict) Resolve(target Side) Operation {
+	return conflict.Difference.operation(conflict.Field, target)
+}
+
+// Plan is the minimal set of operations bringing both lists in sync.
+type Plan struct {
+	Service    mapping.Service
+	Operations []Operation
+	// Conflicts have to be resolved before the lists are fully in sync.
+	Conflicts []Conflict
+	// Unmapped are proxer entries that can't be synced, since they have no
+	// mapping for the service.
+	Unmapped []*proxerscrape.Media
+	// UnmappedRemote are remote entries that can't be added to proxer, since
+	// no proxer entry is mapped to them.
+	UnmappedRemote []*RemoteEntry
+}
+
+// For returns the operations changing the given side.
+func (plan *Plan) For(target Side) []Operation {
+	var operations []Operation
+	for _, operation := range plan.Operations {
+		if operation.Target == target {
+			operations = append(operations, operation)
+		}
+	}
+	return operations
+}
+
+// operation creates the operation applying the value of the field from the
+// side opposite of the target.
+func (difference *Difference) operation(field OperationType, target Side) Operation {
+	operation := Operation{
+		Type:       field,
+		Target:     target,
+		ProxerID:   difference.Media.ProxerID(),
+		ExternalID: difference.Remote.ExternalID,
+		Title:      difference.Media.Title,
+		Media:      difference.Media,
+		Remote:     difference.Remote,
+	}
+	if target == SideRemote {
+		operation.Category = difference.Media.Category
+		operation.Progress = difference.Media.EpisodesWatched
+		operation.Score = difference.Media.UserRating
+	} else {
+		operation.Category = difference.Remote.Category
+		operation.Progress = difference.Remote.Progress
+		operation.Score = difference.Remote.Score
+	}
+	return operation
+}
+
+// PlanSync computes the operations required for bringing the lists of the
+// report in sync. Syncing in both directions, progress, status and scores
+// are taken from the side that is ahead. Differences where neither side is
+// ahead are returned as conflicts. Scores are never cleared.
+func PlanSync(report *Report, store *mapping.Store, direction Direction) *Plan {
+	plan := &Plan{Service: report.Service, Unmapped: report.Unmapped}
+	pushes := direction == DirectionBoth || direction == DirectionPush
+	pulls := direction == DirectionBoth || direction == DirectionPull
+
+	for _, difference := range report.Differences {
+		plan.planDifference(difference, direction)
+	}
+
+	if pushes {
+		for _, item := range report.OnlyProxer {
+			itemMapping, _ := store.Get(item.ProxerID(), report.Service)
+			plan.Operations = append(plan.Operations, Operation{
+				Type:       OperationAdd,
+				Target:     SideRemote,
+				ProxerID:   item.ProxerID(),
+				ExternalID: itemMapping.ExternalID,
+				Title:      item.Title,
+				Media:      item,
+				Category:   item.Category,
+				Progress:   item.EpisodesWatched,
+				Score:      item.UserRating,
+			})
+		}
+	}
+	if pulls {
+		for _, remote := range report.OnlyRemote {
+			proxerID, present := store.ProxerID(report.Service, remote.ExternalID)
+			if !present {
+				plan.UnmappedRemote = append(plan.UnmappedRemote, remote)
+				continue
+			}
+			plan.Operations = append(plan.Operations, Operation{
+				Type:       OperationAdd,
+				Target:     SideProxer,
+				ProxerID:   proxerID,
+				ExternalID: remote.ExternalID,
+				Title:      remote.Title,
+				Remote:     remote,
+				Category:   remote.Category,
+				Progress:   remote.Progress,
+				Score:      remote.Score,
+			})
+		}
+	}
+	return plan
+}
+
+func (plan *Plan) planDifference(difference *Difference, direction Direction) {
+	// One-way syncs always take the values of the source.
+	ahead := SideRemote
+	switch {
+	case direction == DirectionPush:
+		ahead = SideProxer
+	case direction == DirectionPull:
+	case difference.ProgressDelta > 0:
+		ahead = SideProxer
+	case difference.ProgressDelta == 0:
+		ahead = ""
+	}
+	target := SideProxer
+	if ahead == SideProxer {
+		target = SideRemote
+	}
+
+	if difference.ProgressDelta != 0 {
+		plan.Operations = append(plan.Operations, difference.operation(OperationSetProgress, target))
+	}
+	if difference.StatusMismatch {
+		if ahead == "" {
+			plan.Conflicts = append(plan.Conflicts, Conflict{Field: OperationSetStatus, Difference: difference})
+		} else {
+			plan.Operations = append(plan.Operations, difference.operation(OperationSetStatus, target))
+		}
+	}
+
+	proxerScore, remoteScore := difference.Media.UserRating, difference.Remote.Score
+	switch {
+	case proxerScore == remoteScore:
+	case remoteScore == 0 && direction != DirectionPull:
+		plan.Operations = append(plan.Operations, difference.operation(OperationSetScore, SideRemote))
+	case proxerScore == 0 && direction != DirectionPush:
+		plan.Operations = append(plan.Operations, difference.operation(OperationSetScore, SideProxer))
+	case proxerScore != 0 && remoteScore != 0 && direction == DirectionBoth:
+		plan.Conflicts = append(plan.Conflicts, Conflict{Field: OperationSetScore, Difference: difference})
+	case proxerScore != 0 && remoteScore != 0:
+		plan.Operations = append(plan.Operations, difference.operation(OperationSetScore, target))
+	}
+}
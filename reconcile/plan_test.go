@@ -0,0 +1,79 @@
+package reconcile
+
+import (
+	"testing"
+
+	"github.com/Bios-Marcel/proxerscrape"
+	"github.com/Bios-Marcel/proxerscrape/mapping"
+)
+
+func planFixture() (*proxerscrape.Watchlist, []RemoteEntry, *mapping.Store) {
+	watchlist := &proxerscrape.Watchlist{}
+	watchlist.CurrentlyWatching.Data = []*proxerscrape.Media{
+		// Ahead on proxer, unrated on remote.
+		{ProxerURL: "/info/1", Title: "Ahead", EpisodesWatched: 5, UserRating: 7, Category: proxerscrape.CategoryCurrentlyWatching},
+		// Same progress, but different status and score.
+		{ProxerURL: "/info/2", Title: "Conflicting", EpisodesWatched: 3, UserRating: 6, Category: proxerscrape.CategoryCurrentlyWatching},
+		{ProxerURL: "/info/3", Title: "Only Proxer", EpisodesWatched: 1, Category: proxerscrape.CategoryCurrentlyWatching},
+	}
+	remote := []RemoteEntry{
+		{ExternalID: "10", Title: "Ahead", Progress: 2, Category: proxerscrape.CategoryCurrentlyWatching},
+		{ExternalID: "20", Title: "Conflicting", Progress: 3, Score: 9, Category: proxerscrape.CategoryStoppedWatching},
+		{ExternalID: "40", Title: "Only Remote", Progress: 12, Score: 8, Category: proxerscrape.CategoryWatched},
+		{ExternalID: "50", Title: "Unmapped Remote"},
+	}
+	store := &mapping.Store{}
+	store.Set("1", mapping.ServiceAniList, mapping.Mapping{ExternalID: "10"})
+	store.Set("2", mapping.ServiceAniList, mapping.Mapping{ExternalID: "20"})
+	store.Set("3", mapping.ServiceAniList, mapping.Mapping{ExternalID: "30"})
+	store.Set("4", mapping.ServiceAniList, mapping.Mapping{ExternalID: "40"})
+	return watchlist, remote, store
+}
+
+func TestPlanSync_Both(t *testing.T) {
+	watchlist, remote, store := planFixture()
+	report := Reconcile(watchlist, remote, store, mapping.ServiceAniList)
+	plan := PlanSync(report, store, DirectionBoth)
+
+	expected := []string{
+		"remote: set progress of 'Ahead' to 5",
+		"remote: set score of 'Ahead' to 7",
+		"remote: add 'Only Proxer' as watching, progress 1, score 0",
+		"proxer: add 'Only Remote' as watched, progress 12, score 8",
+	}
+	if len(plan.Operations) != len(expected) {
+		t.Fatalf("Unexpected operations: %v", plan.Operations)
+	}
+	for index, operation := range plan.Operations {
+		if operation.String() != expected[index] {
+			t.Errorf("Operation %d is %q, expected %q", index, operation, expected[index])
+		}
+	}
+	if plan.Operations[3].ProxerID != "4" {
+		t.Errorf("Remote entry not mapped back: %+v", plan.Operations[3])
+	}
+	if len(plan.Conflicts) != 2 || plan.Conflicts[0].Field != OperationSetStatus || plan.Conflicts[1].Field != OperationSetScore {
+		t.Errorf("Unexpected conflicts: %v", plan.Conflicts)
+	}
+	if resolved := plan.Conflicts[1].Resolve(SideProxer); resolved.Score != 9 || resolved.Target != SideProxer {
+		t.Errorf("Unexpected resolution: %v", resolved)
+	}
+	if len(plan.UnmappedRemote) != 1 || plan.UnmappedRemote[0].ExternalID != "50" {
+		t.Errorf("Unexpected unmapped remote entries: %v", plan.UnmappedRemote)
+	}
+}
+
+func TestPlanSync_Push(t *testing.T) {
+	watchlist, remote, store := planFixture()
+	report := Reconcile(watchlist, remote, store, mapping.ServiceAniList)
+	plan := PlanSync(report, store, DirectionPush)
+
+	if len(plan.Conflicts) != 0 || len(plan.For(SideProxer)) != 0 {
+		t.Errorf("One-way plan changes proxer: %v, %v", plan.Operations, plan.Conflicts)
+	}
+	// Progress and score of the first entry, status and score of the
+	// second and the missing entry.
+	if operations := plan.For(SideRemote); len(operations) != 5 {
+		t.Errorf("Unexpected operations: %v", operations)
+	}
+}
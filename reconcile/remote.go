@@ -0,0 +1,29 @@
+// Package reconcile compares a proxer.me watchlist with the list of the same
+// user on an external service, such as MyAnimeList or AniList.
+package reconcile
+
+import (
+	"time"
+
+	"github.com/Bios-Marcel/proxerscrape"
+	"github.com/Bios-Marcel/proxerscrape/mapping"
+)
+
+// RemoteEntry is an entry of a list on an external service.
+type RemoteEntry struct {
+	Service    mapping.Service
+	ExternalID string
+	Title      string
+	// Category is the status of the entry, translated to the proxer
+	// categories.
+	Category proxerscrape.ListCategory
+	Progress uint16
+	// Score is the users rating on a scale from 1 to 10, 0 meaning unrated.
+	Score uint8
+	// UpdatedAt is the last time the entry was changed, if known.
+	UpdatedAt time.Time
+	// Repeat is how often the entry has been rewatched and Notes are the
+	// user's notes, both only filled by services supporting them.
+	Repeat uint16
+	Notes  string
+}
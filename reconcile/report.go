@@ -0,0 +1,89 @@
+package reconcile
+
+import (
+	"github.com/Bios-Marcel/proxerscrape"
+	"github.com/Bios-Marcel/proxerscrape/mapping"
+)
+
+// Difference is the comparison of a proxer entry and its mapped remote entry.
+type Difference struct {
+	Media  *proxerscrape.Media
+	Remote *RemoteEntry
+	// ProgressDelta is positive if proxer is ahead and negative if the
+	// remote service is ahead.
+	ProgressDelta  int
+	StatusMismatch bool
+	// ScoreMismatch is only set if both sides have a score.
+	ScoreMismatch bool
+}
+
+// InSync tells whether there are no differences at all.
+func (difference *Difference) InSync() bool {
+	return difference.ProgressDelta == 0 && !difference.StatusMismatch && !difference.ScoreMismatch
+}
+
+// Report is the result of reconciling a proxer watchlist with a remote list.
+type Report struct {
+	Service     mapping.Service
+	Differences []*Difference
+	// Unmapped are proxer entries without a mapping for the service.
+	Unmapped []*proxerscrape.Media
+	// OnlyProxer are mapped entries which aren't on the remote list.
+	OnlyProxer []*proxerscrape.Media
+	// OnlyRemote are remote entries which aren't on the proxer list.
+	OnlyRemote []*RemoteEntry
+}
+
+// OutOfSync returns all differences that aren't in sync.
+func (report *Report) OutOfSync() []*Difference {
+	var outOfSync []*Difference
+	for _, difference := range report.Differences {
+		if !difference.InSync() {
+			outOfSync = append(outOfSync, difference)
+		}
+	}
+	return outOfSync
+}
+
+// Reconcile compares all entries of the watchlist with the remote entries,
+// using the mapping store to find the matching remote entry.
+func Reconcile(watchlist *proxerscrape.Watchlist, remote []RemoteEntry, store *mapping.Store, service mapping.Service) *Report {
+	report := &Report{Service: service}
+
+	remoteByID := make(map[string]*RemoteEntry, len(remote))
+	for index := range remote {
+		remoteByID[remote[index].ExternalID] = &remote[index]
+	}
+
+	matched := make(map[string]bool)
+	for _, item := range watchlist.All() {
+		itemMapping, present := store.Get(item.ProxerID(), service)
+		if !present {
+			report.Unmapped = append(report.Unmapped, item)
+			continue
+		}
+
+		remoteEntry, present := remoteByID[itemMapping.ExternalID]
+		if !present {
+			report.OnlyProxer = append(report.OnlyProxer, item)
+			continue
+		}
+
+		matched[itemMapping.ExternalID] = true
+		report.Differences = append(report.Differences, &Difference{
+			Media:          item,
+			Remote:         remoteEntry,
+			ProgressDelta:  int(item.EpisodesWatched) - int(remoteEntry.Progress),
+			StatusMismatch: item.Category != remoteEntry.Category,
+			ScoreMismatch:  item.UserRating != 0 && remoteEntry.Score != 0 && item.UserRating != remoteEntry.Score,
+		})
+	}
+
+	for index := range remote {
+		if !matched[remote[index].ExternalID] {
+			report.OnlyRemote = append(report.OnlyRemote, &remote[index])
+		}
+	}
+
+	return report
+}
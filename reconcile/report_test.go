@@ -0,0 +1,53 @@
+package reconcile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Bios-Marcel/proxerscrape"
+	"github.com/Bios-Marcel/proxerscrape/mapping"
+)
+
+const malExport = `<?xml version="1.0" encoding="UTF-8" ?>
+<myanimelist>
+	<anime>
+		<series_animedb_id>35958</series_animedb_id>
+		<series_title><![CDATA[Tsurune]]></series_title>
+		<my_watched_episodes>10</my_watched_episodes>
+		<my_score>8</my_score>
+		<my_status>Watching</my_status>
+	</anime>
+	<anime>
+		<series_animedb_id>1</series_animedb_id>
+		<series_title><![CDATA[Cowboy Bebop]]></series_title>
+		<my_watched_episodes>26</my_watched_episodes>
+		<my_score>10</my_score>
+		<my_status>Completed</my_status>
+	</anime>
+</myanimelist>`
+
+func TestReconcile(t *testing.T) {
+	remote, err := ParseMALExport(strings.NewReader(malExport))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	watchlist := &proxerscrape.Watchlist{}
+	watchlist.Watched.Data = []*proxerscrape.Media{
+		{ProxerURL: "/info/296", Title: "Tsurune", EpisodesWatched: 13, UserRating: 8, Category: proxerscrape.CategoryWatched},
+		{ProxerURL: "/info/297", Title: "Unmapped", Category: proxerscrape.CategoryWatched},
+	}
+
+	store := &mapping.Store{}
+	store.Set("296", mapping.ServiceMyAnimeList, mapping.Mapping{ExternalID: "35958"})
+
+	report := Reconcile(watchlist, remote, store, mapping.ServiceMyAnimeList)
+	if len(report.Unmapped) != 1 || len(report.OnlyRemote) != 1 || len(report.Differences) != 1 {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+
+	difference := report.Differences[0]
+	if difference.ProgressDelta != 3 || !difference.StatusMismatch || difference.ScoreMismatch {
+		t.Errorf("Unexpected difference: %+v", difference)
+	}
+}
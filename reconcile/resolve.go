@@ -0,0 +1,119 @@
+package reconcile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ConflictStrategy decides which side wins a conflict.
+type ConflictStrategy string
+
+const (
+	StrategyPreferProxer ConflictStrategy = "prefer-proxer"
+	StrategyPreferRemote ConflictStrategy = "prefer-remote"
+	// StrategyNewest prefers the side that has been changed last.
+	StrategyNewest ConflictStrategy = "newest"
+	// StrategyInteractive asks the user for every conflict.
+	StrategyInteractive ConflictStrategy = "interactive"
+)
+
+// ConflictResolver returns the side whose value wins the conflict. An empty
+// side leaves the conflict unresolved.
+type ConflictResolver func(Conflict) (Side, error)
+
+// PreferSide always lets the given side win.
+func PreferSide(winner Side) ConflictResolver {
+	return func(Conflict) (Side, error) {
+		return winner, nil
+	}
+}
+
+// PreferNewest lets the side win that has been changed last. Since proxer.me
+// doesn't track changes, the last time an entry has been watched is used,
+// keyed by proxer ID, see proxerscrape.LastWatched. Conflicts are left
+// unresolved if either time is unknown.
+func PreferNewest(proxerUpdatedAt map[string]time.Time) ConflictResolver {
+	return func(conflict Conflict) (Side, error) {
+		proxerTime, known := proxerUpdatedAt[conflict.Difference.Media.ProxerID()]
+		remoteTime := conflict.Difference.Remote.UpdatedAt
+		if !known || remoteTime.IsZero() {
+			return "", nil
+		}
+		if proxerTime.After(remoteTime) {
+			return SideProxer, nil
+		}
+		return SideRemote, nil
+	}
+}
+
+// Prompt asks for every conflict which side wins, reading the answers from
+// input. Answering with anything but p or r skips the conflict.
+func Prompt(input io.Reader, output io.Writer) ConflictResolver {
+	scanner := bufio.NewScanner(input)
+	return func(conflict Conflict) (Side, error) {
+		fmt.Fprintf(output, "%s\nKeep [p]roxer, [r]emote or [s]kip? ", conflict)
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return "", err
+			}
+			return "", io.ErrUnexpectedEOF
+		}
+		switch strings.ToLower(strings.TrimSpace(scanner.Text())) {
+		case "p", "proxer":
+			return SideProxer, nil
+		case "r", "remote":
+			return SideRemote, nil
+		}
+		return "", nil
+	}
+}
+
+// ResolveConflicts turns all conflicts the resolver decides into
+// operations. Conflicts left unresolved stay in the plan.
+func (plan *Plan) ResolveConflicts(resolve ConflictResolver) error {
+	var unresolved []Conflict
+	for index, conflict := range plan.Conflicts {
+		winner, err := resolve(conflict)
+		if err != nil {
+			plan.Conflicts = append(unresolved, plan.Conflicts[index:]...)
+			return err
+		}
+		switch winner {
+		case SideProxer:
+			plan.Operations = append(plan.Operations, conflict.Resolve(SideRemote))
+		case SideRemote:
+			plan.Operations = append(plan.Operations, conflict.Resolve(SideProxer))
+		default:
+			unresolved = append(unresolved, conflict)
+		}
+	}
+	plan.Conflicts = unresolved
+	return nil
+}
+
+// Writer applies operations to one side of a sync.
+type Writer interface {
+	Apply(operation Operation) error
+}
+
+// Execute applies all operations of the plan to their target side. It stops
+// at the first error, returning how many operations have been applied. A nil
+// writer rejects all operations of its side.
+func (plan *Plan) Execute(proxer, remote Writer) (int, error) {
+	for index, operation := range plan.Operations {
+		writer := remote
+		if operation.Target == SideProxer {
+			writer = proxer
+		}
+		if writer == nil {
+			return index, fmt.Errorf("can't apply '%s', %s isn't writable", operation, operation.Target)
+		}
+		if err := writer.Apply(operation); err != nil {
+			return index, fmt.Errorf("error applying '%s': %w", operation, err)
+		}
+	}
+	return len(plan.Operations), nil
+}
@@ -0,0 +1,82 @@
+package reconcile
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Bios-Marcel/proxerscrape/mapping"
+)
+
+type recordingWriter struct {
+	applied []Operation
+	fail    bool
+}
+
+func (writer *recordingWriter) Apply(operation Operation) error {
+	if writer.fail {
+		return errors.New("rejected")
+	}
+	writer.applied = append(writer.applied, operation)
+	return nil
+}
+
+func TestResolveConflicts(t *testing.T) {
+	watchlist, remote, store := planFixture()
+	remote[1].UpdatedAt = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	report := Reconcile(watchlist, remote, store, mapping.ServiceAniList)
+
+	plan := PlanSync(report, store, DirectionBoth)
+	if err := plan.ResolveConflicts(PreferSide(SideRemote)); err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Conflicts) != 0 || len(plan.For(SideProxer)) != 3 {
+		t.Errorf("Conflicts not resolved towards proxer: %v", plan.Operations)
+	}
+
+	plan = PlanSync(report, store, DirectionBoth)
+	if err := plan.ResolveConflicts(PreferNewest(map[string]time.Time{"2": time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)})); err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Conflicts) != 0 || len(plan.For(SideRemote)) != 5 {
+		t.Errorf("Newer proxer entry didn't win: %v", plan.Operations)
+	}
+
+	// Without a known change on proxer, nothing can be decided.
+	plan = PlanSync(report, store, DirectionBoth)
+	if err := plan.ResolveConflicts(PreferNewest(nil)); err != nil || len(plan.Conflicts) != 2 {
+		t.Errorf("Unexpected result: %v, %v", err, plan.Conflicts)
+	}
+
+	plan = PlanSync(report, store, DirectionBoth)
+	var output strings.Builder
+	if err := plan.ResolveConflicts(Prompt(strings.NewReader("r\n"), &output)); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected end of input, got %v", err)
+	}
+	if len(plan.Conflicts) != 1 || plan.Conflicts[0].Field != OperationSetScore || !strings.Contains(output.String(), "Keep [p]roxer") {
+		t.Errorf("Unexpected conflicts: %v", plan.Conflicts)
+	}
+}
+
+func TestExecute(t *testing.T) {
+	watchlist, remote, store := planFixture()
+	plan := PlanSync(Reconcile(watchlist, remote, store, mapping.ServiceAniList), store, DirectionBoth)
+
+	proxer, remoteWriter := &recordingWriter{}, &recordingWriter{}
+	applied, err := plan.Execute(proxer, remoteWriter)
+	if err != nil || applied != 4 || len(proxer.applied) != 1 || len(remoteWriter.applied) != 3 {
+		t.Errorf("Unexpected result: %d, %v", applied, err)
+	}
+
+	remoteWriter.fail = true
+	if applied, err := plan.Execute(proxer, remoteWriter); err == nil || applied != 0 {
+		t.Errorf("Expected failure on first operation, got %d, %v", applied, err)
+	}
+
+	remoteWriter.fail = false
+	if applied, err := plan.Execute(nil, remoteWriter); err == nil || applied == len(plan.Operations) {
+		t.Errorf("Expected the proxer operation to be rejected, got %d, %v", applied, err)
+	}
+}
@@ -0,0 +1,68 @@
+package proxerscrape
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+)
+
+// Redaction decides how titles and URLs appear in logs and errors.
+type Redaction string
+
+const (
+	// RedactNone prints titles and URLs as they are.
+	RedactNone Redaction = "none"
+	// RedactHash replaces titles with a short hash, so that log lines about
+	// the same entry can still be correlated.
+	RedactHash Redaction = "hash"
+	// RedactOmit leaves out titles completely.
+	RedactOmit Redaction = "omit"
+)
+
+// LogRedaction is applied to all titles and URLs that are logged. In every
+// mode except RedactNone, URLs are reduced to the proxer ID, which is kept
+// for debugging purposes.
+var LogRedaction = RedactNone
+
+var (
+	urlRegex      = regexp.MustCompile(`https?://[^\s"']+|/info/\d+[^\s"']*`)
+	proxerIDRegex = regexp.MustCompile(`/info/(\d+)`)
+)
+
+// describeMedia formats an item for logs and errors, respecting
+// LogRedaction.
+func describeMedia(item *Media) string {
+	switch LogRedaction {
+	case RedactHash:
+		return fmt.Sprintf("'%s'(%s)", hashTitle(item.Title), redactURL(item.ProxerURL))
+	case RedactOmit:
+		return redactURL(item.ProxerURL)
+	}
+	return fmt.Sprintf("'%s'(%s)", item.Title, item.ProxerURL)
+}
+
+// redactURL formats a URL for logs, respecting LogRedaction.
+func redactURL(url string) string {
+	if LogRedaction == RedactNone || LogRedaction == "" {
+		return url
+	}
+	if match := proxerIDRegex.FindStringSubmatch(url); match != nil {
+		return match[1]
+	}
+	return "<redacted>"
+}
+
+// RedactText removes all URLs from arbitrary text, such as error messages,
+// respecting LogRedaction.
+func RedactText(text string) string {
+	if LogRedaction == RedactNone || LogRedaction == "" {
+		return text
+	}
+	return urlRegex.ReplaceAllStringFunc(text, redactURL)
+}
+
+func hashTitle(title string) string {
+	hash := sha256.Sum256([]byte(title))
+	return "#" + hex.EncodeToString(hash[:4])
+}
@@ -0,0 +1,33 @@
+package proxerscrape
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_describeMedia(t *testing.T) {
+	defer func() { LogRedaction = RedactNone }()
+	item := &Media{Title: "Secret Title", ProxerURL: "/info/296#top"}
+
+	LogRedaction = RedactNone
+	if description := describeMedia(item); description != "'Secret Title'(/info/296#top)" {
+		t.Errorf("Unredacted description = %s", description)
+	}
+
+	LogRedaction = RedactHash
+	hashed := describeMedia(item)
+	if strings.Contains(hashed, "Secret") || !strings.Contains(hashed, "296") {
+		t.Errorf("Hashed description = %s", hashed)
+	}
+	if describeMedia(item) != hashed {
+		t.Error("Hash isn't stable")
+	}
+
+	LogRedaction = RedactOmit
+	if description := describeMedia(item); description != "296" {
+		t.Errorf("Omitted description = %s", description)
+	}
+	if text := RedactText(`Get "https://proxer.me/info/296/relation": timeout`); text != `Get "296`+`": timeout` {
+		t.Errorf("Redacted text = %s", text)
+	}
+}
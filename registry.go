@@ -0,0 +1,176 @@
+package proxerscrape
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+)
+
+// Registry deduplicates entries by their ID across categories, profile tabs
+// and merged watchlists, so that the extra data of each entry is retrieved
+// only once per session. The first entry added for an ID is retrieved, while
+// all later ones receive a copy of its extra data. A Registry is safe for
+// concurrent use.
+type Registry struct {
+	lock    sync.Mutex
+	entries map[uint]*registryEntry
+	// order keeps the IDs in the order they were added in, so that entries
+	// are retrieved in a stable order.
+	order []uint
+}
+
+type registryEntry struct {
+	canonical  *Media
+	duplicates []*Media
+	loaded     bool
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{entries: make(map[uint]*registryEntry)}
+}
+
+// Add registers the given entries. Entries without an ID are ignored, since
+// their extra data can't be retrieved anyway. If the extra data of an ID has
+// already been loaded, it's copied into the added entry right away.
+func (registry *Registry) Add(items ...*Media) {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	for _, item := range items {
+		id := item.mediaID()
+		if id == 0 {
+			continue
+		}
+		entry, present := registry.entries[id]
+		if !present {
+			registry.entries[id] = &registryEntry{canonical: item}
+			registry.order = append(registry.order, id)
+			continue
+		}
+		if entry.canonical == item || containsMedia(entry.duplicates, item) {
+			continue
+		}
+		entry.duplicates = append(entry.duplicates, item)
+		if entry.loaded {
+			item.copyExtraData(entry.canonical)
+		}
+	}
+}
+
+func containsMedia(items []*Media, item *Media) bool {
+	for _, candidate := range items {
+		if candidate == item {
+			return true
+		}
+	}
+	return false
+}
+
+// AddWatchlist registers all entries of the watchlist, see Add.
+func (registry *Registry) AddWatchlist(watchlist *Watchlist) {
+	registry.Add(watchlist.All()...)
+}
+
+// AddLibrary registers all entries of all tabs of the library, see Add.
+func (registry *Registry) AddLibrary(library *Library) {
+	registry.Add(library.All()...)
+}
+
+// Get returns the entry first added for the given ID.
+func (registry *Registry) Get(id uint) (*Media, bool) {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	entry, present := registry.entries[id]
+	if !present {
+		return nil, false
+	}
+	return entry.canonical, true
+}
+
+// Len returns the amount of distinct entries.
+func (registry *Registry) Len() int {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	return len(registry.entries)
+}
+
+// LoadExtraData retrieves the extra data of all entries that haven't been
+// loaded yet, see LoadExtraDataContext.
+func (registry *Registry) LoadExtraData(cache *Cache) error {
+	return registry.LoadExtraDataContext(context.Background(), cache, LoadOptions{})
+}
+
+// LoadExtraDataContext retrieves the extra data of every ID that hasn't been
+// loaded yet exactly once and copies it into all of its entries. Anime and
+// manga are retrieved via their respective ratelimiter. IDs that failed are
+// retried on the next call, analogous to WatchlistCategory.LoadExtraData.
+// IDs that couldn't be retrieved due to the ratelimit are deferred to the
+// cache's queue.
+func (registry *Registry) LoadExtraDataContext(ctx context.Context, cache *Cache, options LoadOptions) error {
+	if options.Workers == 0 {
+		options.Workers = cache.Profile.Workers
+	}
+
+	// The lock isn't held while retrieving, so that entries can still be
+	// added in the meantime.
+	var pending WatchlistCategory
+	registry.lock.Lock()
+	for _, id := range registry.order {
+		if entry := registry.entries[id]; !entry.loaded {
+			pending.Data = append(pending.Data, entry.canonical)
+		}
+	}
+	registry.lock.Unlock()
+
+	requests, err := cache.PendingMediaRequests(pending.Data)
+	if err != nil {
+		return err
+	}
+	if err := cache.Reserve(requests); err != nil {
+		return err
+	}
+
+	retrieveRawData := func(item *Media) (io.ReadCloser, CacheInvalidator, error) {
+		if item.Type.IsAnime() {
+			return cache.RetrieveAnimeRawData(item)
+		}
+		return cache.RetrieveMangaRawData(item)
+	}
+	err = pending.LoadExtraDataContext(ctx, retrieveRawData, options)
+
+	// Items that were skipped due to cancellation are part of the item
+	// errors, so only entries that were actually loaded are marked.
+	failed := make(map[*Media]bool)
+	var itemErrors ExtraDataError
+	if errors.As(err, &itemErrors) {
+		for _, itemError := range itemErrors {
+			failed[itemError.Item] = true
+			// Entries stopped by the ratelimit are resumed by the next run,
+			// see Cache.ResumeQueue.
+			if errors.Is(itemError.Err, ErrRateLimited) && cache.Queue != nil {
+				if errQueue := cache.Queue.Push(DeferMedia(itemError.Item)); errQueue != nil {
+					return errQueue
+				}
+			}
+		}
+	} else if err != nil {
+		return err
+	}
+
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+	for _, item := range pending.Data {
+		entry := registry.entries[item.mediaID()]
+		// Failed entries might have been marked unavailable or restricted,
+		// which the duplicates should know as well.
+		for _, duplicate := range entry.duplicates {
+			duplicate.copyExtraData(item)
+		}
+		entry.loaded = !failed[item]
+	}
+	return err
+}
@@ -0,0 +1,75 @@
+package proxerscrape
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_LoadExtraData(t *testing.T) {
+	cache, _ := newTestCache(map[string]string{"/info/296": detailPage})
+	var library Library
+	library.Anime.ToWatch.Data = []*Media{{ID: 296, ProxerURL: "/info/296", Type: MediaTypeSeries}}
+	library.Anime.StoppedWatching.Data = []*Media{{ID: 296, ProxerURL: "/info/296", Type: MediaTypeSeries}}
+	library.Manga.Watched.Data = []*Media{{ProxerURL: "/info/296#top", Type: MediaTypeManga}}
+
+	registry := NewRegistry()
+	registry.AddLibrary(&library)
+	registry.AddLibrary(&library)
+	if registry.Len() != 1 {
+		t.Fatalf("Expected a single entry, got %d", registry.Len())
+	}
+
+	started := 0
+	options := LoadOptions{Progress: func(event ProgressEvent) {
+		if event.Type == ProgressStarted {
+			started++
+		}
+	}}
+	for i := 0; i < 2; i++ {
+		if err := registry.LoadExtraDataContext(context.Background(), cache, options); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if started != 1 {
+		t.Errorf("Entry retrieved %d times", started)
+	}
+	for _, item := range library.All() {
+		if item.Rating != 8.12 {
+			t.Errorf("Entry in %s wasn't loaded", item.Category)
+		}
+	}
+
+	// Entries added later, for example by merging an import, are completed
+	// without another retrieval.
+	late := &Media{ProxerURL: "/info/296"}
+	registry.Add(late)
+	if late.Rating != 8.12 {
+		t.Error("Late entry wasn't completed")
+	}
+	if canonical, ok := registry.Get(296); !ok || canonical != library.Anime.ToWatch.Data[0] {
+		t.Errorf("Unexpected canonical entry: %+v", canonical)
+	}
+}
+
+func TestRegistry_DefersRateLimited(t *testing.T) {
+	captcha := `<script src="//www.google.com/recaptcha/api.js"></script>`
+	cache, _ := newTestCache(nil)
+	cache.QueryMedia = func(item *Media, header http.Header) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(captcha))}, nil
+	}
+	cache.Queue = &FetchQueue{}
+
+	registry := NewRegistry()
+	registry.Add(&Media{ID: 1, ProxerURL: "/info/1", Type: MediaTypeSeries}, &Media{ID: 2, ProxerURL: "/info/2", Type: MediaTypeManga})
+	if err := registry.LoadExtraDataContext(context.Background(), cache, LoadOptions{Workers: 1}); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected ErrRateLimited, got %v", err)
+	}
+	// The second entry was never retrieved, but has to be resumed as well.
+	if pending := cache.Queue.Pending(); len(pending) != 2 || pending[1] != DeferMedia(&Media{ProxerURL: "/info/2", Type: MediaTypeManga}) {
+		t.Errorf("Unexpected queue: %+v", pending)
+	}
+}
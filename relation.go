@@ -0,0 +1,281 @@
+package proxerscrape
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// RelatedMedia is an entry listed on the relation tab of another one.
+type RelatedMedia struct {
+	// Media only contains title, URL, type and the release season.
+	Media *Media
+	// Kind is RelationUnknown if the page doesn't state it, which is the
+	// case for the entry itself.
+	Kind RelationKind
+	// RawKind is the kind as displayed by proxer.me.
+	RawKind string
+}
+
+// relationColumns are the indices of the columns of the relations table.
+type relationColumns struct {
+	name, mediaType, season, kind int
+}
+
+// parseRelationColumns determines the columns by the header of the table.
+// Without a header, the columns are assumed to be name, type and season.
+func parseRelationColumns(document *goquery.Document) relationColumns {
+	columns := relationColumns{name: 0, mediaType: 1, season: 2, kind: -1}
+	document.Find("table#relations tr").First().Find("th").Each(func(index int, header *goquery.Selection) {
+		switch strings.TrimSpace(header.Text()) {
+		case "Name":
+			columns.name = index
+		case "Typ":
+			columns.mediaType = index
+		case "Season":
+			columns.season = index
+		case "Beziehung", "Art":
+			columns.kind = index
+		}
+	})
+	return columns
+}
+
+// ParseRelatedMedia takes an HTML dump of the relation tab of a media entry
+// and parses all entries related to it, for example sequels, specials or
+// movies of the same franchise, alongside the kind of relation.
+func ParseRelatedMedia(reader io.Reader) ([]RelatedMedia, error) {
+	document, parseError := newDocument(reader)
+	if parseError != nil {
+		return nil, parseError
+	}
+
+	columns := parseRelationColumns(document)
+	var related []RelatedMedia
+	document.Find("table#relations tr").Each(func(i int, s *goquery.Selection) {
+		cells := s.Find("td")
+		link := cells.Eq(columns.name).Find("a[href^='/info/']").First()
+		href, present := link.Attr("href")
+		if !present {
+			return
+		}
+
+		item := &Media{
+			ID:        parseMediaID(href),
+			ProxerURL: href,
+			Title:     normalizeText(strings.TrimSpace(link.Text())),
+		}
+		if cells.Length() > columns.mediaType {
+			item.RawType = strings.TrimSpace(cells.Eq(columns.mediaType).Text())
+			item.Type = ParseMediaType(item.RawType)
+		}
+		if cells.Length() > columns.season {
+			season, year, err := parseSeason(strings.TrimSpace(cells.Eq(columns.season).Text()))
+			if err == nil {
+				item.ReleasePeriod.FromSeason = season
+				item.ReleasePeriod.FromYear = year
+			}
+		}
+		relation := RelatedMedia{Media: item}
+		if columns.kind >= 0 && cells.Length() > columns.kind {
+			relation.RawKind = strings.TrimSpace(cells.Eq(columns.kind).Text())
+			relation.Kind = ParseRelationKind(relation.RawKind)
+		}
+		related = append(related, relation)
+	})
+
+	return related, nil
+}
+
+// ParseRelations takes an HTML dump of the relation tab of a media entry and
+// parses all entries related to it, see ParseRelatedMedia. The returned
+// entries only contain title, URL, type and the release season.
+func ParseRelations(reader io.Reader) ([]*Media, error) {
+	related, err := ParseRelatedMedia(reader)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*Media, 0, len(related))
+	for _, relation := range related {
+		items = append(items, relation.Media)
+	}
+	return items, nil
+}
+
+// Relation is an edge of a RelationGraph, where To is listed on the relation
+// tab of From, for example as its sequel.
+type Relation struct {
+	From *Media
+	To   *Media
+	Kind RelationKind
+}
+
+// RelationGraph is the franchise of a media entry, ordered in the way it
+// should be watched.
+type RelationGraph struct {
+	// Root is the entry the graph was built for.
+	Root *Media
+	// WatchOrder contains all entries of the franchise, including the root,
+	// ordered by their release.
+	WatchOrder []*Media
+	// Relations contains the relations listed on the relation tabs of all
+	// retrieved entries.
+	Relations []Relation
+}
+
+// retrieveRelatedMedia retrieves and parses the relation tab of the item.
+func retrieveRelatedMedia(retrieveRawData MediaRawDataRetriever, item *Media) ([]RelatedMedia, error) {
+	reader, _, err := retrieveRawData(item)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ParseRelatedMedia(reader)
+}
+
+// BuildRelationGraph retrieves the relations of the given media entry and,
+// up to the given depth, those of its related entries. All entries are
+// ordered by release, resulting in a suggested watch order. A depth of 1
+// only retrieves the relations of the root, which usually lists most of the
+// franchise already. Each further level costs a request per newly found
+// entry, but finds entries only related to other parts of the franchise,
+// such as the sequel of a spin-off.
+func BuildRelationGraph(retrieveRawData MediaRawDataRetriever, root *Media, depth int) (*RelationGraph, error) {
+	type edge struct {
+		from, to uint
+		kind     RelationKind
+	}
+
+	rootIdentifier := root.mediaID()
+	nodes := map[uint]*Media{rootIdentifier: root}
+	order := []uint{rootIdentifier}
+	seenEdges := make(map[edge]bool)
+	var edges []edge
+
+	level := []*Media{root}
+	for current := 0; current < depth && len(level) > 0; current++ {
+		var next []*Media
+		for _, item := range level {
+			related, err := retrieveRelatedMedia(retrieveRawData, item)
+			if err != nil {
+				return nil, err
+			}
+
+			itemIdentifier := item.mediaID()
+			for _, relation := range related {
+				identifier := relation.Media.mediaID()
+				if _, known := nodes[identifier]; !known {
+					nodes[identifier] = relation.Media
+					order = append(order, identifier)
+					next = append(next, relation.Media)
+				} else if identifier == rootIdentifier && nodes[identifier] == root {
+					// The relation page usually lists the entry itself as
+					// well, but with more data than we have for the root.
+					nodes[identifier] = relation.Media
+				}
+				if identifier == itemIdentifier {
+					continue
+				}
+				relationEdge := edge{from: itemIdentifier, to: identifier, kind: relation.Kind}
+				if !seenEdges[relationEdge] {
+					seenEdges[relationEdge] = true
+					edges = append(edges, relationEdge)
+				}
+			}
+		}
+		level = next
+	}
+
+	graph := &RelationGraph{Root: nodes[rootIdentifier]}
+	for _, identifier := range order {
+		graph.WatchOrder = append(graph.WatchOrder, nodes[identifier])
+	}
+	sort.SliceStable(graph.WatchOrder, func(a, b int) bool {
+		return releasedBefore(graph.WatchOrder[a].ReleasePeriod, graph.WatchOrder[b].ReleasePeriod)
+	})
+	for _, relationEdge := range edges {
+		graph.Relations = append(graph.Relations, Relation{
+			From: nodes[relationEdge.from],
+			To:   nodes[relationEdge.to],
+			Kind: relationEdge.kind,
+		})
+	}
+
+	return graph, nil
+}
+
+func releasedBefore(a, b ReleasePeriod) bool {
+	if a.FromYear != b.FromYear {
+		return a.FromYear < b.FromYear
+	}
+	return a.FromSeason < b.FromSeason
+}
+
+// relationNodeLabel labels the entry with its position in the watch order.
+func relationNodeLabel(position int, item *Media) string {
+	label := fmt.Sprintf("%d. %s", position+1, item.Title)
+	if item.Type != MediaTypeUnknown {
+		label += fmt.Sprintf(" (%s)", item.Type)
+	}
+	if item.ReleasePeriod.FromYear != 0 {
+		label += fmt.Sprintf(" %s %d", item.ReleasePeriod.FromSeason.Name(), item.ReleasePeriod.FromYear)
+	}
+	return label
+}
+
+// WriteDOT writes the relations as a Graphviz DOT graph, where each entry is
+// labelled with its position in the watch order and each edge with the kind
+// of relation. Notes of the entries are written as comments.
+func (graph *RelationGraph) WriteDOT(writer io.Writer) error {
+	var builder strings.Builder
+	builder.WriteString("digraph watchorder {\n\trankdir=LR;\n")
+	for position, item := range graph.WatchOrder {
+		fmt.Fprintf(&builder, "\t%q [label=%q];\n", getCacheIdentifier(item), relationNodeLabel(position, item))
+	}
+	for _, relation := range graph.Relations {
+		fmt.Fprintf(&builder, "\t%q -> %q", getCacheIdentifier(relation.From), getCacheIdentifier(relation.To))
+		if relation.Kind != RelationUnknown {
+			fmt.Fprintf(&builder, " [label=%q]", string(relation.Kind))
+		}
+		builder.WriteString(";\n")
+	}
+	for _, item := range graph.WatchOrder {
+		for _, note := range item.Notes {
+			fmt.Fprintf(&builder, "\t// %s: %s\n", item.Title, note)
+		}
+	}
+	builder.WriteString("}\n")
+
+	_, err := io.WriteString(writer, builder.String())
+	return err
+}
+
+// WriteMermaid writes the relations as a Mermaid flowchart, just like
+// WriteDOT. Notes of the entries are written as comments.
+func (graph *RelationGraph) WriteMermaid(writer io.Writer) error {
+	var builder strings.Builder
+	builder.WriteString("flowchart LR\n")
+	for position, item := range graph.WatchOrder {
+		// Mermaid doesn't support escaping quotes inside of labels.
+		label := strings.ReplaceAll(relationNodeLabel(position, item), `"`, "'")
+		fmt.Fprintf(&builder, "\tm%s[\"%s\"]\n", getCacheIdentifier(item), label)
+	}
+	for _, relation := range graph.Relations {
+		if relation.Kind != RelationUnknown {
+			fmt.Fprintf(&builder, "\tm%s -->|%s| m%s\n", getCacheIdentifier(relation.From), relation.Kind, getCacheIdentifier(relation.To))
+		} else {
+			fmt.Fprintf(&builder, "\tm%s --> m%s\n", getCacheIdentifier(relation.From), getCacheIdentifier(relation.To))
+		}
+	}
+	for _, item := range graph.WatchOrder {
+		for _, note := range item.Notes {
+			fmt.Fprintf(&builder, "\t%%%% %s: %s\n", item.Title, note)
+		}
+	}
+
+	_, err := io.WriteString(writer, builder.String())
+	return err
+}
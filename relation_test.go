@@ -0,0 +1,139 @@
+package proxerscrape
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+const relationPage = `<html><body><table id="relations">
+<tr><th>Name</th><th>Typ</th><th>Beziehung</th><th>Season</th></tr>
+<tr><td><a href="/info/2#top">Second</a></td><td>Animeserie</td><td></td><td>Herbst 2012</td></tr>
+<tr><td><a href="/info/3#top">Movie</a></td><td>Movie</td><td>Nebengeschichte</td><td>Sommer 2012</td></tr>
+<tr><td><a href="/info/1#top">First</a></td><td>Animeserie</td><td>Vorgeschichte</td><td>Winter 2011</td></tr>
+</table></body></html>`
+
+// movieRelationPage lists a spin-off, which isn't on relationPage.
+const movieRelationPage = `<html><body><table id="relations">
+<tr><th>Name</th><th>Typ</th><th>Beziehung</th><th>Season</th></tr>
+<tr><td><a href="/info/3#top">Movie</a></td><td>Movie</td><td></td><td>Sommer 2012</td></tr>
+<tr><td><a href="/info/4#top">Spin-off</a></td><td>OVA</td><td>Ableger</td><td>Winter 2014</td></tr>
+</table></body></html>`
+
+func TestParseRelatedMedia(t *testing.T) {
+	related, err := ParseRelatedMedia(strings.NewReader(relationPage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(related) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(related))
+	}
+	kinds := []RelationKind{RelationUnknown, RelationSideStory, RelationPrequel}
+	for index, relation := range related {
+		if relation.Kind != kinds[index] {
+			t.Errorf("Expected %s to be %q, got %q", relation.Media.Title, kinds[index], relation.Kind)
+		}
+	}
+	if related[1].Media.Type != MediaTypeMovie || related[1].Media.ReleasePeriod.FromYear != 2012 {
+		t.Errorf("Columns weren't detected by the header: %+v", related[1].Media)
+	}
+}
+
+func TestBuildRelationGraph(t *testing.T) {
+	var requested []string
+	retriever := func(item *Media) (io.ReadCloser, CacheInvalidator, error) {
+		requested = append(requested, item.ProxerURL)
+		page := relationPage
+		if item.ProxerID() == "3" {
+			page = movieRelationPage
+		}
+		return io.NopCloser(strings.NewReader(page)), func() error { return nil }, nil
+	}
+
+	graph, err := BuildRelationGraph(retriever, &Media{ProxerURL: "/info/2"}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if graph.Root.Title != "Second" {
+		t.Errorf("Root wasn't replaced by the parsed entry: %+v", graph.Root)
+	}
+
+	var order []string
+	for _, item := range graph.WatchOrder {
+		order = append(order, item.Title)
+	}
+	if strings.Join(order, ",") != "First,Movie,Second" {
+		t.Errorf("Unexpected watch order: %v", order)
+	}
+	if len(graph.Relations) != 2 || graph.Relations[0].To.Title != "Movie" || graph.Relations[0].Kind != RelationSideStory {
+		t.Errorf("Unexpected relations: %+v", graph.Relations)
+	}
+
+	graph.Root.Notes = []string{"Start with the movie"}
+	var builder strings.Builder
+	if err := graph.WriteMermaid(&builder); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(builder.String(), "m2 -->|side-story| m3") || !strings.Contains(builder.String(), "%% Second: Start with the movie") {
+		t.Errorf("Mermaid output misses edge:\n%s", builder.String())
+	}
+
+	requested = nil
+	graph, err = BuildRelationGraph(retriever, &Media{ProxerURL: "/info/2"}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requested) != 3 {
+		t.Errorf("Expected the relations of the root and both related entries to be retrieved, got %v", requested)
+	}
+	if len(graph.WatchOrder) != 4 || graph.WatchOrder[3].Title != "Spin-off" {
+		t.Errorf("Spin-off of the movie wasn't found: %+v", graph.WatchOrder)
+	}
+}
+
+func TestRelationGraph_WriteDOT(t *testing.T) {
+	first := &Media{ProxerURL: "/info/1", Title: "First", Type: MediaTypeSeries, ReleasePeriod: ReleasePeriod{FromSeason: Q1, FromYear: 2011}}
+	second := &Media{ProxerURL: "/info/2", Title: `"Second"`, Notes: []string{"Skip the recap"}}
+	graph := &RelationGraph{
+		Root:       second,
+		WatchOrder: []*Media{first, second},
+		Relations: []Relation{
+			{From: second, To: first, Kind: RelationPrequel},
+			{From: first, To: second},
+		},
+	}
+
+	var builder strings.Builder
+	if err := graph.WriteDOT(&builder); err != nil {
+		t.Fatal(err)
+	}
+	expected := `digraph watchorder {
+	rankdir=LR;
+	"1" [label="1. First (series) Winter 2011"];
+	"2" [label="2. \"Second\""];
+	"2" -> "1" [label="prequel"];
+	"1" -> "2";
+	// "Second": Skip the recap
+}
+`
+	if builder.String() != expected {
+		t.Errorf("Unexpected DOT output:\n%s", builder.String())
+	}
+}
+
+func TestCache_RetrieveRelationsRawData_Limiter(t *testing.T) {
+	cache, _ := newTestCache(map[string]string{"/info/1": relationPage})
+	cache.QueryRelations = cache.QueryMedia
+	cache.AnimeQueryRatelimiter = NewLimiter(1, time.Hour)
+	cache.MangaQueryRatelimiter = NewLimiter(1, time.Hour)
+
+	reader, _, err := cache.RetrieveRelationsRawData(&Media{ProxerURL: "/info/1", Type: MediaTypeManga})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader.Close()
+	if cache.AnimeQueryRatelimiter.Delay() != 0 || cache.MangaQueryRatelimiter.Delay() == 0 {
+		t.Error("Expected the relations of a manga to be retrieved via the manga limiter")
+	}
+}
@@ -0,0 +1,258 @@
+// Package rest exposes the scraped data as JSON via plain GET requests, so
+// that dashboards and scripts in any language can use proxerscrape as a
+// small backend. All data is served via the cache, so the usual maximum ages
+// and ratelimits apply.
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Bios-Marcel/proxerscrape"
+	"github.com/Bios-Marcel/proxerscrape/jsonrpc"
+)
+
+// Server serves the endpoints
+//
+//	/watchlist/{user}/{anime|manga|novel}
+//	/media/{id}[/{anime|manga|novel}]
+//	/stats/{user}
+//	/metrics
+//
+// Manga and novels are retrieved via a separate ratelimit, so their type has
+// to be passed to /media, which otherwise assumes an anime. /metrics serves the metrics of the cache in the format of Prometheus. If
+// RPC is set, JSON-RPC calls are accepted via POST on /rpc as well.
+type Server struct {
+	Cache *proxerscrape.Cache
+	// RPC serves /rpc. The endpoints can be registered as methods via
+	// RegisterMethods.
+	RPC *jsonrpc.Server
+}
+
+// Stats summarises the anime watchlist and profile of a user.
+type Stats struct {
+	Profile         proxerscrape.Profile              `json:"profile"`
+	Entries         map[proxerscrape.ListCategory]int `json:"entries"`
+	EpisodesWatched uint64                            `json:"episodesWatched"`
+	// The watch times are estimated, see Media.EstimatedWatchTime.
+	MinutesWatched uint64 `json:"minutesWatched"`
+	MinutesLeft    uint64 `json:"minutesLeft"`
+}
+
+// errorResponse is the body of all failed requests.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (server *Server) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if request.URL.Path == "/rpc" && server.RPC != nil {
+		server.RPC.ServeHTTP(writer, request)
+		return
+	}
+	if request.Method != http.MethodGet {
+		writer.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	if request.URL.Path == "/metrics" {
+		server.metrics(writer)
+		return
+	}
+
+	segments := strings.Split(strings.Trim(request.URL.Path, "/"), "/")
+	var result any
+	var err error
+	switch {
+	case len(segments) == 3 && segments[0] == "watchlist":
+		result, err = server.watchlist(segments[1], proxerscrape.ProfileTabType(segments[2]))
+	case len(segments) == 2 && segments[0] == "media":
+		result, err = server.media(segments[1], proxerscrape.ProfileTabAnime)
+	case len(segments) == 3 && segments[0] == "media":
+		result, err = server.media(segments[1], proxerscrape.ProfileTabType(segments[2]))
+	case len(segments) == 2 && segments[0] == "stats":
+		result, err = server.stats(segments[1])
+	default:
+		writeJSON(writer, http.StatusNotFound, errorResponse{Error: "unknown endpoint"})
+		return
+	}
+
+	if err != nil {
+		writeJSON(writer, statusOf(err), errorResponse{Error: err.Error()})
+		return
+	}
+	if raw, ok := result.(json.RawMessage); ok {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.Write(raw)
+		return
+	}
+	writeJSON(writer, http.StatusOK, result)
+}
+
+// RegisterMethods registers the endpoints as the JSON-RPC methods
+// `getWatchlist` ({"user": "...", "tab": "anime"}), `getMedia` ({"id": "...",
+// "tab": "anime"}) and `getUserStats` ({"user": "..."}), which are served by
+// the same handlers.
+func (server *Server) RegisterMethods(rpc *jsonrpc.Server) {
+	type params struct {
+		User string                      `json:"user"`
+		Tab  proxerscrape.ProfileTabType `json:"tab"`
+		ID   string                      `json:"id"`
+	}
+	register := func(name string, handle func(params) (any, error)) {
+		rpc.Register(name, func(raw json.RawMessage) (any, error) {
+			call := params{Tab: proxerscrape.ProfileTabAnime}
+			if err := jsonrpc.DecodeParams(raw, &call); err != nil {
+				return nil, err
+			}
+			result, err := handle(call)
+			if errors.As(err, &badRequest{}) {
+				return nil, &jsonrpc.Error{Code: jsonrpc.CodeInvalidParams, Message: err.Error()}
+			}
+			return result, err
+		})
+	}
+	register("getWatchlist", func(call params) (any, error) {
+		return server.watchlist(call.User, call.Tab)
+	})
+	register("getMedia", func(call params) (any, error) {
+		return server.media(call.ID, call.Tab)
+	})
+	register("getUserStats", func(call params) (any, error) {
+		return server.stats(call.User)
+	})
+}
+
+// badRequest marks errors caused by invalid paths or params.
+type badRequest struct {
+	message string
+}
+
+func (err badRequest) Error() string {
+	return err.message
+}
+
+// statusOf picks the HTTP status matching the error.
+func statusOf(err error) int {
+	var statusError *proxerscrape.StatusError
+	switch {
+	case errors.As(err, &badRequest{}):
+		return http.StatusBadRequest
+	case errors.Is(err, proxerscrape.ErrRateLimited):
+		return http.StatusTooManyRequests
+	case errors.As(err, &statusError) && statusError.StatusCode == http.StatusNotFound:
+		return http.StatusNotFound
+	case errors.Is(err, proxerscrape.ErrDeadLink):
+		return http.StatusGone
+	case errors.Is(err, proxerscrape.ErrLoginRequired):
+		return http.StatusForbidden
+	}
+	return http.StatusBadGateway
+}
+
+func writeJSON(writer http.ResponseWriter, status int, value any) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	json.NewEncoder(writer).Encode(value)
+}
+
+func validID(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, char := range id {
+		if char < '0' || char > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func validTab(tabType proxerscrape.ProfileTabType) error {
+	switch tabType {
+	case proxerscrape.ProfileTabAnime, proxerscrape.ProfileTabManga, proxerscrape.ProfileTabNovel:
+		return nil
+	}
+	return badRequest{"unknown tab '" + string(tabType) + "'"}
+}
+
+func (server *Server) retrieveWatchlist(userID string, tabType proxerscrape.ProfileTabType) (proxerscrape.Watchlist, error) {
+	if !validID(userID) {
+		return proxerscrape.Watchlist{}, badRequest{"invalid user ID '" + userID + "'"}
+	}
+	if err := validTab(tabType); err != nil {
+		return proxerscrape.Watchlist{}, err
+	}
+
+	reader, _, err := server.Cache.RetrieveProfileTabRawData(userID, tabType)
+	if err != nil {
+		return proxerscrape.Watchlist{}, err
+	}
+	defer reader.Close()
+	return proxerscrape.ParseProfileMediaTab(reader)
+}
+
+// watchlist serves the watchlist in the format of ExportWatchlistJSON.
+func (server *Server) watchlist(userID string, tabType proxerscrape.ProfileTabType) (any, error) {
+	watchlist, err := server.retrieveWatchlist(userID, tabType)
+	if err != nil {
+		return nil, err
+	}
+	var builder strings.Builder
+	if err := proxerscrape.ExportWatchlistJSON(&builder, &watchlist); err != nil {
+		return nil, err
+	}
+	return json.RawMessage(builder.String()), nil
+}
+
+// media serves an entry including its extra data. The tab decides which
+// ratelimit applies, since the type is only known after retrieving the entry.
+func (server *Server) media(id string, tabType proxerscrape.ProfileTabType) (any, error) {
+	if !validID(id) {
+		return nil, badRequest{"invalid media ID '" + id + "'"}
+	}
+	if err := validTab(tabType); err != nil {
+		return nil, err
+	}
+	retrieveRawData := server.Cache.RetrieveMangaRawData
+	if tabType == proxerscrape.ProfileTabAnime {
+		retrieveRawData = server.Cache.RetrieveAnimeRawData
+	}
+	category := proxerscrape.WatchlistCategory{Data: []*proxerscrape.Media{{ProxerURL: "/info/" + id}}}
+	if err := category.LoadExtraData(retrieveRawData); err != nil {
+		return nil, err
+	}
+	return category.Data[0], nil
+}
+
+func (server *Server) stats(userID string) (any, error) {
+	watchlist, err := server.retrieveWatchlist(userID, proxerscrape.ProfileTabAnime)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := server.Cache.RetrieveProfile(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := Stats{Profile: profile, Entries: make(map[proxerscrape.ListCategory]int)}
+	for _, category := range proxerscrape.ListCategories {
+		stats.Entries[category] = len(watchlist.Category(category).Data)
+	}
+	for _, item := range watchlist.All() {
+		stats.EpisodesWatched += uint64(item.EpisodesWatched)
+		stats.MinutesWatched += uint64(item.EstimatedWatchTime(item.EpisodesWatched).Minutes())
+		if item.Category != proxerscrape.CategoryStoppedWatching {
+			stats.MinutesLeft += uint64(item.EstimatedWatchTimeLeft().Minutes())
+		}
+	}
+	return stats, nil
+}
+
+func (server *Server) metrics(writer http.ResponseWriter) {
+	// Failing to read the store shouldn't hide the remaining metrics.
+	stats, _ := server.Cache.Stats()
+	writer.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	proxerscrape.WritePrometheus(writer, server.Cache.Metrics(), stats)
+}
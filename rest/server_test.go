@@ -0,0 +1,186 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Bios-Marcel/proxerscrape"
+	"github.com/Bios-Marcel/proxerscrape/jsonrpc"
+)
+
+const watchlistPage = `<html><body>
+<a name="state0"></a><table><tr><th>Name</th></tr><tr><th>Filter</th></tr>
+<tr><td><img title="Abgeschlossen"></td><td><a href="/info/53">One Piece</a></td><td>Animeserie</td><td></td><td><span>12 / 12</span></td></tr>
+</table>
+<a name="state1"></a><table><tr><th>Name</th></tr><tr><th>Filter</th></tr></table>
+<a name="state2"></a><table><tr><th>Name</th></tr><tr><th>Filter</th></tr></table>
+<a name="state3"></a><table><tr><th>Name</th></tr><tr><th>Filter</th></tr></table>
+</body></html>`
+
+const mediaPage = `<html><head><title>Tsurune - Anime - Proxer.Me</title></head><body>
+<table class="details"><tbody>
+<tr><td><b>Englischer Titel</b></td><td>Tsurune: Kazemai High School Kyudo Club</td></tr>
+</tbody></table>
+<span class="average">8.12</span> (<span class="count">1337</span> Stimmen)
+</body></html>`
+
+const profilePage = `<html><body><table>
+<tr><td>Benutzername:</td><td>Marcel</td></tr>
+<tr><td>Animepunkte:</td><td>1.234 Punkte</td></tr>
+</table></body></html>`
+
+func respond(status int, page string) (*http.Response, error) {
+	return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(page))}, nil
+}
+
+func TestServer(t *testing.T) {
+	// Manga have to be retrieved via their own ratelimit.
+	mangaLimiter := proxerscrape.NewLimiter(1, time.Hour)
+	server := httptest.NewServer(&Server{Cache: &proxerscrape.Cache{
+		Store:                 proxerscrape.NewMemoryStore(),
+		MangaQueryRatelimiter: mangaLimiter,
+		QueryProfileTab: func(profileID string, tabType proxerscrape.ProfileTabType, header http.Header) (*http.Response, error) {
+			if profileID != "1" {
+				return respond(http.StatusServiceUnavailable, "")
+			}
+			return respond(http.StatusOK, watchlistPage)
+		},
+		QueryMedia: func(item *proxerscrape.Media, header http.Header) (*http.Response, error) {
+			switch item.ProxerURL {
+			case "/info/296", "/info/297":
+				return respond(http.StatusOK, mediaPage)
+			case "/info/404":
+				return respond(http.StatusOK, `<html><head><title>Proxer.Me - 404</title></head></html>`)
+			}
+			return respond(http.StatusOK, `<html><head><title>Proxer.Me</title></head><body><h3>Bitte logge dich ein.</h3></body></html>`)
+		},
+		QueryPage: func(path string, header http.Header) (*http.Response, error) {
+			return respond(http.StatusOK, profilePage)
+		},
+	}})
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/watchlist/1/anime")
+	if err != nil {
+		t.Fatal(err)
+	}
+	watchlist, err := proxerscrape.ImportWatchlistJSON(response.Body)
+	response.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(watchlist.Watched.Data) != 1 || watchlist.Watched.Data[0].Title != "One Piece" {
+		t.Errorf("Unexpected watchlist: %+v", watchlist.Watched.Data)
+	}
+
+	response, err = http.Get(server.URL + "/media/296")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var media proxerscrape.Media
+	json.NewDecoder(response.Body).Decode(&media)
+	response.Body.Close()
+	if media.Rating != 8.12 || media.EnglishTitle != "Tsurune: Kazemai High School Kyudo Club" {
+		t.Errorf("Unexpected media: %+v", media)
+	}
+
+	response, err = http.Get(server.URL + "/media/297/manga")
+	if err != nil {
+		t.Fatal(err)
+	}
+	json.NewDecoder(response.Body).Decode(&media)
+	response.Body.Close()
+	if media.Rating != 8.12 || mangaLimiter.Delay() == 0 {
+		t.Errorf("Unexpected manga: %+v", media)
+	}
+
+	response, err = http.Get(server.URL + "/stats/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stats Stats
+	json.NewDecoder(response.Body).Decode(&stats)
+	response.Body.Close()
+	if stats.Profile.Username != "Marcel" || stats.Entries[proxerscrape.CategoryWatched] != 1 || stats.EpisodesWatched != 12 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	response, err = http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics, _ := io.ReadAll(response.Body)
+	response.Body.Close()
+	if !strings.Contains(string(metrics), "proxerscrape_requests_total 4\n") {
+		t.Errorf("Unexpected metrics:\n%s", metrics)
+	}
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/watchlist/1/unknown", http.StatusBadRequest},
+		{"/watchlist/abc/anime", http.StatusBadRequest},
+		{"/watchlist/2/anime", http.StatusBadGateway},
+		{"/media/abc", http.StatusBadRequest},
+		{"/media/296/unknown", http.StatusBadRequest},
+		{"/media/404", http.StatusGone},
+		{"/media/18", http.StatusForbidden},
+		{"/unknown", http.StatusNotFound},
+	}
+	for _, test := range tests {
+		response, err := http.Get(server.URL + test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var body errorResponse
+		json.NewDecoder(response.Body).Decode(&body)
+		response.Body.Close()
+		if response.StatusCode != test.status || body.Error == "" {
+			t.Errorf("%s: expected status %d with error, got %d (%q)", test.path, test.status, response.StatusCode, body.Error)
+		}
+	}
+}
+
+func TestServer_RPC(t *testing.T) {
+	restServer := &Server{
+		Cache: &proxerscrape.Cache{
+			Store: proxerscrape.NewMemoryStore(),
+			QueryProfileTab: func(profileID string, tabType proxerscrape.ProfileTabType, header http.Header) (*http.Response, error) {
+				return respond(http.StatusOK, watchlistPage)
+			},
+		},
+		RPC: jsonrpc.NewServer(),
+	}
+	restServer.RegisterMethods(restServer.RPC)
+	server := httptest.NewServer(restServer)
+	defer server.Close()
+
+	call := func(body string) jsonrpc.Response {
+		response, err := http.Post(server.URL+"/rpc", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer response.Body.Close()
+		var rpcResponse jsonrpc.Response
+		if err := json.NewDecoder(response.Body).Decode(&rpcResponse); err != nil {
+			t.Fatal(err)
+		}
+		return rpcResponse
+	}
+
+	response := call(`{"jsonrpc":"2.0","id":1,"method":"getWatchlist","params":{"user":"1"}}`)
+	if response.Error != nil || !strings.Contains(fmt.Sprint(response.Result), "One Piece") {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+	response = call(`{"jsonrpc":"2.0","id":2,"method":"getMedia","params":{"id":"abc"}}`)
+	if response.Error == nil || response.Error.Code != jsonrpc.CodeInvalidParams {
+		t.Errorf("Expected invalid params, got %+v", response)
+	}
+}
@@ -0,0 +1,77 @@
+package proxerscrape
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// StatusError is returned if proxer.me responds with an error status other
+// than 404, which is used for dead links. Such pages are never cached.
+type StatusError struct {
+	StatusCode int
+	URL        string
+}
+
+func (err *StatusError) Error() string {
+	return fmt.Sprintf("request to '%s' failed with status %d", err.URL, err.StatusCode)
+}
+
+// Is makes 429 Too Many Requests match ErrRateLimited, so that it's handled
+// like the captcha wall.
+func (err *StatusError) Is(target error) bool {
+	return target == ErrRateLimited && err.StatusCode == http.StatusTooManyRequests
+}
+
+// RetryPolicy configures how often failed requests are retried, before the
+// error is returned. Only transient errors, such as server errors, timeouts
+// and connection resets are retried.
+type RetryPolicy struct {
+	// Attempts is the amount of retries after the initial request. Zero
+	// disables retrying.
+	Attempts int
+	// Backoff is the delay before the first retry, it doubles with each
+	// further retry.
+	Backoff time.Duration
+	// Jitter randomly varies the delay by the given fraction, for example
+	// 0.5 means up to 50% shorter or longer.
+	Jitter float64
+}
+
+// DefaultRetryPolicy retries three times, waiting roughly 2, 4 and 8
+// seconds.
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts: 3,
+	Backoff:  2 * time.Second,
+	Jitter:   0.5,
+}
+
+// delay returns the time to wait before the given retry, starting at 0.
+func (policy RetryPolicy) delay(retry int) time.Duration {
+	delay := policy.Backoff << retry
+	if policy.Jitter > 0 {
+		delay += time.Duration((rand.Float64()*2 - 1) * policy.Jitter * float64(delay))
+	}
+	return delay
+}
+
+// isTransient decides whether an error is worth retrying.
+func isTransient(err error) bool {
+	var statusError *StatusError
+	if errors.As(err, &statusError) {
+		return statusError.StatusCode >= http.StatusInternalServerError
+	}
+	var netError net.Error
+	if errors.As(err, &netError) && netError.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
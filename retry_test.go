@@ -0,0 +1,89 @@
+package proxerscrape
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestCache_Retry(t *testing.T) {
+	failures := []error{syscall.ECONNRESET, &StatusError{StatusCode: http.StatusServiceUnavailable}}
+	queries := 0
+	cache := &Cache{
+		Store: NewMemoryStore(),
+		Retry: RetryPolicy{Attempts: 2, Backoff: time.Millisecond},
+		QueryMedia: func(item *Media, header http.Header) (*http.Response, error) {
+			queries++
+			if queries == 2 {
+				return &http.Response{StatusCode: http.StatusBadGateway, Body: io.NopCloser(strings.NewReader("error"))}, nil
+			}
+			if queries <= len(failures) {
+				return nil, failures[queries-1]
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("page"))}, nil
+		},
+	}
+
+	reader, _, err := cache.RetrieveAnimeRawData(&Media{ProxerURL: "/info/296"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := io.ReadAll(reader); string(data) != "page" || queries != 3 {
+		t.Errorf("Data = %s after %d queries", data, queries)
+	}
+
+	queries = 0
+	cache.Retry.Attempts = 1
+	cache.Store = NewMemoryStore()
+	_, _, err = cache.RetrieveAnimeRawData(&Media{ProxerURL: "/info/296"})
+	var statusError *StatusError
+	if !errors.As(err, &statusError) || queries != 2 {
+		t.Errorf("Expected StatusError after 2 queries, got %v after %d", err, queries)
+	}
+	if _, _, err := cache.Store.Get("296"); !errors.Is(err, ErrCacheMiss) {
+		t.Error("Error page was cached")
+	}
+}
+
+func Test_isTransient(t *testing.T) {
+	if isTransient(errors.New("invalid URL")) {
+		t.Error("Arbitrary error is considered transient")
+	}
+	if isTransient(&StatusError{StatusCode: http.StatusNotFound}) {
+		t.Error("404 is considered transient")
+	}
+	if !isTransient(&StatusError{StatusCode: http.StatusInternalServerError}) {
+		t.Error("500 isn't considered transient")
+	}
+}
+
+func TestCache_ClientErrors(t *testing.T) {
+	tests := []struct {
+		status      int
+		err         bool
+		rateLimited bool
+	}{
+		{http.StatusTooManyRequests, true, true},
+		{http.StatusForbidden, true, false},
+		{http.StatusNotFound, false, false},
+	}
+	for _, test := range tests {
+		cache := &Cache{
+			Store: NewMemoryStore(),
+			QueryMedia: func(item *Media, header http.Header) (*http.Response, error) {
+				return &http.Response{StatusCode: test.status, Body: io.NopCloser(strings.NewReader("error"))}, nil
+			},
+		}
+		_, _, err := cache.RetrieveAnimeRawData(&Media{ProxerURL: "/info/296"})
+		if (err != nil) != test.err || errors.Is(err, ErrRateLimited) != test.rateLimited {
+			t.Errorf("%d: unexpected error %v", test.status, err)
+		}
+		if _, _, errStore := cache.Store.Get("296"); errors.Is(errStore, ErrCacheMiss) != test.err {
+			t.Errorf("%d: expected the page to be cached only without error, got %v", test.status, errStore)
+		}
+	}
+}
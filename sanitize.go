@@ -0,0 +1,73 @@
+package proxerscrape
+
+import (
+	"io"
+	"regexp"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+var (
+	sanitizeUserLinkRegex = regexp.MustCompile(`/user/\d+`)
+	// Proxer includes the username in the title of a profile page, such as
+	// "Proxer.Me - Profil von XXX".
+	sanitizeTitleRegex  = regexp.MustCompile(`(?i)(profil(?:\s+von)?\s*)\S+`)
+	sanitizeAvatarRegex = regexp.MustCompile(`(?i)avatar|comprofiler`)
+)
+
+// Sanitize removes user specific data from an HTML page, so that it can be
+// shared safely, for example as a test fixture or in a bug report. This
+// removes usernames, user IDs, avatars, scripts other than the captcha,
+// hidden form fields (which carry session tokens) and HTML comments. The
+// structure relevant for parsing is kept intact.
+func Sanitize(reader io.Reader, writer io.Writer) error {
+	document, err := goquery.NewDocumentFromReader(reader)
+	if err != nil {
+		return err
+	}
+
+	// Scripts contain session specific data, such as the logged in user and
+	// tokens and aren't required for parsing anyway. The captcha script is
+	// kept, as it's used for detecting the ratelimit.
+	document.Find("script:not([src*='recaptcha']), noscript, iframe, input[type=hidden], meta[name*=token], meta[name*=csrf]").Remove()
+
+	document.Find("title").Each(func(i int, s *goquery.Selection) {
+		s.SetText(sanitizeTitleRegex.ReplaceAllString(s.Text(), "${1}user"))
+	})
+
+	// Avatars are usually the only images linking to user specific content.
+	document.Find("img").Each(func(i int, s *goquery.Selection) {
+		if src, present := s.Attr("src"); present && sanitizeAvatarRegex.MatchString(src) {
+			s.SetAttr("src", "/images/comprofiler/nophoto.png")
+		}
+		s.RemoveAttr("alt")
+	})
+
+	// Replace all links to users and their usernames.
+	document.Find("a[href*='/user/']").Each(func(i int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		s.SetAttr("href", sanitizeUserLinkRegex.ReplaceAllString(href, "/user/0"))
+		if s.Find("img").Length() == 0 {
+			s.SetText("user")
+		}
+	})
+
+	removeComments(document.Nodes...)
+
+	return html.Render(writer, document.Get(0))
+}
+
+func removeComments(nodes ...*html.Node) {
+	for _, node := range nodes {
+		for child := node.FirstChild; child != nil; {
+			next := child.NextSibling
+			if child.Type == html.CommentNode {
+				node.RemoveChild(child)
+			} else {
+				removeComments(child)
+			}
+			child = next
+		}
+	}
+}
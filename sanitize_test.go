@@ -0,0 +1,48 @@
+package proxerscrape
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	page := `<html><head><title>Proxer.Me - Profil von SecretName</title>
+<script>var token = "abc";</script></head><body>
+<!-- session 123 -->
+<a href="/user/252835#top">SecretName</a>
+<img src="/images/comprofiler/avatar_252835.jpg" alt="SecretName"/>
+<input type="hidden" name="token" value="abc"/>
+<a href="/info/296#top">Tsurune</a>
+</body></html>`
+
+	var builder strings.Builder
+	if err := Sanitize(strings.NewReader(page), &builder); err != nil {
+		t.Fatal(err)
+	}
+
+	result := builder.String()
+	for _, secret := range []string{"SecretName", "252835", "abc", "session 123"} {
+		if strings.Contains(result, secret) {
+			t.Errorf("Result still contains '%s':\n%s", secret, result)
+		}
+	}
+	if !strings.Contains(result, `<a href="/info/296#top">Tsurune</a>`) {
+		t.Errorf("Result doesn't contain media link anymore:\n%s", result)
+	}
+}
+
+func TestSanitize_KeepsCaptcha(t *testing.T) {
+	page, err := os.ReadFile(filepath.Join("testdata", "fixtures", "info_captcha.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var builder strings.Builder
+	if err := Sanitize(strings.NewReader(string(page)), &builder); err != nil {
+		t.Fatal(err)
+	}
+	if !isCaptchaPage([]byte(builder.String())) {
+		t.Errorf("Captcha isn't detected anymore:\n%s", builder.String())
+	}
+}
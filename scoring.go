@@ -0,0 +1,101 @@
+package proxerscrape
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// VoteRecommender scores candidates by the amount of votes their rating is
+// based on, so that ratings of a handful of voters don't outweigh those of
+// thousands. 10000 votes and more score 1.
+type VoteRecommender struct{}
+
+func (VoteRecommender) Score(_ *Watchlist, candidate *Media) (float64, error) {
+	return math.Min(1, math.Log10(float64(candidate.Votes)+1)/4), nil
+}
+
+// LengthRecommender prefers short entries, which are easier to fit in. Up to
+// a single cour of 13 episodes scores 1, longer entries score less the longer
+// they are. Entries with an unknown episode count score 0.5.
+type LengthRecommender struct{}
+
+func (LengthRecommender) Score(_ *Watchlist, candidate *Media) (float64, error) {
+	if candidate.EpisodeCount == 0 {
+		return 0.5, nil
+	}
+	return math.Min(1, 13/float64(candidate.EpisodeCount)), nil
+}
+
+// AiringRecommender prefers finished entries, since they can be watched in one
+// go, over entries that are still airing.
+type AiringRecommender struct{}
+
+func (AiringRecommender) Score(_ *Watchlist, candidate *Media) (float64, error) {
+	switch candidate.Status {
+	case StatusFinished:
+		return 1, nil
+	case StatusAiring:
+		return 0.5, nil
+	case StatusCancelled:
+		return 0.25, nil
+	}
+	return 0.5, nil
+}
+
+// sequelTitleRegex matches titles of sequels, such as "X Season 2",
+// "X 2nd Season", "X Part 2" or "X 2". The first group is the title of the
+// franchise, one of the other groups the number of the sequel.
+var sequelTitleRegex = regexp.MustCompile(`(?i)^(.+?)[\s:]+(?:season\s*(\d+)|(\d+)(?:st|nd|rd|th)\s+season|part\s*(\d+)|(\d+)|(II|III|IV))$`)
+
+var romanNumerals = map[string]int{"ii": 2, "iii": 3, "iv": 4}
+
+// sequelOf returns the title of the franchise and the number of the sequel, 0
+// if the title doesn't look like a sequel.
+func sequelOf(title string) (string, int) {
+	match := sequelTitleRegex.FindStringSubmatch(strings.TrimSpace(title))
+	if match == nil {
+		return "", 0
+	}
+	for _, group := range match[2:6] {
+		if number, err := strconv.Atoi(group); err == nil {
+			return match[1], number
+		}
+	}
+	return match[1], romanNumerals[strings.ToLower(match[6])]
+}
+
+// SequelRecommender keeps sequels from being suggested before their prequel
+// has been watched. Sequels are detected by their title, such as "X Season 2".
+// A sequel whose prequel is on the watchlist, but hasn't been finished,
+// scores 0, while one whose prequel has been watched scores 1. Everything
+// else scores 0.5.
+type SequelRecommender struct{}
+
+func (SequelRecommender) Score(watchlist *Watchlist, candidate *Media) (float64, error) {
+	franchise, number := sequelOf(candidate.Title)
+	if number < 2 {
+		return 0.5, nil
+	}
+
+	franchise = normalizeSearchText(franchise)
+	score := 0.5
+	for _, item := range watchlist.All() {
+		if item == candidate {
+			continue
+		}
+		isPrequel := normalizeSearchText(item.Title) == franchise
+		if title, itemNumber := sequelOf(item.Title); !isPrequel && itemNumber > 0 {
+			isPrequel = itemNumber < number && normalizeSearchText(title) == franchise
+		}
+		if !isPrequel {
+			continue
+		}
+		if item.Category != CategoryWatched {
+			return 0, nil
+		}
+		score = 1
+	}
+	return score, nil
+}
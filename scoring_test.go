@@ -0,0 +1,59 @@
+package proxerscrape
+
+import "testing"
+
+func TestSequelRecommender(t *testing.T) {
+	watchlist := &Watchlist{
+		Watched: WatchlistCategory{Data: []*Media{
+			{Title: "Mob Psycho 100", Category: CategoryWatched},
+		}},
+		CurrentlyWatching: WatchlistCategory{Data: []*Media{
+			{Title: "Shingeki no Kyojin", Category: CategoryCurrentlyWatching},
+		}},
+		ToWatch: WatchlistCategory{Data: []*Media{
+			{Title: "Mob Psycho 100 II", Category: CategoryToWatch},
+			{Title: "Shingeki no Kyojin Season 2", Category: CategoryToWatch},
+			{Title: "Shingeki no Kyojin Season 3", Category: CategoryToWatch},
+			{Title: "Vinland Saga Season 2", Category: CategoryToWatch},
+			{Title: "Steins;Gate", Category: CategoryToWatch},
+		}},
+	}
+	expected := map[string]float64{
+		"Mob Psycho 100 II":           1,
+		"Shingeki no Kyojin Season 2": 0,
+		"Shingeki no Kyojin Season 3": 0,
+		"Vinland Saga Season 2":       0.5,
+		"Steins;Gate":                 0.5,
+	}
+	for _, candidate := range watchlist.ToWatch.Data {
+		score, _ := SequelRecommender{}.Score(watchlist, candidate)
+		if score != expected[candidate.Title] {
+			t.Errorf("%s scored %f, expected %f", candidate.Title, score, expected[candidate.Title])
+		}
+	}
+}
+
+func TestDefaultRecommenderWeights(t *testing.T) {
+	recommender, err := NewBlendedRecommender(DefaultRecommenderWeights)
+	if err != nil {
+		t.Fatal(err)
+	}
+	watchlist := &Watchlist{
+		CurrentlyWatching: WatchlistCategory{Data: []*Media{
+			{Title: "Kingdom", Category: CategoryCurrentlyWatching},
+		}},
+		ToWatch: WatchlistCategory{Data: []*Media{
+			{Title: "Kingdom 2", Rating: 9, Votes: 5000, EpisodeCount: 39, Status: StatusFinished},
+			{Title: "Tsurune", Rating: 8, Votes: 800, EpisodeCount: 13, Status: StatusFinished},
+			{Title: "Obscure", Rating: 9.5, Votes: 3, EpisodeCount: 12, Status: StatusFinished},
+		}},
+	}
+	recommendations, err := Recommend(watchlist, recommender)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recommendations[0].Media.Title != "Tsurune" || recommendations[2].Media.Title != "Kingdom 2" {
+		t.Errorf("Expected the sequel to be ranked last and votes to matter, got %s, %s, %s",
+			recommendations[0].Media.Title, recommendations[1].Media.Title, recommendations[2].Media.Title)
+	}
+}
@@ -0,0 +1,112 @@
+package proxerscrape
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// searchTypes are the values of the `typ` parameter of the search.
+var searchTypes = map[MediaType]string{
+	MediaTypeSeries:    "animeseries",
+	MediaTypeMovie:     "movie",
+	MediaTypeOVA:       "ova",
+	MediaTypeSpecial:   "special",
+	MediaTypeManga:     "mangaseries",
+	MediaTypeOneShot:   "oneshot",
+	MediaTypeDoujinshi: "doujin",
+}
+
+// SearchQuery holds the parameters of the advanced search of proxer.me. Zero
+// values aren't sent.
+type SearchQuery struct {
+	// Name is searched for in all titles of an entry.
+	Name string
+	// Genres are genre IDs, see Media.GenreIDs, which have to all be present.
+	Genres []string
+	Type   MediaType
+	Year   uint
+	Season Season
+	// Language is either "de" or "en" and limits the results to entries
+	// with subtitles or dubs in the language.
+	Language string
+	// Page is the page of the results, starting at 1.
+	Page int
+}
+
+// Validate checks whether the query only uses values supported by the
+// search.
+func (query *SearchQuery) Validate() error {
+	if _, known := searchTypes[query.Type]; query.Type != MediaTypeUnknown && !known {
+		return fmt.Errorf("type '%s' can't be searched for", query.Type)
+	}
+	switch query.Season {
+	case "", Q1, Q2, Q3, Q4:
+	default:
+		return fmt.Errorf("unknown season '%s'", query.Season)
+	}
+	switch query.Language {
+	case "", "de", "en":
+	default:
+		return fmt.Errorf("unknown language '%s'", query.Language)
+	}
+	return nil
+}
+
+// values returns the query parameters of the search page.
+func (query *SearchQuery) values() url.Values {
+	values := url.Values{}
+	values.Set("s", "search")
+	if query.Name != "" {
+		values.Set("name", query.Name)
+	}
+	if len(query.Genres) > 0 {
+		values.Set("genre", strings.Join(query.Genres, " "))
+	}
+	if query.Type != MediaTypeUnknown {
+		values.Set("typ", searchTypes[query.Type])
+	}
+	if query.Year > 0 {
+		values.Set("year", strconv.FormatUint(uint64(query.Year), 10))
+	}
+	if query.Season != "" {
+		// Proxer uses the number of the quarter.
+		values.Set("season", strings.TrimPrefix(string(query.Season), "Q"))
+	}
+	if query.Language != "" {
+		values.Set("sprache", query.Language)
+	}
+	if query.Page > 1 {
+		values.Set("p", strconv.Itoa(query.Page))
+	}
+	return values
+}
+
+// RetrieveSearchRawData retrieves the results page of the given search. Since
+// search results change with every new entry, they are refreshed daily.
+func (cache *Cache) RetrieveSearchRawData(query SearchQuery) (io.ReadCloser, CacheInvalidator, error) {
+	encoded := query.values().Encode()
+	return cache.retrievePage(CacheEntrySearch, "search/"+encoded, "/search?"+encoded, cache.ProfileTabQueryRatelimiter)
+}
+
+// ParseSearchResults parses a results page of the search. Only the data
+// present in the result list is set, the extra data has to be loaded
+// separately, for example via WatchlistCategory.LoadExtraData.
+func ParseSearchResults(reader io.Reader) ([]*Media, error) {
+	return parseMediaListing(reader, nil)
+}
+
+// Search retrieves and parses the results of the given search.
+func (cache *Cache) Search(query SearchQuery) ([]*Media, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	reader, _, err := cache.RetrieveSearchRawData(query)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ParseSearchResults(reader)
+}
@@ -0,0 +1,48 @@
+package proxerscrape
+
+import (
+	"strings"
+	"testing"
+)
+
+const searchPage = `<html><body><table id="box-table-a">
+<tr><th>Name</th><th>Genre</th><th>Typ</th><th>Bewertung</th><th>Status</th></tr>
+<tr>
+	<td><a href="/info/53#top"><img src="/cover/53.jpg"></a><a href="/info/53#top"> Naruto </a></td>
+	<td><a href="/search?genre=Action">Action</a> <a href="/search?genre=Comedy">Komödie</a></td>
+	<td>Animeserie</td>
+	<td>8.12</td>
+	<td><img src="/images/status/abgeschlossen.png" title="Abgeschlossen"></td>
+</tr>
+<tr><td><a href="/info/9">Movie</a></td><td></td><td>Movie</td><td>7.5 / 10</td><td>Airing</td></tr>
+<tr><td><a href="/info/53">Duplicate</a></td></tr>
+</table></body></html>`
+
+func TestParseSearchResults(t *testing.T) {
+	results, err := ParseSearchResults(strings.NewReader(searchPage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	naruto := results[0]
+	if naruto.Title != "Naruto" || naruto.ProxerURL != "/info/53" || naruto.Type != MediaTypeSeries ||
+		naruto.Status != StatusFinished || naruto.Rating != 8.12 {
+		t.Errorf("Unexpected result: %+v", naruto)
+	}
+	if strings.Join(naruto.GenreIDs, ",") != "Action,Comedy" || naruto.Generes[1] != "Komödie" {
+		t.Errorf("Unexpected genres: %v %v", naruto.Generes, naruto.GenreIDs)
+	}
+	if movie := results[1]; movie.Type != MediaTypeMovie || movie.Status != StatusAiring || movie.Rating != 7.5 {
+		t.Errorf("Unexpected result: %+v", movie)
+	}
+}
+
+func TestSearchQuery_Values(t *testing.T) {
+	query := SearchQuery{Name: "Fate", Genres: []string{"Action", "Drama"}, Type: MediaTypeMovie, Year: 2012, Season: Q4, Language: "de", Page: 2}
+	expected := "genre=Action+Drama&name=Fate&p=2&s=search&season=4&sprache=de&typ=movie&year=2012"
+	if actual := query.values().Encode(); actual != expected {
+		t.Errorf("Got %s, expected %s", actual, expected)
+	}
+}
@@ -0,0 +1,116 @@
+package proxerscrape
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// SeasonEntry is an entry of the seasonal listing.
+type SeasonEntry struct {
+	*Media
+	// Weekday is the day new episodes air on. Only valid if KnownWeekday is
+	// set, since movies and finished entries don't have one.
+	Weekday      time.Weekday
+	KnownWeekday bool
+}
+
+// germanWeekdays are the weekdays as displayed by proxer.me.
+var germanWeekdays = map[string]time.Weekday{
+	"montag":     time.Monday,
+	"dienstag":   time.Tuesday,
+	"mittwoch":   time.Wednesday,
+	"donnerstag": time.Thursday,
+	"freitag":    time.Friday,
+	"samstag":    time.Saturday,
+	"sonntag":    time.Sunday,
+}
+
+// findWeekday returns the first German weekday contained in the text.
+func findWeekday(text string) (time.Weekday, bool) {
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !('a' <= r && r <= 'z')
+	}) {
+		if weekday, present := germanWeekdays[word]; present {
+			return weekday, true
+		}
+	}
+	return 0, false
+}
+
+// rowWeekday returns the first weekday found in the cells of the row.
+func rowWeekday(row *goquery.Selection) (time.Weekday, bool) {
+	var weekday time.Weekday
+	var found bool
+	row.Find("td, th").EachWithBreak(func(_ int, cell *goquery.Selection) bool {
+		weekday, found = findWeekday(cell.Text())
+		return !found
+	})
+	return weekday, found
+}
+
+// RetrieveSeasonRawData retrieves the listing of all anime released in the
+// given season.
+func (cache *Cache) RetrieveSeasonRawData(season Season, year uint) (io.ReadCloser, CacheInvalidator, error) {
+	quarter := strings.TrimPrefix(string(season), "Q")
+	cacheKey := fmt.Sprintf("season/%d/%s", year, season)
+	path := fmt.Sprintf("/season?year=%d&season=%s", year, quarter)
+	return cache.retrievePage(CacheEntrySeason, cacheKey, path, cache.ProfileTabQueryRatelimiter)
+}
+
+// ParseSeasonListing parses the seasonal listing. The weekday is either
+// taken from the row of an entry or from the last row of the same table
+// announcing a weekday, since the listing may be grouped by weekday.
+func ParseSeasonListing(reader io.Reader) ([]SeasonEntry, error) {
+	var entries []SeasonEntry
+	var groupWeekday time.Weekday
+	var inGroup bool
+	var table *html.Node
+	_, err := parseMediaListing(reader, func(row *goquery.Selection, item *Media) {
+		if rowTable := row.Closest("table").Get(0); rowTable != table {
+			table, inGroup = rowTable, false
+		}
+		weekday, found := rowWeekday(row)
+		if item == nil {
+			if found {
+				groupWeekday, inGroup = weekday, true
+			}
+			return
+		}
+		if !found && inGroup {
+			weekday, found = groupWeekday, true
+		}
+		entries = append(entries, SeasonEntry{Media: item, Weekday: weekday, KnownWeekday: found})
+	})
+	return entries, err
+}
+
+// ScrapeSeason retrieves and parses the listing of the given season.
+func (cache *Cache) ScrapeSeason(season Season, year uint) ([]SeasonEntry, error) {
+	reader, _, err := cache.RetrieveSeasonRawData(season, year)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ParseSeasonListing(reader)
+}
+
+// NotOnWatchlist returns the entries that aren't in any category of the
+// watchlist.
+func NotOnWatchlist(entries []SeasonEntry, watchlist *Watchlist) []SeasonEntry {
+	known := make(map[string]bool)
+	for _, item := range watchlist.All() {
+		known[item.ProxerID()] = true
+	}
+	var missing []SeasonEntry
+	for _, entry := range entries {
+		if !known[entry.ProxerID()] {
+			missing = append(missing, entry)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,49 @@
+package proxerscrape
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const seasonPage = `<html><body><table>
+<tr><th colspan="3">Montag</th></tr>
+<tr><td><a href="/info/1">Monday Show</a></td><td>Animeserie</td><td><img title="Airing"></td></tr>
+<tr><td><a href="/info/2">Moved</a></td><td>Animeserie</td><td>Freitag, 18:00</td></tr>
+<tr><th colspan="3">Sonntag</th></tr>
+<tr><td><a href="/info/3">Sunday Show</a></td><td>Animeserie</td><td><img title="Nicht erschienen (Pre-Airing)"></td></tr>
+</table>
+<table><tr><td><a href="/info/4">Movie</a></td><td>Movie</td></tr></table>
+</body></html>`
+
+func TestParseSeasonListing(t *testing.T) {
+	entries, err := ParseSeasonListing(strings.NewReader(seasonPage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("Expected 4 entries, got %d", len(entries))
+	}
+	expected := []struct {
+		weekday time.Weekday
+		known   bool
+		status  Status
+	}{
+		{time.Monday, true, StatusAiring},
+		{time.Friday, true, StatusUnknown},
+		{time.Sunday, true, StatusPreAiring},
+		// Grouping ends with the table.
+		{0, false, StatusUnknown},
+	}
+	for index, entry := range entries {
+		if entry.Weekday != expected[index].weekday || entry.KnownWeekday != expected[index].known || entry.Status != expected[index].status {
+			t.Errorf("Unexpected entry %d: %+v", index, entry)
+		}
+	}
+
+	watchlist := &Watchlist{}
+	watchlist.ToWatch.Data = []*Media{{ProxerURL: "/info/1"}}
+	if missing := NotOnWatchlist(entries, watchlist); len(missing) != 3 || missing[0].Title != "Moved" {
+		t.Errorf("Unexpected missing entries: %+v", missing)
+	}
+}
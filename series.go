@@ -0,0 +1,75 @@
+package proxerscrape
+
+import "errors"
+
+// NextInSeries finds entries of the franchises of all watched entries, that
+// were released after the watched entry and aren't on any list yet, such as
+// a new season. Only entries of the same kind, anime or manga, are returned.
+// This retrieves the relations of every watched entry, which are cached
+// permanently for finished entries. Entries whose relations couldn't be
+// retrieved are skipped and returned as ExtraDataError, alongside the entries
+// found for the others. Once ratelimited, all remaining entries are skipped.
+func (watchlist *Watchlist) NextInSeries(retrieveRelations MediaRawDataRetriever) ([]*Media, error) {
+	known := make(map[uint]bool)
+	for _, item := range watchlist.All() {
+		known[item.mediaID()] = true
+	}
+
+	var next []*Media
+	var itemErrors ExtraDataError
+	for index, watched := range watchlist.Watched.Data {
+		graph, err := BuildRelationGraph(retrieveRelations, watched, 1)
+		if errors.Is(err, ErrRateLimited) {
+			for _, skipped := range watchlist.Watched.Data[index:] {
+				itemErrors = append(itemErrors, &ItemError{Item: skipped, Err: err})
+			}
+			break
+		}
+		if err != nil {
+			itemErrors = append(itemErrors, &ItemError{Item: watched, Err: err})
+			continue
+		}
+		for _, item := range graph.WatchOrder {
+			if known[item.mediaID()] || !releasedBefore(graph.Root.ReleasePeriod, item.ReleasePeriod) {
+				continue
+			}
+			if item.Type != MediaTypeUnknown && watched.Type != MediaTypeUnknown && item.Type.IsAnime() != watched.Type.IsAnime() {
+				continue
+			}
+			known[item.mediaID()] = true
+			next = append(next, item)
+		}
+	}
+	if len(itemErrors) > 0 {
+		return next, itemErrors
+	}
+	return next, nil
+}
+
+// Prerequisites returns the series of the entry's franchise that were
+// released before it, but haven't been watched yet. Movies, OVAs and
+// specials aren't considered prerequisites, as they are usually optional.
+func (watchlist *Watchlist) Prerequisites(retrieveRelations MediaRawDataRetriever, item *Media) ([]*Media, error) {
+	graph, err := BuildRelationGraph(retrieveRelations, item, 1)
+	if err != nil {
+		return nil, err
+	}
+	// Without a release date, nothing can be said about the order.
+	if graph.Root.ReleasePeriod.FromYear == 0 {
+		return nil, nil
+	}
+
+	watched := make(map[uint]bool)
+	for _, watchedItem := range watchlist.Watched.Data {
+		watched[watchedItem.mediaID()] = true
+	}
+	var prerequisites []*Media
+	for _, related := range graph.WatchOrder {
+		if related.Type == MediaTypeSeries && !watched[related.mediaID()] &&
+			related.ReleasePeriod.FromYear != 0 &&
+			releasedBefore(related.ReleasePeriod, graph.Root.ReleasePeriod) {
+			prerequisites = append(prerequisites, related)
+		}
+	}
+	return prerequisites, nil
+}
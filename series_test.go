@@ -0,0 +1,82 @@
+package proxerscrape
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWatchlist_NextInSeries(t *testing.T) {
+	retriever := func(*Media) (io.ReadCloser, CacheInvalidator, error) {
+		return io.NopCloser(strings.NewReader(relationPage)), func() error { return nil }, nil
+	}
+
+	watchlist := &Watchlist{
+		Watched: WatchlistCategory{Data: []*Media{
+			{ProxerURL: "/info/1", Title: "First", Type: MediaTypeSeries, Category: CategoryWatched},
+		}},
+	}
+	next, err := watchlist.NextInSeries(retriever)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(next) != 2 || next[0].Title != "Movie" || next[1].Title != "Second" {
+		t.Errorf("Expected movie and second season, got %+v", next)
+	}
+
+	watchlist.ToWatch.Data = []*Media{{ProxerURL: "/info/2", Title: "Second", Category: CategoryToWatch}}
+	if next, _ := watchlist.NextInSeries(retriever); len(next) != 1 || next[0].Title != "Movie" {
+		t.Errorf("Expected entries on the to-watch list to be skipped, got %+v", next)
+	}
+
+	prerequisites, err := watchlist.Prerequisites(retriever, watchlist.ToWatch.Data[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prerequisites) != 0 {
+		t.Errorf("Expected no prerequisites, since the first season has been watched, got %+v", prerequisites)
+	}
+	watchlist.Watched.Data = nil
+	prerequisites, _ = watchlist.Prerequisites(retriever, watchlist.ToWatch.Data[0])
+	if len(prerequisites) != 1 || prerequisites[0].Title != "First" {
+		t.Errorf("Expected the first season as prerequisite, got %+v", prerequisites)
+	}
+}
+
+func TestWatchlist_NextInSeries_Errors(t *testing.T) {
+	var requested []string
+	retriever := func(item *Media) (io.ReadCloser, CacheInvalidator, error) {
+		requested = append(requested, item.ProxerURL)
+		switch item.ProxerURL {
+		case "/info/5":
+			return nil, nil, errors.New("broken")
+		case "/info/6":
+			return nil, nil, ErrRateLimited
+		}
+		return io.NopCloser(strings.NewReader(relationPage)), func() error { return nil }, nil
+	}
+
+	watchlist := &Watchlist{
+		Watched: WatchlistCategory{Data: []*Media{
+			{ProxerURL: "/info/5", Title: "Broken", Type: MediaTypeSeries, Category: CategoryWatched},
+			{ProxerURL: "/info/1", Title: "First", Type: MediaTypeSeries, Category: CategoryWatched},
+			{ProxerURL: "/info/6", Title: "Limited", Type: MediaTypeSeries, Category: CategoryWatched},
+			{ProxerURL: "/info/7", Title: "Skipped", Type: MediaTypeSeries, Category: CategoryWatched},
+		}},
+	}
+	next, err := watchlist.NextInSeries(retriever)
+	if len(next) != 2 {
+		t.Errorf("Expected the sequels of the first season despite the errors, got %+v", next)
+	}
+	var extraDataError ExtraDataError
+	if !errors.As(err, &extraDataError) || len(extraDataError) != 3 {
+		t.Fatalf("Expected three item errors, got %v", err)
+	}
+	if !errors.Is(extraDataError[2].Err, ErrRateLimited) || extraDataError[2].Item.Title != "Skipped" {
+		t.Errorf("Expected the remaining entries to be skipped once ratelimited, got %v", extraDataError[2])
+	}
+	if len(requested) != 3 {
+		t.Errorf("Expected no requests after the ratelimit, got %v", requested)
+	}
+}
@@ -0,0 +1,92 @@
+package proxerscrape
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Session performs requests that require being logged in, such as reading
+// the user control panel. Unlike the Cache, nothing is cached, since the
+// data is expected to change with every request.
+type Session struct {
+	// Get performs an authenticated GET request for the given path, such as
+	// `/ucp?s=reminder`.
+	Get func(path string) (*http.Response, error)
+	// Post performs an authenticated POST request of the given form for the
+	// given path.
+	Post func(path string, form url.Values) (*http.Response, error)
+	// Limiter is waited on before every request. May be nil.
+	Limiter *Limiter
+}
+
+// Session returns a session sharing the login cookie and HTTP client of the
+// cache. Requests count against the profile tab ratelimiter. In offline
+// mode, all requests fail with ErrNotCached.
+func (cache *Cache) Session() *Session {
+	client := cache.client
+	if client == nil {
+		client = &Client{}
+	}
+	return &Session{
+		Get: func(path string) (*http.Response, error) {
+			if cache.Offline {
+				return nil, ErrNotCached
+			}
+			return client.Query(client.URL(path), nil)
+		},
+		Post: func(path string, form url.Values) (*http.Response, error) {
+			if cache.Offline {
+				return nil, ErrNotCached
+			}
+			return client.PostForm(client.URL(path), form)
+		},
+		Limiter: cache.ProfileTabQueryRatelimiter,
+	}
+}
+
+func (session *Session) wait() {
+	if session.Limiter != nil {
+		session.Limiter.Wait()
+	}
+}
+
+// getDocument retrieves and parses the given page. ErrLoginRequired is
+// returned if the session isn't logged in.
+func (session *Session) getDocument(path string) (*goquery.Document, error) {
+	session.wait()
+	response, err := session.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, &StatusError{StatusCode: response.StatusCode, URL: path}
+	}
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if isCaptchaPage(data) {
+		return nil, ErrRateLimited
+	}
+	document, err := newDocument(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if isLoginPage(document) {
+		return nil, ErrLoginRequired
+	}
+	return document, nil
+}
+
+// isLoginPage checks whether proxer.me asks to log in instead of showing
+// the requested page.
+func isLoginPage(document *goquery.Document) bool {
+	return strings.HasPrefix(strings.TrimSpace(document.Find("h3").First().Text()), "Bitte logge dich ein")
+}
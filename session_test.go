@@ -0,0 +1,58 @@
+package proxerscrape
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// newTestSession creates a session responding with the given pages by path.
+func newTestSession(pages map[string]string) *Session {
+	return &Session{
+		Get: func(path string) (*http.Response, error) {
+			page, present := pages[path]
+			if !present {
+				return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(""))}, nil
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(page))}, nil
+		},
+	}
+}
+
+func TestSession_LoadBookmarks(t *testing.T) {
+	session := newTestSession(map[string]string{"/ucp?s=reminder": `<table>
+<tr><th>Titel</th><th>Episode</th></tr>
+<tr><td><a href="/info/53">Naruto</a></td><td><a href="/watch/53/12/gerdub">Episode 12</a></td></tr>
+<tr><td><a href="/info/7">Berserk</a></td><td><a href="/read/7/300/de">Kapitel 300</a></td></tr>
+</table>`})
+	bookmarks, err := session.LoadBookmarks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bookmarks) != 2 {
+		t.Fatalf("Expected 2 bookmarks, got %d", len(bookmarks))
+	}
+	if first := bookmarks[0]; first.Title != "Naruto" || first.Episode != 12 || first.Language != LanguageGermanDub || first.Manga {
+		t.Errorf("Unexpected bookmark: %+v", first)
+	}
+	if !bookmarks[1].Manga {
+		t.Errorf("Chapter not detected as manga: %+v", bookmarks[1])
+	}
+
+	userData := &UserData{}
+	if applied := userData.ApplyBookmarks(bookmarks); applied != 1 {
+		t.Errorf("Expected only the anime bookmark to be applied, got %d", applied)
+	}
+	if progress := userData.EpisodesWatched(&Media{ProxerURL: "/info/53"}, LanguageGermanDub); progress != 12 {
+		t.Errorf("Progress = %d, instead of 12", progress)
+	}
+}
+
+func TestSession_LoginRequired(t *testing.T) {
+	session := newTestSession(map[string]string{"/ucp?s=reminder": `<h3>Bitte logge dich ein, um diese Seite zu sehen.</h3>`})
+	if _, err := session.LoadBookmarks(); !errors.Is(err, ErrLoginRequired) {
+		t.Errorf("Expected ErrLoginRequired, got %v", err)
+	}
+}
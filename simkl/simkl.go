@@ -0,0 +1,254 @@
+// Package simkl pushes the status and progress of proxer.me watchlists to
+// the anime list of a Simkl user.
+package simkl
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Bios-Marcel/proxerscrape"
+	"github.com/Bios-Marcel/proxerscrape/mapping"
+)
+
+// BaseURL is the location of the Simkl API.
+const BaseURL = "https://api.simkl.com"
+
+var (
+	// ErrUnauthorized is returned if the token is invalid or has been
+	// revoked.
+	ErrUnauthorized = errors.New("simkl token is invalid or revoked")
+	// ErrPINExpired is returned if the user didn't enter the PIN in time.
+	ErrPINExpired = errors.New("simkl pin expired")
+)
+
+// statuses translates proxer categories into the list names of Simkl.
+var statuses = map[proxerscrape.ListCategory]string{
+	proxerscrape.CategoryWatched:           "completed",
+	proxerscrape.CategoryCurrentlyWatching: "watching",
+	proxerscrape.CategoryToWatch:           "plantowatch",
+	proxerscrape.CategoryStoppedWatching:   "dropped",
+}
+
+// Client talks to the Simkl API on behalf of a user. Applications have to be
+// registered at https://simkl.com/settings/developer/ for obtaining a client
+// ID.
+type Client struct {
+	ClientID string
+	// Token is the OAuth access token of the user, see RequestPIN.
+	Token      string
+	HTTPClient *http.Client
+	// BaseURL defaults to BaseURL.
+	BaseURL string
+	// Limiter keeps us from hammering Simkl, which doesn't document its
+	// ratelimit. If nil, requests aren't limited.
+	Limiter *proxerscrape.Limiter
+}
+
+// NewClient creates a client with a conservative ratelimit.
+func NewClient(clientID string) *Client {
+	return &Client{
+		ClientID: clientID,
+		Limiter:  proxerscrape.NewLimiter(60, time.Minute),
+	}
+}
+
+// request performs an API request, encoding body and decoding the response
+// into result. Both may be nil.
+func (client *Client) request(method, path string, body, result any) error {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	baseURL := client.BaseURL
+	if baseURL == "" {
+		baseURL = BaseURL
+	}
+	request, err := http.NewRequest(method, baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("simkl-api-key", client.ClientID)
+	if client.Token != "" {
+		request.Header.Set("Authorization", "Bearer "+client.Token)
+	}
+
+	if client.Limiter != nil {
+		client.Limiter.Wait()
+	}
+	httpClient := client.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusUnauthorized {
+		return ErrUnauthorized
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("simkl request %s %s failed with status %d", method, path, response.StatusCode)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(response.Body).Decode(result)
+}
+
+// PIN is the code the user has to enter on the verification page in order
+// to authorize the client.
+type PIN struct {
+	UserCode        string `json:"user_code"`
+	VerificationURL string `json:"verification_url"`
+	// ExpiresIn and Interval are in seconds.
+	ExpiresIn int `json:"expires_in"`
+	Interval  int `json:"interval"`
+}
+
+// RequestPIN starts the OAuth PIN flow. The user has to enter the returned
+// UserCode at the VerificationURL, while PollToken waits for that.
+func (client *Client) RequestPIN() (PIN, error) {
+	var pin PIN
+	err := client.request(http.MethodGet, "/oauth/pin?client_id="+url.QueryEscape(client.ClientID), nil, &pin)
+	return pin, err
+}
+
+// PollToken waits until the user entered the PIN and sets the obtained
+// access token.
+func (client *Client) PollToken(pin PIN) error {
+	interval := time.Duration(pin.Interval) * time.Second
+	deadline := time.Now().Add(time.Duration(pin.ExpiresIn) * time.Second)
+	path := "/oauth/pin/" + url.PathEscape(pin.UserCode) + "?client_id=" + url.QueryEscape(client.ClientID)
+	for time.Now().Before(deadline) {
+		var result struct {
+			Result      string `json:"result"`
+			AccessToken string `json:"access_token"`
+		}
+		if err := client.request(http.MethodGet, path, nil, &result); err != nil {
+			return err
+		}
+		// Until the PIN has been entered, the result is "KO".
+		if result.Result == "OK" && result.AccessToken != "" {
+			client.Token = result.AccessToken
+			return nil
+		}
+		time.Sleep(interval)
+	}
+	return ErrPINExpired
+}
+
+type ids struct {
+	Simkl int `json:"simkl"`
+}
+
+type episode struct {
+	Number int `json:"number"`
+}
+
+type show struct {
+	To       string    `json:"to,omitempty"`
+	IDs      ids       `json:"ids"`
+	Episodes []episode `json:"episodes,omitempty"`
+}
+
+type batch struct {
+	Shows []show `json:"shows"`
+}
+
+// listEntry is the state of an anime in the list of the user.
+type listEntry struct {
+	status  string
+	watched int
+}
+
+func (client *Client) list() (map[int]listEntry, error) {
+	var result struct {
+		Anime []struct {
+			Status               string `json:"status"`
+			WatchedEpisodesCount int    `json:"watched_episodes_count"`
+			Show                 struct {
+				IDs struct {
+					Simkl int `json:"simkl"`
+				} `json:"ids"`
+			} `json:"show"`
+		} `json:"anime"`
+	}
+	if err := client.request(http.MethodGet, "/sync/all-items/anime", nil, &result); err != nil {
+		return nil, err
+	}
+	entries := make(map[int]listEntry, len(result.Anime))
+	for _, anime := range result.Anime {
+		entries[anime.Show.IDs.Simkl] = listEntry{status: anime.Status, watched: anime.WatchedEpisodesCount}
+	}
+	return entries, nil
+}
+
+// Sync moves all mapped anime of the watchlist into the matching Simkl list
+// and marks their watched episodes. Entries already in the right list with
+// at least as many watched episodes are left alone. Completed entries don't
+// need their episodes marked, as Simkl does that by itself. Entries without
+// a mapping are returned, see 'map resolve --service simkl', just like those
+// that aren't exportable, see proxerscrape.Media.Exportable.
+func (client *Client) Sync(watchlist *proxerscrape.Watchlist, store *mapping.Store) ([]*proxerscrape.Media, error) {
+	existing, err := client.list()
+	if err != nil {
+		return nil, err
+	}
+
+	var skipped []*proxerscrape.Media
+	var lists, history batch
+	for _, item := range watchlist.All() {
+		if !item.Type.IsAnime() {
+			continue
+		}
+		if !item.Exportable() {
+			skipped = append(skipped, item)
+			continue
+		}
+		simklMapping, present := store.Get(item.ProxerID(), mapping.ServiceSimkl)
+		if !present {
+			skipped = append(skipped, item)
+			continue
+		}
+		id, err := strconv.Atoi(simklMapping.ExternalID)
+		if err != nil {
+			return skipped, fmt.Errorf("invalid simkl id '%s' for '%s': %w", simklMapping.ExternalID, item.Title, err)
+		}
+
+		status := statuses[item.Category]
+		entry := existing[id]
+		if status != "" && entry.status != status {
+			lists.Shows = append(lists.Shows, show{To: status, IDs: ids{Simkl: id}})
+		}
+		if item.Category != proxerscrape.CategoryWatched && int(item.EpisodesWatched) > entry.watched {
+			watched := show{IDs: ids{Simkl: id}}
+			for number := entry.watched + 1; number <= int(item.EpisodesWatched); number++ {
+				watched.Episodes = append(watched.Episodes, episode{Number: number})
+			}
+			history.Shows = append(history.Shows, watched)
+		}
+	}
+
+	if len(lists.Shows) > 0 {
+		if err := client.request(http.MethodPost, "/sync/add-to-list", lists, nil); err != nil {
+			return skipped, err
+		}
+	}
+	if len(history.Shows) > 0 {
+		if err := client.request(http.MethodPost, "/sync/history", history, nil); err != nil {
+			return skipped, err
+		}
+	}
+	return skipped, nil
+}
@@ -0,0 +1,96 @@
+package simkl
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Bios-Marcel/proxerscrape"
+	"github.com/Bios-Marcel/proxerscrape/mapping"
+)
+
+func TestPollToken(t *testing.T) {
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != "/oauth/pin/ABCDE" || request.URL.Query().Get("client_id") != "id" {
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		polls++
+		if polls == 1 {
+			writer.Write([]byte(`{"result":"KO","message":"Authorization pending"}`))
+			return
+		}
+		writer.Write([]byte(`{"result":"OK","access_token":"token"}`))
+	}))
+	defer server.Close()
+
+	client := &Client{ClientID: "id", BaseURL: server.URL}
+	if err := client.PollToken(PIN{UserCode: "ABCDE", ExpiresIn: 10}); err != nil {
+		t.Fatal(err)
+	}
+	if client.Token != "token" || polls != 2 {
+		t.Errorf("Unexpected token '%s' after %d polls", client.Token, polls)
+	}
+}
+
+func TestSync(t *testing.T) {
+	var lists, history batch
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sync/all-items/anime", func(writer http.ResponseWriter, request *http.Request) {
+		if request.Header.Get("Authorization") != "Bearer token" || request.Header.Get("simkl-api-key") != "id" {
+			writer.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writer.Write([]byte(`{"anime":[
+			{"status":"watching","watched_episodes_count":3,"show":{"ids":{"simkl":2}}},
+			{"status":"completed","watched_episodes_count":12,"show":{"ids":{"simkl":3}}}
+		]}`))
+	})
+	mux.HandleFunc("/sync/add-to-list", func(writer http.ResponseWriter, request *http.Request) {
+		json.NewDecoder(request.Body).Decode(&lists)
+		writer.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("/sync/history", func(writer http.ResponseWriter, request *http.Request) {
+		json.NewDecoder(request.Body).Decode(&history)
+		writer.WriteHeader(http.StatusCreated)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	watchlist := proxerscrape.Watchlist{}
+	watchlist.Watched.Data = []*proxerscrape.Media{
+		{ProxerURL: "/info/1", Title: "New", Type: proxerscrape.MediaTypeSeries, Category: proxerscrape.CategoryWatched, EpisodesWatched: 12},
+		{ProxerURL: "/info/3", Title: "Unchanged", Type: proxerscrape.MediaTypeSeries, Category: proxerscrape.CategoryWatched, EpisodesWatched: 12},
+		{ProxerURL: "/info/4", Title: "Unmapped", Type: proxerscrape.MediaTypeSeries},
+	}
+	watchlist.CurrentlyWatching.Data = []*proxerscrape.Media{
+		{ProxerURL: "/info/2", Title: "Ahead", Type: proxerscrape.MediaTypeSeries, Category: proxerscrape.CategoryCurrentlyWatching, EpisodesWatched: 5},
+	}
+	store := &mapping.Store{}
+	store.Set("1", mapping.ServiceSimkl, mapping.Mapping{ExternalID: "1"})
+	store.Set("2", mapping.ServiceSimkl, mapping.Mapping{ExternalID: "2"})
+	store.Set("3", mapping.ServiceSimkl, mapping.Mapping{ExternalID: "3"})
+
+	client := &Client{ClientID: "id", BaseURL: server.URL}
+	if _, err := client.Sync(&watchlist, store); err != ErrUnauthorized {
+		t.Errorf("Expected ErrUnauthorized, got %v", err)
+	}
+
+	client.Token = "token"
+	skipped, err := client.Sync(&watchlist, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skipped) != 1 || skipped[0].Title != "Unmapped" {
+		t.Errorf("Unexpected skipped entries: %v", skipped)
+	}
+	if len(lists.Shows) != 1 || lists.Shows[0].IDs.Simkl != 1 || lists.Shows[0].To != "completed" {
+		t.Errorf("Unexpected list changes: %+v", lists.Shows)
+	}
+	if len(history.Shows) != 1 || history.Shows[0].IDs.Simkl != 2 ||
+		len(history.Shows[0].Episodes) != 2 || history.Shows[0].Episodes[0].Number != 4 {
+		t.Errorf("Unexpected history changes: %+v", history.Shows)
+	}
+}
@@ -0,0 +1,193 @@
+package proxerscrape
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Bios-Marcel/proxerscrape/internal/atomicfile"
+)
+
+// ErrCacheMiss is returned by CacheStore.Get if no entry exists for a key.
+var ErrCacheMiss = errors.New("no cache entry present")
+
+// CacheStore is the storage backing a Cache. Keys are slash separated paths,
+// such as `profile/252835/anime`, allowing implementations to group entries.
+type CacheStore interface {
+	// Get returns the data stored for the given key or ErrCacheMiss if there
+	// is no such entry.
+	Get(key string) (io.ReadCloser, CacheMetadata, error)
+	// Put stores the data for the given key, replacing any existing entry.
+	Put(key string, data []byte, metadata CacheMetadata) error
+	// Delete removes the entry for the given key. Deleting an entry that
+	// doesn't exist isn't an error.
+	Delete(key string) error
+	// Stats returns information about the entries currently stored.
+	Stats() (CacheStats, error)
+	// Walk calls the given function for each entry. Entries mustn't be
+	// modified from within the callback.
+	Walk(callback func(key string, metadata CacheMetadata) error) error
+}
+
+// CacheMetadata is additional information stored alongside a cache entry.
+type CacheMetadata struct {
+	// URL is the location the entry has been fetched from.
+	URL string
+	// FetchedAt is the point in time at which the entry was fetched.
+	FetchedAt time.Time
+	// ETag is the value of the `ETag` header of the response, if any.
+	ETag string
+	// LastModified is the value of the `Last-Modified` header of the
+	// response, if any.
+	LastModified string
+}
+
+// CacheStats describes the current content of a CacheStore.
+type CacheStats struct {
+	Entries int
+	// Size is the total size of all entries in bytes.
+	Size int64
+	// Oldest and Newest are the fetch times of the oldest and newest entry.
+	Oldest, Newest time.Time
+
+	// Hits and Misses count how often an entry was served from the store and
+	// how often it had to be fetched. These are only set by Cache.Stats and
+	// only count requests of the current session.
+	Hits, Misses uint64
+}
+
+func (stats *CacheStats) addEntry(size int64, fetchedAt time.Time) {
+	stats.Entries++
+	stats.Size += size
+	if fetchedAt.IsZero() {
+		return
+	}
+	if stats.Oldest.IsZero() || fetchedAt.Before(stats.Oldest) {
+		stats.Oldest = fetchedAt
+	}
+	if fetchedAt.After(stats.Newest) {
+		stats.Newest = fetchedAt
+	}
+}
+
+// FileStore is a CacheStore that keeps each entry as an HTML file inside of a
+// base directory. Metadata is kept in a JSON file next to the HTML file. Directories are only created on first write, so reading
+// from a read-only location is possible.
+type FileStore struct {
+	baseDir string
+}
+
+// NewFileStore creates a FileStore writing to the given directory.
+func NewFileStore(baseDir string) *FileStore {
+	return &FileStore{baseDir: baseDir}
+}
+
+func (store *FileStore) path(key string) string {
+	return filepath.Join(store.baseDir, filepath.FromSlash(key)+".html")
+}
+
+func (store *FileStore) metadataPath(key string) string {
+	return filepath.Join(store.baseDir, filepath.FromSlash(key)+".meta.json")
+}
+
+func (store *FileStore) Get(key string) (io.ReadCloser, CacheMetadata, error) {
+	var metadata CacheMetadata
+	path := store.path(key)
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, metadata, ErrCacheMiss
+	}
+	if err != nil {
+		return nil, metadata, err
+	}
+
+	if info, errStat := file.Stat(); errStat == nil {
+		metadata = store.readMetadata(key, info)
+	}
+	return file, metadata, nil
+}
+
+func (store *FileStore) readMetadata(key string, info fs.FileInfo) CacheMetadata {
+	var metadata CacheMetadata
+	metadataBytes, err := os.ReadFile(store.metadataPath(key))
+	if err == nil {
+		err = json.Unmarshal(metadataBytes, &metadata)
+	}
+	// Entries written by older versions don't have any metadata, so we
+	// fall back to the modification time of the file.
+	if err != nil {
+		metadata = CacheMetadata{FetchedAt: info.ModTime()}
+	}
+	return metadata
+}
+
+func (store *FileStore) Put(key string, data []byte, metadata CacheMetadata) error {
+	metadataBytes, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	// Data is written first, so that an interrupted write never results in
+	// outdated data with fresh metadata. The other way around, the entry
+	// is merely considered stale too early.
+	if err := atomicfile.WriteFile(store.path(key), data, 0o644); err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(store.metadataPath(key), metadataBytes, 0o644)
+}
+
+func (store *FileStore) Delete(key string) error {
+	for _, path := range []string{store.path(key), store.metadataPath(key)} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (store *FileStore) Walk(callback func(key string, metadata CacheMetadata) error) error {
+	return store.walkFiles(func(key string, info fs.FileInfo) error {
+		return callback(key, store.readMetadata(key, info))
+	})
+}
+
+// walkFiles calls the callback for each HTML file in the base directory.
+func (store *FileStore) walkFiles(callback func(key string, info fs.FileInfo) error) error {
+	return filepath.WalkDir(store.baseDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// A cache that hasn't been written to yet is simply empty.
+			if os.IsNotExist(err) && path == store.baseDir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if entry.IsDir() || filepath.Ext(path) != ".html" {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(store.baseDir, path)
+		if err != nil {
+			return err
+		}
+		return callback(filepath.ToSlash(strings.TrimSuffix(relativePath, ".html")), info)
+	})
+}
+
+func (store *FileStore) Stats() (CacheStats, error) {
+	var stats CacheStats
+	err := store.walkFiles(func(key string, info fs.FileInfo) error {
+		// The modification time is the time the entry was written, which
+		// saves us from reading all metadata files.
+		stats.addEntry(info.Size(), info.ModTime())
+		return nil
+	})
+	return stats, err
+}
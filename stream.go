@@ -0,0 +1,259 @@
+package proxerscrape
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+)
+
+// StreamProfileMediaTab parses a profile tab just like ParseProfileMediaTab,
+// but instead of building the whole document in memory, the HTML is
+// tokenized and handle is called for each entry as soon as its row has been
+// read. This keeps the memory usage low for profiles with thousands of
+// entries. Parsing stops at the first error returned by handle. Rows that
+// can't be parsed are skipped and returned as warnings.
+func StreamProfileMediaTab(reader io.Reader, handle func(item *Media) error) ([]ParseWarning, error) {
+	reader, err := charset.NewReader(reader, "")
+	if err != nil {
+		return nil, err
+	}
+	stream := profileTabStream{tokenizer: html.NewTokenizer(reader), handle: handle, categoryIndex: -1}
+	if err := stream.run(); err != nil {
+		return stream.warnings, err
+	}
+	if !stream.foundTable {
+		return stream.warnings, &ParseError{Selector: profileTabSelector, Err: ErrLayoutChanged}
+	}
+	return stream.warnings, nil
+}
+
+// StreamResult is the outcome of StreamProfileMediaTabChannel.
+type StreamResult struct {
+	Warnings []ParseWarning
+	Err      error
+}
+
+// StreamProfileMediaTabChannel is StreamProfileMediaTab, but sends the
+// entries over the returned channel. The channel is closed once parsing is
+// done, after which the result channel receives the result. The entries
+// have to be drained, otherwise parsing never finishes.
+func StreamProfileMediaTabChannel(reader io.Reader) (<-chan *Media, <-chan StreamResult) {
+	items := make(chan *Media)
+	result := make(chan StreamResult, 1)
+	go func() {
+		warnings, err := StreamProfileMediaTab(reader, func(item *Media) error {
+			items <- item
+			return nil
+		})
+		close(items)
+		result <- StreamResult{Warnings: warnings, Err: err}
+	}()
+	return items, result
+}
+
+// profileTabStream is the state of StreamProfileMediaTab. The layout of the
+// rows is the same as parsed by parseProfileTabMediaTable.
+type profileTabStream struct {
+	tokenizer *html.Tokenizer
+	handle    func(item *Media) error
+
+	// categoryIndex is the index of the category whose table is being read,
+	// or -1 outside of a category table.
+	categoryIndex int
+	// pendingIndex is the category announced by the last anchor, whose table
+	// hasn't started yet.
+	pendingIndex int
+	tableDepth   int
+	row          int
+	cell         int
+	inLink       bool
+	inSpan       bool
+	// typeTexts are the text nodes of the type cell, the first one being the
+	// base type and the second one the concrete type, if any.
+	typeTexts []string
+	progress  string
+	item      *Media
+	warnings  []ParseWarning
+	// foundTable tells whether any category table has been found.
+	foundTable bool
+}
+
+func (stream *profileTabStream) run() error {
+	stream.pendingIndex = -1
+	for {
+		switch stream.tokenizer.Next() {
+		case html.ErrorToken:
+			if err := stream.tokenizer.Err(); err != io.EOF {
+				return err
+			}
+			return nil
+		case html.StartTagToken, html.SelfClosingTagToken:
+			stream.startTag(stream.tokenizer.Token())
+		case html.EndTagToken:
+			if err := stream.endTag(stream.tokenizer.Token()); err != nil {
+				return err
+			}
+		case html.TextToken:
+			stream.text(string(stream.tokenizer.Text()))
+		}
+	}
+}
+
+func (stream *profileTabStream) startTag(token html.Token) {
+	switch token.DataAtom {
+	case atom.A:
+		if name := getTokenAttribute(token, "name"); strings.HasPrefix(name, "state") {
+			// The anchors are named after the category index, starting at state0.
+			if index, err := strconv.Atoi(strings.TrimPrefix(name, "state")); err == nil && index < len(ListCategories) {
+				stream.pendingIndex = index
+			}
+		} else if stream.item != nil && stream.cell == 1 && stream.item.ProxerURL == "" {
+			stream.item.ProxerURL = getTokenAttribute(token, "href")
+			stream.inLink = true
+		}
+	case atom.Table:
+		if stream.categoryIndex >= 0 {
+			stream.tableDepth++
+		} else if stream.pendingIndex >= 0 {
+			stream.categoryIndex, stream.pendingIndex = stream.pendingIndex, -1
+			stream.tableDepth, stream.row = 1, 0
+			stream.foundTable = true
+		}
+	case atom.Tr:
+		if stream.categoryIndex >= 0 && stream.tableDepth == 1 {
+			// The first two rows are the header and the filter.
+			if stream.row >= 2 {
+				stream.item = &Media{Category: ListCategories[stream.categoryIndex]}
+				stream.cell, stream.typeTexts, stream.progress = -1, nil, ""
+			}
+			stream.row++
+		}
+	case atom.Td:
+		if stream.item != nil {
+			stream.cell++
+		}
+	case atom.Br:
+		if stream.item != nil && stream.cell == 2 && len(stream.typeTexts) == 1 {
+			stream.typeTexts = append(stream.typeTexts, "")
+		}
+	case atom.Img:
+		if stream.item == nil {
+			return
+		}
+		switch stream.cell {
+		case 0:
+			if stream.item.RawStatus == "" {
+				stream.item.RawStatus = getTokenAttribute(token, "title")
+			}
+		case 3:
+			// Own rating, displayed as stars, where grey stars are unset.
+			if src := getTokenAttribute(token, "src"); strings.Contains(src, "stern") && !strings.Contains(src, "grau") {
+				stream.item.UserRating++
+			}
+		}
+	case atom.Span:
+		if stream.item != nil && stream.cell == 4 {
+			stream.inSpan = true
+		}
+	}
+}
+
+func (stream *profileTabStream) text(text string) {
+	if stream.item == nil {
+		return
+	}
+	switch {
+	case stream.inLink:
+		stream.item.Title += text
+	case stream.cell == 2:
+		if len(stream.typeTexts) == 0 {
+			stream.typeTexts = append(stream.typeTexts, text)
+		} else if len(stream.typeTexts) == 2 {
+			stream.typeTexts[1] += text
+		}
+	case stream.inSpan:
+		stream.progress += text
+	}
+}
+
+func (stream *profileTabStream) endTag(token html.Token) error {
+	switch token.DataAtom {
+	case atom.A:
+		stream.inLink = false
+	case atom.Span:
+		stream.inSpan = false
+	case atom.Table:
+		if stream.categoryIndex >= 0 {
+			stream.tableDepth--
+			if stream.tableDepth == 0 {
+				stream.categoryIndex = -1
+			}
+		}
+	case atom.Tr:
+		if stream.item == nil || stream.tableDepth != 1 {
+			return nil
+		}
+		item := stream.item
+		stream.item = nil
+		return stream.finish(item)
+	}
+	return nil
+}
+
+// finish fills in the fields that depend on multiple tokens and hands the
+// entry to the callback. Malformed rows are skipped with a warning.
+func (stream *profileTabStream) finish(item *Media) error {
+	item.Title = normalizeText(titleSpaceRegex.ReplaceAllString(item.Title, " "))
+	if err := stream.complete(item); err != nil {
+		stream.warnings = append(stream.warnings, ParseWarning{
+			Category: item.Category,
+			// The first two rows are the header and the filter.
+			Row:   stream.row - 2,
+			Title: item.Title,
+			Err:   err,
+		})
+		return nil
+	}
+	return stream.handle(item)
+}
+
+func (stream *profileTabStream) complete(item *Media) error {
+	if item.ProxerURL == "" {
+		return errors.New("missing link to info page")
+	}
+	item.ID = parseMediaID(item.ProxerURL)
+	if item.RawStatus == "" {
+		return errors.New("missing status")
+	}
+	item.Status = ParseStatus(item.RawStatus)
+
+	if len(stream.typeTexts) == 0 {
+		return errors.New("missing type")
+	}
+	baseType := stream.typeTexts[0]
+	// Just like parseProfileTabMediaTable, only manga use the concrete
+	// types, as there's Manhwa, Webtoon and more.
+	item.RawType = baseType
+	if !ParseMediaType(baseType).IsAnime() && len(stream.typeTexts) > 1 {
+		item.RawType = stream.typeTexts[1]
+	}
+	item.Type = ParseMediaType(item.RawType)
+
+	var err error
+	item.EpisodesWatched, item.EpisodeCount, err = parseProgress(stream.progress)
+	return err
+}
+
+func getTokenAttribute(token html.Token, name string) string {
+	for _, attr := range token.Attr {
+		if strings.EqualFold(attr.Key, name) {
+			return attr.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,103 @@
+package proxerscrape
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const streamedProfilePage = `<html><body>
+<a name="state0"></a><table>
+<tr><th>Name</th></tr><tr><th>Filter</th></tr>
+<tr><td><img title="Abgeschlossen"></td><td><a href="/info/1">Tsurune
+    Kazemai</a></td><td>Animeserie</td><td><img src="/images/misc/stern.png"><img src="/images/misc/stern.png"><img src="/images/misc/sterngrau.png"></td><td><span>13 / 13</span></td></tr>
+<tr><td></td><td><a href="/info/3">Without status</a></td><td>Animeserie</td><td></td><td><span>1 / 12</span></td></tr>
+</table>
+<a name="state1"></a><table>
+<tr><th>Name</th></tr><tr><th>Filter</th></tr>
+<tr><td><img title="Airing"></td><td><a href="/info/2">Solo &amp; Leveling</a></td><td>Mangaserie<br>Manhwa</td><td></td><td><span>50 / ?</span></td></tr>
+</table>
+<a name="state2"></a><table><tr><th>Name</th></tr><tr><th>Filter</th></tr></table>
+<a name="state3"></a><table><tr><th>Name</th></tr><tr><th>Filter</th></tr></table>
+</body></html>`
+
+func TestStreamProfileMediaTab(t *testing.T) {
+	var streamed []*Media
+	warnings, err := StreamProfileMediaTab(strings.NewReader(streamedProfilePage), func(item *Media) error {
+		streamed = append(streamed, item)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 || warnings[0].Row != 2 || warnings[0].Category != CategoryWatched {
+		t.Errorf("Expected the row without status to be skipped, got %v", warnings)
+	}
+
+	watchlist, expectedWarnings, err := ParseProfileMediaTabWithOptions(strings.NewReader(streamedProfilePage), ParseOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(warnings, expectedWarnings) {
+		t.Errorf("Warnings differ:\n%v\n%v", warnings, expectedWarnings)
+	}
+	if expected := watchlist.All(); !reflect.DeepEqual(streamed, expected) {
+		t.Errorf("Streamed entries differ:\n%+v\n%+v", streamed, expected)
+	}
+	if len(streamed) != 2 || streamed[0].Title != "Tsurune Kazemai" || streamed[0].UserRating != 2 ||
+		streamed[1].RawType != "Manhwa" || streamed[1].Category != CategoryCurrentlyWatching || streamed[1].EpisodeCount != 0 {
+		t.Errorf("Unexpected entries: %+v", streamed)
+	}
+}
+
+func TestStreamProfileMediaTab_StopsOnError(t *testing.T) {
+	stop := errors.New("stop")
+	var calls int
+	_, err := StreamProfileMediaTab(strings.NewReader(streamedProfilePage), func(item *Media) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Errorf("Expected to stop after the first entry, got %v after %d calls", err, calls)
+	}
+
+	items, result := StreamProfileMediaTabChannel(strings.NewReader(streamedProfilePage))
+	var received int
+	for range items {
+		received++
+	}
+	if result := <-result; result.Err != nil || len(result.Warnings) != 1 || received != 2 {
+		t.Errorf("Expected 2 entries over the channel, got %d: %+v", received, result)
+	}
+}
+
+func TestStreamProfileMediaTab_Fixtures(t *testing.T) {
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "fixtures", "profile_*.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fixture := range fixtures {
+		page, err := os.ReadFile(fixture)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var streamed []*Media
+		if _, err := StreamProfileMediaTab(bytes.NewReader(page), func(item *Media) error {
+			streamed = append(streamed, item)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		watchlist, err := ParseProfileMediaTab(bytes.NewReader(page))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(streamed, watchlist.All()) {
+			t.Errorf("Streamed entries of %s differ:\n%+v\n%+v", fixture, streamed, watchlist.All())
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package proxerscrape
+
+// TitleKind is one of the different titles a media entry can have.
+type TitleKind string
+
+const (
+	// TitleOriginal is the main title displayed by proxer.me, usually the
+	// romanized Japanese title.
+	TitleOriginal TitleKind = "original"
+	TitleJapanese TitleKind = "japanese"
+	TitleEnglish  TitleKind = "english"
+	TitleGerman   TitleKind = "german"
+	// TitleSynonym is the first synonym of the entry.
+	TitleSynonym TitleKind = "synonym"
+)
+
+// TitlePolicy is an ordered list of title preferences. The first kind of
+// title that is present for an entry is used.
+type TitlePolicy []TitleKind
+
+// DefaultTitlePolicy prefers the titles that external services such as
+// MyAnimeList or AniList match best on.
+var DefaultTitlePolicy = TitlePolicy{TitleJapanese, TitleEnglish, TitleGerman, TitleSynonym}
+
+// TitleOf returns the title of the given kind or an empty string if the entry
+// doesn't have such a title.
+func (item *Media) TitleOf(kind TitleKind) string {
+	switch kind {
+	case TitleOriginal:
+		return item.Title
+	case TitleJapanese:
+		return item.JapaneseTitle
+	case TitleEnglish:
+		return item.EnglishTitle
+	case TitleGerman:
+		return item.GermanTitle
+	case TitleSynonym:
+		if len(item.Synonyms) > 0 {
+			return item.Synonyms[0]
+		}
+	}
+	return ""
+}
+
+// Select returns the most preferred title present for the given entry. If
+// none of the preferred titles are present, the original title is used.
+// Note that most titles are only present after loading extra data.
+func (policy TitlePolicy) Select(item *Media) string {
+	for _, kind := range policy {
+		if title := item.TitleOf(kind); title != "" {
+			return title
+		}
+	}
+	return item.Title
+}
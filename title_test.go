@@ -0,0 +1,16 @@
+package proxerscrape
+
+import "testing"
+
+func TestTitlePolicy_Select(t *testing.T) {
+	item := &Media{Title: "Tsurune", GermanTitle: "Tsurune DE", Synonyms: []string{"Tsurune Synonym"}}
+	if title := DefaultTitlePolicy.Select(item); title != "Tsurune DE" {
+		t.Errorf("Title = %s, instead of Tsurune DE", title)
+	}
+	if title := (TitlePolicy{TitleSynonym, TitleGerman}).Select(item); title != "Tsurune Synonym" {
+		t.Errorf("Title = %s, instead of Tsurune Synonym", title)
+	}
+	if title := (TitlePolicy{TitleEnglish}).Select(item); title != "Tsurune" {
+		t.Errorf("Title = %s, instead of Tsurune", title)
+	}
+}
@@ -0,0 +1,59 @@
+package proxerscrape
+
+import (
+	"fmt"
+	"io"
+)
+
+// TopListKind is one of the rankings of anime proxer.me offers.
+type TopListKind string
+
+const (
+	// TopListRated ranks anime by their average rating.
+	TopListRated TopListKind = "toplist"
+	// TopListPopular ranks anime by the amount of users having them on
+	// their watchlist.
+	TopListPopular TopListKind = "popular"
+)
+
+// RetrieveTopListRawData retrieves a single page of the given toplist,
+// starting at 1.
+func (cache *Cache) RetrieveTopListRawData(kind TopListKind, page int) (io.ReadCloser, CacheInvalidator, error) {
+	cacheKey := fmt.Sprintf("toplist/%s/%d", kind, page)
+	path := fmt.Sprintf("/anime/%s?p=%d", kind, page)
+	return cache.retrievePage(CacheEntryTopList, cacheKey, path, cache.ProfileTabQueryRatelimiter)
+}
+
+// ParseTopList parses a page of a toplist. The entries are ordered by rank.
+func ParseTopList(reader io.Reader) ([]*Media, error) {
+	return parseMediaListing(reader, nil)
+}
+
+// TopList retrieves the given amount of pages of the toplist. Entries moving
+// to another page between requests are only returned once. Retrieval stops
+// early once a page is empty.
+func (cache *Cache) TopList(kind TopListKind, pages int) ([]*Media, error) {
+	seen := make(map[uint]bool)
+	var entries []*Media
+	for page := 1; page <= pages; page++ {
+		reader, _, err := cache.RetrieveTopListRawData(kind, page)
+		if err != nil {
+			return entries, err
+		}
+		pageEntries, err := ParseTopList(reader)
+		reader.Close()
+		if err != nil {
+			return entries, err
+		}
+		if len(pageEntries) == 0 {
+			break
+		}
+		for _, item := range pageEntries {
+			if !seen[item.mediaID()] {
+				seen[item.mediaID()] = true
+				entries = append(entries, item)
+			}
+		}
+	}
+	return entries, nil
+}
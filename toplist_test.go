@@ -0,0 +1,22 @@
+package proxerscrape
+
+import "testing"
+
+func TestCache_TopList(t *testing.T) {
+	cache, queries := newTestCache(map[string]string{
+		"/anime/toplist?p=1": `<table><tr><td><a href="/info/1">First</a></td><td>8.91</td></tr><tr><td><a href="/info/2">Second</a></td><td>8.90</td></tr></table>`,
+		// The second entry moved down in between requests.
+		"/anime/toplist?p=2": `<table><tr><td><a href="/info/2">Second</a></td></tr><tr><td><a href="/info/3">Third</a></td></tr></table>`,
+		"/anime/toplist?p=3": `<table></table>`,
+	})
+	entries, err := cache.TopList(TopListRated, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 || entries[0].Title != "First" || entries[0].Rating != 8.91 || entries[2].Title != "Third" {
+		t.Errorf("Unexpected entries: %+v", entries)
+	}
+	if *queries != 3 {
+		t.Errorf("Expected retrieval to stop after the empty page, got %d queries", *queries)
+	}
+}
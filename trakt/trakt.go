@@ -0,0 +1,368 @@
+// Package trakt pushes the watched episodes of proxer.me watchlists to the
+// history of a Trakt user, for those tracking all their TV in one place.
+package trakt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Bios-Marcel/proxerscrape"
+	"github.com/Bios-Marcel/proxerscrape/mapping"
+)
+
+// BaseURL is the location of the Trakt API.
+const BaseURL = "https://api.trakt.tv"
+
+var (
+	// ErrUnauthorized is returned if the token is invalid or has expired.
+	ErrUnauthorized = errors.New("trakt token is invalid or expired")
+	// ErrDeviceCodeExpired is returned if the user didn't authorize the
+	// device in time.
+	ErrDeviceCodeExpired = errors.New("trakt device code expired")
+	// ErrAccessDenied is returned if the user denied the authorization.
+	ErrAccessDenied = errors.New("trakt authorization was denied")
+)
+
+// Client talks to the Trakt API on behalf of a user. Applications have to be
+// registered at https://trakt.tv/oauth/applications for obtaining a client
+// ID and secret.
+type Client struct {
+	ClientID     string
+	ClientSecret string
+	// Token is the OAuth access token of the user, see
+	// RequestDeviceCode.
+	Token      string
+	HTTPClient *http.Client
+	// BaseURL defaults to BaseURL.
+	BaseURL string
+	// Limiter keeps us below the ratelimit of Trakt. If nil, requests
+	// aren't limited.
+	Limiter *proxerscrape.Limiter
+}
+
+// NewClient creates a client that stays below the documented limit of 1000
+// requests per 5 minutes.
+func NewClient(clientID, clientSecret string) *Client {
+	return &Client{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Limiter:      proxerscrape.NewLimiter(1000, 5*time.Minute),
+	}
+}
+
+// request performs an API request, encoding body and decoding the response
+// into result. Both may be nil. The status code is returned for callers that
+// have to tell apart non-successful responses.
+func (client *Client) request(method, path string, body, result any) (int, error) {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return 0, err
+		}
+	}
+	baseURL := client.BaseURL
+	if baseURL == "" {
+		baseURL = BaseURL
+	}
+	request, err := http.NewRequest(method, baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("trakt-api-version", "2")
+	request.Header.Set("trakt-api-key", client.ClientID)
+	if client.Token != "" {
+		request.Header.Set("Authorization", "Bearer "+client.Token)
+	}
+
+	if client.Limiter != nil {
+		client.Limiter.Wait()
+	}
+	httpClient := client.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusUnauthorized {
+		return response.StatusCode, ErrUnauthorized
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("trakt request %s %s failed with status %d", method, path, response.StatusCode)
+	}
+	if result == nil {
+		return response.StatusCode, nil
+	}
+	return response.StatusCode, json.NewDecoder(response.Body).Decode(result)
+}
+
+// DeviceCode is the code the user has to enter on the verification page in
+// order to authorize the client.
+type DeviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURL string `json:"verification_url"`
+	// ExpiresIn and Interval are in seconds.
+	ExpiresIn int `json:"expires_in"`
+	Interval  int `json:"interval"`
+}
+
+// RequestDeviceCode starts the OAuth device flow. The user has to enter the
+// returned UserCode at the VerificationURL, while PollToken waits for that.
+func (client *Client) RequestDeviceCode() (DeviceCode, error) {
+	var code DeviceCode
+	_, err := client.request(http.MethodPost, "/oauth/device/code", map[string]string{"client_id": client.ClientID}, &code)
+	return code, err
+}
+
+// PollToken waits until the user authorized the device code and sets the
+// obtained access token.
+func (client *Client) PollToken(code DeviceCode) error {
+	interval := time.Duration(code.Interval) * time.Second
+	deadline := time.Now().Add(time.Duration(code.ExpiresIn) * time.Second)
+	body := map[string]string{
+		"code":          code.DeviceCode,
+		"client_id":     client.ClientID,
+		"client_secret": client.ClientSecret,
+	}
+	for time.Now().Before(deadline) {
+		var token struct {
+			AccessToken string `json:"access_token"`
+		}
+		status, err := client.request(http.MethodPost, "/oauth/device/token", body, &token)
+		switch status {
+		case http.StatusOK:
+			if err != nil {
+				return err
+			}
+			client.Token = token.AccessToken
+			return nil
+		// The user hasn't authorized the device yet.
+		case http.StatusBadRequest:
+		case http.StatusTooManyRequests:
+			interval *= 2
+		case http.StatusNotFound, http.StatusGone:
+			return ErrDeviceCodeExpired
+		case http.StatusTeapot:
+			return ErrAccessDenied
+		default:
+			return err
+		}
+		time.Sleep(interval)
+	}
+	return ErrDeviceCodeExpired
+}
+
+// NoSeason is the season of a Target whose season is unknown.
+const NoSeason = -1
+
+// Target is what a proxer entry is mapped to on Trakt, see
+// mapping.ServiceTrakt.
+type Target struct {
+	Movie bool
+	ID    int
+	// Season is the season the episodes of the proxer entry belong to.
+	// proxer.me usually lists seasons as separate entries, which share one
+	// show on Trakt, so there's no sensible default. NoSeason if unknown.
+	Season int
+}
+
+// ParseTarget parses the external ID of a Trakt mapping, such as "show:123",
+// "show:123:2" or "movie:456".
+func ParseTarget(externalID string) (Target, error) {
+	parts := strings.Split(externalID, ":")
+	if len(parts) < 2 || len(parts) > 3 || (parts[0] != "show" && parts[0] != "movie") {
+		return Target{}, fmt.Errorf("invalid trakt id '%s'", externalID)
+	}
+	target := Target{Movie: parts[0] == "movie", Season: NoSeason}
+	var err error
+	if target.ID, err = strconv.Atoi(parts[1]); err != nil {
+		return Target{}, fmt.Errorf("invalid trakt id '%s': %w", externalID, err)
+	}
+	if len(parts) == 3 {
+		if target.Movie {
+			return Target{}, fmt.Errorf("invalid trakt id '%s', movies have no seasons", externalID)
+		}
+		if target.Season, err = strconv.Atoi(parts[2]); err != nil || target.Season < 0 {
+			return Target{}, fmt.Errorf("invalid trakt id '%s', invalid season", externalID)
+		}
+	}
+	return target, nil
+}
+
+// String returns the external ID of the target, as parsed by ParseTarget.
+func (target Target) String() string {
+	if target.Movie {
+		return "movie:" + strconv.Itoa(target.ID)
+	}
+	if target.Season == NoSeason {
+		return "show:" + strconv.Itoa(target.ID)
+	}
+	return fmt.Sprintf("show:%d:%d", target.ID, target.Season)
+}
+
+type ids struct {
+	Trakt int `json:"trakt"`
+}
+
+// released tells Trakt to use the release date as the time of watching.
+const released = "released"
+
+type episode struct {
+	Number int `json:"number"`
+	// WatchedAt is either a timestamp or "released". If empty, Trakt uses
+	// the current time.
+	WatchedAt string `json:"watched_at,omitempty"`
+}
+
+type season struct {
+	Number   int       `json:"number"`
+	Episodes []episode `json:"episodes"`
+}
+
+type show struct {
+	IDs     ids      `json:"ids"`
+	Seasons []season `json:"seasons,omitempty"`
+}
+
+type movie struct {
+	IDs       ids    `json:"ids"`
+	WatchedAt string `json:"watched_at,omitempty"`
+}
+
+// watched are the episodes and movies already in the history of the user.
+type watched struct {
+	episodes map[Target]map[int]bool
+	movies   map[int]bool
+}
+
+func (client *Client) watched() (watched, error) {
+	result := watched{episodes: make(map[Target]map[int]bool), movies: make(map[int]bool)}
+	var shows []struct {
+		Show struct {
+			IDs ids `json:"ids"`
+		} `json:"show"`
+		Seasons []season `json:"seasons"`
+	}
+	if _, err := client.request(http.MethodGet, "/sync/watched/shows", nil, &shows); err != nil {
+		return result, err
+	}
+	for _, show := range shows {
+		for _, season := range show.Seasons {
+			target := Target{ID: show.Show.IDs.Trakt, Season: season.Number}
+			result.episodes[target] = make(map[int]bool)
+			for _, episode := range season.Episodes {
+				result.episodes[target][episode.Number] = true
+			}
+		}
+	}
+
+	var movies []struct {
+		Movie movie `json:"movie"`
+	}
+	if _, err := client.request(http.MethodGet, "/sync/watched/movies", nil, &movies); err != nil {
+		return result, err
+	}
+	for _, movie := range movies {
+		result.movies[movie.Movie.IDs.Trakt] = true
+	}
+	return result, nil
+}
+
+// SyncResult is the outcome of Sync.
+type SyncResult struct {
+	// Episodes and Movies are the amount of newly added history entries.
+	Episodes int
+	Movies   int
+	// Skipped are the entries without a Trakt mapping and those that aren't
+	// exportable, see proxerscrape.Media.Exportable.
+	Skipped []*proxerscrape.Media
+	// Unseasoned are the entries mapped to a show without a season. Since
+	// proxer.me lists seasons separately, their episodes can't be assigned.
+	Unseasoned []*proxerscrape.Media
+}
+
+// Sync adds the watched episodes of all anime in the watchlist to the
+// history of the user. Episodes already in the history are skipped, so that
+// syncing repeatedly doesn't add them again. proxer.me only knows the amount
+// of watched episodes, so episodes 1 up to that amount are added. Movies are
+// added once they are in the watched category.
+//
+// The time of watching is taken from watchedAt, keyed by proxer ID, see
+// proxerscrape.LastWatched, which may be nil. Entries missing from it are
+// recorded as watched at their release, rather than now.
+func (client *Client) Sync(watchlist *proxerscrape.Watchlist, store *mapping.Store, watchedAt map[string]time.Time) (SyncResult, error) {
+	var result SyncResult
+	alreadyWatched, err := client.watched()
+	if err != nil {
+		return result, err
+	}
+
+	var history struct {
+		Shows  []show  `json:"shows,omitempty"`
+		Movies []movie `json:"movies,omitempty"`
+	}
+	for _, item := range watchlist.All() {
+		if !item.Type.IsAnime() {
+			continue
+		}
+		if !item.Exportable() {
+			result.Skipped = append(result.Skipped, item)
+			continue
+		}
+		traktMapping, present := store.Get(item.ProxerID(), mapping.ServiceTrakt)
+		if !present {
+			result.Skipped = append(result.Skipped, item)
+			continue
+		}
+		target, err := ParseTarget(traktMapping.ExternalID)
+		if err != nil {
+			return result, fmt.Errorf("error syncing '%s': %w", item.Title, err)
+		}
+
+		watched := released
+		if at, present := watchedAt[item.ProxerID()]; present {
+			watched = at.UTC().Format(time.RFC3339)
+		}
+		if target.Movie {
+			if item.Category == proxerscrape.CategoryWatched && !alreadyWatched.movies[target.ID] {
+				history.Movies = append(history.Movies, movie{IDs: ids{Trakt: target.ID}, WatchedAt: watched})
+				alreadyWatched.movies[target.ID] = true
+				result.Movies++
+			}
+			continue
+		}
+		if target.Season == NoSeason {
+			result.Unseasoned = append(result.Unseasoned, item)
+			continue
+		}
+
+		newSeason := season{Number: target.Season}
+		watchedEpisodes := alreadyWatched.episodes[Target{ID: target.ID, Season: target.Season}]
+		for number := 1; number <= int(item.EpisodesWatched); number++ {
+			if !watchedEpisodes[number] {
+				newSeason.Episodes = append(newSeason.Episodes, episode{Number: number, WatchedAt: watched})
+			}
+		}
+		if len(newSeason.Episodes) > 0 {
+			history.Shows = append(history.Shows, show{IDs: ids{Trakt: target.ID}, Seasons: []season{newSeason}})
+			result.Episodes += len(newSeason.Episodes)
+		}
+	}
+
+	if len(history.Shows) == 0 && len(history.Movies) == 0 {
+		return result, nil
+	}
+	_, err = client.request(http.MethodPost, "/sync/history", history, nil)
+	return result, err
+}
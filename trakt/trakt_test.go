@@ -0,0 +1,136 @@
+package trakt
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Bios-Marcel/proxerscrape"
+	"github.com/Bios-Marcel/proxerscrape/mapping"
+)
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		id      string
+		want    Target
+		wantErr bool
+	}{
+		{id: "show:12", want: Target{ID: 12, Season: NoSeason}},
+		{id: "show:12:3", want: Target{ID: 12, Season: 3}},
+		{id: "movie:7", want: Target{Movie: true, ID: 7, Season: NoSeason}},
+		{id: "movie:7:2", wantErr: true},
+		{id: "show:12:-1", wantErr: true},
+		{id: "episode:1", wantErr: true},
+		{id: "show:abc", wantErr: true},
+		{id: "12", wantErr: true},
+	}
+	for _, test := range tests {
+		got, err := ParseTarget(test.id)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ParseTarget(%s): unexpected error %v", test.id, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("ParseTarget(%s) = %v, want %v", test.id, got, test.want)
+		}
+		if !test.wantErr && got.String() != test.id {
+			t.Errorf("ParseTarget(%s).String() = %s", test.id, got)
+		}
+	}
+}
+
+func TestPollToken(t *testing.T) {
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		polls++
+		// The first poll happens before the user authorized the device.
+		if polls == 1 {
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		writer.Write([]byte(`{"access_token":"token"}`))
+	}))
+	defer server.Close()
+
+	client := &Client{BaseURL: server.URL}
+	if err := client.PollToken(DeviceCode{DeviceCode: "code", ExpiresIn: 10}); err != nil {
+		t.Fatal(err)
+	}
+	if client.Token != "token" || polls != 2 {
+		t.Errorf("Unexpected token '%s' after %d polls", client.Token, polls)
+	}
+}
+
+func TestSync(t *testing.T) {
+	var history struct {
+		Shows  []show  `json:"shows"`
+		Movies []movie `json:"movies"`
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sync/watched/shows", func(writer http.ResponseWriter, request *http.Request) {
+		if request.Header.Get("Authorization") != "Bearer token" || request.Header.Get("trakt-api-key") != "id" {
+			writer.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writer.Write([]byte(`[{"show":{"ids":{"trakt":1}},"seasons":[{"number":2,"episodes":[{"number":1},{"number":2}]}]}]`))
+	})
+	mux.HandleFunc("/sync/watched/movies", func(writer http.ResponseWriter, request *http.Request) {
+		writer.Write([]byte(`[{"movie":{"ids":{"trakt":5}}}]`))
+	})
+	mux.HandleFunc("/sync/history", func(writer http.ResponseWriter, request *http.Request) {
+		json.NewDecoder(request.Body).Decode(&history)
+		writer.WriteHeader(http.StatusCreated)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	watchlist := proxerscrape.Watchlist{}
+	watchlist.Watched.Data = []*proxerscrape.Media{
+		{ProxerURL: "/info/1", Title: "Season Two", Type: proxerscrape.MediaTypeSeries, Category: proxerscrape.CategoryWatched, EpisodesWatched: 4},
+		{ProxerURL: "/info/2", Title: "New Movie", Type: proxerscrape.MediaTypeMovie, Category: proxerscrape.CategoryWatched, EpisodesWatched: 1},
+		{ProxerURL: "/info/3", Title: "Old Movie", Type: proxerscrape.MediaTypeMovie, Category: proxerscrape.CategoryWatched, EpisodesWatched: 1},
+		{ProxerURL: "/info/4", Title: "Unmapped", Type: proxerscrape.MediaTypeSeries},
+		{ProxerURL: "/info/5", Title: "No Season", Type: proxerscrape.MediaTypeSeries, Category: proxerscrape.CategoryWatched, EpisodesWatched: 12},
+		{ProxerURL: "/info/6", Title: "Gone", Type: proxerscrape.MediaTypeMovie, Category: proxerscrape.CategoryWatched, Unavailable: true},
+	}
+	store := &mapping.Store{}
+	store.Set("1", mapping.ServiceTrakt, mapping.Mapping{ExternalID: "show:1:2"})
+	store.Set("2", mapping.ServiceTrakt, mapping.Mapping{ExternalID: "movie:4"})
+	store.Set("3", mapping.ServiceTrakt, mapping.Mapping{ExternalID: "movie:5"})
+	store.Set("5", mapping.ServiceTrakt, mapping.Mapping{ExternalID: "show:1"})
+	store.Set("6", mapping.ServiceTrakt, mapping.Mapping{ExternalID: "movie:6"})
+	watchedAt := map[string]time.Time{"1": time.Date(2019, 3, 14, 20, 0, 0, 0, time.UTC)}
+
+	client := &Client{ClientID: "id", BaseURL: server.URL}
+	if _, err := client.Sync(&watchlist, store, watchedAt); err != ErrUnauthorized {
+		t.Errorf("Expected ErrUnauthorized, got %v", err)
+	}
+
+	client.Token = "token"
+	result, err := client.Sync(&watchlist, store, watchedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Episodes != 2 || result.Movies != 1 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if len(result.Skipped) != 2 || result.Skipped[0].Title != "Unmapped" || result.Skipped[1].Title != "Gone" {
+		t.Errorf("Unexpected skipped entries: %v", result.Skipped)
+	}
+	if len(result.Unseasoned) != 1 || result.Unseasoned[0].Title != "No Season" {
+		t.Errorf("Show without season wasn't refused: %v", result.Unseasoned)
+	}
+	if len(history.Shows) != 1 || history.Shows[0].IDs.Trakt != 1 ||
+		len(history.Shows[0].Seasons) != 1 || history.Shows[0].Seasons[0].Number != 2 ||
+		len(history.Shows[0].Seasons[0].Episodes) != 2 || history.Shows[0].Seasons[0].Episodes[0].Number != 3 {
+		t.Errorf("Unexpected shows pushed: %+v", history.Shows)
+	}
+	if history.Shows[0].Seasons[0].Episodes[0].WatchedAt != "2019-03-14T20:00:00Z" {
+		t.Errorf("Episodes weren't dated by the history: %+v", history.Shows[0].Seasons[0].Episodes)
+	}
+	if len(history.Movies) != 1 || history.Movies[0].IDs.Trakt != 4 || history.Movies[0].WatchedAt != "released" {
+		t.Errorf("Unexpected movies pushed: %+v", history.Movies)
+	}
+}
@@ -0,0 +1,344 @@
+// Package tui is an interactive terminal browser for watchlists. It lists
+// the four categories, allows filtering and sorting them, shows details with
+// lazily loaded extra data and triggers exports.
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Bios-Marcel/proxerscrape"
+	"github.com/Bios-Marcel/proxerscrape/mapping"
+)
+
+// Options configure the browser.
+type Options struct {
+	Watchlist *proxerscrape.Watchlist
+	// RetrieveRawData is used for loading the extra data of an entry once
+	// its details are shown. If nil, only the watchlist data is shown.
+	RetrieveRawData proxerscrape.MediaRawDataRetriever
+	// Export is triggered via `e` and returns a message shown to the user,
+	// such as the path of the written file. If nil, exporting is disabled.
+	Export func(watchlist *proxerscrape.Watchlist) (string, error)
+	// UserData provides the local notes, tags and progress per language,
+	// which are shown in the details. Filtering for `#tag` lists the entries
+	// with that tag. May be nil.
+	UserData *proxerscrape.UserData
+}
+
+// Run shows the browser until the user quits.
+func Run(options Options) error {
+	_, err := tea.NewProgram(newModel(options), tea.WithAltScreen()).Run()
+	return err
+}
+
+// sortOrder is an order the entries can be listed in.
+type sortOrder struct {
+	name string
+	less func(a, b *proxerscrape.Media) bool
+}
+
+var sortOrders = []sortOrder{
+	{"title", func(a, b *proxerscrape.Media) bool {
+		return strings.ToLower(a.Title) < strings.ToLower(b.Title)
+	}},
+	{"rating", func(a, b *proxerscrape.Media) bool { return a.Rating > b.Rating }},
+	{"own rating", func(a, b *proxerscrape.Media) bool { return a.UserRating > b.UserRating }},
+	{"progress", func(a, b *proxerscrape.Media) bool { return a.EpisodesWatched > b.EpisodesWatched }},
+}
+
+// extraDataMsg is sent once the extra data of an entry has been loaded.
+type extraDataMsg struct {
+	item   *proxerscrape.Media
+	loaded proxerscrape.Media
+	err    error
+}
+
+// statusMsg replaces the status line, for example after exporting.
+type statusMsg string
+
+type model struct {
+	options  Options
+	category int
+	cursor   int
+	order    int
+	filter   string
+	// filtering is set while the filter is being typed.
+	filtering bool
+	// detail is the entry whose details are shown, nil for the list.
+	detail  *proxerscrape.Media
+	loading map[*proxerscrape.Media]bool
+	loaded  map[*proxerscrape.Media]bool
+	status  string
+	height  int
+}
+
+func newModel(options Options) *model {
+	return &model{
+		options: options,
+		loading: make(map[*proxerscrape.Media]bool),
+		loaded:  make(map[*proxerscrape.Media]bool),
+		height:  24,
+	}
+}
+
+func (m *model) Init() tea.Cmd {
+	return nil
+}
+
+// visible returns the entries of the current category matching the filter,
+// in the current order.
+func (m *model) visible() []*proxerscrape.Media {
+	category := m.options.Watchlist.Category(proxerscrape.ListCategories[m.category])
+	query := mapping.NormalizeTitle(m.filter)
+	byTag := strings.HasPrefix(m.filter, "#")
+	tag := strings.TrimPrefix(m.filter, "#")
+	var entries []*proxerscrape.Media
+	for _, item := range category.Data {
+		if byTag {
+			if m.note(item).HasTag(tag) {
+				entries = append(entries, item)
+			}
+		} else if query == "" || strings.Contains(mapping.NormalizeTitle(item.Title), query) {
+			entries = append(entries, item)
+		}
+	}
+	less := sortOrders[m.order].less
+	sort.SliceStable(entries, func(a, b int) bool {
+		return less(entries[a], entries[b])
+	})
+	return entries
+}
+
+// note returns the local note of the entry or nil if there's none.
+func (m *model) note(item *proxerscrape.Media) *proxerscrape.EntryNote {
+	if m.options.UserData == nil {
+		return nil
+	}
+	return m.options.UserData.Note(item)
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+	case extraDataMsg:
+		delete(m.loading, msg.item)
+		if msg.err != nil {
+			m.status = "Loading details failed: " + msg.err.Error()
+		} else {
+			// Entries are loaded into a copy and only replaced here, on the
+			// UI goroutine, since the view reads them in the meantime.
+			*msg.item = msg.loaded
+			m.loaded[msg.item] = true
+		}
+	case statusMsg:
+		m.status = string(msg)
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC {
+			return m, tea.Quit
+		}
+		if m.filtering {
+			m.updateFilter(msg)
+			return m, nil
+		}
+		if m.detail != nil {
+			switch msg.String() {
+			case "esc", "backspace", "q":
+				m.detail = nil
+			}
+			return m, nil
+		}
+		return m, m.updateList(msg)
+	}
+	return m, nil
+}
+
+func (m *model) updateFilter(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.filtering = false
+	case tea.KeyEsc:
+		m.filtering = false
+		m.filter = ""
+	case tea.KeyBackspace:
+		if runes := []rune(m.filter); len(runes) > 0 {
+			m.filter = string(runes[:len(runes)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.filter += string(msg.Runes)
+	}
+	m.cursor = 0
+}
+
+func (m *model) updateList(msg tea.KeyMsg) tea.Cmd {
+	entries := m.visible()
+	switch msg.String() {
+	case "q":
+		return tea.Quit
+	case "left", "h", "shift+tab":
+		m.category = (m.category + len(proxerscrape.ListCategories) - 1) % len(proxerscrape.ListCategories)
+		m.cursor = 0
+	case "right", "l", "tab":
+		m.category = (m.category + 1) % len(proxerscrape.ListCategories)
+		m.cursor = 0
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(entries)-1 {
+			m.cursor++
+		}
+	case "/":
+		m.filtering = true
+	case "s":
+		m.order = (m.order + 1) % len(sortOrders)
+		m.status = "Sorted by " + sortOrders[m.order].name
+	case "e":
+		return m.export()
+	case "enter":
+		if m.cursor < len(entries) {
+			m.detail = entries[m.cursor]
+			return m.loadExtraData(m.detail)
+		}
+	}
+	return nil
+}
+
+func (m *model) loadExtraData(item *proxerscrape.Media) tea.Cmd {
+	retrieve := m.options.RetrieveRawData
+	if retrieve == nil || m.loaded[item] || m.loading[item] {
+		return nil
+	}
+	m.loading[item] = true
+	loaded := *item
+	return func() tea.Msg {
+		category := proxerscrape.WatchlistCategory{Data: []*proxerscrape.Media{&loaded}}
+		err := category.LoadExtraData(retrieve)
+		return extraDataMsg{item: item, loaded: loaded, err: err}
+	}
+}
+
+func (m *model) export() tea.Cmd {
+	if m.options.Export == nil {
+		m.status = "Exporting isn't available"
+		return nil
+	}
+	m.status = "Exporting ..."
+	// Update keeps replacing entries whose details finish loading, so the
+	// export works on a copy.
+	watchlist := snapshot(m.options.Watchlist)
+	exportWatchlist := m.options.Export
+	return func() tea.Msg {
+		message, err := exportWatchlist(watchlist)
+		if err != nil {
+			return statusMsg("Exporting failed: " + err.Error())
+		}
+		return statusMsg(message)
+	}
+}
+
+func (m *model) View() string {
+	if m.detail != nil {
+		return m.detailView()
+	}
+
+	var builder strings.Builder
+	for index, category := range proxerscrape.ListCategories {
+		name := fmt.Sprintf("%s (%d)", category, len(m.options.Watchlist.Category(category).Data))
+		if index == m.category {
+			fmt.Fprintf(&builder, "[%s] ", name)
+		} else {
+			fmt.Fprintf(&builder, " %s  ", name)
+		}
+	}
+	builder.WriteString("\n")
+	if m.filtering || m.filter != "" {
+		fmt.Fprintf(&builder, "Filter: %s", m.filter)
+		if m.filtering {
+			builder.WriteString("_")
+		}
+	}
+	builder.WriteString("\n\n")
+
+	entries := m.visible()
+	// Header, filter and footer take up six lines.
+	rows := m.height - 6
+	if rows < 1 {
+		rows = 1
+	}
+	start := 0
+	if m.cursor >= rows {
+		start = m.cursor - rows + 1
+	}
+	for index := start; index < len(entries) && index < start+rows; index++ {
+		item := entries[index]
+		cursor := "  "
+		if index == m.cursor {
+			cursor = "> "
+		}
+		fmt.Fprintf(&builder, "%s%s (%d/%d)\n", cursor, item.Title, item.EpisodesWatched, item.EpisodeCount)
+	}
+	if len(entries) == 0 {
+		builder.WriteString("  No entries\n")
+	}
+
+	fmt.Fprintf(&builder, "\n%s\n", m.status)
+	builder.WriteString("←/→ category · ↑/↓ select · enter details · / filter · s sort · e export · q quit")
+	return builder.String()
+}
+
+func (m *model) detailView() string {
+	item := m.detail
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "%s\n\n", item.Title)
+	fmt.Fprintf(&builder, "Type:      %s\n", item.Type)
+	fmt.Fprintf(&builder, "Status:    %s\n", item.Status)
+	fmt.Fprintf(&builder, "Progress:  %d / %d\n", item.EpisodesWatched, item.EpisodeCount)
+	if m.options.UserData != nil {
+		for _, language := range m.options.UserData.TrackedLanguages(item) {
+			fmt.Fprintf(&builder, "  %s:   %d / %d\n", language, m.options.UserData.EpisodesWatched(item, language), item.EpisodeCount)
+		}
+	}
+	if item.UserRating > 0 {
+		fmt.Fprintf(&builder, "Own rating: %d\n", item.UserRating)
+	}
+	if note := m.note(item); note != nil {
+		if len(note.Tags) > 0 {
+			fmt.Fprintf(&builder, "Tags:      %s\n", strings.Join(note.Tags, ", "))
+		}
+		if note.Text != "" {
+			fmt.Fprintf(&builder, "Note:      %s\n", note.Text)
+		}
+	}
+	switch {
+	case m.loading[item]:
+		builder.WriteString("\nLoading details ...\n")
+	case m.loaded[item]:
+		fmt.Fprintf(&builder, "Rating:    %.2f\n", item.Rating)
+		fmt.Fprintf(&builder, "Genres:    %s\n", strings.Join(item.Generes, ", "))
+		if period := item.ReleasePeriod; period.FromSeason != "" {
+			fmt.Fprintf(&builder, "Season:    %s %d\n", period.FromSeason, period.FromYear)
+		}
+		fmt.Fprintf(&builder, "Time left: %s\n", item.EstimatedWatchTimeLeft())
+	}
+	fmt.Fprintf(&builder, "\nhttps://proxer.me%s\n\n%s\nesc back", item.ProxerURL, m.status)
+	return builder.String()
+}
+
+// snapshot copies the watchlist including its entries.
+func snapshot(watchlist *proxerscrape.Watchlist) *proxerscrape.Watchlist {
+	var copied proxerscrape.Watchlist
+	for _, category := range proxerscrape.ListCategories {
+		target := copied.Category(category)
+		for _, item := range watchlist.Category(category).Data {
+			entry := *item
+			target.Data = append(target.Data, &entry)
+		}
+	}
+	return &copied
+}
@@ -0,0 +1,149 @@
+package tui
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Bios-Marcel/proxerscrape"
+)
+
+func press(m *model, keys ...string) tea.Cmd {
+	var cmd tea.Cmd
+	for _, key := range keys {
+		var msg tea.KeyMsg
+		switch key {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		}
+		_, cmd = m.Update(msg)
+	}
+	return cmd
+}
+
+func titles(entries []*proxerscrape.Media) string {
+	var names []string
+	for _, item := range entries {
+		names = append(names, item.Title)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestModel(t *testing.T) {
+	watchlist := &proxerscrape.Watchlist{
+		Watched: proxerscrape.WatchlistCategory{Data: []*proxerscrape.Media{
+			{ProxerURL: "/info/1", Title: "Steins;Gate", Rating: 9},
+			{ProxerURL: "/info/2", Title: "Naruto", Rating: 7},
+			{ProxerURL: "/info/3", Title: "Naruto Shippuden", Rating: 8},
+		}},
+		CurrentlyWatching: proxerscrape.WatchlistCategory{Data: []*proxerscrape.Media{
+			{ProxerURL: "/info/4", Title: "One Piece"},
+		}},
+	}
+	m := newModel(Options{
+		Watchlist: watchlist,
+		RetrieveRawData: func(*proxerscrape.Media) (io.ReadCloser, proxerscrape.CacheInvalidator, error) {
+			page := `<html><head><title>One Piece - Anime - Proxer.Me</title></head><body><table class="details"></table><span class="average">8.5</span></body></html>`
+			return io.NopCloser(strings.NewReader(page)), func() error { return nil }, nil
+		},
+	})
+
+	if got := titles(m.visible()); got != "Naruto,Naruto Shippuden,Steins;Gate" {
+		t.Errorf("Expected sorting by title, got %s", got)
+	}
+	press(m, "s")
+	if got := titles(m.visible()); got != "Steins;Gate,Naruto Shippuden,Naruto" {
+		t.Errorf("Expected sorting by rating, got %s", got)
+	}
+	press(m, "/", "n", "a", "r", "enter")
+	if got := titles(m.visible()); got != "Naruto Shippuden,Naruto" {
+		t.Errorf("Expected filtered entries, got %s", got)
+	}
+	press(m, "l")
+	if got := titles(m.visible()); got != "" {
+		t.Errorf("Expected the filter to apply to the next category, got %s", got)
+	}
+	press(m, "/", "esc")
+	if got := titles(m.visible()); got != "One Piece" {
+		t.Errorf("Expected the cleared filter to show all entries, got %s", got)
+	}
+
+	cmd := press(m, "enter")
+	if m.detail == nil || cmd == nil || !strings.Contains(m.View(), "Loading details") {
+		t.Fatal("Expected details to be loaded")
+	}
+	m.Update(cmd())
+	if m.detail.Rating != 8.5 || !strings.Contains(m.View(), "Rating:    8.50") {
+		t.Errorf("Expected loaded details, got:\n%s", m.View())
+	}
+	press(m, "esc")
+	if m.detail != nil {
+		t.Error("Expected esc to return to the list")
+	}
+}
+
+func TestModel_Notes(t *testing.T) {
+	watchlist := &proxerscrape.Watchlist{
+		Watched: proxerscrape.WatchlistCategory{Data: []*proxerscrape.Media{
+			{ProxerURL: "/info/1", Title: "Steins;Gate", EpisodeCount: 24},
+			{ProxerURL: "/info/2", Title: "Naruto"},
+		}},
+	}
+	userData := &proxerscrape.UserData{}
+	userData.AddTag(watchlist.Watched.Data[0], "favourite")
+	userData.SetNote(watchlist.Watched.Data[0], "Watch the movie next")
+	userData.SetLanguageProgress(watchlist.Watched.Data[0], proxerscrape.LanguageGermanDub, 3)
+	m := newModel(Options{Watchlist: watchlist, UserData: userData})
+
+	press(m, "/", "#", "f", "a", "v", "o", "u", "r", "i", "t", "e", "enter")
+	if got := titles(m.visible()); got != "Steins;Gate" {
+		t.Errorf("Expected entries filtered by tag, got %s", got)
+	}
+	press(m, "enter")
+	if view := m.View(); !strings.Contains(view, "Tags:      favourite") || !strings.Contains(view, "Note:      Watch the movie next") ||
+		!strings.Contains(view, "gerdub:   3 / 24") {
+		t.Errorf("Expected the note in the details, got:\n%s", view)
+	}
+}
+
+func TestModel_Export(t *testing.T) {
+	watchlist := &proxerscrape.Watchlist{
+		Watched: proxerscrape.WatchlistCategory{Data: []*proxerscrape.Media{
+			{ProxerURL: "/info/1", Title: "One Piece"},
+		}},
+	}
+	var exported *proxerscrape.Watchlist
+	m := newModel(Options{
+		Watchlist: watchlist,
+		RetrieveRawData: func(*proxerscrape.Media) (io.ReadCloser, proxerscrape.CacheInvalidator, error) {
+			page := `<html><head><title>One Piece - Anime - Proxer.Me</title></head><body><table class="details"></table><span class="average">8.5</span></body></html>`
+			return io.NopCloser(strings.NewReader(page)), func() error { return nil }, nil
+		},
+		Export: func(watchlist *proxerscrape.Watchlist) (string, error) {
+			exported = watchlist
+			return "Exported", nil
+		},
+	})
+
+	load := press(m, "enter")
+	press(m, "esc")
+	export := press(m, "e")
+	// The export runs while the loaded details are applied.
+	done := make(chan tea.Msg)
+	go func() { done <- export() }()
+	m.Update(load())
+	m.Update(<-done)
+
+	if exported.Watched.Data[0] == watchlist.Watched.Data[0] || exported.Watched.Data[0].Title != "One Piece" {
+		t.Errorf("Expected a copy of the watchlist to be exported, got %+v", exported.Watched.Data)
+	}
+	if watchlist.Watched.Data[0].Rating != 8.5 || m.status != "Exported" {
+		t.Errorf("Unexpected state after exporting: %+v, %q", watchlist.Watched.Data[0], m.status)
+	}
+}
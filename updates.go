@@ -0,0 +1,106 @@
+package proxerscrape
+
+import "fmt"
+
+// UpdateType tells what changed about a tracked entry.
+type UpdateType string
+
+const (
+	// UpdateEpisodes means the episode count of the entry has increased.
+	UpdateEpisodes UpdateType = "episodes"
+	// UpdateStatus means the entry has, for example, started or finished
+	// airing.
+	UpdateStatus UpdateType = "status"
+	// UpdateRelease means a new episode or chapter has been released,
+	// according to the notifications of the user.
+	UpdateRelease UpdateType = "release"
+)
+
+// Update is a change of a tracked entry, as opposed to Change, which
+// covers changes made by the user.
+type Update struct {
+	Type              UpdateType
+	Previous, Current *Media
+	// Release is the notification an UpdateRelease is based on. Previous
+	// isn't set for releases.
+	Release *Notification
+}
+
+// Message describes the update in a single sentence.
+func (update Update) Message() string {
+	switch update.Type {
+	case UpdateStatus:
+		return fmt.Sprintf("%s: %s", update.Current.Title, update.Current.RawStatus)
+	case UpdateRelease:
+		return fmt.Sprintf("%s: %s %d released (%s), %d watched",
+			update.Current.Title, update.Release.Kind, update.Release.Episode,
+			update.Release.Language, update.Current.EpisodesWatched)
+	}
+	return fmt.Sprintf("%s: %d new episodes, %d / %d watched",
+		update.Current.Title, update.Current.EpisodeCount-update.Previous.EpisodeCount,
+		update.Current.EpisodesWatched, update.Current.EpisodeCount)
+}
+
+// tracked checks whether the user is still interested in updates of the
+// entry.
+func (item *Media) tracked() bool {
+	return item.Category == CategoryCurrentlyWatching || item.Category == CategoryToWatch
+}
+
+// DetectUpdates compares two versions of a watchlist and returns the updates
+// of all entries being watched or planned to be watched. New entries have no
+// previous state and are therefore skipped. Since profile tabs only show the
+// announced amount of episodes, episode updates are rare for airing entries.
+// If the user is logged in, DetectReleases is the better source for those.
+func DetectUpdates(previous, current Watchlist) []Update {
+	previousByID := make(map[uint]*Media)
+	for _, item := range previous.All() {
+		previousByID[item.mediaID()] = item
+	}
+
+	var updates []Update
+	for _, item := range current.All() {
+		old, present := previousByID[item.mediaID()]
+		if !present || !item.tracked() {
+			continue
+		}
+		if item.EpisodeCount > old.EpisodeCount {
+			updates = append(updates, Update{Type: UpdateEpisodes, Previous: old, Current: item})
+		}
+		if item.Status != old.Status {
+			updates = append(updates, Update{Type: UpdateStatus, Previous: old, Current: item})
+		}
+	}
+	return updates
+}
+
+// Key identifies the release a notification is about, so that releases
+// aren't reported twice, see DetectReleases.
+func (notification *Notification) Key() string {
+	return fmt.Sprintf("%s/%d/%s", notification.ProxerID(), notification.Episode, notification.Language)
+}
+
+// DetectReleases returns an update for every new episode or chapter of an
+// entry being watched or planned to be watched, based on the notifications
+// of the user. Releases whose key is in notified are skipped, all reported
+// ones are added to it.
+func DetectReleases(notifications []Notification, current Watchlist, notified map[string]bool) []Update {
+	tracked := make(map[string]*Media)
+	for _, item := range current.All() {
+		if item.tracked() {
+			tracked[item.ProxerID()] = item
+		}
+	}
+
+	var updates []Update
+	for index := range notifications {
+		release := &notifications[index]
+		item, present := tracked[release.ProxerID()]
+		if release.Kind == NotificationOther || !present || notified[release.Key()] {
+			continue
+		}
+		notified[release.Key()] = true
+		updates = append(updates, Update{Type: UpdateRelease, Current: item, Release: release})
+	}
+	return updates
+}
@@ -0,0 +1,67 @@
+package proxerscrape
+
+import "testing"
+
+func TestDetectUpdates(t *testing.T) {
+	previous := Watchlist{
+		CurrentlyWatching: WatchlistCategory{Data: []*Media{
+			{ProxerURL: "/info/1", Title: "Airing", EpisodeCount: 6, EpisodesWatched: 5, Status: StatusAiring, Category: CategoryCurrentlyWatching},
+		}},
+		ToWatch: WatchlistCategory{Data: []*Media{
+			{ProxerURL: "/info/2", Title: "Upcoming", Status: StatusPreAiring, Category: CategoryToWatch},
+		}},
+		Watched: WatchlistCategory{Data: []*Media{
+			{ProxerURL: "/info/3", Title: "Untracked", EpisodeCount: 12, Status: StatusAiring, Category: CategoryWatched},
+		}},
+	}
+	current := Watchlist{
+		CurrentlyWatching: WatchlistCategory{Data: []*Media{
+			{ProxerURL: "/info/1", Title: "Airing", EpisodeCount: 7, EpisodesWatched: 5, Status: StatusAiring, Category: CategoryCurrentlyWatching},
+			{ProxerURL: "/info/4", Title: "New", EpisodeCount: 3, Status: StatusAiring, Category: CategoryCurrentlyWatching},
+		}},
+		ToWatch: WatchlistCategory{Data: []*Media{
+			{ProxerURL: "/info/2", Title: "Upcoming", RawStatus: "Airing", Status: StatusAiring, Category: CategoryToWatch},
+		}},
+		Watched: WatchlistCategory{Data: []*Media{
+			{ProxerURL: "/info/3", Title: "Untracked", EpisodeCount: 13, Status: StatusFinished, Category: CategoryWatched},
+		}},
+	}
+
+	updates := DetectUpdates(previous, current)
+	if len(updates) != 2 {
+		t.Fatalf("Expected 2 updates, got %+v", updates)
+	}
+	if updates[0].Type != UpdateEpisodes || updates[0].Message() != "Airing: 1 new episodes, 5 / 7 watched" {
+		t.Errorf("Unexpected episode update: %s", updates[0].Message())
+	}
+	if updates[1].Type != UpdateStatus || updates[1].Message() != "Upcoming: Airing" {
+		t.Errorf("Unexpected status update: %s", updates[1].Message())
+	}
+}
+
+func TestDetectReleases(t *testing.T) {
+	current := Watchlist{
+		CurrentlyWatching: WatchlistCategory{Data: []*Media{
+			{ProxerURL: "/info/1", Title: "Airing", EpisodeCount: 12, EpisodesWatched: 5, Category: CategoryCurrentlyWatching},
+		}},
+		Watched: WatchlistCategory{Data: []*Media{
+			{ProxerURL: "/info/3", Title: "Untracked", Category: CategoryWatched},
+		}},
+	}
+	notifications := []Notification{
+		{Kind: NotificationNewEpisode, ProxerURL: "/info/1", Episode: 6, Language: "engsub"},
+		{Kind: NotificationNewEpisode, ProxerURL: "/info/3", Episode: 2, Language: "engsub"},
+		{Kind: NotificationOther, Text: "News"},
+	}
+
+	notified := make(map[string]bool)
+	updates := DetectReleases(notifications, current, notified)
+	if len(updates) != 1 || updates[0].Message() != "Airing: episode 6 released (engsub), 5 watched" {
+		t.Fatalf("Unexpected updates: %+v", updates)
+	}
+	// The announced episode count hasn't changed, but the release is still
+	// only reported once.
+	if updates := DetectReleases(notifications, current, notified); len(updates) != 0 {
+		t.Errorf("Release was reported twice: %+v", updates)
+	}
+}
@@ -0,0 +1,274 @@
+package proxerscrape
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/Bios-Marcel/proxerscrape/internal/atomicfile"
+)
+
+// Language is a language version of an anime, as differentiated by proxer.me.
+type Language string
+
+const (
+	LanguageGermanSub  Language = "gersub"
+	LanguageGermanDub  Language = "gerdub"
+	LanguageEnglishSub Language = "engsub"
+	LanguageEnglishDub Language = "engdub"
+)
+
+// Languages are all languages progress can be tracked for.
+var Languages = []Language{LanguageGermanSub, LanguageGermanDub, LanguageEnglishSub, LanguageEnglishDub}
+
+// UserData is data tracked locally, which proxer.me has no concept of. It's
+// kept separate from the cache, since it can't be restored by fetching again.
+type UserData struct {
+	// LanguageProgress holds the watched episodes per language, keyed by
+	// the proxer ID of the media.
+	LanguageProgress map[string]map[Language]uint16 `json:"languageProgress,omitempty"`
+	// Notes holds free text notes and tags, keyed by the proxer ID of the
+	// media.
+	Notes map[string]*EntryNote `json:"notes,omitempty"`
+	// Completions is the ledger of finished entries, which allows
+	// summarising seasons without scraping the whole history.
+	Completions []Completion `json:"completions,omitempty"`
+	// Rewatches holds how often an entry has been watched again after
+	// finishing it, keyed by the proxer ID of the media.
+	Rewatches map[string]uint16 `json:"rewatches,omitempty"`
+	// RecommenderWeights are the weights of the builtin recommenders used
+	// for this user, see NewBlendedRecommender.
+	RecommenderWeights map[string]float64 `json:"recommenderWeights,omitempty"`
+}
+
+// EntryNote is a local note on a media entry, since the comment field on
+// proxer.me is awkward for keeping track of things such as "waiting for dub".
+type EntryNote struct {
+	Text string   `json:"text,omitempty"`
+	Tags []string `json:"tags,omitempty"`
+}
+
+// DefaultUserDataPath returns the path to the user data file inside of the
+// user config directory.
+func DefaultUserDataPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "proxerscrape", "userdata.json"), nil
+}
+
+// LoadUserData reads the user data from the given file. If the file doesn't
+// exist, empty user data is returned.
+func LoadUserData(path string) (*UserData, error) {
+	userData := &UserData{}
+	// A previous save might have been interrupted.
+	if err := atomicfile.Recover(path); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return userData, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, userData); err != nil {
+		return nil, err
+	}
+	return userData, nil
+}
+
+// Save atomically writes the user data to the given file, creating missing
+// directories.
+func (userData *UserData) Save(path string) error {
+	data, err := json.MarshalIndent(userData, "", "\t")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(path, data, 0o644)
+}
+
+// SetLanguageProgress records the amount of episodes watched in the given
+// language.
+func (userData *UserData) SetLanguageProgress(item *Media, language Language, episodesWatched uint16) {
+	if userData.LanguageProgress == nil {
+		userData.LanguageProgress = make(map[string]map[Language]uint16)
+	}
+	identifier := getCacheIdentifier(item)
+	if userData.LanguageProgress[identifier] == nil {
+		userData.LanguageProgress[identifier] = make(map[Language]uint16)
+	}
+	userData.LanguageProgress[identifier][language] = episodesWatched
+}
+
+// TrackedLanguages returns the languages progress has been recorded for via
+// SetLanguageProgress, in the order of Languages.
+func (userData *UserData) TrackedLanguages(item *Media) []Language {
+	var tracked []Language
+	for _, language := range Languages {
+		if _, present := userData.LanguageProgress[getCacheIdentifier(item)][language]; present {
+			tracked = append(tracked, language)
+		}
+	}
+	return tracked
+}
+
+// EpisodesWatched returns the amount of episodes watched in the given
+// language. If no progress has been tracked for the language, the progress
+// from the proxer.me profile is returned.
+func (userData *UserData) EpisodesWatched(item *Media, language Language) uint16 {
+	if progress, present := userData.LanguageProgress[getCacheIdentifier(item)][language]; present {
+		return progress
+	}
+	return item.EpisodesWatched
+}
+
+// Note returns the note for the given item or nil if there's none.
+func (userData *UserData) Note(item *Media) *EntryNote {
+	return userData.Notes[getCacheIdentifier(item)]
+}
+
+// note returns the note for the given item, creating it if necessary.
+func (userData *UserData) note(item *Media) *EntryNote {
+	if userData.Notes == nil {
+		userData.Notes = make(map[string]*EntryNote)
+	}
+	identifier := getCacheIdentifier(item)
+	if userData.Notes[identifier] == nil {
+		userData.Notes[identifier] = &EntryNote{}
+	}
+	return userData.Notes[identifier]
+}
+
+// removeIfEmpty removes the note of the given item if it holds no data.
+func (userData *UserData) removeIfEmpty(item *Media) {
+	identifier := getCacheIdentifier(item)
+	if note := userData.Notes[identifier]; note != nil && note.Text == "" && len(note.Tags) == 0 {
+		delete(userData.Notes, identifier)
+	}
+}
+
+// SetNote sets the text of the note for the given item. An empty text
+// removes the text, but keeps the tags.
+func (userData *UserData) SetNote(item *Media, text string) {
+	userData.note(item).Text = text
+	userData.removeIfEmpty(item)
+}
+
+// AddTag tags the given item. Tags are kept sorted and unique.
+func (userData *UserData) AddTag(item *Media, tag string) {
+	note := userData.note(item)
+	if note.HasTag(tag) {
+		return
+	}
+	note.Tags = append(note.Tags, tag)
+	sort.Strings(note.Tags)
+}
+
+// RemoveTag removes the tag from the given item.
+func (userData *UserData) RemoveTag(item *Media, tag string) {
+	note := userData.Note(item)
+	if note == nil {
+		return
+	}
+	for index, existing := range note.Tags {
+		if existing == tag {
+			note.Tags = append(note.Tags[:index], note.Tags[index+1:]...)
+			break
+		}
+	}
+	userData.removeIfEmpty(item)
+}
+
+// HasTag checks whether the note has the given tag.
+func (note *EntryNote) HasTag(tag string) bool {
+	if note == nil {
+		return false
+	}
+	for _, existing := range note.Tags {
+		if existing == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Tagged returns the proxer IDs of all entries with the given tag, sorted
+// ascending.
+func (userData *UserData) Tagged(tag string) []string {
+	var identifiers []string
+	for identifier, note := range userData.Notes {
+		if note.HasTag(tag) {
+			identifiers = append(identifiers, identifier)
+		}
+	}
+	sort.Strings(identifiers)
+	return identifiers
+}
+
+// Rewatched returns how often the given item has been watched again.
+func (userData *UserData) Rewatched(item *Media) uint16 {
+	return userData.Rewatches[getCacheIdentifier(item)]
+}
+
+// SetRewatches sets how often the given item has been watched again.
+func (userData *UserData) SetRewatches(item *Media, rewatches uint16) {
+	if userData.Rewatches == nil {
+		userData.Rewatches = make(map[string]uint16)
+	}
+	if rewatches == 0 {
+		delete(userData.Rewatches, getCacheIdentifier(item))
+		return
+	}
+	userData.Rewatches[getCacheIdentifier(item)] = rewatches
+}
+
+// AddRewatch counts another rewatch of the given item.
+func (userData *UserData) AddRewatch(item *Media) {
+	userData.SetRewatches(item, userData.Rewatched(item)+1)
+}
+
+// RecordRewatches counts a rewatch for every entry among the changes whose
+// progress has been reset after finishing it. The amount of recorded
+// rewatches is returned.
+func (userData *UserData) RecordRewatches(changes []Change) int {
+	recorded := 0
+	for _, change := range changes {
+		if change.Restarted() {
+			userData.AddRewatch(change.Current)
+			recorded++
+		}
+	}
+	return recorded
+}
+
+// TotalRewatches returns the sum of all rewatches.
+func (userData *UserData) TotalRewatches() uint64 {
+	var total uint64
+	for _, rewatches := range userData.Rewatches {
+		total += uint64(rewatches)
+	}
+	return total
+}
+
+// Weights returns a copy of the user's recommender weights, or of
+// DefaultRecommenderWeights if none are configured.
+func (userData *UserData) Weights() map[string]float64 {
+	source := userData.RecommenderWeights
+	if len(source) == 0 {
+		source = DefaultRecommenderWeights
+	}
+	weights := make(map[string]float64, len(source))
+	for name, weight := range source {
+		weights[name] = weight
+	}
+	return weights
+}
+
+// Recommender returns the recommender blended with the user's weights, see
+// Weights.
+func (userData *UserData) Recommender() (Recommender, error) {
+	return NewBlendedRecommender(userData.Weights())
+}
@@ -0,0 +1,69 @@
+package proxerscrape
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestUserData_LanguageProgress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "userdata.json")
+	userData, err := LoadUserData(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	item := &Media{ProxerURL: "/info/296", EpisodesWatched: 5}
+	userData.SetLanguageProgress(item, LanguageGermanDub, 3)
+	if err := userData.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	userData, err = LoadUserData(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if watched := userData.EpisodesWatched(item, LanguageGermanDub); watched != 3 {
+		t.Errorf("Dub progress = %d, instead of 3", watched)
+	}
+	if watched := userData.EpisodesWatched(item, LanguageEnglishSub); watched != 5 {
+		t.Errorf("Sub progress = %d, instead of 5", watched)
+	}
+	if tracked := userData.TrackedLanguages(item); len(tracked) != 1 || tracked[0] != LanguageGermanDub {
+		t.Errorf("Expected only the dub to be tracked, got %v", tracked)
+	}
+}
+
+func TestUserData_Notes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "userdata.json")
+	userData, err := LoadUserData(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	item := &Media{ProxerURL: "/info/296"}
+	other := &Media{ProxerURL: "/info/297"}
+	userData.SetNote(item, "Continue after the movie")
+	userData.AddTag(item, "waiting for dub")
+	userData.AddTag(item, "waiting for dub")
+	userData.AddTag(other, "waiting for dub")
+	if err := userData.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	userData, err = LoadUserData(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	note := userData.Note(item)
+	if note == nil || note.Text != "Continue after the movie" || len(note.Tags) != 1 {
+		t.Errorf("Unexpected note: %+v", note)
+	}
+	if tagged := userData.Tagged("waiting for dub"); len(tagged) != 2 || tagged[0] != "296" {
+		t.Errorf("Tagged = %v", tagged)
+	}
+
+	userData.RemoveTag(other, "waiting for dub")
+	if userData.Note(other) != nil {
+		t.Error("Empty note wasn't removed")
+	}
+}
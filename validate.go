@@ -0,0 +1,92 @@
+package proxerscrape
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IssueType is a kind of inconsistency in a watchlist.
+type IssueType string
+
+const (
+	// IssueDuplicate means the entry is listed in multiple categories.
+	IssueDuplicate IssueType = "duplicate"
+	// IssueProgressExceedsCount means more episodes have been watched than
+	// the entry has.
+	IssueProgressExceedsCount IssueType = "progress-exceeds-count"
+	// IssueFinishedButWatching means all episodes of an entry, that isn't
+	// airing anymore, have been watched, but it hasn't been moved to the
+	// watched category.
+	IssueFinishedButWatching IssueType = "finished-but-watching"
+)
+
+// Issue is an inconsistency of a single entry.
+type Issue struct {
+	Type  IssueType
+	Media *Media
+	// Categories are all categories listing the entry, for duplicates.
+	Categories []ListCategory
+}
+
+func (issue Issue) String() string {
+	switch issue.Type {
+	case IssueDuplicate:
+		categories := make([]string, 0, len(issue.Categories))
+		for _, category := range issue.Categories {
+			categories = append(categories, string(category))
+		}
+		return fmt.Sprintf("%s is listed in multiple categories: %s", issue.Media.Title, strings.Join(categories, ", "))
+	case IssueProgressExceedsCount:
+		return fmt.Sprintf("%s has %d of %d episodes watched", issue.Media.Title, issue.Media.EpisodesWatched, issue.Media.EpisodeCount)
+	case IssueFinishedButWatching:
+		return fmt.Sprintf("%s has been finished, but is still being watched", issue.Media.Title)
+	}
+	return fmt.Sprintf("%s: %s", issue.Media.Title, issue.Type)
+}
+
+// ValidationReport holds all issues found in a watchlist, in the order of the
+// categories.
+type ValidationReport []Issue
+
+// Count returns the amount of issues of the given type.
+func (report ValidationReport) Count(issueType IssueType) int {
+	var count int
+	for _, issue := range report {
+		if issue.Type == issueType {
+			count++
+		}
+	}
+	return count
+}
+
+// Validate checks the watchlist for inconsistencies, such as entries listed
+// in multiple categories. Duplicates are reported once, for their first
+// occurrence.
+func (watchlist *Watchlist) Validate() ValidationReport {
+	categoriesByID := make(map[uint][]ListCategory)
+	for _, category := range ListCategories {
+		for _, item := range watchlist.Category(category).Data {
+			categoriesByID[item.mediaID()] = append(categoriesByID[item.mediaID()], category)
+		}
+	}
+
+	var report ValidationReport
+	reported := make(map[uint]bool)
+	for _, category := range ListCategories {
+		for _, item := range watchlist.Category(category).Data {
+			identifier := item.mediaID()
+			if categories := categoriesByID[identifier]; len(categories) > 1 && !reported[identifier] {
+				reported[identifier] = true
+				report = append(report, Issue{Type: IssueDuplicate, Media: item, Categories: categories})
+			}
+			if item.EpisodeCount > 0 && item.EpisodesWatched > item.EpisodeCount {
+				report = append(report, Issue{Type: IssueProgressExceedsCount, Media: item})
+			}
+			if category == CategoryCurrentlyWatching && item.Status != StatusAiring &&
+				item.EpisodeCount > 0 && item.EpisodesWatched >= item.EpisodeCount {
+				report = append(report, Issue{Type: IssueFinishedButWatching, Media: item})
+			}
+		}
+	}
+	return report
+}
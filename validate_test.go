@@ -0,0 +1,38 @@
+package proxerscrape
+
+import "testing"
+
+func TestWatchlist_Validate(t *testing.T) {
+	watchlist := &Watchlist{
+		Watched: WatchlistCategory{Data: []*Media{
+			{ProxerURL: "/info/1", Title: "Duplicate", EpisodesWatched: 12, EpisodeCount: 12},
+			{ProxerURL: "/info/2", Title: "Overwatched", EpisodesWatched: 13, EpisodeCount: 12},
+		}},
+		CurrentlyWatching: WatchlistCategory{Data: []*Media{
+			{ProxerURL: "/info/3", Title: "Done", EpisodesWatched: 24, EpisodeCount: 24, Status: StatusFinished},
+			{ProxerURL: "/info/4", Title: "Caught up", EpisodesWatched: 6, EpisodeCount: 6, Status: StatusAiring},
+			{ProxerURL: "/info/5", Title: "Unknown count", EpisodesWatched: 6},
+		}},
+		ToWatch: WatchlistCategory{Data: []*Media{
+			{ProxerURL: "/info/1", Title: "Duplicate"},
+		}},
+	}
+
+	report := watchlist.Validate()
+	if len(report) != 3 {
+		t.Fatalf("Expected 3 issues, got %v", report)
+	}
+	expected := []string{
+		"Duplicate is listed in multiple categories: watched, planned",
+		"Overwatched has 13 of 12 episodes watched",
+		"Done has been finished, but is still being watched",
+	}
+	for index, issue := range report {
+		if issue.String() != expected[index] {
+			t.Errorf("Got %q, expected %q", issue.String(), expected[index])
+		}
+	}
+	if report.Count(IssueDuplicate) != 1 {
+		t.Errorf("Expected 1 duplicate, got %d", report.Count(IssueDuplicate))
+	}
+}
@@ -0,0 +1,102 @@
+package proxerscrape
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// WarmResult summarizes a WarmCache run.
+type WarmResult struct {
+	// Fetched is the amount of info pages that have been retrieved.
+	Fetched int
+	// Failed is the amount of info pages that couldn't be retrieved, for
+	// example because they don't exist anymore.
+	Failed int
+	// Remaining is the amount of info pages that haven't been retrieved,
+	// since the budget has been used up or the ratelimit has been hit.
+	Remaining int
+}
+
+// WarmCache retrieves the info pages of the given entries that aren't cached
+// or are stale, until the budget is used up. A budget of 0 means no limit.
+// No request is started that would have to wait for the ratelimit beyond the
+// budget. Since every retrieved page is cached right away, calling this again
+// continues where the previous run stopped. If the captcha wall is hit and
+// its cooldown would outlast the budget, the run stops instead of waiting.
+// If the ratelimit stops the run, the current entry is deferred, see
+// Cache.Defer, and ErrRateLimited is returned. Other failures only count as
+// Failed.
+func (cache *Cache) WarmCache(items []*Media, budget time.Duration, progress ProgressFunc) (WarmResult, error) {
+	var result WarmResult
+	var pending []*Media
+	for _, item := range items {
+		required, err := cache.requiresMediaRequest(item)
+		if err != nil {
+			return result, err
+		}
+		if required {
+			pending = append(pending, item)
+		}
+	}
+
+	var deadline time.Time
+	if budget > 0 {
+		deadline = time.Now().Add(budget)
+	}
+	reporter := newProgressReporter(progress, len(pending))
+	for index, item := range pending {
+		if !deadline.IsZero() && !time.Now().Add(cache.mediaDelay(item)).Before(deadline) {
+			result.Remaining = len(pending) - index
+			return result, nil
+		}
+
+		reporter.report(ProgressEvent{Type: ProgressStarted, Item: item})
+		err := cache.retrieveMediaUntil(item, deadline)
+		if errors.Is(err, ErrRateLimited) {
+			result.Remaining = len(pending) - index
+			return result, cache.Defer(DeferMedia(item), err)
+		}
+		if err != nil {
+			result.Failed++
+			reporter.report(ProgressEvent{Type: ProgressFailed, Item: item, Err: err})
+			continue
+		}
+		result.Fetched++
+		reporter.report(ProgressEvent{Type: ProgressDone, Item: item})
+	}
+	return result, nil
+}
+
+// retrieveMediaUntil retrieves the info page of the item via the limiter of
+// its type, without cooling down past the deadline, see retrieveEntry.
+func (cache *Cache) retrieveMediaUntil(item *Media, deadline time.Time) error {
+	limiter := cache.MangaQueryRatelimiter
+	if item.Type.IsAnime() {
+		limiter = cache.AnimeQueryRatelimiter
+	}
+	cacheKey, err := mediaCacheKey(item)
+	if err != nil {
+		return err
+	}
+	reader, _, _, err := cache.retrieveEntry(CacheEntryMedia, item, cacheKey, limiter, func(header http.Header) (*http.Response, error) {
+		return cache.QueryMedia(item, header)
+	}, false, deadline)
+	if err != nil {
+		return err
+	}
+	return reader.Close()
+}
+
+// mediaDelay returns how long retrieving the info page of the given item
+// currently has to wait for the ratelimit.
+func (cache *Cache) mediaDelay(item *Media) time.Duration {
+	limiter := cache.MangaQueryRatelimiter
+	if item.Type.IsAnime() {
+		limiter = cache.AnimeQueryRatelimiter
+	}
+	if limiter == nil {
+		return 0
+	}
+	return limiter.Delay()
+}
@@ -0,0 +1,69 @@
+package proxerscrape
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCache_WarmCache(t *testing.T) {
+	cache, queries := newTestCache(map[string]string{"/info/1": "one", "/info/2": "two", "/info/3": "three"})
+	items := []*Media{
+		{ProxerURL: "/info/1", Type: MediaTypeSeries},
+		{ProxerURL: "/info/2", Type: MediaTypeSeries},
+		{ProxerURL: "/info/3", Type: MediaTypeSeries},
+	}
+	if reader, _, err := cache.RetrieveAnimeRawData(items[0]); err != nil {
+		t.Fatal(err)
+	} else {
+		reader.Close()
+	}
+
+	// Only a single request fits into the budget.
+	cache.AnimeQueryRatelimiter = NewLimiter(1, time.Hour)
+	var done int
+	result, err := cache.WarmCache(items, time.Minute, func(event ProgressEvent) {
+		if event.Type == ProgressDone {
+			done++
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Fetched != 1 || result.Remaining != 1 || done != 1 || *queries != 2 {
+		t.Errorf("Unexpected result %+v after %d queries", result, *queries)
+	}
+
+	// The next run continues with the remaining entry.
+	cache.AnimeQueryRatelimiter = nil
+	result, err = cache.WarmCache(items, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Fetched != 1 || result.Remaining != 0 || *queries != 3 {
+		t.Errorf("Unexpected result %+v after %d queries", result, *queries)
+	}
+}
+
+func TestCache_WarmCache_Cooldown(t *testing.T) {
+	captcha := `<script src="//www.google.com/recaptcha/api.js"></script>`
+	cache, queries := newTestCache(map[string]string{"/info/1": captcha, "/info/2": "two"})
+	cache.AnimeQueryRatelimiter = NewLimiter(10, time.Minute)
+	items := []*Media{
+		{ProxerURL: "/info/1", Type: MediaTypeSeries},
+		{ProxerURL: "/info/2", Type: MediaTypeSeries},
+	}
+
+	// The cooldown after the captcha outlasts the budget, so the run stops
+	// instead of waiting for it.
+	result, err := cache.WarmCache(items, time.Minute, nil)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected ErrRateLimited, got %v", err)
+	}
+	if result.Fetched != 0 || result.Remaining != 2 || *queries != 1 {
+		t.Errorf("Unexpected result %+v after %d queries", result, *queries)
+	}
+	if cache.Cooldown().IsZero() {
+		t.Error("Expected the limiter to be cooling down")
+	}
+}
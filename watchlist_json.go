@@ -0,0 +1,77 @@
+package proxerscrape
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// watchlistJSONVersion is increased whenever the JSON format changes in an
+// incompatible way.
+const watchlistJSONVersion = 1
+
+type watchlistJSON struct {
+	Version    int                     `json:"version"`
+	Categories []watchlistCategoryJSON `json:"categories"`
+}
+
+type watchlistCategoryJSON struct {
+	Category ListCategory `json:"category"`
+	// ExtraDataLoaded is kept, so that an imported watchlist isn't enriched
+	// a second time.
+	ExtraDataLoaded bool     `json:"extraDataLoaded"`
+	Entries         []*Media `json:"entries"`
+}
+
+// ExportWatchlistJSON writes the watchlist, including any extra data already
+// loaded, as JSON. This allows analysing a watchlist offline without having
+// to scrape it again, see ImportWatchlistJSON.
+func ExportWatchlistJSON(writer io.Writer, watchlist *Watchlist) error {
+	document := watchlistJSON{Version: watchlistJSONVersion}
+	for _, category := range ListCategories {
+		watchlistCategory := watchlist.Category(category)
+		entries := watchlistCategory.Data
+		if entries == nil {
+			entries = []*Media{}
+		}
+		document.Categories = append(document.Categories, watchlistCategoryJSON{
+			Category:        category,
+			ExtraDataLoaded: watchlistCategory.extraDataLoaded,
+			Entries:         entries,
+		})
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(document)
+}
+
+// ImportWatchlistJSON reads a watchlist written by ExportWatchlistJSON.
+func ImportWatchlistJSON(reader io.Reader) (Watchlist, error) {
+	var watchlist Watchlist
+	var document watchlistJSON
+	if err := json.NewDecoder(reader).Decode(&document); err != nil {
+		return watchlist, err
+	}
+	if document.Version != watchlistJSONVersion {
+		return watchlist, fmt.Errorf("unsupported watchlist version %d", document.Version)
+	}
+
+	for _, categoryDocument := range document.Categories {
+		watchlistCategory := watchlist.Category(categoryDocument.Category)
+		if watchlistCategory == nil {
+			return watchlist, fmt.Errorf("unknown category '%s'", categoryDocument.Category)
+		}
+		for index, item := range categoryDocument.Entries {
+			if item == nil {
+				return watchlist, fmt.Errorf("entry %d of category '%s' is empty", index, categoryDocument.Category)
+			}
+			if !proxerIDRegex.MatchString(item.ProxerURL) {
+				return watchlist, fmt.Errorf("entry '%s' has an invalid url '%s'", item.Title, item.ProxerURL)
+			}
+		}
+		watchlistCategory.Data = categoryDocument.Entries
+		watchlistCategory.extraDataLoaded = categoryDocument.ExtraDataLoaded
+	}
+	return watchlist, nil
+}
@@ -0,0 +1,47 @@
+package proxerscrape
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWatchlistJSON_RoundTrip(t *testing.T) {
+	watchlist := Watchlist{}
+	watchlist.Watched = WatchlistCategory{
+		Data: []*Media{{
+			ProxerURL:     "/info/296",
+			Title:         "Tsurune",
+			Type:          MediaTypeSeries,
+			Category:      CategoryWatched,
+			Rating:        8.4,
+			Generes:       []string{"Drama", "Sport"},
+			ReleasePeriod: ReleasePeriod{FromSeason: Q4, FromYear: 2018},
+		}},
+		extraDataLoaded: true,
+	}
+	watchlist.ToWatch.Data = []*Media{{ProxerURL: "/info/297", Title: "Pending", Category: CategoryToWatch}}
+
+	var buffer bytes.Buffer
+	if err := ExportWatchlistJSON(&buffer, &watchlist); err != nil {
+		t.Fatal(err)
+	}
+	imported, err := ImportWatchlistJSON(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(imported.Watched, watchlist.Watched) || !reflect.DeepEqual(imported.ToWatch, watchlist.ToWatch) {
+		t.Errorf("Imported watchlist differs:\n%+v\n%+v", imported.Watched.Data[0], watchlist.Watched.Data[0])
+	}
+	if !imported.Watched.extraDataLoaded || imported.ToWatch.extraDataLoaded {
+		t.Error("Loaded state wasn't kept")
+	}
+
+	if _, err := ImportWatchlistJSON(strings.NewReader(`{"version":1,"categories":[{"category":"watched","entries":[{"proxerUrl":"/invalid"}]}]}`)); err == nil {
+		t.Error("Expected error for invalid url")
+	}
+	if _, err := ImportWatchlistJSON(strings.NewReader(`{"version":1,"categories":[{"category":"watched","entries":[null]}]}`)); err == nil {
+		t.Error("Expected error for empty entry")
+	}
+}
@@ -0,0 +1,76 @@
+package proxerscrape
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AverageEpisodeDurations are used for estimating the watch time of entries
+// whose detail page doesn't state the episode duration, or whose extra data
+// hasn't been loaded.
+var AverageEpisodeDurations = map[MediaType]time.Duration{
+	MediaTypeSeries:  24 * time.Minute,
+	MediaTypeOVA:     25 * time.Minute,
+	MediaTypeSpecial: 10 * time.Minute,
+	MediaTypeMovie:   90 * time.Minute,
+}
+
+var (
+	durationHoursRegex   = regexp.MustCompile(`(\d+)\s*(?:h|std)`)
+	durationMinutesRegex = regexp.MustCompile(`(\d+)\s*(?:m|min)`)
+)
+
+// parseEpisodeDuration parses durations as displayed on the detail page, such
+// as "ca. 24 Min." or "1 Std. 50 Min.". 0 is returned if the text contains
+// no duration.
+func parseEpisodeDuration(text string) time.Duration {
+	text = strings.ToLower(text)
+	var duration time.Duration
+	if match := durationHoursRegex.FindStringSubmatch(text); match != nil {
+		hours, _ := strconv.Atoi(match[1])
+		duration += time.Duration(hours) * time.Hour
+	}
+	if match := durationMinutesRegex.FindStringSubmatch(text); match != nil {
+		minutes, _ := strconv.Atoi(match[1])
+		duration += time.Duration(minutes) * time.Minute
+	}
+	return duration
+}
+
+// EstimatedEpisodeDuration returns the episode duration stated on the detail
+// page if known and the average duration for the type otherwise.
+func (item *Media) EstimatedEpisodeDuration() time.Duration {
+	if item.EpisodeDuration > 0 {
+		return item.EpisodeDuration
+	}
+	return AverageEpisodeDurations[item.Type]
+}
+
+// EstimatedWatchTime returns how long watching the given amount of episodes
+// takes.
+func (item *Media) EstimatedWatchTime(episodes uint16) time.Duration {
+	return time.Duration(episodes) * item.EstimatedEpisodeDuration()
+}
+
+// EstimatedWatchTimeLeft returns how long watching the remaining episodes
+// takes, based on the progress in the profile. Entries with an unknown
+// episode count are assumed to have a single episode, which is usually the
+// case for movies that haven't aired yet.
+func (item *Media) EstimatedWatchTimeLeft() time.Duration {
+	return item.EstimatedWatchTimeAfter(item.EpisodesWatched)
+}
+
+// EstimatedWatchTimeAfter is like EstimatedWatchTimeLeft, but allows using
+// progress that isn't tracked by the profile, see UserData.EpisodesWatched.
+func (item *Media) EstimatedWatchTimeAfter(episodesWatched uint16) time.Duration {
+	episodeCount := item.EpisodeCount
+	if episodeCount == 0 {
+		episodeCount = 1
+	}
+	if episodesWatched >= episodeCount {
+		return 0
+	}
+	return item.EstimatedWatchTime(episodeCount - episodesWatched)
+}
@@ -0,0 +1,40 @@
+package proxerscrape
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseEpisodeDuration(t *testing.T) {
+	for text, expected := range map[string]time.Duration{
+		"ca. 24 Min.":     24 * time.Minute,
+		"1 Std. 50 Min.":  110 * time.Minute,
+		"2h":              2 * time.Hour,
+		"Nicht bekannt":   0,
+		"  23 min / Ep. ": 23 * time.Minute,
+	} {
+		if actual := parseEpisodeDuration(text); actual != expected {
+			t.Errorf("parseEpisodeDuration(%q) = %s, expected %s", text, actual, expected)
+		}
+	}
+}
+
+func TestEstimatedWatchTimeLeft(t *testing.T) {
+	series := &Media{Type: MediaTypeSeries, EpisodeCount: 12, EpisodesWatched: 2}
+	if left := series.EstimatedWatchTimeLeft(); left != 10*AverageEpisodeDurations[MediaTypeSeries] {
+		t.Errorf("Unexpected estimate for series: %s", left)
+	}
+	series.EpisodeDuration = 12 * time.Minute
+	if left := series.EstimatedWatchTimeLeft(); left != 2*time.Hour {
+		t.Errorf("Scraped duration not used: %s", left)
+	}
+
+	movie := &Media{Type: MediaTypeMovie}
+	if left := movie.EstimatedWatchTimeLeft(); left != AverageEpisodeDurations[MediaTypeMovie] {
+		t.Errorf("Unexpected estimate for movie without episode count: %s", left)
+	}
+	movie.EpisodeCount, movie.EpisodesWatched = 1, 1
+	if left := movie.EstimatedWatchTimeLeft(); left != 0 {
+		t.Errorf("Watched movie has time left: %s", left)
+	}
+}